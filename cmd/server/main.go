@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,10 +11,18 @@ import (
 	"syscall"
 	"time"
 
+	"user_mgmt_go/internal/buildinfo"
 	"user_mgmt_go/internal/config"
 	"user_mgmt_go/internal/handlers"
+	"user_mgmt_go/internal/importer"
+	"user_mgmt_go/internal/mailer"
 	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/oidc"
 	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/internal/scanner"
+	"user_mgmt_go/internal/scheduler"
+	"user_mgmt_go/internal/storage"
 	"user_mgmt_go/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -65,10 +74,13 @@ type Application struct {
 	middlewareManager *middleware.MiddlewareManager
 	handlerManager    *handlers.HandlerManager
 	jwtManager        *utils.JWTManager
+	scheduler         *scheduler.Scheduler
+	startedAt         time.Time
 }
 
 func main() {
 	log.Println("🚀 Starting User Management System...")
+	printVersionInfo()
 
 	// Initialize application
 	app, err := initializeApplication()
@@ -76,18 +88,21 @@ func main() {
 		log.Fatalf("❌ Failed to initialize application: %v", err)
 	}
 
+	app.logStartup()
+
 	// Start server in a goroutine
 	go func() {
 		if err := app.start(); err != nil && err != http.ErrServerClosed {
+			app.logShutdown("server_error", err)
 			log.Fatalf("❌ Failed to start server: %v", err)
 		}
 	}()
 
 	// Wait for interrupt signal
-	app.waitForShutdown()
+	sig := app.waitForShutdown()
 
 	// Graceful shutdown
-	if err := app.shutdown(); err != nil {
+	if err := app.shutdown(sig.String()); err != nil {
 		log.Printf("❌ Error during shutdown: %v", err)
 		os.Exit(1)
 	}
@@ -106,8 +121,54 @@ func initializeApplication() (*Application, error) {
 	// Set Gin mode based on configuration
 	gin.SetMode(cfg.Server.GinMode)
 
+	if err := cfg.DPoP.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid dpop configuration: %w", err)
+	}
+	if err := cfg.Anomaly.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid anomaly configuration: %w", err)
+	}
+	if err := cfg.Social.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid social configuration: %w", err)
+	}
+	if err := cfg.Email.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid email configuration: %w", err)
+	}
+	if err := cfg.APIKeys.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid api_keys configuration: %w", err)
+	}
+	if err := cfg.Registration.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid registration configuration: %w", err)
+	}
+	if err := cfg.Scanner.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scanner configuration: %w", err)
+	}
+	if err := cfg.SoftLaunch.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid soft_launch configuration: %w", err)
+	}
+	if err := cfg.LogExport.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid log_export configuration: %w", err)
+	}
+	if err := cfg.Tracing.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid tracing configuration: %w", err)
+	}
+	if err := cfg.AuditSubscriptions.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid audit_subscriptions configuration: %w", err)
+	}
+	if err := cfg.LogIngest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid log_ingest configuration: %w", err)
+	}
+	if err := cfg.Reports.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid reports configuration: %w", err)
+	}
+	if err := cfg.Sandbox.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid sandbox configuration: %w", err)
+	}
+	if cfg.Sandbox.Contains(cfg.Admin.Email) {
+		return nil, fmt.Errorf("invalid sandbox configuration: email_domain covers the admin email %s, which would be purged", cfg.Admin.Email)
+	}
+
 	// Initialize JWT manager
-	jwtManager := utils.NewJWTManager(cfg.JWT.Secret, cfg.JWT.Expiry)
+	jwtManager := utils.NewJWTManager(cfg.JWT.Secret, cfg.JWT.Expiry, cfg.JWT.Leeway)
 
 	// Initialize repository manager (database connections)
 	repoManager, err := repository.NewRepositoryManager(&cfg)
@@ -118,8 +179,80 @@ func initializeApplication() (*Application, error) {
 	// Initialize middleware manager
 	middlewareManager := middleware.NewMiddlewareManager(&cfg, jwtManager, repoManager)
 
+	// Build log redaction policy for non-admin log viewers
+	logRedaction, err := models.NewLogRedactionPolicy(cfg.LogRedaction.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log redaction configuration: %w", err)
+	}
+
+	// Build user import field mappings
+	importMappings, err := importer.NewMappings(cfg.Import.Mappings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid import configuration: %w", err)
+	}
+
+	// Initialize user asset storage
+	assetStorage, err := storage.NewLocal(cfg.Storage.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize asset storage: %w", err)
+	}
+
+	// Initialize the OpenID Connect provider
+	var oidcProvider *oidc.Provider
+	if cfg.OIDC.Enabled {
+		oidcProvider, err = oidc.NewProvider(cfg.OIDC)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize OIDC provider: %w", err)
+		}
+		log.Printf("🔑 OIDC provider enabled with issuer %s", oidcProvider.Issuer())
+	}
+
+	// Initialize ID token verifiers for linkable external identity providers
+	identityProviders := make(map[string]*utils.IDTokenVerifier, len(cfg.Social.Providers))
+	for _, provider := range cfg.Social.Providers {
+		identityProviders[provider.Name] = utils.NewIDTokenVerifier(provider.Issuer, provider.ClientID, provider.JWKSURL)
+	}
+
+	// Initialize email notices for admin changes to user accounts
+	var accountNotifier *mailer.AccountNotifier
+	if cfg.Email.Enabled && cfg.Email.NotifyAccountChanges {
+		accountNotifier = mailer.NewAccountNotifier(mailer.NewSMTPMailer(cfg.Email), cfg.Email.SupportContact, logRedaction)
+	} else if cfg.Email.NotifyAccountChanges {
+		log.Println("⚠️  Account change notices are enabled but email is disabled; users will not be notified")
+	}
+
 	// Initialize handler manager
-	handlerManager := handlers.NewHandlerManager(jwtManager, repoManager, middlewareManager)
+	handlerManager := handlers.NewHandlerManager(
+		jwtManager,
+		repoManager,
+		middlewareManager,
+		logRedaction,
+		importMappings,
+		assetStorage,
+		scanner.New(cfg.Scanner),
+		cfg.Storage.AvatarMaxSize*1024,
+		cfg.Storage.AssetMaxAge,
+		oidcProvider,
+		cfg.DPoP.Mode,
+		cfg.Directory,
+		identityProviders,
+		accountNotifier,
+		cfg.APIKeys,
+		&cfg,
+	)
+
+	// Self-check admin panel templates so broken ones fail at startup, not on first visit
+	if err := handlerManager.AdminPanelHandler.CheckTemplates(); err != nil {
+		if cfg.Server.GinMode == "release" {
+			return nil, fmt.Errorf("admin panel template check failed: %w", err)
+		}
+		log.Printf("⚠️  Admin panel template check failed, affected pages will show an error page: %v", err)
+	} else {
+		log.Println("✅ Admin panel templates OK")
+	}
+
+	// Warm up connections and caches before the server starts listening
+	warmup(&cfg, repoManager, middlewareManager, identityProviders)
 
 	// Create Gin router
 	router := gin.New()
@@ -134,6 +267,11 @@ func initializeApplication() (*Application, error) {
 		log.Println("📚 Swagger documentation enabled at /swagger/index.html")
 	}
 
+	// Generate the route manifest from the registered routes
+	if err := handlerManager.BuildRouteManifest(router); err != nil {
+		log.Printf("⚠️  Route manifest is incomplete: %v", err)
+	}
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:           fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
@@ -144,6 +282,92 @@ func initializeApplication() (*Application, error) {
 		MaxHeaderBytes: 1 << 20, // 1MB
 	}
 
+	// Register scheduled jobs
+	var jobScheduler *scheduler.Scheduler
+	if cfg.Scheduler.Enabled {
+		jobScheduler = scheduler.New(repoManager.Repos.Job, repoManager.Repos.Job)
+		jobScheduler.Register(scheduler.Job{
+			Name:     "maintenance",
+			Interval: cfg.Scheduler.MaintenanceInterval,
+			Timeout:  10 * time.Minute,
+			Run:      repoManager.RunMaintenance,
+		})
+		if cfg.Roles.AdminGrantCooldown > 0 {
+			jobScheduler.Register(scheduler.Job{
+				Name:     "role_changes",
+				Interval: time.Minute,
+				Timeout:  time.Minute,
+				Run:      repoManager.ApplyDueRoleChanges,
+			})
+		}
+		if cfg.Anomaly.Enabled {
+			jobScheduler.Register(scheduler.Job{
+				Name:     "log_anomalies",
+				Interval: cfg.Anomaly.Window,
+				Run:      repoManager.DetectLogAnomalies,
+			})
+		}
+		if cfg.APIKeys.RotationPeriod > 0 {
+			jobScheduler.Register(scheduler.Job{
+				Name:     "api_key_rotation",
+				Interval: time.Hour,
+				Run:      repoManager.RemindAPIKeyRotations,
+			})
+		}
+		if cfg.LogExport.Enabled {
+			jobScheduler.Register(scheduler.Job{
+				Name:     "log_export",
+				Interval: cfg.LogExport.Interval,
+				Run:      repoManager.ExportLogs,
+			})
+		}
+		if cfg.AuditSubscriptions.Enabled {
+			jobScheduler.Register(scheduler.Job{
+				Name:     "audit_subscriptions",
+				Interval: cfg.AuditSubscriptions.Interval,
+				Run:      repoManager.NotifyAuditSubscribers,
+			})
+		}
+		if cfg.Reports.Enabled {
+			jobScheduler.Register(scheduler.Job{
+				Name:     "reports",
+				Interval: cfg.Reports.CheckInterval,
+				Timeout:  10 * time.Minute,
+				Run:      repoManager.GenerateDueReports,
+			})
+		}
+		if cfg.Sandbox.Enabled {
+			jobScheduler.Register(scheduler.Job{
+				Name:     "sandbox_purge",
+				Interval: time.Hour,
+				Timeout:  10 * time.Minute,
+				Run:      repoManager.PurgeSandbox,
+			})
+		}
+	} else {
+		if cfg.Roles.AdminGrantCooldown > 0 {
+			log.Println("⚠️  Admin grant cooldown is set but the scheduler is disabled; scheduled role changes will not be applied")
+		}
+		if cfg.Anomaly.Enabled {
+			log.Println("⚠️  Anomaly detection is enabled but the scheduler is disabled; log volume will not be checked")
+		}
+		if cfg.APIKeys.RotationPeriod > 0 {
+			log.Println("⚠️  API key rotation is configured but the scheduler is disabled; rotation reminders will not be sent")
+		}
+		if cfg.LogExport.Enabled {
+			log.Println("⚠️  Log export is enabled but the scheduler is disabled; logs will not be exported")
+		}
+		if cfg.AuditSubscriptions.Enabled {
+			log.Println("⚠️  Audit subscriptions are enabled but the scheduler is disabled; admins will not be notified")
+		}
+		if cfg.Reports.Enabled {
+			log.Println("⚠️  Scheduled reports are enabled but the scheduler is disabled; reports will not be generated")
+		}
+		if cfg.Sandbox.Enabled {
+			log.Println("⚠️  The sandbox is enabled but the scheduler is disabled; sandbox users will not be purged")
+		}
+	}
+
 	app := &Application{
 		config:            &cfg,
 		server:            server,
@@ -151,6 +375,8 @@ func initializeApplication() (*Application, error) {
 		middlewareManager: middlewareManager,
 		handlerManager:    handlerManager,
 		jwtManager:        jwtManager,
+		scheduler:         jobScheduler,
+		startedAt:         time.Now(),
 	}
 
 	log.Printf("✅ Application initialized successfully")
@@ -159,6 +385,33 @@ func initializeApplication() (*Application, error) {
 	return app, nil
 }
 
+// warmup pre-establishes and primes database connections and preloads
+// config-derived caches, so the first requests after a deploy do not pay for
+// them. Failures only cost that latency, so they are logged, not fatal.
+func warmup(cfg *config.Config, repoManager *repository.RepositoryManager, middlewareManager *middleware.MiddlewareManager, identityProviders map[string]*utils.IDTokenVerifier) {
+	if cfg.Server.WarmupConnections <= 0 {
+		return
+	}
+
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.WarmupTimeout)
+	defer cancel()
+
+	if err := repoManager.Warmup(ctx, cfg.Server.WarmupConnections); err != nil {
+		log.Printf("⚠️  Database warmup incomplete: %v", err)
+	}
+	if _, err := middlewareManager.ProfileRequirements().Required(ctx); err != nil {
+		log.Printf("⚠️  Failed to preload required profile fields: %v", err)
+	}
+	for name, verifier := range identityProviders {
+		if err := verifier.Preload(ctx); err != nil {
+			log.Printf("⚠️  Failed to preload %s signing keys: %v", name, err)
+		}
+	}
+
+	log.Printf("✅ Warmup finished in %s", time.Since(started).Round(time.Millisecond))
+}
+
 // start begins the HTTP server
 func (app *Application) start() error {
 	log.Printf("🌐 Starting HTTP server on %s", app.server.Addr)
@@ -172,21 +425,28 @@ func (app *Application) start() error {
 	// Print available routes summary
 	app.printRoutesSummary()
 
+	// Start scheduled jobs
+	if app.scheduler != nil {
+		app.scheduler.Start()
+	}
+
 	return app.server.ListenAndServe()
 }
 
-// waitForShutdown waits for interrupt signals
-func (app *Application) waitForShutdown() {
+// waitForShutdown waits for interrupt signals and returns the one received
+func (app *Application) waitForShutdown() os.Signal {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	sig := <-quit
 	log.Printf("🛑 Received shutdown signal: %v", sig)
+	return sig
 }
 
-// shutdown gracefully stops the application
-func (app *Application) shutdown() error {
+// shutdown gracefully stops the application, recording why in the audit log
+func (app *Application) shutdown(reason string) error {
 	log.Println("🔄 Initiating graceful shutdown...")
+	app.logShutdown(reason, nil)
 
 	// Create context with timeout for shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -199,6 +459,12 @@ func (app *Application) shutdown() error {
 		return err
 	}
 
+	// Stop scheduled jobs before closing the connections they use
+	if app.scheduler != nil {
+		log.Println("🔄 Stopping scheduled jobs...")
+		app.scheduler.Stop()
+	}
+
 	// Close repository connections
 	log.Println("🔄 Closing database connections...")
 	if err := app.repoManager.Close(); err != nil {
@@ -217,10 +483,94 @@ func (app *Application) shutdown() error {
 	return nil
 }
 
+// instanceName identifies this instance in lifecycle events, so restarts of
+// one instance can be told apart from others sharing the audit log
+func instanceName() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "unknown"
+	}
+	return hostname
+}
+
+// lifecycleDetails returns the details shared by startup and shutdown events
+func (app *Application) lifecycleDetails() map[string]interface{} {
+	info := buildinfo.Get()
+	details := map[string]interface{}{
+		"instance":   instanceName(),
+		"version":    info.Version,
+		"git_commit": info.GitCommit,
+		"build_date": info.BuildDate,
+		"pid":        os.Getpid(),
+	}
+	if hash, err := app.config.SettingsHash(); err != nil {
+		log.Printf("⚠️  Failed to hash configuration: %v", err)
+	} else {
+		details["config_hash"] = hash
+	}
+	return details
+}
+
+// logStartup records a SYSTEM_STARTUP event. If this instance's last
+// lifecycle event is a startup, the previous run never shut down cleanly and
+// the reason is "crash_recovery".
+func (app *Application) logStartup() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	details := app.lifecycleDetails()
+	reason := "start"
+	previous, err := app.repoManager.Repos.Log.GetLatestLifecycleEvent(ctx, details["instance"].(string))
+	if err != nil {
+		log.Printf("⚠️  Failed to read previous lifecycle event: %v", err)
+	} else if previous != nil && previous.Event == models.SystemStartup {
+		reason = "crash_recovery"
+		details["previous_startup_at"] = previous.Timestamp
+		details["previous_version"] = previous.AppVersion
+	}
+	details["reason"] = reason
+
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		Event:   models.SystemStartup,
+		Action:  "SYSTEM_STARTUP",
+		Details: details,
+	})
+	if err := app.repoManager.Repos.Log.Create(ctx, logEntry); err != nil {
+		log.Printf("⚠️  Failed to log startup: %v", err)
+		return
+	}
+	if reason == "crash_recovery" {
+		log.Println("⚠️  Previous run did not shut down cleanly")
+	}
+}
+
+// logShutdown records a SYSTEM_SHUTDOWN event. It writes synchronously, as
+// the async pipeline is closed during shutdown.
+func (app *Application) logShutdown(reason string, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	details := app.lifecycleDetails()
+	details["reason"] = reason
+	details["uptime_seconds"] = int64(time.Since(app.startedAt).Seconds())
+
+	req := models.UserLogCreateRequest{
+		Event:   models.SystemShutdown,
+		Action:  "SYSTEM_SHUTDOWN",
+		Details: details,
+	}
+	if cause != nil {
+		req.Error = cause.Error()
+	}
+	if err := app.repoManager.Repos.Log.Create(ctx, models.NewUserLog(req)); err != nil {
+		log.Printf("⚠️  Failed to log shutdown: %v", err)
+	}
+}
+
 // printRoutesSummary displays available API routes
 func (app *Application) printRoutesSummary() {
 	log.Println("📋 Available API Routes:")
-	routes := app.handlerManager.GetRouteSummary()
+	routes := app.handlerManager.RouteManifestByCategory()
 	
 	for category, endpoints := range routes {
 		log.Printf("  📂 %s:", category)
@@ -276,7 +626,6 @@ func printBanner() {
 	banner := `
 ╔══════════════════════════════════════════════════════════════════╗
 ║                    User Management System                        ║
-║                         Version 1.0.0                           ║
 ║                                                                  ║
 ║  🔐 JWT Authentication    📊 Async Logging    🛡️  Security       ║
 ║  👥 User Management      🔍 Advanced Search   📈 Analytics       ║
@@ -291,16 +640,13 @@ func init() {
 	printBanner()
 }
 
-// Version information
-var (
-	Version   = "1.0.0"
-	BuildTime = "unknown"
-	GitCommit = "unknown"
-)
-
-// printVersionInfo displays version information
+// printVersionInfo logs the build information as a single structured line so
+// log aggregators can tell instances of a mixed-version fleet apart
 func printVersionInfo() {
-	log.Printf("📦 Version: %s", Version)
-	log.Printf("🕐 Build Time: %s", BuildTime)
-	log.Printf("🔗 Git Commit: %s", GitCommit)
-} 
\ No newline at end of file
+	info, err := json.Marshal(buildinfo.Get())
+	if err != nil {
+		log.Printf("📦 Version: %s (%s)", buildinfo.Version, buildinfo.GitCommit)
+		return
+	}
+	log.Printf("📦 Build info: %s", info)
+}
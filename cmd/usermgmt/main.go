@@ -0,0 +1,148 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/migrations"
+)
+
+const usage = `Usage: usermgmt <command> [arguments]
+
+Commands:
+  migrate up [N]          Apply all (or the next N) pending migrations
+  migrate down [N]        Roll back the last N applied migrations (default 1)
+  migrate status          Show applied and pending migrations
+  migrate create <name>   Create a new empty up/down migration pair
+
+Flags:
+  -config <dir>           Directory containing config.yaml (default ".")
+  -dir <dir>              Migrations directory used by "create" (default "migrations")
+`
+
+func main() {
+	configPath := flag.String("config", ".", "directory containing config.yaml")
+	migrationsDir := flag.String("dir", "migrations", "migrations directory used by create")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 || args[0] != "migrate" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := runMigrate(args[1], args[2:], *configPath, *migrationsDir); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+}
+
+// runMigrate dispatches a migrate subcommand
+func runMigrate(command string, args []string, configPath, migrationsDir string) error {
+	// create only touches the filesystem, no database needed
+	if command == "create" {
+		if len(args) != 1 {
+			return fmt.Errorf("usage: usermgmt migrate create <name>")
+		}
+		upPath, downPath, err := repository.CreateMigrationFiles(migrationsDir, args[0])
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ Created %s", upPath)
+		log.Printf("✅ Created %s", downPath)
+		return nil
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	db, err := repository.OpenPostgreSQL(&cfg)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	migrator, err := repository.NewMigrator(db, migrations.FS)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	switch command {
+	case "up":
+		steps, err := parseSteps(args, 0)
+		if err != nil {
+			return err
+		}
+		applied, err := migrator.Up(steps)
+		for _, migration := range applied {
+			log.Printf("⬆️  Applied %04d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			log.Println("✅ No pending migrations")
+		}
+
+	case "down":
+		steps, err := parseSteps(args, 1)
+		if err != nil {
+			return err
+		}
+		rolledBack, err := migrator.Down(steps)
+		for _, migration := range rolledBack {
+			log.Printf("⬇️  Rolled back %04d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(rolledBack) == 0 {
+			log.Println("✅ No applied migrations to roll back")
+		}
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		version, err := migrator.CurrentVersion()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Schema version: %d (latest: %d)\n\n", version, migrator.LatestVersion())
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("  %04d_%-40s %s\n", status.Version, status.Name, state)
+		}
+
+	default:
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+
+	return nil
+}
+
+// parseSteps reads the optional step count argument
+func parseSteps(args []string, defaultSteps int) (int, error) {
+	if len(args) == 0 {
+		return defaultSteps, nil
+	}
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps < 1 {
+		return 0, fmt.Errorf("invalid step count %q", args[0])
+	}
+	return steps, nil
+}
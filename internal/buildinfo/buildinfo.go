@@ -0,0 +1,35 @@
+// Package buildinfo exposes the version information embedded at build time.
+//
+// Values are set with -ldflags, for example:
+//
+//	go build -ldflags "-X user_mgmt_go/internal/buildinfo.Version=v1.2.3 \
+//	  -X user_mgmt_go/internal/buildinfo.GitCommit=$(git rev-parse --short HEAD) \
+//	  -X user_mgmt_go/internal/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import "runtime"
+
+// Set via -ldflags at build time
+var (
+	Version   = "dev"
+	GitCommit = "unknown"
+	BuildDate = "unknown"
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version" example:"v1.2.3"`
+	GitCommit string `json:"git_commit" example:"4a320ab"`
+	BuildDate string `json:"build_date" example:"2024-01-01T00:00:00Z"`
+	GoVersion string `json:"go_version" example:"go1.24.0"`
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+}
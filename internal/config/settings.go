@@ -0,0 +1,144 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RedactedSetting replaces secret values in exported settings
+const RedactedSetting = "[REDACTED]"
+
+// secretSettings are the keys whose values are never exported
+var secretSettings = map[string]bool{
+	"password":            true,
+	"secret":              true,
+	"client_secret":       true,
+	"smtp_password":       true,
+	"uri":                 true, // MongoDB URIs carry credentials
+	"webhook_url":         true, // Webhook URLs often embed tokens
+	"api_token":           true,
+	"clickhouse_password": true,
+}
+
+// Settings returns the configuration as nested maps keyed like config.yaml,
+// with durations as strings and secrets redacted
+func (c Config) Settings() map[string]interface{} {
+	return settingsValue(reflect.ValueOf(c)).(map[string]interface{})
+}
+
+// SettingsHash returns a SHA-256 of the exported settings, identifying the
+// configuration an instance runs with. Secrets are redacted before hashing,
+// so changing only a secret leaves the hash unchanged.
+func (c Config) SettingsHash() (string, error) {
+	// Map keys are encoded sorted, so equal settings hash the same
+	data, err := json.Marshal(c.Settings())
+	if err != nil {
+		return "", fmt.Errorf("failed to encode settings: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// settingsValue converts a configuration value to its exported form
+func settingsValue(v reflect.Value) interface{} {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		settings := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			key := field.Tag.Get("mapstructure")
+			if key == "" || !field.IsExported() {
+				continue
+			}
+			if secretSettings[key] {
+				if !v.Field(i).IsZero() {
+					settings[key] = RedactedSetting
+				} else {
+					settings[key] = ""
+				}
+				continue
+			}
+			settings[key] = settingsValue(v.Field(i))
+		}
+		return settings
+	case reflect.Map:
+		settings := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			settings[fmt.Sprint(iter.Key().Interface())] = settingsValue(iter.Value())
+		}
+		return settings
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = settingsValue(v.Index(i))
+		}
+		return items
+	default:
+		return v.Interface()
+	}
+}
+
+// DiffSettings returns the dotted keys whose values differ between two
+// exported settings trees, sorted. Redacted values are never compared, and
+// lists are compared whole.
+func DiffSettings(current, other map[string]interface{}) ([]string, error) {
+	currentFlat, err := flattenSettings(current)
+	if err != nil {
+		return nil, err
+	}
+	otherFlat, err := flattenSettings(other)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := []string{}
+	for key, value := range otherFlat {
+		if value == `"`+RedactedSetting+`"` {
+			continue
+		}
+		if currentFlat[key] != value {
+			diff = append(diff, key)
+		}
+	}
+	sort.Strings(diff)
+	return diff, nil
+}
+
+// flattenSettings maps dotted keys to JSON-encoded leaf values, so values
+// compare equal whether they came from the config or were decoded from JSON
+func flattenSettings(settings map[string]interface{}) (map[string]string, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode settings: %w", err)
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to decode settings: %w", err)
+	}
+
+	flat := make(map[string]string)
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			for key, child := range nested {
+				walk(strings.TrimPrefix(prefix+"."+key, "."), child)
+			}
+			return
+		}
+		encoded, _ := json.Marshal(value)
+		flat[prefix] = string(encoded)
+	}
+	walk("", normalized)
+	return flat, nil
+}
@@ -1,35 +1,64 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"user_mgmt_go/internal/importer"
+	"user_mgmt_go/internal/jobs"
+	"user_mgmt_go/internal/mailer"
 	"user_mgmt_go/internal/middleware"
 	"user_mgmt_go/internal/models"
 	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/internal/scanner"
 	"user_mgmt_go/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 )
 
 // AdminHandler handles admin-specific requests
 type AdminHandler struct {
-	userRepo    repository.UserRepository
-	logRepo     repository.UserLogRepository
-	repoManager *repository.RepositoryManager
+	userRepo       repository.UserRepository
+	logRepo        repository.UserLogRepository
+	repoManager    *repository.RepositoryManager
+	importMappings importer.Mappings
+	jobs           *jobs.Tracker
+
+	// accountNotifier is nil when account change notices are disabled
+	accountNotifier *mailer.AccountNotifier
+	scanner         scanner.Scanner // Scans import files before they are parsed
 }
 
+// maxBulkUsers is the most users a single bulk create or import may contain
+const maxBulkUsers = 100
+
 // NewAdminHandler creates a new admin handler
 func NewAdminHandler(
 	userRepo repository.UserRepository,
 	logRepo repository.UserLogRepository,
 	repoManager *repository.RepositoryManager,
+	importMappings importer.Mappings,
+	jobTracker *jobs.Tracker,
+	accountNotifier *mailer.AccountNotifier,
+	uploadScanner scanner.Scanner,
 ) *AdminHandler {
 	return &AdminHandler{
-		userRepo:    userRepo,
-		logRepo:     logRepo,
-		repoManager: repoManager,
+		userRepo:        userRepo,
+		logRepo:         logRepo,
+		repoManager:     repoManager,
+		importMappings:  importMappings,
+		jobs:            jobTracker,
+		accountNotifier: accountNotifier,
+		scanner:         uploadScanner,
 	}
 }
 
@@ -60,9 +89,10 @@ func (h *AdminHandler) GetSystemStats(c *gin.Context) {
 	// Get health status
 	health := h.repoManager.HealthCheck()
 	stats["database_health"] = health
+	stats["log_pipeline"] = h.repoManager.LogPipelineStatus()
 
 	// Add admin-specific stats
-	deletedUsers, err := h.userRepo.GetAllDeleted(c.Request.Context(), repository.ListParams{PageSize: 1})
+	deletedUsers, err := h.userRepo.GetAllDeleted(c.Request.Context(), repository.ListParams{PageSize: 1}, repository.DeletedUserFilter{})
 	if err == nil {
 		stats["deleted_users_count"] = deletedUsers.Total
 	}
@@ -70,6 +100,69 @@ func (h *AdminHandler) GetSystemStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// ListStatsWidgets godoc
+// @Summary List stats widgets
+// @Description List the stats widgets that can be fetched individually, with their chart type and suggested polling interval
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.StatsWidgetInfo
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /admin/stats/widgets [get]
+func (h *AdminHandler) ListStatsWidgets(c *gin.Context) {
+	c.JSON(http.StatusOK, h.repoManager.StatsWidgets())
+}
+
+// GetStatsWidget godoc
+// @Summary Get stats widget
+// @Description Get one Stats page chart dataset, so dashboards fetch only what they render and poll each widget at its own interval
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param name path string true "Widget name" Enums(summary, health, log_pipeline, event_distribution, activity_timeline)
+// @Param days query int false "Period in days for widgets covering one (1-90, default per widget)"
+// @Success 200 {object} models.StatsWidget
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/stats/widgets/{name} [get]
+func (h *AdminHandler) GetStatsWidget(c *gin.Context) {
+	days := 0
+	if daysStr := c.Query("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Period",
+				"days must be a whole number of days",
+				err.Error(),
+			))
+			return
+		}
+		days = parsed
+	}
+
+	widget, err := h.repoManager.StatsWidget(c.Request.Context(), c.Param("name"), days)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidStatsPeriod) {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Period",
+				"days must be between 1 and 90",
+				err.Error(),
+			))
+			return
+		}
+		respondRepositoryError(c, err, "Widget Retrieval Failed", "Failed to retrieve stats widget")
+		return
+	}
+
+	c.JSON(http.StatusOK, widget)
+}
+
 // GetUserLogs godoc
 // @Summary Get user activity logs
 // @Description Get paginated user activity logs with filtering options
@@ -85,6 +178,8 @@ func (h *AdminHandler) GetSystemStats(c *gin.Context) {
 // @Param end_date query string false "End date (RFC3339)"
 // @Param ip_address query string false "Filter by IP address"
 // @Param action query string false "Filter by action"
+// @Param batch_id query string false "Filter by bulk operation batch ID"
+// @Param expand query string false "Set to user to add actor and target names and emails"
 // @Success 200 {object} models.UserLogsListResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
@@ -127,6 +222,10 @@ func (h *AdminHandler) GetUserLogs(c *gin.Context) {
 		filter.Action = action
 	}
 
+	if batchID := c.Query("batch_id"); batchID != "" {
+		filter.BatchID = batchID
+	}
+
 	// Note: For date filtering, you would parse start_date and end_date
 	// from query parameters and convert them to time.Time
 
@@ -141,6 +240,7 @@ func (h *AdminHandler) GetUserLogs(c *gin.Context) {
 		))
 		return
 	}
+	expandLogUsers(c, h.userRepo, logs.Logs)
 
 	c.JSON(http.StatusOK, logs)
 }
@@ -154,6 +254,9 @@ func (h *AdminHandler) GetUserLogs(c *gin.Context) {
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(10)
+// @Param search query string false "Search by name or email"
+// @Param deleted_after query string false "Deleted on or after (RFC3339)"
+// @Param deleted_before query string false "Deleted on or before (RFC3339)"
 // @Success 200 {object} models.UsersListResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
@@ -177,8 +280,41 @@ func (h *AdminHandler) GetDeletedUsers(c *gin.Context) {
 		params.PageSize = pageSize
 	}
 
+	// Parse filters
+	filter := repository.DeletedUserFilter{
+		Search: strings.TrimSpace(c.Query("search")),
+	}
+
+	if deletedAfterStr := c.Query("deleted_after"); deletedAfterStr != "" {
+		deletedAfter, err := time.Parse(time.RFC3339, deletedAfterStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Date",
+				"deleted_after must be an RFC3339 timestamp",
+				err.Error(),
+			))
+			return
+		}
+		filter.DeletedAfter = &deletedAfter
+	}
+
+	if deletedBeforeStr := c.Query("deleted_before"); deletedBeforeStr != "" {
+		deletedBefore, err := time.Parse(time.RFC3339, deletedBeforeStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Date",
+				"deleted_before must be an RFC3339 timestamp",
+				err.Error(),
+			))
+			return
+		}
+		filter.DeletedBefore = &deletedBefore
+	}
+
 	// Get deleted users
-	deletedUsers, err := h.userRepo.GetAllDeleted(c.Request.Context(), params)
+	deletedUsers, err := h.userRepo.GetAllDeleted(c.Request.Context(), params, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
 			http.StatusInternalServerError,
@@ -192,6 +328,85 @@ func (h *AdminHandler) GetDeletedUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, deletedUsers)
 }
 
+// GetDeletedUser godoc
+// @Summary Get deleted user
+// @Description Get a soft-deleted user including deletion time and the deleting admin from the audit log
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.DeletedUserResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/users/deleted/{id} [get]
+func (h *AdminHandler) GetDeletedUser(c *gin.Context) {
+	// Parse user ID
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid User ID",
+			"User ID must be a valid UUID",
+			err.Error(),
+		))
+		return
+	}
+
+	// Get deleted user
+	user, err := h.userRepo.GetDeletedByID(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse(
+				http.StatusNotFound,
+				"User Not Found",
+				"Deleted user with the specified ID was not found",
+				err.Error(),
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Retrieval Failed",
+			"Failed to retrieve deleted user",
+			err.Error(),
+		))
+		return
+	}
+
+	response := models.DeletedUserResponse{
+		UserResponse: user.ToResponse(),
+		DeletedAt:    user.DeletedAt.Time,
+	}
+
+	// Look up who deleted the user; the record is still useful without it
+	deletion, err := h.logRepo.GetLatestDeletion(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("⚠️  Failed to look up deletion audit entry for %s: %v", userID, err)
+	}
+	if deletion != nil {
+		logResponse := deletion.ToResponse()
+		actor := &models.DeletionActor{
+			UserID:    logResponse.UserID,
+			LogID:     logResponse.ID,
+			Action:    logResponse.Data.Action,
+			Timestamp: logResponse.Timestamp,
+		}
+		if actor.UserID != nil {
+			if admin, err := h.userRepo.GetByID(c.Request.Context(), *actor.UserID); err == nil {
+				actor.Email = admin.Email
+				actor.Name = admin.Name
+			}
+		}
+		response.DeletedBy = actor
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // RestoreUser godoc
 // @Summary Restore deleted user
 // @Description Restore a soft-deleted user account
@@ -222,17 +437,17 @@ func (h *AdminHandler) RestoreUser(c *gin.Context) {
 
 	// Restore user
 	if err := h.userRepo.RestoreDeleted(c.Request.Context(), userID); err != nil {
-		c.JSON(http.StatusNotFound, models.NewErrorResponse(
-			http.StatusNotFound,
-			"Restore Failed",
-			"Failed to restore user - user may not be deleted or may not exist",
-			err.Error(),
-		))
+		respondRepositoryError(c, err, "Restore Failed", "Failed to restore user - user may not be deleted or may not exist")
 		return
 	}
 
-	// Log user restoration
-	h.logUserRestoration(c, userID)
+	// Log user restoration and tell the user their account is back
+	logEntry := h.logUserRestoration(c, userID)
+	if h.accountNotifier != nil {
+		if user, err := h.userRepo.GetByID(c.Request.Context(), userID); err == nil {
+			h.accountNotifier.AccountChanged(user, mailer.AccountRestored, logEntry)
+		}
+	}
 
 	c.JSON(http.StatusOK, models.NewSuccessResponse(
 		"User restored successfully",
@@ -285,12 +500,7 @@ func (h *AdminHandler) PermanentDeleteUser(c *gin.Context) {
 
 	// Permanently delete user
 	if err := h.userRepo.PermanentDelete(c.Request.Context(), userID); err != nil {
-		c.JSON(http.StatusNotFound, models.NewErrorResponse(
-			http.StatusNotFound,
-			"Deletion Failed",
-			"Failed to permanently delete user",
-			err.Error(),
-		))
+		respondRepositoryError(c, err, "Deletion Failed", "Failed to permanently delete user")
 		return
 	}
 
@@ -314,10 +524,15 @@ func (h *AdminHandler) PermanentDeleteUser(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body BulkCreateUsersRequest true "Bulk user creation data"
+// @Param dry_run query bool false "Validate the users and report the per-user results without creating them"
+// @Param job_id query string false "Client-generated UUID to poll progress at /admin/jobs/{job_id}"
+// @Success 200 {object} BulkCreateUsersResponse "Dry run report"
 // @Success 201 {object} BulkCreateUsersResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /admin/users/bulk-create [post]
 func (h *AdminHandler) BulkCreateUsers(c *gin.Context) {
@@ -345,22 +560,241 @@ func (h *AdminHandler) BulkCreateUsers(c *gin.Context) {
 		return
 	}
 
-	if len(req.Users) > 100 {
+	if len(req.Users) > maxBulkUsers {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Too Many Users",
+			fmt.Sprintf("Maximum %d users can be created at once", maxBulkUsers),
+			nil,
+		))
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	job, ok := h.startJob(c, "bulk_create", len(req.Users))
+	if !ok {
+		return
+	}
+
+	response, err := h.createUsersInBulk(c, job, req.Users, false, dryRun)
+	job.Finish(err)
+	if errors.Is(err, repository.ErrQuotaExceeded) {
+		respondQuotaExceeded(c, *response.Quota, response.SuccessCount)
+		return
+	}
+	if errors.Is(err, repository.ErrUserLimitReached) {
+		respondUserLimitReached(c, *response.UserLimit, response.SuccessCount)
+		return
+	}
+	if errors.Is(err, repository.ErrRegionNotAllowed) {
+		respondRegionNotAllowed(c, err)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Bulk Creation Failed",
+			"Failed to create users in batch",
+			err.Error(),
+		))
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	} else if response.ErrorCount > 0 {
+		status = http.StatusPartialContent
+	}
+
+	c.JSON(status, response)
+}
+
+// ImportUsers godoc
+// @Summary Import users from an IdP export
+// @Description Import users from an Azure AD CSV, Google Workspace CSV or generic JSON export through the bulk creation pipeline. The export is scanned for malware before it is parsed. Imported users get a temporary password, returned once in the results, that must be changed at first login.
+// @Tags admin
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param format query string true "Export format" Enums(azure_ad_csv, google_workspace_csv, generic_json)
+// @Param file formData file true "Export file"
+// @Param dry_run query bool false "Validate the imported users and report the per-row results without creating them"
+// @Param job_id query string false "Client-generated UUID to poll progress at /admin/jobs/{job_id}"
+// @Success 200 {object} ImportUsersResponse "Dry run report"
+// @Success 201 {object} ImportUsersResponse
+// @Success 206 {object} ImportUsersResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /admin/users/import [post]
+func (h *AdminHandler) ImportUsers(c *gin.Context) {
+	// Validate format
+	format := importer.Format(c.Query("format"))
+	mapping, ok := h.importMappings[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Format",
+			"format must be one of azure_ad_csv, google_workspace_csv, generic_json",
+			nil,
+		))
+		return
+	}
+
+	// Read export file
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Missing File",
+			"Please upload the export as the \"file\" form field",
+			err.Error(),
+		))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid File",
+			"Failed to read the uploaded export",
+			err.Error(),
+		))
+		return
+	}
+	defer file.Close()
+
+	// Scan before parsing, then read the export again from the start
+	details := map[string]interface{}{"format": string(format)}
+	if !scanUpload(c, h.scanner, h.logRepo, "import", fileHeader.Filename, file, details) {
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Import Failed",
+			"Failed to read the uploaded export",
+			err.Error(),
+		))
+		return
+	}
+
+	// Transform export into user records
+	records, rowErrors, err := importer.Transform(format, file, mapping)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Export",
+			"Failed to parse the export file",
+			err.Error(),
+		))
+		return
+	}
+
+	if len(records) > maxBulkUsers {
 		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
 			http.StatusBadRequest,
 			"Too Many Users",
-			"Maximum 100 users can be created at once",
+			fmt.Sprintf("Maximum %d users can be imported at once, split the export into smaller files", maxBulkUsers),
 			nil,
 		))
 		return
 	}
 
+	dryRun := c.Query("dry_run") == "true"
+	job, ok := h.startJob(c, "import", len(records))
+	if !ok {
+		return
+	}
+
+	// Imported users get a random temporary password
+	userReqs := make([]models.UserCreateRequest, len(records))
+	for i, record := range records {
+		password, err := utils.GenerateRandomPassword(16)
+		if err != nil {
+			job.Finish(err)
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				http.StatusInternalServerError,
+				"Import Failed",
+				"Failed to generate temporary passwords",
+				err.Error(),
+			))
+			return
+		}
+		userReqs[i] = models.UserCreateRequest{
+			Name:     record.Name,
+			Email:    record.Email,
+			Password: password,
+		}
+	}
+
+	response, err := h.createUsersInBulk(c, job, userReqs, true, dryRun)
+	job.Finish(err)
+	if errors.Is(err, repository.ErrQuotaExceeded) {
+		respondQuotaExceeded(c, *response.Quota, response.SuccessCount)
+		return
+	}
+	if errors.Is(err, repository.ErrUserLimitReached) {
+		respondUserLimitReached(c, *response.UserLimit, response.SuccessCount)
+		return
+	}
+	if errors.Is(err, repository.ErrRegionNotAllowed) {
+		respondRegionNotAllowed(c, err)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Import Failed",
+			"Failed to create imported users",
+			err.Error(),
+		))
+		return
+	}
+
+	// Map results back to source rows and hand out temporary passwords once
+	for i := range response.Results {
+		result := &response.Results[i]
+		result.Row = records[result.Index].Row
+		if result.Success && !dryRun {
+			result.TemporaryPassword = userReqs[result.Index].Password
+		}
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	} else if response.ErrorCount > 0 || len(rowErrors) > 0 {
+		status = http.StatusPartialContent
+	}
+
+	c.JSON(status, ImportUsersResponse{
+		Format:                  string(format),
+		BulkCreateUsersResponse: *response,
+		SkippedRows:             rowErrors,
+	})
+}
+
+// createUsersInBulk validates, hashes and creates users in one batch, logging
+// the batch on success. Per-user problems are reported in the results, and
+// progress is reported to job as users are processed. With dryRun the users
+// are validated and checked against the limits and quota, but nothing is
+// created, consumed or logged.
+func (h *AdminHandler) createUsersInBulk(c *gin.Context, job *jobs.Job, userReqs []models.UserCreateRequest, mustChangePassword, dryRun bool) (*BulkCreateUsersResponse, error) {
 	var users []*models.User
 	var results []BulkCreateResult
 	var successCount, errorCount int
+	seenEmails := make(map[string]bool, len(userReqs))
 
 	// Process each user
-	for i, userReq := range req.Users {
+	for i, userReq := range userReqs {
 		result := BulkCreateResult{
 			Index: i,
 			Email: userReq.Email,
@@ -372,6 +806,41 @@ func (h *AdminHandler) BulkCreateUsers(c *gin.Context) {
 			result.Error = "Missing required fields"
 			errorCount++
 			results = append(results, result)
+			job.Advance(1, 0, 1)
+			continue
+		}
+
+		// Apply the same rules as single user creation, such as the
+		// password length, which binding the batch does not check per user
+		if err := binding.Validator.ValidateStruct(&userReq); err != nil {
+			result.Success = false
+			result.Error = bulkValidationError(err)
+			errorCount++
+			results = append(results, result)
+			job.Advance(1, 0, 1)
+			continue
+		}
+
+		// Reject an email repeated within the batch, which would fail the
+		// whole batch on insert
+		emailKey := strings.ToLower(userReq.Email)
+		if seenEmails[emailKey] {
+			result.Success = false
+			result.Error = "Duplicate email in batch"
+			errorCount++
+			results = append(results, result)
+			job.Advance(1, 0, 1)
+			continue
+		}
+		seenEmails[emailKey] = true
+
+		// Check the email domain against the registration rules
+		if err := h.repoManager.CheckEmailDomain(userReq.Email); err != nil {
+			result.Success = false
+			result.Error = "Email domain not allowed"
+			errorCount++
+			results = append(results, result)
+			job.Advance(1, 0, 1)
 			continue
 		}
 
@@ -386,44 +855,94 @@ func (h *AdminHandler) BulkCreateUsers(c *gin.Context) {
 			}
 			errorCount++
 			results = append(results, result)
+			job.Advance(1, 0, 1)
 			continue
 		}
 
-		// Hash password
-		hashedPassword, err := h.hashPassword(userReq.Password)
+		// Check the region, which would otherwise fail the whole batch on insert
+		if err := h.repoManager.CheckRegion(userReq.Region); err != nil {
+			result.Success = false
+			result.Error = "Region not allowed"
+			errorCount++
+			results = append(results, result)
+			job.Advance(1, 0, 1)
+			continue
+		}
+
+		// Hash password; a dry run has nothing to store
+		hashedPassword := ""
+		if !dryRun {
+			hashedPassword, err = h.hashPassword(userReq.Password)
+		}
 		if err != nil {
 			result.Success = false
 			result.Error = "Password hashing failed"
 			errorCount++
 			results = append(results, result)
+			job.Advance(1, 0, 1)
 			continue
 		}
 
 		// Create user object
 		user := &models.User{
-			Name:     userReq.Name,
-			Email:    userReq.Email,
-			Password: hashedPassword,
+			Name:               userReq.Name,
+			Email:              userReq.Email,
+			Password:           hashedPassword,
+			MustChangePassword: mustChangePassword,
+			Region:             userReq.Region,
 		}
 
 		users = append(users, user)
 		result.Success = true
 		successCount++
 		results = append(results, result)
+
+		// Validated users succeed once the batch is written
+		job.Advance(1, 0, 0)
+	}
+
+	// Check what creating the valid users would run into
+	var quota *models.QuotaStatus
+	if dryRun && len(users) > 0 {
+		userLimit, err := h.repoManager.PreviewUserLimit(c.Request.Context(), len(users))
+		if err != nil {
+			return &BulkCreateUsersResponse{SuccessCount: len(users), UserLimit: &userLimit, JobID: job.ID(), DryRun: true}, err
+		}
+		warnUserSoftLimit(c, userLimit)
+
+		status, err := h.repoManager.PreviewQuota(c.Request.Context(), quotaPrincipal(c), models.QuotaResourceUsers, len(users))
+		if err != nil {
+			return &BulkCreateUsersResponse{SuccessCount: len(users), Quota: &status, JobID: job.ID(), DryRun: true}, err
+		}
+		quota = &status
+		job.Advance(0, len(users), 0)
 	}
 
 	// Perform bulk creation for valid users
-	if len(users) > 0 {
+	if !dryRun && len(users) > 0 {
+		// The whole batch counts against the active user limits; the
+		// returned response carries the limit status when one is reached
+		userLimit, err := h.repoManager.CheckUserLimit(c.Request.Context(), len(users))
+		if err != nil {
+			return &BulkCreateUsersResponse{SuccessCount: len(users), UserLimit: &userLimit, JobID: job.ID()}, err
+		}
+		warnUserSoftLimit(c, userLimit)
+
+		// Likewise against the creation quota
+		principal := quotaPrincipal(c)
+		status, err := h.repoManager.ConsumeQuota(c.Request.Context(), principal, models.QuotaResourceUsers, len(users))
+		if err != nil {
+			return &BulkCreateUsersResponse{SuccessCount: len(users), Quota: &status, JobID: job.ID()}, err
+		}
+		quota = &status
+
 		if err := h.userRepo.CreateBatch(c.Request.Context(), users); err != nil {
-			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-				http.StatusInternalServerError,
-				"Bulk Creation Failed",
-				"Failed to create users in batch",
-				err.Error(),
-			))
-			return
+			h.repoManager.ReleaseQuota(c.Request.Context(), principal, models.QuotaResourceUsers, len(users))
+			return nil, err
 		}
 
+		job.Advance(0, len(users), 0)
+
 		// Update results with created user IDs
 		userIndex := 0
 		for i := range results {
@@ -437,14 +956,182 @@ func (h *AdminHandler) BulkCreateUsers(c *gin.Context) {
 		h.logBulkCreation(c, users)
 	}
 
-	response := BulkCreateUsersResponse{
-		TotalProcessed: len(req.Users),
+	return &BulkCreateUsersResponse{
+		TotalProcessed: len(userReqs),
 		SuccessCount:   successCount,
 		ErrorCount:     errorCount,
 		Results:        results,
+		Quota:          quota,
+		JobID:          job.ID(),
+		DryRun:         dryRun,
+	}, nil
+}
+
+// bulkValidationError describes why a user in a batch failed validation,
+// naming the offending fields
+func bulkValidationError(err error) string {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return "Invalid user data"
+	}
+	problems := make([]string, len(fieldErrors))
+	for i, fieldError := range fieldErrors {
+		switch fieldError.Tag() {
+		case "required":
+			problems[i] = fmt.Sprintf("%s is required", strings.ToLower(fieldError.Field()))
+		case "email":
+			problems[i] = "email is not a valid email address"
+		case "min":
+			problems[i] = fmt.Sprintf("%s must be at least %s characters", strings.ToLower(fieldError.Field()), fieldError.Param())
+		case "max":
+			problems[i] = fmt.Sprintf("%s must be at most %s characters", strings.ToLower(fieldError.Field()), fieldError.Param())
+		default:
+			problems[i] = fmt.Sprintf("%s is invalid", strings.ToLower(fieldError.Field()))
+		}
 	}
+	return "Invalid user data: " + strings.Join(problems, "; ")
+}
 
-	status := http.StatusCreated
+// BulkDeleteUsers godoc
+// @Summary Bulk delete users
+// @Description Soft delete multiple users in a single operation
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body BulkDeleteUsersRequest true "IDs of users to delete"
+// @Param job_id query string false "Client-generated UUID to poll progress at /admin/jobs/{job_id}"
+// @Success 200 {object} BulkDeleteUsersResponse
+// @Success 206 {object} BulkDeleteUsersResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/users/bulk-delete [post]
+func (h *AdminHandler) BulkDeleteUsers(c *gin.Context) {
+	var req BulkDeleteUsersRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Request",
+			"Please provide valid user IDs",
+			err.Error(),
+		))
+		return
+	}
+
+	// Validate bulk size limits
+	if len(req.UserIDs) == 0 {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Empty Request",
+			"No users provided for deletion",
+			nil,
+		))
+		return
+	}
+
+	if len(req.UserIDs) > 100 {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Too Many Users",
+			"Maximum 100 users can be deleted at once",
+			nil,
+		))
+		return
+	}
+
+	var currentUserID uuid.UUID
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		currentUserID = userClaims.UserID
+	}
+
+	job, ok := h.startJob(c, "bulk_delete", len(req.UserIDs))
+	if !ok {
+		return
+	}
+
+	// Resolve the users in one query so the audit trail records who was deleted
+	found, err := h.userRepo.GetByIDs(c.Request.Context(), req.UserIDs)
+	if err != nil {
+		job.Finish(err)
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Bulk Deletion Failed",
+			"Failed to look up users",
+			err.Error(),
+		))
+		return
+	}
+
+	var users []*models.User
+	var ids []uuid.UUID
+	var results []BulkDeleteResult
+	var successCount, errorCount int
+
+	for i, userID := range req.UserIDs {
+		result := BulkDeleteResult{
+			Index:  i,
+			UserID: userID,
+		}
+
+		if userID == currentUserID {
+			result.Error = "Cannot delete your own account"
+			errorCount++
+			results = append(results, result)
+			job.Advance(1, 0, 1)
+			continue
+		}
+
+		user, ok := found[userID]
+		if !ok {
+			result.Error = "User not found"
+			errorCount++
+			results = append(results, result)
+			job.Advance(1, 0, 1)
+			continue
+		}
+
+		users = append(users, user)
+		ids = append(ids, userID)
+		result.Success = true
+		successCount++
+		results = append(results, result)
+		job.Advance(1, 0, 0)
+	}
+
+	// Perform bulk deletion for resolved users
+	if len(ids) > 0 {
+		if err := h.userRepo.DeleteBatch(c.Request.Context(), ids); err != nil {
+			job.Finish(err)
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				http.StatusInternalServerError,
+				"Bulk Deletion Failed",
+				"Failed to delete users in batch",
+				err.Error(),
+			))
+			return
+		}
+
+		job.Advance(0, len(ids), 0)
+
+		// Log bulk deletion
+		h.logBulkDeletion(c, users)
+	}
+	job.Finish(nil)
+
+	response := BulkDeleteUsersResponse{
+		TotalProcessed: len(req.UserIDs),
+		SuccessCount:   successCount,
+		ErrorCount:     errorCount,
+		Results:        results,
+		JobID:          job.ID(),
+	}
+
+	status := http.StatusOK
 	if errorCount > 0 {
 		status = http.StatusPartialContent
 	}
@@ -452,6 +1139,335 @@ func (h *AdminHandler) BulkCreateUsers(c *gin.Context) {
 	c.JSON(status, response)
 }
 
+// GetJob godoc
+// @Summary Get bulk operation progress
+// @Description Get processed, succeeded and failed counts of a bulk create, import or delete. Pass a client-generated job_id when starting the operation to poll it while it runs. Finished jobs are kept for an hour.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} jobs.Progress
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/jobs/{id} [get]
+func (h *AdminHandler) GetJob(c *gin.Context) {
+	progress, ok := h.jobs.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			http.StatusNotFound,
+			"Job Not Found",
+			"No bulk operation with the specified job ID is running or recently finished",
+			nil,
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// GetLogBatches godoc
+// @Summary List bulk operation batches
+// @Description Get paginated summary entries of bulk operations, one per batch
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Param expand query string false "Set to user to add actor and target names and emails"
+// @Success 200 {object} models.UserLogsListResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/logs/batches [get]
+func (h *AdminHandler) GetLogBatches(c *gin.Context) {
+	filter := models.LogFilterRequest{
+		Page:           1,
+		PageSize:       10,
+		BatchSummaries: true,
+	}
+
+	if page, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && page > 0 {
+		filter.Page = page
+	}
+
+	if pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10")); err == nil && pageSize > 0 && pageSize <= 100 {
+		filter.PageSize = pageSize
+	}
+
+	batches, err := h.logRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Logs Retrieval Failed",
+			"Failed to retrieve bulk operation batches",
+			err.Error(),
+		))
+		return
+	}
+	expandLogUsers(c, h.userRepo, batches.Logs)
+
+	c.JSON(http.StatusOK, batches)
+}
+
+// GetLogBatch godoc
+// @Summary Get bulk operation batch
+// @Description Get the summary and per-user entries written by a single bulk operation
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param batch_id path string true "Batch ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(100)
+// @Param expand query string false "Set to user to add actor and target names and emails"
+// @Success 200 {object} models.UserLogsListResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/logs/batches/{batch_id} [get]
+func (h *AdminHandler) GetLogBatch(c *gin.Context) {
+	filter := models.LogFilterRequest{
+		Page:     1,
+		PageSize: 100,
+		BatchID:  c.Param("batch_id"),
+	}
+
+	if page, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && page > 0 {
+		filter.Page = page
+	}
+
+	if pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "100")); err == nil && pageSize > 0 && pageSize <= 100 {
+		filter.PageSize = pageSize
+	}
+
+	entries, err := h.logRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Logs Retrieval Failed",
+			"Failed to retrieve batch log entries",
+			err.Error(),
+		))
+		return
+	}
+
+	if entries.Total == 0 {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			http.StatusNotFound,
+			"Batch Not Found",
+			"No log entries found for the specified batch ID",
+			nil,
+		))
+		return
+	}
+	expandLogUsers(c, h.userRepo, entries.Logs)
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetAdminSessions godoc
+// @Summary List active admin sessions
+// @Description List unrevoked, unexpired admin sessions with device, IP and last activity
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.SessionResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/sessions [get]
+func (h *AdminHandler) GetAdminSessions(c *gin.Context) {
+	sessions, err := h.repoManager.Repos.Session.ListActive(c.Request.Context(), "admin")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Sessions Retrieval Failed",
+			"Failed to retrieve active sessions",
+			err.Error(),
+		))
+		return
+	}
+
+	var currentSessionID string
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		currentSessionID = userClaims.SessionID
+	}
+
+	response := make([]models.SessionResponse, len(sessions))
+	for i := range sessions {
+		response[i] = sessions[i].ToResponse(currentSessionID)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Revoke a login session; its access and refresh tokens stop working immediately
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/sessions/{id} [delete]
+func (h *AdminHandler) RevokeSession(c *gin.Context) {
+	// Parse session ID
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Session ID",
+			"Please provide a valid session ID",
+			err.Error(),
+		))
+		return
+	}
+
+	var adminID uuid.UUID
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		adminID = userClaims.UserID
+	}
+
+	// Load the session first so the audit entry names its owner
+	session, err := h.repoManager.Repos.Session.GetByID(c.Request.Context(), sessionID)
+	if err == nil && !session.IsActive() {
+		err = repository.ErrSessionNotFound
+	}
+	if err == nil {
+		err = h.repoManager.Repos.Session.Revoke(c.Request.Context(), sessionID, adminID)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse(
+				http.StatusNotFound,
+				"Session Not Found",
+				"The session does not exist, has expired or has already been revoked",
+				nil,
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Revoke Failed",
+			"Failed to revoke session",
+			err.Error(),
+		))
+		return
+	}
+
+	// Log session revocation
+	h.logSessionRevocation(c, session)
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(
+		"Session revoked successfully",
+		map[string]interface{}{
+			"revoked_session_id": sessionID,
+			"user_id":            session.UserID,
+		},
+	))
+}
+
+// MaintainIndexes godoc
+// @Summary Verify or rebuild database indexes
+// @Description Report missing, invalid and unused PostgreSQL and MongoDB indexes. With build set, missing and invalid PostgreSQL indexes are built concurrently and missing MongoDB indexes are created before reporting.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body IndexMaintenanceRequest false "Whether to build missing indexes"
+// @Success 200 {object} repository.IndexReport
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/maintenance/indexes [post]
+func (h *AdminHandler) MaintainIndexes(c *gin.Context) {
+	// Verify only unless asked to build
+	var req IndexMaintenanceRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Request",
+				"Please provide a valid index maintenance request",
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	report, err := h.repoManager.CheckIndexes(c.Request.Context(), req.Build)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Index Check Failed",
+			"Failed to check database indexes",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetQuotaUsage godoc
+// @Summary Get creation quota usage
+// @Description Get the configured daily creation quotas and each admin's or API key's usage in the current window
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.QuotaUsageResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/quotas [get]
+func (h *AdminHandler) GetQuotaUsage(c *gin.Context) {
+	usage, err := h.repoManager.QuotaUsage(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Quota Retrieval Failed",
+			"Failed to retrieve quota usage",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// GetUsage godoc
+// @Summary Get usage report
+// @Description Get active and deleted user counts against the configured user limits, audit log volume and creation quota usage, for billing and capacity planning
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.UsageReport
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/usage [get]
+func (h *AdminHandler) GetUsage(c *gin.Context) {
+	report, err := h.repoManager.Usage(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Usage Retrieval Failed",
+			"Failed to retrieve usage report",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // RunMaintenance godoc
 // @Summary Run system maintenance
 // @Description Run system maintenance tasks (log cleanup, etc.)
@@ -480,6 +1496,11 @@ func (h *AdminHandler) RunMaintenance(c *gin.Context) {
 	))
 }
 
+// IndexMaintenanceRequest selects whether index maintenance builds missing indexes
+type IndexMaintenanceRequest struct {
+	Build bool `json:"build" example:"false"`
+}
+
 // Request/Response types for bulk operations
 
 type BulkCreateUsersRequest struct {
@@ -487,28 +1508,95 @@ type BulkCreateUsersRequest struct {
 }
 
 type BulkCreateUsersResponse struct {
+	TotalProcessed int                 `json:"total_processed"`
+	SuccessCount   int                 `json:"success_count"`
+	ErrorCount     int                 `json:"error_count"`
+	Results        []BulkCreateResult  `json:"results"`
+	Quota          *models.QuotaStatus `json:"quota,omitempty"` // Creation quota after this batch
+	JobID          string              `json:"job_id"`
+	DryRun         bool                `json:"dry_run,omitempty"` // Users were validated but not created
+
+	UserLimit *models.UsageLimitStatus `json:"-"` // Set when the batch would pass the active user limit
+}
+
+type BulkCreateResult struct {
+	Index             int        `json:"index"`
+	Row               int        `json:"row,omitempty"` // Source row, for imports
+	Email             string     `json:"email"`
+	UserID            *uuid.UUID `json:"user_id,omitempty"`
+	Success           bool       `json:"success"`
+	Error             string     `json:"error,omitempty"`
+	TemporaryPassword string     `json:"temporary_password,omitempty"` // Only returned once, for imports
+}
+
+type ImportUsersResponse struct {
+	Format string `json:"format"`
+	BulkCreateUsersResponse
+	SkippedRows []importer.RowError `json:"skipped_rows,omitempty"`
+}
+
+type BulkDeleteUsersRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids" binding:"required"`
+}
+
+type BulkDeleteUsersResponse struct {
 	TotalProcessed int                `json:"total_processed"`
 	SuccessCount   int                `json:"success_count"`
 	ErrorCount     int                `json:"error_count"`
-	Results        []BulkCreateResult `json:"results"`
+	Results        []BulkDeleteResult `json:"results"`
+	JobID          string             `json:"job_id"`
 }
 
-type BulkCreateResult struct {
-	Index   int        `json:"index"`
-	Email   string     `json:"email"`
-	UserID  *uuid.UUID `json:"user_id,omitempty"`
-	Success bool       `json:"success"`
-	Error   string     `json:"error,omitempty"`
+type BulkDeleteResult struct {
+	Index   int       `json:"index"`
+	UserID  uuid.UUID `json:"user_id"`
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty"`
 }
 
 // Helper methods
 
+// startJob starts tracking a bulk operation under the job_id query parameter,
+// or a new ID when none is given. It writes an error response and returns
+// false when the ID is invalid or in use.
+func (h *AdminHandler) startJob(c *gin.Context, operation string, total int) (*jobs.Job, bool) {
+	jobID := c.Query("job_id")
+	if jobID == "" {
+		jobID = uuid.New().String()
+	} else if _, err := uuid.Parse(jobID); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Job ID",
+			"job_id must be a UUID",
+			err.Error(),
+		))
+		return nil, false
+	}
+
+	var startedBy string
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		startedBy = userClaims.UserID.String()
+	}
+
+	job, err := h.jobs.Start(jobID, operation, startedBy, total)
+	if err != nil {
+		c.JSON(http.StatusConflict, models.NewErrorResponse(
+			http.StatusConflict,
+			"Job ID In Use",
+			"A bulk operation with this job ID is already tracked, use a new ID",
+			err.Error(),
+		))
+		return nil, false
+	}
+	return job, true
+}
+
 func (h *AdminHandler) hashPassword(password string) (string, error) {
 	// Import utils package at the top and use the actual hashing function
 	return utils.HashPassword(password)
 }
 
-func (h *AdminHandler) logUserRestoration(c *gin.Context, userID uuid.UUID) {
+func (h *AdminHandler) logUserRestoration(c *gin.Context, userID uuid.UUID) *models.UserLog {
 	// Get admin from context
 	var adminID *uuid.UUID
 	if userClaims, exists := middleware.GetUserFromContext(c); exists {
@@ -528,6 +1616,33 @@ func (h *AdminHandler) logUserRestoration(c *gin.Context, userID uuid.UUID) {
 		UserAgent: c.Request.UserAgent(),
 	})
 
+	h.logRepo.CreateAsync(logEntry)
+	return logEntry
+}
+
+func (h *AdminHandler) logSessionRevocation(c *gin.Context, session *models.Session) {
+	// Get admin from context
+	var adminID *uuid.UUID
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		adminID = &userClaims.UserID
+	}
+
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID: adminID,
+		Event:  models.SessionRevoked,
+		Action: "REVOKE_SESSION",
+		Details: map[string]interface{}{
+			"session_id":         session.ID,
+			"session_user_id":    session.UserID,
+			"session_ip_address": session.IPAddress,
+			"session_device":     models.DescribeUserAgent(session.UserAgent),
+			"ip_address":         c.ClientIP(),
+			"user_agent":         c.Request.UserAgent(),
+		},
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
 	h.logRepo.CreateAsync(logEntry)
 }
 
@@ -552,7 +1667,13 @@ func (h *AdminHandler) logPermanentDeletion(c *gin.Context, userID uuid.UUID) {
 		UserAgent: c.Request.UserAgent(),
 	})
 
-	h.logRepo.CreateAsync(logEntry)
+	// Written before responding: the user changes feed reads permanent
+	// deletions from the audit trail, and an entry still queued when a client
+	// reads past it would never reach that client
+	if err := h.logRepo.Create(c.Request.Context(), logEntry); err != nil {
+		log.Printf("Failed to log permanent deletion of user %s, queueing it: %v", userID, err)
+		h.logRepo.CreateAsync(logEntry)
+	}
 }
 
 func (h *AdminHandler) logBulkCreation(c *gin.Context, users []*models.User) {
@@ -562,12 +1683,15 @@ func (h *AdminHandler) logBulkCreation(c *gin.Context, users []*models.User) {
 		adminID = &userClaims.UserID
 	}
 
+	batchID := uuid.New().String()
+
 	userIDs := make([]uuid.UUID, len(users))
 	for i, user := range users {
 		userIDs[i] = user.ID
 	}
 
-	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+	// Batch summary entry
+	summaryEntry := models.NewUserLog(models.UserLogCreateRequest{
 		UserID: adminID,
 		Event:  models.UserCreated,
 		Action: "BULK_CREATE_USERS",
@@ -577,9 +1701,98 @@ func (h *AdminHandler) logBulkCreation(c *gin.Context, users []*models.User) {
 			"ip_address":         c.ClientIP(),
 			"user_agent":         c.Request.UserAgent(),
 		},
-		IPAddress: c.ClientIP(),
-		UserAgent: c.Request.UserAgent(),
+		IPAddress:    c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+		BatchID:      batchID,
+		BatchSummary: true,
 	})
 
-	h.logRepo.CreateAsync(logEntry)
-} 
\ No newline at end of file
+	h.logRepo.CreateAsync(summaryEntry)
+
+	// Per-user entries, shaped like single CREATE_USER entries so per-user
+	// history stays complete
+	for _, user := range users {
+		logEntry := models.NewUserLog(models.UserLogCreateRequest{
+			UserID: adminID,
+			Event:  models.UserCreated,
+			Action: "CREATE_USER",
+			Details: map[string]interface{}{
+				"created_user_id":    user.ID,
+				"created_user_email": user.Email,
+				"created_user_name":  user.Name,
+				"ip_address":         c.ClientIP(),
+				"user_agent":         c.Request.UserAgent(),
+			},
+			NewValues: map[string]interface{}{
+				"id":    user.ID,
+				"email": user.Email,
+				"name":  user.Name,
+			},
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			BatchID:   batchID,
+		})
+
+		h.logRepo.CreateAsync(logEntry)
+	}
+}
+
+func (h *AdminHandler) logBulkDeletion(c *gin.Context, users []*models.User) {
+	// Get admin from context
+	var adminID *uuid.UUID
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		adminID = &userClaims.UserID
+	}
+
+	batchID := uuid.New().String()
+
+	userIDs := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+
+	// Batch summary entry
+	summaryEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID: adminID,
+		Event:  models.UserDeleted,
+		Action: "BULK_DELETE_USERS",
+		Details: map[string]interface{}{
+			"deleted_user_count": len(users),
+			"deleted_user_ids":   userIDs,
+			"ip_address":         c.ClientIP(),
+			"user_agent":         c.Request.UserAgent(),
+		},
+		IPAddress:    c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+		BatchID:      batchID,
+		BatchSummary: true,
+	})
+
+	h.logRepo.CreateAsync(summaryEntry)
+
+	// Per-user entries, shaped like single DELETE_USER entries
+	for _, user := range users {
+		logEntry := models.NewUserLog(models.UserLogCreateRequest{
+			UserID: adminID,
+			Event:  models.UserDeleted,
+			Action: "DELETE_USER",
+			Details: map[string]interface{}{
+				"deleted_user_id":    user.ID,
+				"deleted_user_email": user.Email,
+				"deleted_user_name":  user.Name,
+				"ip_address":         c.ClientIP(),
+				"user_agent":         c.Request.UserAgent(),
+			},
+			OldValues: map[string]interface{}{
+				"id":    user.ID,
+				"email": user.Email,
+				"name":  user.Name,
+			},
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			BatchID:   batchID,
+		})
+
+		h.logRepo.CreateAsync(logEntry)
+	}
+}
@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"html/template"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"user_mgmt_go/internal/config"
 	"user_mgmt_go/internal/middleware"
 	"user_mgmt_go/internal/models"
 	"user_mgmt_go/internal/repository"
@@ -19,7 +26,7 @@ type AdminPanelHandler struct {
 	userRepo    repository.UserRepository
 	logRepo     repository.UserLogRepository
 	repoManager *repository.RepositoryManager
-	templates   *template.Template
+	tracing     config.TracingConfig
 }
 
 // NewAdminPanelHandler creates a new admin panel handler
@@ -27,49 +34,33 @@ func NewAdminPanelHandler(
 	userRepo repository.UserRepository,
 	logRepo repository.UserLogRepository,
 	repoManager *repository.RepositoryManager,
+	tracing config.TracingConfig,
 ) *AdminPanelHandler {
-	handler := &AdminPanelHandler{
+	return &AdminPanelHandler{
 		userRepo:    userRepo,
 		logRepo:     logRepo,
 		repoManager: repoManager,
+		tracing:     tracing,
 	}
-	
-	// Load templates
-	handler.loadTemplates()
-	
-	return handler
 }
 
-// loadTemplates loads all HTML templates
-func (h *AdminPanelHandler) loadTemplates() {
-	var err error
-	h.templates, err = template.New("").Funcs(template.FuncMap{
-		"formatTime": func(t time.Time) string {
-			return t.Format("2006-01-02 15:04:05")
-		},
-		"formatDate": func(t time.Time) string {
-			return t.Format("2006-01-02")
-		},
-		"add": func(a, b int) int {
-			return a + b
-		},
-		"sub": func(a, b int) int {
-			return a - b
-		},
-	}).ParseFiles(
-		"templates/admin/base.html",
-		"templates/admin/login.html", 
-		"templates/admin/dashboard.html",
-		"templates/admin/users.html",
-		"templates/admin/logs.html",
-		"templates/admin/stats.html",
-		"templates/admin/deleted-users.html",
-	)
-	
-	if err != nil {
-		// Templates not loaded, will create them
-		h.templates = template.New("")
+// adminPages lists the admin panel page templates, each rendered together with base.html
+var adminPages = []string{"login", "dashboard", "users", "logs", "stats", "deleted-users", "sessions", "traces", "notifications"}
+
+// errorPageTemplate is rendered when a page fails to render. It is standalone
+// so it still works when base.html is broken.
+const errorPageTemplate = "error"
+
+// CheckTemplates parses every admin panel template and returns the failures,
+// so broken templates are found at startup rather than on first visit
+func (h *AdminPanelHandler) CheckTemplates() error {
+	var errs []error
+	for _, page := range append(adminPages, errorPageTemplate) {
+		if _, err := parsePageTemplate(page); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", page, err))
+		}
 	}
+	return errors.Join(errs...)
 }
 
 // PageData represents common data for all admin pages
@@ -90,6 +81,17 @@ type UsersPageData struct {
 	Page        int
 	PageSize    int
 	TotalPages  int
+	// Filter values for the template
+	CurrentSearch            string
+	CurrentRole              string
+	CurrentRegion            string
+	CurrentCreatedWithinDays string
+	CurrentSortBy            string
+	CurrentSortDir           string
+	// FilterQuery carries the filters into pagination links
+	FilterQuery template.URL
+	// Saved searches of the current admin
+	SavedSearches []models.SavedUserSearch
 }
 
 // LogsPageData represents data specifically for the logs page
@@ -107,6 +109,40 @@ type LogsPageData struct {
 	CurrentEvent    string
 	CurrentAction   string
 	CurrentPageSize string
+	// Date range as YYYY-MM-DD for the date inputs
+	CurrentStartDate string
+	CurrentEndDate   string
+	// Saved filter presets of the current admin
+	Presets []models.LogFilterPreset
+}
+
+// TracesPageData represents data specifically for the request traces page
+type TracesPageData struct {
+	Title       string
+	CurrentUser *models.UserResponse
+	CurrentTime time.Time
+	Samples     []models.RequestSample
+	// SlowThreshold is the duration from which requests count as slow
+	SlowThreshold time.Duration
+	// Linked is set when trace IDs link to the tracing backend
+	Linked bool
+	// Filter values for the template
+	CurrentUserFilter string
+	// UserNotFound is set when the user filter matches no user
+	UserNotFound bool
+}
+
+// NotificationsPageData represents data specifically for the notifications page
+type NotificationsPageData struct {
+	Title         string
+	CurrentUser   *models.UserResponse
+	CurrentTime   time.Time
+	Notifications []models.AdminNotification
+	Unread        int64
+	// Subscription of the current admin, nil when not subscribed
+	Subscription *models.AuditSubscription
+	// EventsText lists the subscribed events for the subscription form
+	EventsText string
 }
 
 // DashboardData represents data for the admin dashboard
@@ -118,6 +154,15 @@ type DashboardData struct {
 	RecentLogs  []models.UserLogResponse
 }
 
+// ErrorPageData represents data for the admin error page
+type ErrorPageData struct {
+	Title       string
+	Status      int
+	Message     string
+	Page        string
+	CurrentTime time.Time
+}
+
 // DashboardPageData represents data specifically for the dashboard page
 type DashboardPageData struct {
 	Title       string
@@ -150,6 +195,7 @@ func (h *AdminPanelHandler) Dashboard(c *gin.Context) {
 	recentLogsResp, _ := h.logRepo.List(c.Request.Context(), models.LogFilterRequest{
 		Page: 1, PageSize: 5,
 	})
+	nameLogUsers(c.Request.Context(), h.userRepo, recentLogsResp.Logs)
 
 	dashboardPageData := DashboardPageData{
 		Title:       "Admin Dashboard",
@@ -173,25 +219,25 @@ func (h *AdminPanelHandler) Users(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	search := c.Query("search")
+	query := c.Request.URL.Query()
+	search, filter, params := parseUserListQuery(query, time.Now())
 
-	params := repository.ListParams{
-		Page: page, PageSize: pageSize, SortBy: "created_at", SortDir: "desc",
+	usersResp, err := h.userRepo.ListFiltered(c.Request.Context(), search, filter, params)
+	if err != nil {
+		usersResp = &models.UsersListResponse{}
 	}
 
-	var usersResp *models.UsersListResponse
-	var err error
-
-	if search != "" {
-		usersResp, err = h.userRepo.Search(c.Request.Context(), search, params)
-	} else {
-		usersResp, err = h.userRepo.List(c.Request.Context(), params)
+	savedSearches, err := h.repoManager.Repos.SavedUserSearch.ListForAdmin(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Printf("Failed to list saved user searches: %v", err)
 	}
 
-	if err != nil {
-		usersResp = &models.UsersListResponse{}
+	// Keep the filters, not the page, in pagination links
+	filterQuery := url.Values{}
+	for _, key := range []string{"search", "role", "region", "created_within_days", "sort_by", "sort_dir"} {
+		if value := query.Get(key); value != "" {
+			filterQuery.Set(key, value)
+		}
 	}
 
 	usersPageData := UsersPageData{
@@ -203,6 +249,15 @@ func (h *AdminPanelHandler) Users(c *gin.Context) {
 		Page:        usersResp.Page,
 		PageSize:    usersResp.PageSize,
 		TotalPages:  usersResp.TotalPages,
+
+		CurrentSearch:            search,
+		CurrentRole:              filter.Role,
+		CurrentRegion:            filter.Region,
+		CurrentCreatedWithinDays: query.Get("created_within_days"),
+		CurrentSortBy:            params.SortBy,
+		CurrentSortDir:           params.SortDir,
+		FilterQuery:              template.URL(filterQuery.Encode()),
+		SavedSearches:            savedSearches,
 	}
 
 	h.renderUsersTemplate(c, "users", usersPageData)
@@ -220,7 +275,7 @@ func (h *AdminPanelHandler) Logs(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 	userID := c.Query("user_id")
 	event := c.Query("event")
-	action := c.Query("action")  // Add action parameter
+	action := c.Query("action") // Add action parameter
 
 	filter := models.LogFilterRequest{
 		Page: page, PageSize: pageSize,
@@ -240,27 +295,47 @@ func (h *AdminPanelHandler) Logs(c *gin.Context) {
 	}
 
 	if action != "" {
-		filter.Action = action  // Add action filtering
+		filter.Action = action // Add action filtering
+	}
+
+	// Parse date filters; the date inputs send YYYY-MM-DD, presets RFC3339
+	var startDate, endDate string
+	if parsed, ok := parseLogFilterDate(c.Query("start_date"), false); ok {
+		filter.StartDate = &parsed
+		startDate = parsed.Format(time.DateOnly)
+	}
+	if parsed, ok := parseLogFilterDate(c.Query("end_date"), true); ok {
+		filter.EndDate = &parsed
+		endDate = parsed.Format(time.DateOnly)
 	}
 
 	logsResp, err := h.logRepo.List(c.Request.Context(), filter)
 	if err != nil {
 		logsResp = &models.UserLogsListResponse{}
 	}
+	nameLogUsers(c.Request.Context(), h.userRepo, logsResp.Logs)
+
+	presets, err := h.repoManager.Repos.LogFilterPreset.ListForAdmin(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Printf("Failed to list log filter presets: %v", err)
+	}
 
 	logsPageData := LogsPageData{
-		Title:           "Activity Logs",
-		CurrentUser:     user,
-		CurrentTime:     time.Now(),
-		Logs:            logsResp.Logs,
-		Total:           logsResp.Total,
-		Page:            logsResp.Page,
-		PageSize:        logsResp.PageSize,
-		TotalPages:      logsResp.TotalPages,
-		CurrentUserID:   userID,
-		CurrentEvent:    event,
-		CurrentAction:   action,
-		CurrentPageSize: strconv.Itoa(pageSize),
+		Title:            "Activity Logs",
+		CurrentUser:      user,
+		CurrentTime:      time.Now(),
+		Logs:             logsResp.Logs,
+		Total:            logsResp.Total,
+		Page:             logsResp.Page,
+		PageSize:         logsResp.PageSize,
+		TotalPages:       logsResp.TotalPages,
+		CurrentUserID:    userID,
+		CurrentEvent:     event,
+		CurrentAction:    action,
+		CurrentPageSize:  strconv.Itoa(pageSize),
+		CurrentStartDate: startDate,
+		CurrentEndDate:   endDate,
+		Presets:          presets,
 	}
 
 	h.renderLogsTemplate(c, "logs", logsPageData)
@@ -310,7 +385,7 @@ func (h *AdminPanelHandler) DeletedUsers(c *gin.Context) {
 		Page: page, PageSize: pageSize, SortBy: "deleted_at", SortDir: "desc",
 	}
 
-	deletedUsers, err := h.userRepo.GetAllDeleted(c.Request.Context(), params)
+	deletedUsers, err := h.userRepo.GetAllDeleted(c.Request.Context(), params, repository.DeletedUserFilter{})
 	if err != nil {
 		deletedUsers = &models.UsersListResponse{}
 	}
@@ -325,6 +400,129 @@ func (h *AdminPanelHandler) DeletedUsers(c *gin.Context) {
 	h.renderTemplate(c, "deleted-users", pageData)
 }
 
+// Sessions renders the active admin sessions page
+func (h *AdminPanelHandler) Sessions(c *gin.Context) {
+	user := h.getCurrentUser(c)
+	if user == nil {
+		c.Redirect(http.StatusTemporaryRedirect, "/admin/login")
+		return
+	}
+
+	var currentSessionID string
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		currentSessionID = userClaims.SessionID
+	}
+
+	sessions, _ := h.repoManager.Repos.Session.ListActive(c.Request.Context(), "admin")
+	responses := make([]models.SessionResponse, len(sessions))
+	for i := range sessions {
+		responses[i] = sessions[i].ToResponse(currentSessionID)
+	}
+
+	pageData := PageData{
+		Title:       "Admin Sessions",
+		CurrentUser: user,
+		CurrentTime: time.Now(),
+		Data:        responses,
+	}
+
+	h.renderTemplate(c, "sessions", pageData)
+}
+
+// Traces renders the latest slow and failed requests from the request logs,
+// each linked to its trace in the tracing backend
+func (h *AdminPanelHandler) Traces(c *gin.Context) {
+	user := h.getCurrentUser(c)
+	if user == nil {
+		c.Redirect(http.StatusTemporaryRedirect, "/admin/login")
+		return
+	}
+
+	pageData := TracesPageData{
+		Title:             "Request Traces",
+		CurrentUser:       user,
+		CurrentTime:       time.Now(),
+		SlowThreshold:     h.tracing.SlowThreshold,
+		Linked:            h.tracing.URLTemplate != "",
+		CurrentUserFilter: c.Query("user"),
+	}
+
+	// Narrow down to one user, by email or ID, to follow up a complaint
+	var userID string
+	if pageData.CurrentUserFilter != "" {
+		if id, err := uuid.Parse(pageData.CurrentUserFilter); err == nil {
+			userID = id.String()
+		} else if found, err := h.userRepo.GetByEmail(c.Request.Context(), pageData.CurrentUserFilter); err == nil {
+			userID = found.ID.String()
+		} else {
+			pageData.UserNotFound = true
+			h.renderTracesTemplate(c, "traces", pageData)
+			return
+		}
+	}
+
+	logs, err := h.logRepo.ListRequestSamples(c.Request.Context(), h.tracing.SlowThreshold, userID, h.tracing.SampleLimit)
+	if err != nil {
+		log.Printf("Failed to list request samples: %v", err)
+	}
+
+	pageData.Samples = make([]models.RequestSample, len(logs))
+	var userIDs []uuid.UUID
+	for i, entry := range logs {
+		sample := models.NewRequestSample(entry)
+		sample.TraceURL = h.tracing.TraceURL(sample.TraceID)
+		if id, err := uuid.Parse(sample.UserID); err == nil {
+			userIDs = append(userIDs, id)
+		}
+		pageData.Samples[i] = sample
+	}
+
+	// Show who made each request with one user lookup
+	if len(userIDs) > 0 {
+		if users, err := h.userRepo.GetByIDs(c.Request.Context(), userIDs); err == nil {
+			for i := range pageData.Samples {
+				if id, err := uuid.Parse(pageData.Samples[i].UserID); err == nil && users[id] != nil {
+					pageData.Samples[i].UserEmail = users[id].Email
+				}
+			}
+		}
+	}
+
+	h.renderTracesTemplate(c, "traces", pageData)
+}
+
+// Notifications renders the current admin's inbox of subscribed audit
+// events together with the subscription form
+func (h *AdminPanelHandler) Notifications(c *gin.Context) {
+	user := h.getCurrentUser(c)
+	if user == nil {
+		c.Redirect(http.StatusTemporaryRedirect, "/admin/login")
+		return
+	}
+
+	pageData := NotificationsPageData{
+		Title:       "Notifications",
+		CurrentUser: user,
+		CurrentTime: time.Now(),
+	}
+
+	ctx := c.Request.Context()
+	repos := h.repoManager.Repos
+	var err error
+	if pageData.Notifications, err = repos.AdminNotification.ListForAdmin(ctx, user.ID, false, 100); err != nil {
+		log.Printf("Failed to list admin notifications: %v", err)
+	}
+	pageData.Unread, _ = repos.AdminNotification.CountUnread(ctx, user.ID)
+	if pageData.Subscription, err = repos.AuditSubscription.Get(ctx, user.ID); err != nil {
+		log.Printf("Failed to load audit subscription: %v", err)
+	}
+	if pageData.Subscription != nil {
+		pageData.EventsText = strings.Join(pageData.Subscription.Events, ", ")
+	}
+
+	h.renderNotificationsTemplate(c, "notifications", pageData)
+}
+
 // Login renders the admin login page
 func (h *AdminPanelHandler) Login(c *gin.Context) {
 	// Check if already logged in
@@ -343,6 +541,25 @@ func (h *AdminPanelHandler) Login(c *gin.Context) {
 
 // Helper methods
 
+// parseLogFilterDate parses an RFC3339 time or a YYYY-MM-DD date; a date
+// used as the end of a range covers the whole day
+func parseLogFilterDate(value string, endOfDay bool) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, true
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if endOfDay {
+		parsed = parsed.Add(24*time.Hour - time.Nanosecond)
+	}
+	return parsed, true
+}
+
 func (h *AdminPanelHandler) getCurrentUser(c *gin.Context) *models.UserResponse {
 	userClaims, exists := middleware.GetUserFromContext(c)
 	if !exists {
@@ -359,79 +576,86 @@ func (h *AdminPanelHandler) getCurrentUser(c *gin.Context) *models.UserResponse
 }
 
 func (h *AdminPanelHandler) renderTemplate(c *gin.Context, templateName string, data PageData) {
-	// Create a fresh template instance for this specific template to avoid conflicts
-	templateFiles := []string{
-		"templates/admin/base.html",
-		"templates/admin/" + templateName + ".html",
-	}
-	
-	tmpl, err := template.New("").Funcs(template.FuncMap{
-		"formatTime": func(t time.Time) string {
-			return t.Format("2006-01-02 15:04:05")
-		},
-		"formatDate": func(t time.Time) string {
-			return t.Format("2006-01-02")
-		},
-		"add": func(a, b int) int {
-			return a + b
-		},
-		"sub": func(a, b int) int {
-			return a - b
-		},
-	}).ParseFiles(templateFiles...)
-	
+	h.renderPage(c, templateName, data)
+}
+
+func (h *AdminPanelHandler) renderUsersTemplate(c *gin.Context, templateName string, data UsersPageData) {
+	h.renderPage(c, templateName, data)
+}
+
+func (h *AdminPanelHandler) renderLogsTemplate(c *gin.Context, templateName string, data LogsPageData) {
+	h.renderPage(c, templateName, data)
+}
+
+func (h *AdminPanelHandler) renderTracesTemplate(c *gin.Context, templateName string, data TracesPageData) {
+	h.renderPage(c, templateName, data)
+}
+
+func (h *AdminPanelHandler) renderNotificationsTemplate(c *gin.Context, templateName string, data NotificationsPageData) {
+	h.renderPage(c, templateName, data)
+}
+
+func (h *AdminPanelHandler) renderDashboardTemplate(c *gin.Context, templateName string, data DashboardPageData) {
+	h.renderPage(c, templateName, data)
+}
+
+// renderPage renders a page into a buffer first, so a failure halfway through
+// shows the error page instead of a truncated page
+func (h *AdminPanelHandler) renderPage(c *gin.Context, templateName string, data interface{}) {
+	tmpl, err := parsePageTemplate(templateName)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Template parsing failed: " + err.Error()})
+		h.renderError(c, templateName, "parse", err)
 		return
 	}
 
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	if err := tmpl.ExecuteTemplate(c.Writer, templateName + ".html", data); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, templateName+".html", data); err != nil {
+		h.renderError(c, templateName, "execute", err)
+		return
 	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
 }
 
-func (h *AdminPanelHandler) renderUsersTemplate(c *gin.Context, templateName string, data UsersPageData) {
-	// Create a fresh template instance for this specific template to avoid conflicts
-	templateFiles := []string{
-		"templates/admin/base.html",
-		"templates/admin/" + templateName + ".html",
+// renderError logs a template failure and renders the error page in its place
+func (h *AdminPanelHandler) renderError(c *gin.Context, templateName, phase string, renderErr error) {
+	log.Printf("❌ Failed to %s admin template %s: %v", phase, templateName, renderErr)
+	h.logRenderFailure(c, templateName, phase, renderErr)
+
+	data := ErrorPageData{
+		Title:       "Page Unavailable",
+		Status:      http.StatusInternalServerError,
+		Message:     "This page could not be displayed. The error has been logged.",
+		Page:        templateName,
+		CurrentTime: time.Now(),
+	}
+
+	var buf bytes.Buffer
+	tmpl, err := parsePageTemplate(errorPageTemplate)
+	if err == nil {
+		err = tmpl.ExecuteTemplate(&buf, errorPageTemplate+".html", data)
 	}
-	
-	tmpl, err := template.New("").Funcs(template.FuncMap{
-		"formatTime": func(t time.Time) string {
-			return t.Format("2006-01-02 15:04:05")
-		},
-		"formatDate": func(t time.Time) string {
-			return t.Format("2006-01-02")
-		},
-		"add": func(a, b int) int {
-			return a + b
-		},
-		"sub": func(a, b int) int {
-			return a - b
-		},
-	}).ParseFiles(templateFiles...)
-	
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Template parsing failed: " + err.Error()})
-		return
+		// Last resort when the error page itself is broken
+		log.Printf("❌ Failed to render admin error page: %v", err)
+		buf.Reset()
+		buf.WriteString("<!DOCTYPE html><html><head><title>Page Unavailable</title></head><body><h1>500 - Page Unavailable</h1><p>This page could not be displayed. The error has been logged.</p></body></html>")
 	}
 
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	if err := tmpl.ExecuteTemplate(c.Writer, templateName + ".html", data); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-	}
+	c.Data(http.StatusInternalServerError, "text/html; charset=utf-8", buf.Bytes())
 }
 
-func (h *AdminPanelHandler) renderLogsTemplate(c *gin.Context, templateName string, data LogsPageData) {
-	// Create a fresh template instance for this specific template to avoid conflicts
-	templateFiles := []string{
-		"templates/admin/base.html",
-		"templates/admin/" + templateName + ".html",
+// parsePageTemplate parses a page template together with base.html, except
+// for the standalone error page. Templates are parsed per request so edits
+// show up without a restart.
+func parsePageTemplate(templateName string) (*template.Template, error) {
+	templateFiles := []string{"templates/admin/" + templateName + ".html"}
+	if templateName != errorPageTemplate {
+		templateFiles = append([]string{"templates/admin/base.html"}, templateFiles...)
 	}
-	
-	tmpl, err := template.New("").Funcs(template.FuncMap{
+
+	// Create a fresh template instance for this specific template to avoid conflicts
+	return template.New("").Funcs(template.FuncMap{
 		"formatTime": func(t time.Time) string {
 			return t.Format("2006-01-02 15:04:05")
 		},
@@ -455,49 +679,32 @@ func (h *AdminPanelHandler) renderLogsTemplate(c *gin.Context, templateName stri
 			return seq
 		},
 	}).ParseFiles(templateFiles...)
-	
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Template parsing failed: " + err.Error()})
-		return
-	}
-
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	if err := tmpl.ExecuteTemplate(c.Writer, templateName + ".html", data); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-	}
 }
 
-func (h *AdminPanelHandler) renderDashboardTemplate(c *gin.Context, templateName string, data DashboardPageData) {
-	// Create a fresh template instance for this specific template to avoid conflicts
-	templateFiles := []string{
-		"templates/admin/base.html",
-		"templates/admin/" + templateName + ".html",
-	}
-	
-	tmpl, err := template.New("").Funcs(template.FuncMap{
-		"formatTime": func(t time.Time) string {
-			return t.Format("2006-01-02 15:04:05")
-		},
-		"formatDate": func(t time.Time) string {
-			return t.Format("2006-01-02")
+// logRenderFailure records a template failure as a SYSTEM_ERROR event
+func (h *AdminPanelHandler) logRenderFailure(c *gin.Context, templateName, phase string, renderErr error) {
+	var userID *uuid.UUID
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		userID = &userClaims.UserID
+	}
+
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID: userID,
+		Event:  models.SystemError,
+		Action: "TEMPLATE_RENDER_FAILED",
+		Details: map[string]interface{}{
+			"template":   templateName,
+			"phase":      phase,
+			"path":       c.Request.URL.Path,
+			"ip_address": c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
 		},
-		"add": func(a, b int) int {
-			return a + b
-		},
-		"sub": func(a, b int) int {
-			return a - b
-		},
-	}).ParseFiles(templateFiles...)
-	
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Template parsing failed: " + err.Error()})
-		return
-	}
+		Error:     renderErr.Error(),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
 
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	if err := tmpl.ExecuteTemplate(c.Writer, templateName + ".html", data); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-	}
+	h.logRepo.CreateAsync(logEntry)
 }
 
 // SetupAdminPanelRoutes sets up the admin panel routes
@@ -520,6 +727,9 @@ func (h *AdminPanelHandler) SetupAdminPanelRoutes(router *gin.Engine, middleware
 		protected.GET("/logs", h.Logs)
 		protected.GET("/stats", h.Stats)
 		protected.GET("/deleted-users", h.DeletedUsers)
+		protected.GET("/sessions", h.Sessions)
+		protected.GET("/traces", h.Traces)
+		protected.GET("/notifications", h.Notifications)
 	}
 
 	// Serve static files for admin panel
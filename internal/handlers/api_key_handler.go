@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// APIKeyHandler handles the API key lifecycle. Keys are shared by all
+// admins; each acts with the permissions of the admin who created it.
+type APIKeyHandler struct {
+	keyRepo repository.APIKeyRepository
+	logRepo repository.UserLogRepository
+	policy  config.APIKeysConfig
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(
+	keyRepo repository.APIKeyRepository,
+	logRepo repository.UserLogRepository,
+	policy config.APIKeysConfig,
+) *APIKeyHandler {
+	return &APIKeyHandler{
+		keyRepo: keyRepo,
+		logRepo: logRepo,
+		policy:  policy,
+	}
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List all API keys, newest first, with their expiry, last use and rotation state. Keys themselves are never returned.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.APIKey
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.keyRepo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"API Keys Retrieval Failed",
+			"Failed to retrieve API keys",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey godoc
+// @Summary Create API key
+// @Description Create an API key acting as the current admin. The key is only returned in this response; send it in the X-API-Key header.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.APIKeyCreateRequest true "API key"
+// @Success 201 {object} models.APIKeySecretResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	if !h.requireInteractiveAdmin(c) {
+		return
+	}
+
+	var req models.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Request",
+			"Please provide a key name of at most 100 characters",
+			err.Error(),
+		))
+		return
+	}
+
+	allowlist, err := models.ParseIPAllowlist(req.AllowedIPs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Allowlist",
+			"Please provide valid addresses or CIDR ranges",
+			err.Error(),
+		))
+		return
+	}
+
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Expiry",
+			"expires_at must be in the future",
+			nil,
+		))
+		return
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	key := &models.APIKey{
+		ID:         uuid.New(),
+		Name:       req.Name,
+		OwnerID:    userClaims.UserID,
+		ExpiresAt:  req.ExpiresAt,
+		AllowedIPs: allowlist,
+		CreatedAt:  now,
+	}
+	response, ok := h.issueKey(c, key, now)
+	if !ok {
+		return
+	}
+	if err := h.keyRepo.Create(c.Request.Context(), key); err != nil {
+		respondRepositoryError(c, err, "API Key Creation Failed", "Failed to create API key")
+		return
+	}
+
+	// Log key creation
+	h.logAPIKeyChange(c, "API_KEY_CREATED", key, nil)
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate API key
+// @Description Replace an API key with a new one. The old key keeps working for the overlap window (api_keys.rotation_overlap unless overlap_hours is set) so clients can switch over. The new key is only returned in this response.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Param request body models.APIKeyRotateRequest false "Overlap window"
+// @Success 201 {object} models.APIKeySecretResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	if !h.requireInteractiveAdmin(c) {
+		return
+	}
+
+	// Parse key ID
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid API Key ID",
+			"Please provide a valid API key ID",
+			err.Error(),
+		))
+		return
+	}
+
+	// The body is optional
+	var req models.APIKeyRotateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Request",
+				"overlap_hours must be between 0 and 720",
+				err.Error(),
+			))
+			return
+		}
+	}
+	overlap := h.policy.RotationOverlap
+	if req.OverlapHours != nil {
+		overlap = time.Duration(*req.OverlapHours) * time.Hour
+	}
+
+	old, err := h.keyRepo.GetByID(c.Request.Context(), keyID)
+	if err != nil {
+		h.respondKeyError(c, err)
+		return
+	}
+
+	// The replacement acts as the same owner with the same lifetime and networks
+	now := time.Now()
+	replacement := &models.APIKey{
+		ID:         uuid.New(),
+		Name:       old.Name,
+		OwnerID:    old.OwnerID,
+		AllowedIPs: old.AllowedIPs,
+		CreatedAt:  now,
+	}
+	if old.ExpiresAt != nil {
+		expiresAt := now.Add(old.ExpiresAt.Sub(old.CreatedAt))
+		replacement.ExpiresAt = &expiresAt
+	}
+	response, ok := h.issueKey(c, replacement, now)
+	if !ok {
+		return
+	}
+	if err := h.keyRepo.Rotate(c.Request.Context(), old.ID, replacement, now.Add(overlap)); err != nil {
+		h.respondKeyError(c, err)
+		return
+	}
+
+	// Log key rotation
+	h.logAPIKeyChange(c, "API_KEY_ROTATED", replacement, map[string]interface{}{
+		"rotated_from":    old.ID,
+		"overlap":         overlap.String(),
+		"old_key_expires": now.Add(overlap),
+	})
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke API key
+// @Description Disable an API key immediately
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	// Parse key ID
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid API Key ID",
+			"Please provide a valid API key ID",
+			err.Error(),
+		))
+		return
+	}
+
+	key, err := h.keyRepo.GetByID(c.Request.Context(), keyID)
+	if err != nil {
+		h.respondKeyError(c, err)
+		return
+	}
+	if err := h.keyRepo.Revoke(c.Request.Context(), keyID); err != nil {
+		h.respondKeyError(c, err)
+		return
+	}
+
+	// Log key revocation
+	h.logAPIKeyChange(c, "API_KEY_REVOKED", key, nil)
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse(
+		"API key revoked successfully",
+		map[string]interface{}{
+			"api_key_id": keyID,
+		},
+	))
+}
+
+// requireInteractiveAdmin rejects requests authenticated with an API key,
+// so a leaked key cannot mint or rotate keys
+func (h *APIKeyHandler) requireInteractiveAdmin(c *gin.Context) bool {
+	if userClaims, exists := middleware.GetUserFromContext(c); exists && userClaims.APIKeyID != nil {
+		c.JSON(http.StatusForbidden, models.NewErrorResponse(
+			http.StatusForbidden,
+			"Forbidden",
+			"API keys cannot create or rotate API keys",
+			nil,
+		))
+		return false
+	}
+	return true
+}
+
+// issueKey generates the key material and rotation due date for a new key
+func (h *APIKeyHandler) issueKey(c *gin.Context, key *models.APIKey, now time.Time) (*models.APIKeySecretResponse, bool) {
+	rawKey, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"API Key Creation Failed",
+			"Failed to generate API key",
+			err.Error(),
+		))
+		return nil, false
+	}
+	key.Prefix = prefix
+	key.KeyHash = hash
+
+	// Keys expiring before rotation would be due need no reminder
+	if h.policy.RotationPeriod > 0 {
+		dueAt := now.Add(h.policy.RotationPeriod)
+		if key.ExpiresAt == nil || dueAt.Before(*key.ExpiresAt) {
+			key.RotationDueAt = &dueAt
+		}
+	}
+
+	return &models.APIKeySecretResponse{APIKey: *key, Key: rawKey}, true
+}
+
+// respondKeyError writes the response for an API key repository error
+func (h *APIKeyHandler) respondKeyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			http.StatusNotFound,
+			"API Key Not Found",
+			"No active API key with the specified ID was found",
+			err.Error(),
+		))
+	case errors.Is(err, repository.ErrAPIKeyInactive):
+		c.JSON(http.StatusConflict, models.NewErrorResponse(
+			http.StatusConflict,
+			"API Key Not Active",
+			"Only active keys that have not been rotated already can be rotated",
+			err.Error(),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"API Key Update Failed",
+			"Failed to update API key",
+			err.Error(),
+		))
+	}
+}
+
+// Helper methods for logging
+
+func (h *APIKeyHandler) logAPIKeyChange(c *gin.Context, action string, key *models.APIKey, extra map[string]interface{}) {
+	// Get admin from context
+	var adminID *uuid.UUID
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		adminID = &userClaims.UserID
+	}
+
+	details := map[string]interface{}{
+		"api_key_id": key.ID,
+		"name":       key.Name,
+		"prefix":     key.Prefix,
+		"owner_id":   key.OwnerID,
+		"ip_address": c.ClientIP(),
+		"user_agent": c.Request.UserAgent(),
+	}
+	if key.ExpiresAt != nil {
+		details["expires_at"] = key.ExpiresAt
+	}
+	for name, value := range extra {
+		details[name] = value
+	}
+
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID:    adminID,
+		Event:     models.APIKeyChange,
+		Action:    action,
+		Details:   details,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	h.logRepo.CreateAsync(logEntry)
+}
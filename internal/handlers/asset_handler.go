@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/internal/scanner"
+	"user_mgmt_go/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// avatarExtensions maps the accepted avatar content types to file extensions
+var avatarExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// servedAssetPrefixes lists the storage key prefixes served publicly under /assets/
+var servedAssetPrefixes = []string{"avatars/"}
+
+// AssetHandler handles uploading and serving user assets such as avatars
+type AssetHandler struct {
+	storage       storage.Storage
+	scanner       scanner.Scanner
+	userRepo      repository.UserRepository
+	logRepo       repository.UserLogRepository
+	avatarMaxSize int64         // In bytes
+	assetMaxAge   time.Duration // Cache-Control max-age
+}
+
+// NewAssetHandler creates a new asset handler
+func NewAssetHandler(
+	assetStorage storage.Storage,
+	uploadScanner scanner.Scanner,
+	userRepo repository.UserRepository,
+	logRepo repository.UserLogRepository,
+	avatarMaxSize int64,
+	assetMaxAge time.Duration,
+) *AssetHandler {
+	return &AssetHandler{
+		storage:       assetStorage,
+		scanner:       uploadScanner,
+		userRepo:      userRepo,
+		logRepo:       logRepo,
+		avatarMaxSize: avatarMaxSize,
+		assetMaxAge:   assetMaxAge,
+	}
+}
+
+// UploadAvatar godoc
+// @Summary Upload avatar
+// @Description Upload a PNG, JPEG, GIF or WebP avatar for a user. Every upload gets a new URL, so served avatars can be cached indefinitely. Uploads are scanned for malware before being stored.
+// @Tags users
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "User ID"
+// @Param file formData file true "Avatar image"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /users/{id}/avatar [put]
+func (h *AssetHandler) UploadAvatar(c *gin.Context) {
+	// Parse user ID
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid User ID",
+			"Please provide a valid user ID",
+			err.Error(),
+		))
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		h.respondUserLookupError(c, err)
+		return
+	}
+
+	// Read the upload, allowing for multipart overhead around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.avatarMaxSize+64*1024)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.respondAvatarTooLarge(c)
+			return
+		}
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Missing File",
+			"Please upload the avatar as the \"file\" form field",
+			err.Error(),
+		))
+		return
+	}
+	if fileHeader.Size > h.avatarMaxSize {
+		h.respondAvatarTooLarge(c)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid File",
+			"Failed to read the uploaded avatar",
+			err.Error(),
+		))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.avatarMaxSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid File",
+			"Failed to read the uploaded avatar",
+			err.Error(),
+		))
+		return
+	}
+
+	// Trust the content, not the client-supplied type or file name
+	ext, ok := avatarExtensions[http.DetectContentType(data)]
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Unsupported Image Type",
+			"Avatar must be a PNG, JPEG, GIF or WebP image",
+			nil,
+		))
+		return
+	}
+
+	// Scan before storing; infected avatars are never written
+	details := map[string]interface{}{"updated_user_id": user.ID}
+	if !scanUpload(c, h.scanner, h.logRepo, "avatar", fileHeader.Filename, bytes.NewReader(data), details) {
+		return
+	}
+
+	// Store under a new key so cached copies of the old avatar never go stale
+	key := fmt.Sprintf("avatars/%s-%d%s", user.ID, time.Now().Unix(), ext)
+	if _, err := h.storage.Put(c.Request.Context(), key, bytes.NewReader(data)); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Upload Failed",
+			"Failed to store avatar",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := h.userRepo.Update(c.Request.Context(), user.ID, map[string]interface{}{"avatar_key": key}); err != nil {
+		h.deleteAsset(c, key)
+		respondRepositoryError(c, err, "Upload Failed", "Failed to update user avatar")
+		return
+	}
+
+	oldKey := user.AvatarKey
+	if oldKey != "" {
+		h.deleteAsset(c, oldKey)
+	}
+	user.AvatarKey = key
+
+	// Log avatar change
+	h.logAvatarChange(c, user, "UPDATE_AVATAR", oldKey)
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// DeleteAvatar godoc
+// @Summary Delete avatar
+// @Description Remove a user's avatar
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/{id}/avatar [delete]
+func (h *AssetHandler) DeleteAvatar(c *gin.Context) {
+	// Parse user ID
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid User ID",
+			"Please provide a valid user ID",
+			err.Error(),
+		))
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		h.respondUserLookupError(c, err)
+		return
+	}
+
+	if user.AvatarKey == "" {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			http.StatusNotFound,
+			"Avatar Not Found",
+			"User has no avatar",
+			nil,
+		))
+		return
+	}
+
+	if err := h.userRepo.Update(c.Request.Context(), user.ID, map[string]interface{}{"avatar_key": nil}); err != nil {
+		respondRepositoryError(c, err, "Deletion Failed", "Failed to remove user avatar")
+		return
+	}
+
+	oldKey := user.AvatarKey
+	h.deleteAsset(c, oldKey)
+	user.AvatarKey = ""
+
+	// Log avatar removal
+	h.logAvatarChange(c, user, "DELETE_AVATAR", oldKey)
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// ServeAsset godoc
+// @Summary Serve a user asset
+// @Description Serve an uploaded user asset such as an avatar. Responses carry Cache-Control and ETag headers and honour If-None-Match, If-Modified-Since and Range requests.
+// @Tags assets
+// @Produce image/png,image/jpeg,image/gif,image/webp
+// @Param key path string true "Asset key, e.g. avatars/<file>"
+// @Success 200 {file} binary
+// @Success 304 "Not modified"
+// @Failure 404 {object} models.ErrorResponse
+// @Router /assets/{key} [get]
+func (h *AssetHandler) ServeAsset(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	// Only serve known asset kinds
+	served := false
+	for _, prefix := range servedAssetPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			served = true
+			break
+		}
+	}
+
+	var file io.ReadSeekCloser
+	var object *storage.Object
+	var err error
+	if served {
+		file, object, err = h.storage.Get(c.Request.Context(), key)
+	}
+	if !served || errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			http.StatusNotFound,
+			"Asset Not Found",
+			"The requested asset does not exist",
+			nil,
+		))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Asset Retrieval Failed",
+			"Failed to read the requested asset",
+			err.Error(),
+		))
+		return
+	}
+	defer file.Close()
+
+	// Keys change on every upload, so content under a key never changes
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int(h.assetMaxAge.Seconds())))
+	c.Header("ETag", object.ETag)
+	c.Header("Content-Type", object.ContentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	// ServeContent answers conditional and range requests using the headers above
+	http.ServeContent(c.Writer, c.Request, "", object.ModTime, file)
+}
+
+// respondUserLookupError maps user lookup errors to HTTP responses
+func (h *AssetHandler) respondUserLookupError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			http.StatusNotFound,
+			"User Not Found",
+			"User with the specified ID does not exist",
+			nil,
+		))
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+		http.StatusInternalServerError,
+		"Database Error",
+		"Failed to retrieve user",
+		err.Error(),
+	))
+}
+
+func (h *AssetHandler) respondAvatarTooLarge(c *gin.Context) {
+	c.JSON(http.StatusRequestEntityTooLarge, models.NewErrorResponse(
+		http.StatusRequestEntityTooLarge,
+		"File Too Large",
+		fmt.Sprintf("Avatar must be at most %d KB", h.avatarMaxSize/1024),
+		nil,
+	))
+}
+
+// deleteAsset removes an asset that is no longer referenced; failures only
+// leave an orphaned file behind
+func (h *AssetHandler) deleteAsset(c *gin.Context, key string) {
+	if err := h.storage.Delete(c.Request.Context(), key); err != nil {
+		log.Printf("Failed to delete asset %s: %v", key, err)
+	}
+}
+
+// Helper methods for logging
+
+func (h *AssetHandler) logAvatarChange(c *gin.Context, user *models.User, action, oldKey string) {
+	// Get updater from context
+	var updaterID *uuid.UUID
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		updaterID = &userClaims.UserID
+	}
+
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID: updaterID,
+		Event:  models.UserUpdated,
+		Action: action,
+		Details: map[string]interface{}{
+			"updated_user_id": user.ID,
+			"ip_address":      c.ClientIP(),
+			"user_agent":      c.Request.UserAgent(),
+		},
+		OldValues: map[string]interface{}{
+			"avatar_key": oldKey,
+		},
+		NewValues: map[string]interface{}{
+			"avatar_key": user.AvatarKey,
+		},
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	h.logRepo.CreateAsync(logEntry)
+}
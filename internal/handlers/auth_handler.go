@@ -1,39 +1,82 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"user_mgmt_go/internal/config"
 	"user_mgmt_go/internal/middleware"
 	"user_mgmt_go/internal/models"
 	"user_mgmt_go/internal/repository"
 	"user_mgmt_go/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // AuthHandler handles authentication-related requests
 type AuthHandler struct {
-	jwtManager  *utils.JWTManager
-	userRepo    repository.UserRepository
-	logRepo     repository.UserLogRepository
+	jwtManager   *utils.JWTManager
+	userRepo     repository.UserRepository
+	logRepo      repository.UserLogRepository
+	sessionRepo  repository.SessionRepository
+	identityRepo repository.IdentityRepository
+
+	loginLimiter *middleware.LoginLimiter
+	dpop         *utils.DPoPVerifier
+	dpopMode     string
+	profiles     *middleware.ProfileRequirements
+
+	// softLaunch is nil when soft launch is disabled
+	softLaunch *config.SoftLaunchConfig
+
+	// sandbox is nil when the sandbox tenant is disabled
+	sandbox *config.SandboxConfig
 }
 
+// AdminPanelPageHeader carries the admin panel page a login or logout was
+// made from. The panel's scripts send it so panel access is audited as
+// ADMIN_LOGIN/ADMIN_LOGOUT, apart from API logins.
+const AdminPanelPageHeader = "X-Admin-Panel-Page"
+
+// maxPanelPageLength caps the panel page recorded in the audit log
+const maxPanelPageLength = 200
+
 // NewAuthHandler creates a new authentication handler
 func NewAuthHandler(
 	jwtManager *utils.JWTManager,
 	userRepo repository.UserRepository,
 	logRepo repository.UserLogRepository,
+	sessionRepo repository.SessionRepository,
+	identityRepo repository.IdentityRepository,
+	loginLimiter *middleware.LoginLimiter,
+	dpop *utils.DPoPVerifier,
+	dpopMode string,
+	profiles *middleware.ProfileRequirements,
+	softLaunch *config.SoftLaunchConfig,
+	sandbox *config.SandboxConfig,
 ) *AuthHandler {
 	return &AuthHandler{
-		jwtManager: jwtManager,
-		userRepo:   userRepo,
-		logRepo:    logRepo,
+		jwtManager:   jwtManager,
+		userRepo:     userRepo,
+		logRepo:      logRepo,
+		sessionRepo:  sessionRepo,
+		identityRepo: identityRepo,
+		loginLimiter: loginLimiter,
+		dpop:         dpop,
+		dpopMode:     dpopMode,
+		profiles:     profiles,
+		softLaunch:   softLaunch,
+		sandbox:      sandbox,
 	}
 }
 
 // Login godoc
 // @Summary Admin login
-// @Description Authenticate admin user and return JWT tokens
+// @Description Authenticate admin user and return JWT tokens. With DPoP enabled, a DPoP proof header binds the tokens to the client's key. During soft launch, users not yet admitted are refused with 403.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -41,6 +84,8 @@ func NewAuthHandler(
 // @Success 200 {object} models.LoginResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -57,18 +102,31 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// Reject locked accounts before checking the password
+	if status := h.loginLimiter.Status(req.Email); status.Locked {
+		h.logFailedLogin(c, req.Email, "Account locked")
+		h.respondAccountLocked(c, status)
+		return
+	}
+
+	// Bind the tokens to the client's key when it sends a DPoP proof
+	jkt, err := h.loginKeyThumbprint(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid DPoP Proof",
+			"Please sign a fresh DPoP proof for this request",
+			err.Error(),
+		))
+		return
+	}
+
 	// Get user by email
 	user, err := h.userRepo.GetByEmail(c.Request.Context(), req.Email)
 	if err != nil {
 		// Log failed login attempt
 		h.logFailedLogin(c, req.Email, "User not found")
-		
-		c.JSON(http.StatusUnauthorized, models.NewErrorResponse(
-			http.StatusUnauthorized,
-			"Invalid Credentials",
-			"Invalid email or password",
-			nil,
-		))
+		h.respondInvalidCredentials(c, req.Email)
 		return
 	}
 
@@ -76,24 +134,66 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	if err := utils.VerifyPassword(user.Password, req.Password); err != nil {
 		// Log failed login attempt
 		h.logFailedLogin(c, req.Email, "Invalid password")
-		
-		c.JSON(http.StatusUnauthorized, models.NewErrorResponse(
-			http.StatusUnauthorized,
-			"Invalid Credentials", 
-			"Invalid email or password",
-			nil,
+		h.respondInvalidCredentials(c, req.Email)
+		return
+	}
+
+	h.loginLimiter.Reset(req.Email)
+
+	// Check the account may be used from this network
+	if !user.AllowedIPs.Allows(c.ClientIP()) {
+		h.logFailedLogin(c, req.Email, "IP not allowed")
+		c.JSON(http.StatusForbidden, models.NewErrorResponse(
+			http.StatusForbidden,
+			"IP Not Allowed",
+			"This account cannot be used from your network",
+			map[string]string{"ip_address": c.ClientIP()},
 		))
 		return
 	}
 
-	// Determine user role (for this assignment, we'll use admin for the configured admin email)
-	role := "user"
-	if req.Email == "admin@example.com" { // This should come from config
-		role = "admin"
+	// Determine user role
+	role := user.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	// Only admit selected users during soft launch
+	if h.softLaunch != nil && !h.softLaunch.Admits(user.ID.String(), user.Email, role) {
+		h.logFailedLogin(c, req.Email, "Not yet enabled")
+		middleware.RespondNotYetEnabled(c, h.softLaunch)
+		return
+	}
+
+	// Check required profile fields; admins are never held back by them
+	var missingFields []string
+	if role == models.RoleUser && h.profiles != nil {
+		required, err := h.profiles.Required(c.Request.Context())
+		if err == nil {
+			missingFields = user.Profile.Missing(required)
+		}
+		user.ProfileIncomplete = len(missingFields) > 0
+	}
+
+	// Start a session; it lives as long as the refresh token
+	now := time.Now()
+	session := &models.Session{
+		ID:             uuid.New(),
+		UserID:         user.ID,
+		Role:           role,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.GetHeader("User-Agent"),
+		CreatedAt:      now,
+		LastActivityAt: now,
+		ExpiresAt:      now.Add(h.jwtManager.GetRefreshExpiryTime()),
+	}
+	if err := h.sessionRepo.Create(c.Request.Context(), session); err != nil {
+		respondRepositoryError(c, err, "Session Creation Failed", "Failed to start a session")
+		return
 	}
 
 	// Generate JWT tokens
-	tokenPair, err := h.jwtManager.GenerateTokenPair(user, role)
+	tokenPair, err := h.jwtManager.GenerateBoundTokenPair(user, role, session.ID.String(), jkt)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
 			http.StatusInternalServerError,
@@ -104,30 +204,45 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Log successful login
-	h.logSuccessfulLogin(c, user)
+	// Log successful login; admins signing in to the panel get their own event
+	if page, ok := adminPanelPage(c); ok && role == models.RoleAdmin {
+		h.logAdminPanelLogin(c, user, session.ID.String(), page)
+	} else {
+		h.logSuccessfulLogin(c, user)
+	}
 
-	// Always set HTTP-only cookies for admin panel usage
-	// This allows the admin panel to work with server-side authentication
-	c.SetCookie("admin_token", tokenPair.AccessToken, 3600, "/", "", false, true)
-	if tokenPair.RefreshToken != "" {
-		c.SetCookie("admin_refresh_token", tokenPair.RefreshToken, 7*24*3600, "/", "", false, true)
+	// Set HTTP-only cookies for admin panel usage
+	// This allows the admin panel to work with server-side authentication;
+	// bound tokens are useless in cookies, as browsers cannot sign proofs
+	tokenType := "Bearer"
+	if jkt == "" {
+		c.SetCookie("admin_token", tokenPair.AccessToken, 3600, "/", "", false, true)
+		if tokenPair.RefreshToken != "" {
+			c.SetCookie("admin_refresh_token", tokenPair.RefreshToken, 7*24*3600, "/", "", false, true)
+		}
+	} else {
+		tokenType = "DPoP"
 	}
 
 	// Return login response
 	response := models.LoginResponse{
-		Token:        tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresAt:    tokenPair.ExpiresAt,
-		User:         user.ToResponse(),
+		Token:                  tokenPair.AccessToken,
+		TokenType:              tokenType,
+		RefreshToken:           tokenPair.RefreshToken,
+		ExpiresAt:              tokenPair.ExpiresAt,
+		User:                   user.ToResponse(),
+		PasswordChangeRequired: user.MustChangePassword,
+		MissingProfileFields:   missingFields,
+		Sandbox:                h.sandbox != nil && h.sandbox.Contains(user.Email),
 	}
 
+	middleware.SetSandboxHeaders(c, h.sandbox, user.Email)
 	c.JSON(http.StatusOK, response)
 }
 
 // RefreshToken godoc
 // @Summary Refresh access token
-// @Description Generate new access token using refresh token
+// @Description Generate new access token using refresh token. Refresh tokens bound to a DPoP key need a DPoP header signed by that key, and tokens of revoked or expired sessions are refused.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -136,6 +251,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
 // @Router /auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req models.RefreshTokenRequest
@@ -151,8 +267,38 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
+	// Bound refresh tokens need a proof signed by the bound key
+	var proofJKT string
+	if proof := c.GetHeader(utils.DPoPHeader); proof != "" {
+		jkt, err := h.dpop.Verify(proof, c.Request.Method, utils.DPoPTargetURI(c.Request), "")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid DPoP Proof",
+				"Please sign a fresh DPoP proof for this request",
+				err.Error(),
+			))
+			return
+		}
+		proofJKT = jkt
+	}
+
+	// Refuse refresh tokens of revoked or expired sessions; invalid tokens
+	// are rejected by RefreshAccessToken below
+	if claims, err := h.jwtManager.ValidateToken(req.RefreshToken); err == nil {
+		if status, err := middleware.ValidateSession(c, h.sessionRepo, claims); err != nil {
+			c.JSON(status, models.NewErrorResponse(
+				status,
+				http.StatusText(status),
+				err.Error(),
+				nil,
+			))
+			return
+		}
+	}
+
 	// Generate new access token using refresh token
-	response, err := h.jwtManager.RefreshAccessToken(req.RefreshToken)
+	response, err := h.jwtManager.RefreshAccessToken(req.RefreshToken, proofJKT)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, models.NewErrorResponse(
 			http.StatusUnauthorized,
@@ -171,7 +317,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 
 // Logout godoc
 // @Summary User logout
-// @Description Logout user (client-side token invalidation)
+// @Description Logout user and revoke the current session so its tokens stop working
 // @Tags auth
 // @Security BearerAuth
 // @Produce json
@@ -191,14 +337,27 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	// Log logout
-	h.logUserLogout(c, userClaims)
+	// Revoke the session so its access and refresh tokens stop working
+	if sessionID, err := uuid.Parse(userClaims.SessionID); err == nil {
+		if err := h.sessionRepo.Revoke(c.Request.Context(), sessionID, userClaims.UserID); err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				http.StatusInternalServerError,
+				"Logout Failed",
+				"Failed to end the session",
+				err.Error(),
+			))
+			return
+		}
+	}
 
-	// Note: In a production system, you would typically:
-	// 1. Add token to a blacklist/revocation list
-	// 2. Store revoked tokens in Redis with expiry
-	// 3. Check blacklist in JWT middleware
-	// For this assignment, we'll rely on client-side token removal
+	// Log logout; logging out of the panel also clears its cookies
+	if page, ok := adminPanelPage(c); ok || cookieAuthenticated(c, userClaims) {
+		c.SetCookie("admin_token", "", -1, "/", "", false, true)
+		c.SetCookie("admin_refresh_token", "", -1, "/", "", false, true)
+		h.logAdminPanelLogout(c, userClaims, page)
+	} else {
+		h.logUserLogout(c, userClaims)
+	}
 
 	c.JSON(http.StatusOK, models.NewSuccessResponse(
 		"Logout successful",
@@ -234,16 +393,16 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	// Get full user data from database
 	user, err := h.userRepo.GetByID(c.Request.Context(), userClaims.UserID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.NewErrorResponse(
-			http.StatusNotFound,
-			"User Not Found",
-			"User profile not found",
-			err.Error(),
-		))
+		respondRepositoryError(c, err, "User Not Found", "User profile not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, user.ToResponse())
+	response := user.ToResponse()
+	if identities, err := h.identityRepo.ListForUser(c.Request.Context(), user.ID); err == nil {
+		response.LinkedIdentities = identities
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // ChangePassword godoc
@@ -288,12 +447,7 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	// Get user from database
 	user, err := h.userRepo.GetByID(c.Request.Context(), userClaims.UserID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.NewErrorResponse(
-			http.StatusNotFound,
-			"User Not Found",
-			"User not found",
-			err.Error(),
-		))
+		respondRepositoryError(c, err, "User Not Found", "User not found")
 		return
 	}
 
@@ -333,22 +487,47 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 
 	// Update password in database
 	updates := map[string]interface{}{
-		"password": hashedPassword,
+		"password":             hashedPassword,
+		"must_change_password": false,
 	}
 	
 	if err := h.userRepo.Update(c.Request.Context(), user.ID, updates); err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			http.StatusInternalServerError,
-			"Update Failed",
-			"Failed to update password",
-			err.Error(),
-		))
+		respondRepositoryError(c, err, "Update Failed", "Failed to update password")
 		return
 	}
 
 	// Log password change
 	h.logPasswordChange(c, user)
 
+	// Tokens issued before a required change are restricted, so hand out
+	// unrestricted ones now that the requirement is cleared
+	if userClaims.PasswordChangeRequired {
+		user.MustChangePassword = false
+		tokenPair, err := h.jwtManager.GenerateBoundTokenPair(user, userClaims.Role, userClaims.SessionID, userClaims.BoundKey())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				http.StatusInternalServerError,
+				"Token Generation Failed",
+				"Password changed, but failed to generate new tokens; please log in again",
+				err.Error(),
+			))
+			return
+		}
+
+		if userClaims.BoundKey() == "" {
+			c.SetCookie("admin_token", tokenPair.AccessToken, 3600, "/", "", false, true)
+			if tokenPair.RefreshToken != "" {
+				c.SetCookie("admin_refresh_token", tokenPair.RefreshToken, 7*24*3600, "/", "", false, true)
+			}
+		}
+
+		c.JSON(http.StatusOK, models.NewSuccessResponse(
+			"Password changed successfully",
+			tokenPair,
+		))
+		return
+	}
+
 	c.JSON(http.StatusOK, models.NewSuccessResponse(
 		"Password changed successfully",
 		nil,
@@ -361,6 +540,64 @@ type ChangePasswordRequest struct {
 	NewPassword     string `json:"new_password" binding:"required,min=6" example:"newpassword123"`
 }
 
+// loginKeyThumbprint verifies the login's DPoP proof and returns the
+// thumbprint of its key, or an empty string when tokens should not be bound
+func (h *AuthHandler) loginKeyThumbprint(c *gin.Context) (string, error) {
+	if h.dpopMode == config.DPoPDisabled {
+		return "", nil
+	}
+
+	proof := c.GetHeader(utils.DPoPHeader)
+	if proof == "" {
+		if h.dpopMode == config.DPoPRequired {
+			return "", errors.New("a DPoP proof is required to log in")
+		}
+		return "", nil
+	}
+
+	return h.dpop.Verify(proof, c.Request.Method, utils.DPoPTargetURI(c.Request), "")
+}
+
+// respondInvalidCredentials counts a failed login and writes a 401 response,
+// or a 429 response when the failure locks the account. Unknown emails count
+// too, so responses do not reveal which accounts exist.
+func (h *AuthHandler) respondInvalidCredentials(c *gin.Context, email string) {
+	if !h.loginLimiter.Enabled() {
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse(
+			http.StatusUnauthorized,
+			"Invalid Credentials",
+			"Invalid email or password",
+			nil,
+		))
+		return
+	}
+
+	status := h.loginLimiter.RecordFailure(email)
+	if status.Locked {
+		h.respondAccountLocked(c, status)
+		return
+	}
+
+	c.JSON(http.StatusUnauthorized, models.NewErrorResponse(
+		http.StatusUnauthorized,
+		"Invalid Credentials",
+		"Invalid email or password",
+		status.Details(),
+	))
+}
+
+// respondAccountLocked writes a 429 response with Retry-After for a locked account
+func (h *AuthHandler) respondAccountLocked(c *gin.Context, status middleware.LoginThrottleStatus) {
+	details := status.Details()
+	c.Header("Retry-After", fmt.Sprintf("%d", details["retry_after_seconds"]))
+	c.JSON(http.StatusTooManyRequests, models.NewErrorResponse(
+		http.StatusTooManyRequests,
+		"Account Locked",
+		"Too many failed login attempts, please try again later",
+		details,
+	))
+}
+
 // Helper methods for logging
 
 func (h *AuthHandler) logFailedLogin(c *gin.Context, email, reason string) {
@@ -399,6 +636,26 @@ func (h *AuthHandler) logSuccessfulLogin(c *gin.Context, user *models.User) {
 	h.logRepo.CreateAsync(logEntry)
 }
 
+func (h *AuthHandler) logAdminPanelLogin(c *gin.Context, user *models.User, sessionID, page string) {
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID: &user.ID,
+		Event:  models.AdminLogin,
+		Action: "ADMIN_PANEL_LOGIN",
+		Details: map[string]interface{}{
+			"email":      user.Email,
+			"name":       user.Name,
+			"panel_page": page,
+			"session_id": sessionID,
+			"ip_address": c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+		},
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	h.logRepo.CreateAsync(logEntry)
+}
+
 func (h *AuthHandler) logTokenRefresh(c *gin.Context) {
 	logEntry := models.NewUserLog(models.UserLogCreateRequest{
 		Event:  models.TokenRefresh,
@@ -417,7 +674,7 @@ func (h *AuthHandler) logTokenRefresh(c *gin.Context) {
 func (h *AuthHandler) logUserLogout(c *gin.Context, user *models.JWTClaims) {
 	logEntry := models.NewUserLog(models.UserLogCreateRequest{
 		UserID: &user.UserID,
-		Event:  models.AdminLogout,
+		Event:  models.UserLogout,
 		Action: "USER_LOGOUT",
 		Details: map[string]interface{}{
 			"email":      user.Email,
@@ -432,6 +689,26 @@ func (h *AuthHandler) logUserLogout(c *gin.Context, user *models.JWTClaims) {
 	h.logRepo.CreateAsync(logEntry)
 }
 
+func (h *AuthHandler) logAdminPanelLogout(c *gin.Context, user *models.JWTClaims, page string) {
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID: &user.UserID,
+		Event:  models.AdminLogout,
+		Action: "ADMIN_PANEL_LOGOUT",
+		Details: map[string]interface{}{
+			"email":      user.Email,
+			"name":       user.Name,
+			"panel_page": page,
+			"session_id": user.SessionID,
+			"ip_address": c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+		},
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	h.logRepo.CreateAsync(logEntry)
+}
+
 func (h *AuthHandler) logPasswordChange(c *gin.Context, user *models.User) {
 	logEntry := models.NewUserLog(models.UserLogCreateRequest{
 		UserID: &user.ID,
@@ -448,4 +725,20 @@ func (h *AuthHandler) logPasswordChange(c *gin.Context, user *models.User) {
 	})
 
 	h.logRepo.CreateAsync(logEntry)
-} 
\ No newline at end of file
+}
+
+// adminPanelPage returns the panel page sent in AdminPanelPageHeader, and
+// whether the request came from the admin panel
+func adminPanelPage(c *gin.Context) (string, bool) {
+	page := strings.TrimSpace(c.GetHeader(AdminPanelPageHeader))
+	if len(page) > maxPanelPageLength {
+		page = page[:maxPanelPageLength]
+	}
+	return page, page != ""
+}
+
+// cookieAuthenticated reports whether the request authenticated with the
+// admin panel's admin_token cookie rather than an Authorization header or API key
+func cookieAuthenticated(c *gin.Context, claims *models.JWTClaims) bool {
+	return claims.APIKeyID == nil && c.GetHeader("Authorization") == ""
+}
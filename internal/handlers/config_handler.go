@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	"user_mgmt_go/internal/buildinfo"
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigHandler exports and imports configuration snapshots, to replicate
+// environments and restore them after a disaster
+type ConfigHandler struct {
+	cfg       *config.Config
+	fieldRepo repository.ProfileFieldRepository
+	logRepo   repository.UserLogRepository
+	profiles  *middleware.ProfileRequirements
+	cache     *middleware.ResponseCache
+}
+
+// NewConfigHandler creates a new config handler
+func NewConfigHandler(
+	cfg *config.Config,
+	fieldRepo repository.ProfileFieldRepository,
+	logRepo repository.UserLogRepository,
+	profiles *middleware.ProfileRequirements,
+	cache *middleware.ResponseCache,
+) *ConfigHandler {
+	return &ConfigHandler{
+		cfg:       cfg,
+		fieldRepo: fieldRepo,
+		logRepo:   logRepo,
+		profiles:  profiles,
+		cache:     cache,
+	}
+}
+
+// ExportConfig godoc
+// @Summary Export configuration snapshot
+// @Description Export the effective configuration, with passwords, secrets, the MongoDB URI and webhook URLs redacted, together with the settings stored in the database
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.ConfigSnapshot
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/config/export [get]
+func (h *ConfigHandler) ExportConfig(c *gin.Context) {
+	fields, err := h.fieldRepo.ListRequired(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Config Export Failed",
+			"Failed to read runtime settings",
+			err.Error(),
+		))
+		return
+	}
+
+	required := make([]string, len(fields))
+	for i, field := range fields {
+		required[i] = field.Field
+	}
+
+	snapshot := models.ConfigSnapshot{
+		Version:    models.ConfigSnapshotVersion,
+		ExportedAt: time.Now().UTC(),
+		AppVersion: buildinfo.Version,
+		Config:     h.cfg.Settings(),
+		Settings: models.ConfigSnapshotSettings{
+			RequiredProfileFields: required,
+		},
+	}
+
+	// Log export
+	h.logConfigChange(c, "CONFIG_EXPORTED", nil)
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="config-snapshot-%s.json"`, snapshot.ExportedAt.Format("20060102-150405")))
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// ImportConfig godoc
+// @Summary Import configuration snapshot
+// @Description Apply the runtime settings of an exported snapshot and report the static configuration keys that differ from it. Static configuration is never changed at runtime; those keys must be updated in config.yaml or the environment, followed by a restart. Redacted values are not compared.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "Report changes without applying them"
+// @Param request body models.ConfigSnapshot true "Snapshot"
+// @Success 200 {object} models.ConfigImportResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/config/import [post]
+func (h *ConfigHandler) ImportConfig(c *gin.Context) {
+	var snapshot models.ConfigSnapshot
+	if err := c.ShouldBindJSON(&snapshot); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Snapshot",
+			"Please provide a snapshot exported from /api/admin/config/export",
+			err.Error(),
+		))
+		return
+	}
+	if snapshot.Version != models.ConfigSnapshotVersion {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Unsupported Snapshot Version",
+			fmt.Sprintf("Only version %d snapshots can be imported", models.ConfigSnapshotVersion),
+			map[string]int{"version": snapshot.Version},
+		))
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	// Compare static configuration
+	restartRequired := []string{}
+	if snapshot.Config != nil {
+		diff, err := config.DiffSettings(h.cfg.Settings(), snapshot.Config)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Snapshot",
+				"The snapshot configuration could not be compared",
+				err.Error(),
+			))
+			return
+		}
+		restartRequired = diff
+	}
+
+	// Apply runtime settings
+	applied := []string{}
+	if fields := snapshot.Settings.RequiredProfileFields; fields != nil {
+		changed, ok := h.importRequiredProfileFields(c, fields, dryRun)
+		if !ok {
+			return
+		}
+		if changed {
+			applied = append(applied, "required_profile_fields")
+		}
+	}
+
+	// Cached metadata responses may depend on the applied settings
+	if !dryRun && len(applied) > 0 && h.cache != nil {
+		h.cache.Invalidate()
+	}
+
+	// Log import
+	if !dryRun {
+		h.logConfigChange(c, "CONFIG_IMPORTED", map[string]interface{}{
+			"snapshot_exported_at": snapshot.ExportedAt,
+			"snapshot_app_version": snapshot.AppVersion,
+			"applied":              applied,
+			"restart_required":     restartRequired,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.ConfigImportResponse{
+		DryRun:          dryRun,
+		Applied:         applied,
+		RestartRequired: restartRequired,
+	})
+}
+
+// importRequiredProfileFields replaces the required profile fields unless
+// dryRun is set, reporting whether they change. It writes the error
+// response when the fields are invalid or cannot be saved.
+func (h *ConfigHandler) importRequiredProfileFields(c *gin.Context, fields []string, dryRun bool) (bool, bool) {
+	if len(fields) > models.MaxProfileFields {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Snapshot",
+			fmt.Sprintf("At most %d required profile fields are allowed", models.MaxProfileFields),
+			nil,
+		))
+		return false, false
+	}
+	for _, field := range fields {
+		if !models.IsValidProfileField(field) {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Profile Field",
+				"Profile field names must be lowercase identifiers",
+				map[string]string{"field": field},
+			))
+			return false, false
+		}
+	}
+
+	current, err := h.fieldRepo.ListRequired(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Config Import Failed",
+			"Failed to read runtime settings",
+			err.Error(),
+		))
+		return false, false
+	}
+	currentFields := make([]string, len(current))
+	for i, field := range current {
+		currentFields[i] = field.Field
+	}
+	wanted := append([]string{}, fields...)
+	sort.Strings(currentFields)
+	sort.Strings(wanted)
+	changed := !reflect.DeepEqual(currentFields, wanted)
+	if !changed || dryRun {
+		return changed, true
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	if err := h.fieldRepo.SetRequired(c.Request.Context(), fields, userClaims.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Config Import Failed",
+			"Failed to update required profile fields",
+			err.Error(),
+		))
+		return false, false
+	}
+	if h.profiles != nil {
+		h.profiles.Invalidate()
+	}
+	return true, true
+}
+
+// Helper methods for logging
+
+func (h *ConfigHandler) logConfigChange(c *gin.Context, action string, extra map[string]interface{}) {
+	userClaims, _ := middleware.GetUserFromContext(c)
+
+	details := map[string]interface{}{
+		"ip_address": c.ClientIP(),
+		"user_agent": c.Request.UserAgent(),
+	}
+	for key, value := range extra {
+		details[key] = value
+	}
+
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID:    &userClaims.UserID,
+		Event:     models.ConfigChange,
+		Action:    action,
+		Details:   details,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	h.logRepo.CreateAsync(logEntry)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// directoryMaxPageSize caps directory pages, which are meant for pickers
+const directoryMaxPageSize = 50
+
+// DirectoryHandler serves the user directory to signed-in users
+type DirectoryHandler struct {
+	userRepo  repository.UserRepository
+	showEmail bool
+}
+
+// NewDirectoryHandler creates a new directory handler
+func NewDirectoryHandler(userRepo repository.UserRepository, showEmail bool) *DirectoryHandler {
+	return &DirectoryHandler{
+		userRepo:  userRepo,
+		showEmail: showEmail,
+	}
+}
+
+// GetDirectory godoc
+// @Summary User directory
+// @Description List active users by name for people pickers. Only names and avatars are returned, plus emails when the directory is configured to show them; searches match emails only then.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param q query string false "Search term"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size (max 50)" default(20)
+// @Success 200 {object} models.DirectoryResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /directory [get]
+func (h *DirectoryHandler) GetDirectory(c *gin.Context) {
+	// Parse pagination parameters
+	params := repository.ListParams{
+		Page:     1,
+		PageSize: 20,
+		SortBy:   "name",
+		SortDir:  "asc",
+	}
+
+	if page, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && page > 0 {
+		params.Page = page
+	}
+
+	if pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20")); err == nil && pageSize > 0 && pageSize <= directoryMaxPageSize {
+		params.PageSize = pageSize
+	}
+
+	// Hidden emails must not be searchable either, or searches would reveal them
+	var response *models.UsersListResponse
+	var err error
+	switch query := c.Query("q"); {
+	case query == "":
+		response, err = h.userRepo.List(c.Request.Context(), params)
+	case h.showEmail:
+		response, err = h.userRepo.Search(c.Request.Context(), query, params)
+	default:
+		response, err = h.userRepo.SearchByName(c.Request.Context(), query, params)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Directory Failed",
+			"Failed to retrieve the user directory",
+			err.Error(),
+		))
+		return
+	}
+
+	entries := make([]models.DirectoryEntry, len(response.Users))
+	for i, user := range response.Users {
+		entries[i] = user.ToDirectoryEntry(h.showEmail)
+	}
+
+	c.JSON(http.StatusOK, models.DirectoryResponse{
+		Users:      entries,
+		Total:      response.Total,
+		Page:       response.Page,
+		PageSize:   response.PageSize,
+		TotalPages: response.TotalPages,
+	})
+}
@@ -1,8 +1,20 @@
 package handlers
 
 import (
+	"time"
+
+	"user_mgmt_go/internal/buildinfo"
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/importer"
+	"user_mgmt_go/internal/jobs"
+	"user_mgmt_go/internal/mailer"
+	"user_mgmt_go/internal/maintenance"
 	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/oidc"
 	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/internal/scanner"
+	"user_mgmt_go/internal/storage"
 	"user_mgmt_go/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -10,13 +22,32 @@ import (
 
 // HandlerManager manages all API handlers
 type HandlerManager struct {
-	AuthHandler       *AuthHandler
-	UserHandler       *UserHandler
-	AdminHandler      *AdminHandler
-	AdminPanelHandler *AdminPanelHandler
-	LogHandler        *LogHandler
-	
+	AuthHandler         *AuthHandler
+	UserHandler         *UserHandler
+	AdminHandler        *AdminHandler
+	AdminPanelHandler   *AdminPanelHandler
+	LogHandler          *LogHandler
+	SetupHandler        *SetupHandler
+	RoleHandler         *RoleHandler
+	AssetHandler        *AssetHandler
+	PreferenceHandler   *PreferenceHandler
+	NotificationHandler *NotificationHandler
+	IdentityHandler     *IdentityHandler
+	ProfileHandler      *ProfileFieldHandler
+	SecurityHandler     *SecurityHandler
+	APIKeyHandler       *APIKeyHandler
+	AllowlistHandler    *IPAllowlistHandler
+	ConfigHandler       *ConfigHandler
+	OIDCHandler         *OIDCHandler      // nil when the OIDC provider is disabled
+	DirectoryHandler    *DirectoryHandler // nil when the user directory is disabled
+	LogIngestHandler    *LogIngestHandler // nil when log ingestion is disabled
+	ReportHandler       *ReportHandler    // nil when reports are disabled
+
 	middlewareManager *middleware.MiddlewareManager
+	maintenance       *maintenance.Schedule
+
+	// routeManifest is built by BuildRouteManifest once routes are registered
+	routeManifest []RouteManifestEntry
 }
 
 // NewHandlerManager creates a new handler manager with all dependencies
@@ -24,48 +55,174 @@ func NewHandlerManager(
 	jwtManager *utils.JWTManager,
 	repoManager *repository.RepositoryManager,
 	middlewareManager *middleware.MiddlewareManager,
+	logRedaction models.LogRedactionPolicy,
+	importMappings importer.Mappings,
+	assetStorage storage.Storage,
+	uploadScanner scanner.Scanner,
+	avatarMaxSize int64,
+	assetMaxAge time.Duration,
+	oidcProvider *oidc.Provider,
+	dpopMode string,
+	directory config.DirectoryConfig,
+	identityProviders map[string]*utils.IDTokenVerifier,
+	accountNotifier *mailer.AccountNotifier,
+	apiKeys config.APIKeysConfig,
+	runtimeConfig *config.Config,
 ) *HandlerManager {
+	var oidcHandler *OIDCHandler
+	if oidcProvider != nil {
+		oidcHandler = NewOIDCHandler(
+			oidcProvider,
+			repoManager.Repos.User,
+			repoManager.Repos.OIDCCode,
+			repoManager.Repos.Log,
+		)
+	}
+
+	var directoryHandler *DirectoryHandler
+	if directory.Enabled {
+		directoryHandler = NewDirectoryHandler(repoManager.Repos.User, directory.ShowEmail)
+	}
+
+	var logIngestHandler *LogIngestHandler
+	if runtimeConfig.LogIngest.Enabled {
+		logIngestHandler = NewLogIngestHandler(repoManager.Repos.Log, runtimeConfig.LogIngest)
+	}
+
+	var reportHandler *ReportHandler
+	if runtimeConfig.Reports.Enabled {
+		reportHandler = NewReportHandler(repoManager)
+	}
+
 	return &HandlerManager{
 		AuthHandler: NewAuthHandler(
 			jwtManager,
 			repoManager.Repos.User,
 			repoManager.Repos.Log,
+			repoManager.Repos.Session,
+			repoManager.Repos.Identity,
+			middlewareManager.LoginLimiter(),
+			middlewareManager.DPoPVerifier(),
+			dpopMode,
+			middlewareManager.ProfileRequirements(),
+			middlewareManager.SoftLaunch(),
+			middlewareManager.Sandbox(),
 		),
 		UserHandler: NewUserHandler(
 			repoManager.Repos.User,
 			repoManager.Repos.Log,
+			repoManager,
+			accountNotifier,
 		),
 		AdminHandler: NewAdminHandler(
 			repoManager.Repos.User,
 			repoManager.Repos.Log,
 			repoManager,
+			importMappings,
+			jobs.NewTracker(time.Hour),
+			accountNotifier,
+			uploadScanner,
 		),
 		AdminPanelHandler: NewAdminPanelHandler(
 			repoManager.Repos.User,
 			repoManager.Repos.Log,
 			repoManager,
+			runtimeConfig.Tracing,
 		),
 		LogHandler: NewLogHandler(
 			repoManager.Repos.Log,
+			repoManager.Repos.User,
+			logRedaction,
+		),
+		SetupHandler: NewSetupHandler(repoManager),
+		RoleHandler: NewRoleHandler(
+			repoManager.Repos.Log,
+			repoManager,
+		),
+		AssetHandler: NewAssetHandler(
+			assetStorage,
+			uploadScanner,
+			repoManager.Repos.User,
+			repoManager.Repos.Log,
+			avatarMaxSize,
+			assetMaxAge,
 		),
+		PreferenceHandler: NewPreferenceHandler(
+			repoManager.Repos.LogFilterPreset,
+			repoManager.Repos.SavedUserSearch,
+			repoManager.Repos.AuditSubscription,
+			runtimeConfig.Email.Enabled,
+		),
+		NotificationHandler: NewNotificationHandler(repoManager.Repos.AdminNotification),
+		IdentityHandler: NewIdentityHandler(
+			repoManager.Repos.User,
+			repoManager.Repos.Identity,
+			repoManager.Repos.Log,
+			identityProviders,
+		),
+		ProfileHandler: NewProfileFieldHandler(
+			repoManager.Repos.ProfileField,
+			repoManager.Repos.Log,
+			middlewareManager.ProfileRequirements(),
+		),
+		SecurityHandler: NewSecurityHandler(middlewareManager.RateLimiters()),
+		APIKeyHandler: NewAPIKeyHandler(
+			repoManager.Repos.APIKey,
+			repoManager.Repos.Log,
+			apiKeys,
+		),
+		AllowlistHandler: NewIPAllowlistHandler(
+			repoManager.Repos.User,
+			repoManager.Repos.APIKey,
+			repoManager.Repos.Log,
+			middlewareManager.IPAllowlists(),
+		),
+		ConfigHandler: NewConfigHandler(
+			runtimeConfig,
+			repoManager.Repos.ProfileField,
+			repoManager.Repos.Log,
+			middlewareManager.ProfileRequirements(),
+			middlewareManager.ResponseCache(),
+		),
+		OIDCHandler:       oidcHandler,
+		DirectoryHandler:  directoryHandler,
+		LogIngestHandler:  logIngestHandler,
+		ReportHandler:     reportHandler,
 		middlewareManager: middlewareManager,
+		maintenance:       repoManager.Maintenance(),
 	}
 }
 
 // SetupRoutes configures all API routes with appropriate middleware
 func (hm *HandlerManager) SetupRoutes(router *gin.Engine) {
+	// Answer route manifest probes before any other middleware runs
+	router.Use(routeProbeMiddleware)
+
 	// Setup global middleware
 	hm.middlewareManager.SetupGlobalMiddleware(router)
 
 	// Setup admin panel web interface routes
 	hm.AdminPanelHandler.SetupAdminPanelRoutes(router, hm.middlewareManager)
 
+	// Public user assets, served with caching headers
+	router.GET("/assets/*key", hm.AssetHandler.ServeAsset)
+	router.HEAD("/assets/*key", hm.AssetHandler.ServeAsset)
+
+	// OpenID Connect provider, at the paths relative to the issuer
+	if hm.OIDCHandler != nil {
+		hm.setupOIDCRoutes(router)
+	}
+
 	// API root
 	api := router.Group("/api")
 
 	// Setup route groups with pre-configured middleware
 	hm.setupAuthRoutes(api)
+	hm.setupSetupRoutes(api)
 	hm.setupUserRoutes(api)
+	if hm.DirectoryHandler != nil {
+		hm.setupDirectoryRoutes(api)
+	}
 	hm.setupAdminRoutes(api)
 	hm.setupLogRoutes(api)
 	hm.setupUtilityRoutes(api)
@@ -86,6 +243,30 @@ func (hm *HandlerManager) setupAuthRoutes(api *gin.RouterGroup) {
 		authProtected.POST("/logout", hm.AuthHandler.Logout)
 		authProtected.GET("/profile", hm.AuthHandler.GetProfile)
 		authProtected.POST("/change-password", hm.AuthHandler.ChangePassword)
+		authProtected.GET("/identities", hm.IdentityHandler.GetIdentities)
+		authProtected.POST("/identities", hm.IdentityHandler.LinkIdentity)
+		authProtected.DELETE("/identities/:provider", hm.IdentityHandler.UnlinkIdentity)
+	}
+}
+
+// setupOIDCRoutes configures the OpenID Connect provider routes
+func (hm *HandlerManager) setupOIDCRoutes(router *gin.Engine) {
+	router.GET(oidc.DiscoveryPath, hm.OIDCHandler.Discovery)
+	router.GET(oidc.JWKSPath, hm.OIDCHandler.JWKS)
+
+	// Authorization uses the browser's login session when there is one
+	router.GET(oidc.AuthorizePath, hm.middlewareManager.OptionalAuthMiddleware(), hm.OIDCHandler.Authorize)
+	router.POST(oidc.TokenPath, hm.OIDCHandler.Token)
+	router.GET(oidc.UserInfoPath, hm.OIDCHandler.UserInfo)
+	router.POST(oidc.UserInfoPath, hm.OIDCHandler.UserInfo)
+}
+
+// setupSetupRoutes configures first-boot admin setup routes
+func (hm *HandlerManager) setupSetupRoutes(api *gin.RouterGroup) {
+	setup := api.Group("/setup")
+	{
+		setup.GET("/status", hm.SetupHandler.GetSetupStatus)
+		setup.POST("/admin", hm.SetupHandler.CompleteSetup)
 	}
 }
 
@@ -98,10 +279,27 @@ func (hm *HandlerManager) setupUserRoutes(api *gin.RouterGroup) {
 	{
 		users.GET("", hm.middlewareManager.AdminRequiredMiddleware(), hm.UserHandler.ListUsers)
 		users.POST("", hm.middlewareManager.AdminRequiredMiddleware(), hm.UserHandler.CreateUser)
+		users.GET("/changes", hm.middlewareManager.AdminRequiredMiddleware(), hm.UserHandler.GetUserChanges)
 		users.GET("/:id", hm.middlewareManager.SelfOrAdminMiddleware("id"), hm.UserHandler.GetUser)
 		users.PUT("/:id", hm.middlewareManager.SelfOrAdminMiddleware("id"), hm.UserHandler.UpdateUser)
 		users.DELETE("/:id", hm.middlewareManager.AdminRequiredMiddleware(), hm.UserHandler.DeleteUser)
 	}
+
+	// Avatars
+	{
+		users.PUT("/:id/avatar", hm.middlewareManager.SelfOrAdminMiddleware("id"), hm.AssetHandler.UploadAvatar)
+		users.DELETE("/:id/avatar", hm.middlewareManager.SelfOrAdminMiddleware("id"), hm.AssetHandler.DeleteAvatar)
+	}
+}
+
+// setupDirectoryRoutes configures the user directory, open to every signed-in
+// user with its own per-user rate limit
+func (hm *HandlerManager) setupDirectoryRoutes(api *gin.RouterGroup) {
+	api.GET("/directory",
+		hm.middlewareManager.AuthMiddleware(),
+		hm.middlewareManager.DirectoryRateLimitMiddleware(),
+		hm.DirectoryHandler.GetDirectory,
+	)
 }
 
 // setupAdminRoutes configures admin-specific routes
@@ -113,20 +311,110 @@ func (hm *HandlerManager) setupAdminRoutes(api *gin.RouterGroup) {
 	// System management
 	{
 		admin.GET("/stats", hm.AdminHandler.GetSystemStats)
+		admin.GET("/stats/widgets", hm.AdminHandler.ListStatsWidgets)
+		admin.GET("/stats/widgets/:name", hm.AdminHandler.GetStatsWidget)
 		admin.POST("/maintenance", hm.AdminHandler.RunMaintenance)
+		admin.POST("/maintenance/indexes", hm.AdminHandler.MaintainIndexes)
+	}
+
+	// Scheduled reports
+	if hm.ReportHandler != nil {
+		admin.GET("/reports", hm.ReportHandler.ListReports)
+		admin.GET("/reports/:name/:file", hm.ReportHandler.DownloadReport)
 	}
 	
 	// Advanced user management
 	{
 		admin.GET("/users/deleted", hm.AdminHandler.GetDeletedUsers)
+		admin.GET("/users/deleted/:id", hm.AdminHandler.GetDeletedUser)
 		admin.POST("/users/:id/restore", hm.AdminHandler.RestoreUser)
 		admin.DELETE("/users/:id/permanent-delete", hm.AdminHandler.PermanentDeleteUser)
 		admin.POST("/users/bulk-create", hm.AdminHandler.BulkCreateUsers)
+		admin.POST("/users/bulk-delete", hm.AdminHandler.BulkDeleteUsers)
+		admin.POST("/users/import", hm.AdminHandler.ImportUsers)
+		admin.GET("/jobs/:id", hm.AdminHandler.GetJob)
 	}
 	
 	// Admin log access
 	{
 		admin.GET("/logs", hm.AdminHandler.GetUserLogs)
+		admin.GET("/logs/batches", hm.AdminHandler.GetLogBatches)
+		admin.GET("/logs/batches/:batch_id", hm.AdminHandler.GetLogBatch)
+		if hm.LogIngestHandler != nil {
+			admin.POST("/logs/bulk", hm.middlewareManager.LogIngestRateLimitMiddleware(), hm.LogIngestHandler.IngestLogs)
+		}
+	}
+
+	// Session management
+	{
+		admin.GET("/sessions", hm.AdminHandler.GetAdminSessions)
+		admin.DELETE("/sessions/:id", hm.AdminHandler.RevokeSession)
+	}
+
+	// Creation quotas
+	{
+		admin.GET("/quotas", hm.AdminHandler.GetQuotaUsage)
+		admin.GET("/usage", hm.AdminHandler.GetUsage)
+	}
+
+	// Role management
+	{
+		admin.POST("/users/:id/role", hm.RoleHandler.RequestRoleChange)
+		admin.GET("/role-changes", hm.RoleHandler.GetRoleChanges)
+		admin.POST("/role-changes/:id/approve", hm.RoleHandler.ApproveRoleChange)
+		admin.POST("/role-changes/:id/reject", hm.RoleHandler.RejectRoleChange)
+	}
+
+	// Per-admin preferences
+	{
+		admin.GET("/preferences/log-filters", hm.PreferenceHandler.GetLogFilterPresets)
+		admin.PUT("/preferences/log-filters", hm.PreferenceHandler.SaveLogFilterPreset)
+		admin.DELETE("/preferences/log-filters/:id", hm.PreferenceHandler.DeleteLogFilterPreset)
+		admin.GET("/preferences/user-searches", hm.PreferenceHandler.GetSavedUserSearches)
+		admin.PUT("/preferences/user-searches", hm.PreferenceHandler.SaveUserSearch)
+		admin.DELETE("/preferences/user-searches/:id", hm.PreferenceHandler.DeleteSavedUserSearch)
+		admin.GET("/preferences/audit-subscription", hm.PreferenceHandler.GetAuditSubscription)
+		admin.PUT("/preferences/audit-subscription", hm.PreferenceHandler.SaveAuditSubscription)
+		admin.DELETE("/preferences/audit-subscription", hm.PreferenceHandler.DeleteAuditSubscription)
+	}
+
+	// Audit event notifications of the current admin
+	{
+		admin.GET("/notifications", hm.NotificationHandler.GetNotifications)
+		admin.POST("/notifications/read-all", hm.NotificationHandler.MarkAllNotificationsRead)
+		admin.POST("/notifications/:id/read", hm.NotificationHandler.MarkNotificationRead)
+	}
+
+	// Required profile fields
+	{
+		admin.GET("/profile-fields/required", hm.ProfileHandler.GetRequiredProfileFields)
+		admin.PUT("/profile-fields/required", hm.ProfileHandler.SetRequiredProfileFields)
+	}
+
+	// Security introspection
+	{
+		admin.GET("/security/rate-limits", hm.SecurityHandler.GetRateLimits)
+	}
+
+	// API keys
+	{
+		admin.GET("/api-keys", hm.APIKeyHandler.ListAPIKeys)
+		admin.POST("/api-keys", hm.APIKeyHandler.CreateAPIKey)
+		admin.POST("/api-keys/:id/rotate", hm.APIKeyHandler.RotateAPIKey)
+		admin.DELETE("/api-keys/:id", hm.APIKeyHandler.RevokeAPIKey)
+	}
+
+	// IP allowlists
+	{
+		admin.GET("/users/:id/ip-allowlist", hm.AllowlistHandler.GetUserIPAllowlist)
+		admin.PUT("/users/:id/ip-allowlist", hm.AllowlistHandler.SetUserIPAllowlist)
+		admin.PUT("/api-keys/:id/ip-allowlist", hm.AllowlistHandler.SetAPIKeyIPAllowlist)
+	}
+
+	// Configuration snapshots
+	{
+		admin.GET("/config/export", hm.ConfigHandler.ExportConfig)
+		admin.POST("/config/import", hm.ConfigHandler.ImportConfig)
 	}
 }
 
@@ -139,6 +427,7 @@ func (hm *HandlerManager) setupLogRoutes(api *gin.RouterGroup) {
 	{
 		logs.GET("/my-activity", hm.LogHandler.GetUserLogs)
 		logs.GET("/my-activity/summary", hm.LogHandler.GetUserActivity)
+		logs.GET("/my-activity/export", hm.middlewareManager.ExportRateLimitMiddleware(), hm.LogHandler.ExportUserLogs)
 	}
 	
 	// Admin-only log operations
@@ -152,7 +441,7 @@ func (hm *HandlerManager) setupLogRoutes(api *gin.RouterGroup) {
 	
 	// Public (authenticated) endpoints
 	{
-		logs.GET("/event-types", hm.LogHandler.GetAvailableEventTypes)
+		logs.GET("/event-types", hm.middlewareManager.ResponseCache().Private(), hm.LogHandler.GetAvailableEventTypes)
 	}
 }
 
@@ -160,7 +449,8 @@ func (hm *HandlerManager) setupLogRoutes(api *gin.RouterGroup) {
 func (hm *HandlerManager) setupUtilityRoutes(api *gin.RouterGroup) {
 	// Public endpoints
 	api.GET("/ping", hm.handlePing)
-	api.GET("/version", hm.handleVersion)
+	api.GET("/version", hm.middlewareManager.ResponseCache().Public(), hm.handleVersion)
+	api.GET("/maintenance", hm.handleMaintenance)
 	
 	// Health check is handled by global middleware
 	// but we can add a more detailed version here
@@ -179,14 +469,23 @@ func (hm *HandlerManager) handlePing(c *gin.Context) {
 
 // handleVersion provides version information
 func (hm *HandlerManager) handleVersion(c *gin.Context) {
+	info := buildinfo.Get()
 	c.JSON(200, gin.H{
-		"version":     "1.0.0",
+		"version":     info.Version,
+		"git_commit":  info.GitCommit,
+		"build_date":  info.BuildDate,
+		"go_version":  info.GoVersion,
 		"api_version": "v1",
 		"service":     "user_mgmt_go",
 		"description": "User Management System API",
 	})
 }
 
+// handleMaintenance tells clients whether to show a maintenance banner
+func (hm *HandlerManager) handleMaintenance(c *gin.Context) {
+	c.JSON(200, hm.maintenance.Banner(time.Now()))
+}
+
 // handleDetailedHealth provides detailed health information (authenticated)
 func (hm *HandlerManager) handleDetailedHealth(c *gin.Context) {
 	// This would typically include more detailed health checks
@@ -198,11 +497,14 @@ func (hm *HandlerManager) handleDetailedHealth(c *gin.Context) {
 			"database": true,
 			"mongodb":  true,
 		},
-		"version": "1.0.0",
+		"version": buildinfo.Version,
+		"build":   buildinfo.Get(),
 	})
 }
 
-// GetRouteSummary returns a summary of all available routes
+// GetRouteSummary returns the documented routes by category. The manifest
+// at /api/docs/routes is generated from the registered routes instead and
+// only takes categories and descriptions from here.
 func (hm *HandlerManager) GetRouteSummary() map[string][]RouteInfo {
 	return map[string][]RouteInfo{
 		"Authentication": {
@@ -211,36 +513,103 @@ func (hm *HandlerManager) GetRouteSummary() map[string][]RouteInfo {
 			{Method: "POST", Path: "/api/auth/logout", Description: "User logout", Auth: "Required"},
 			{Method: "GET", Path: "/api/auth/profile", Description: "Get user profile", Auth: "Required"},
 			{Method: "POST", Path: "/api/auth/change-password", Description: "Change password", Auth: "Required"},
+			{Method: "GET", Path: "/api/auth/identities", Description: "List linked external identities", Auth: "Required"},
+			{Method: "POST", Path: "/api/auth/identities", Description: "Link external identity (ID token and password)", Auth: "Required"},
+			{Method: "DELETE", Path: "/api/auth/identities/:provider", Description: "Unlink external identity (password)", Auth: "Required"},
+			{Method: "GET", Path: "/api/setup/status", Description: "Admin setup status", Auth: "Public"},
+			{Method: "POST", Path: "/api/setup/admin", Description: "Complete admin setup with one-time token", Auth: "Public"},
 		},
 		"User Management": {
 			{Method: "GET", Path: "/api/users", Description: "List users", Auth: "Admin"},
 			{Method: "POST", Path: "/api/users", Description: "Create user", Auth: "Admin"},
+			{Method: "GET", Path: "/api/users/changes", Description: "List user changes since a cursor", Auth: "Admin"},
 			{Method: "GET", Path: "/api/users/:id", Description: "Get user", Auth: "Self or Admin"},
 			{Method: "PUT", Path: "/api/users/:id", Description: "Update user", Auth: "Self or Admin"},
 			{Method: "DELETE", Path: "/api/users/:id", Description: "Delete user", Auth: "Admin"},
+			{Method: "PUT", Path: "/api/users/:id/avatar", Description: "Upload avatar", Auth: "Self or Admin"},
+			{Method: "DELETE", Path: "/api/users/:id/avatar", Description: "Delete avatar", Auth: "Self or Admin"},
+			{Method: "GET", Path: "/assets/*key", Description: "Serve avatar or other public asset (cacheable)", Auth: "Public"},
+			{Method: "HEAD", Path: "/assets/*key", Description: "Public asset headers", Auth: "Public"},
+			{Method: "GET", Path: "/api/directory", Description: "User directory for people pickers (when directory.enabled)", Auth: "Required"},
 		},
 		"Admin Operations": {
 			{Method: "GET", Path: "/api/admin/stats", Description: "System statistics", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/stats/widgets", Description: "List stats widgets", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/stats/widgets/:name", Description: "Get one stats widget dataset", Auth: "Admin"},
 			{Method: "POST", Path: "/api/admin/maintenance", Description: "Run maintenance", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/maintenance/indexes", Description: "Verify or build database indexes", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/reports", Description: "List scheduled reports (when reports.enabled)", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/reports/:name/:file", Description: "Download a report file (when reports.enabled)", Auth: "Admin"},
 			{Method: "GET", Path: "/api/admin/users/deleted", Description: "Get deleted users", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/users/deleted/:id", Description: "Get deleted user with deletion context", Auth: "Admin"},
 			{Method: "POST", Path: "/api/admin/users/:id/restore", Description: "Restore user", Auth: "Admin"},
 			{Method: "DELETE", Path: "/api/admin/users/:id/permanent-delete", Description: "Permanent delete", Auth: "Admin"},
-			{Method: "POST", Path: "/api/admin/users/bulk-create", Description: "Bulk create users", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/users/bulk-create", Description: "Bulk create users (?dry_run=true to validate only)", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/users/bulk-delete", Description: "Bulk delete users", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/users/import", Description: "Import users from IdP export (?dry_run=true to validate only)", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/jobs/:id", Description: "Progress of a bulk create, import or delete (start it with ?job_id= to poll)", Auth: "Admin"},
 			{Method: "GET", Path: "/api/admin/logs", Description: "Get all logs", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/logs/batches", Description: "List bulk operation batches", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/logs/batches/:batch_id", Description: "Bulk operation batch entries", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/logs/bulk", Description: "Submit audit events from a trusted service (when log_ingest.enabled)", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/sessions", Description: "Active admin sessions", Auth: "Admin"},
+			{Method: "DELETE", Path: "/api/admin/sessions/:id", Description: "Revoke session", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/quotas", Description: "Creation quota limits and usage", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/usage", Description: "User counts against limits, log volume and quota usage", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/users/:id/role", Description: "Request role change", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/role-changes", Description: "List role changes", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/role-changes/:id/approve", Description: "Approve role change", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/role-changes/:id/reject", Description: "Reject role change", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/preferences/log-filters", Description: "List saved log filters", Auth: "Admin"},
+			{Method: "PUT", Path: "/api/admin/preferences/log-filters", Description: "Save log filter by name", Auth: "Admin"},
+			{Method: "DELETE", Path: "/api/admin/preferences/log-filters/:id", Description: "Delete saved log filter", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/preferences/user-searches", Description: "List saved user searches", Auth: "Admin"},
+			{Method: "PUT", Path: "/api/admin/preferences/user-searches", Description: "Save user search by name", Auth: "Admin"},
+			{Method: "DELETE", Path: "/api/admin/preferences/user-searches/:id", Description: "Delete saved user search", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/preferences/audit-subscription", Description: "Get audit event subscription", Auth: "Admin"},
+			{Method: "PUT", Path: "/api/admin/preferences/audit-subscription", Description: "Save audit event subscription", Auth: "Admin"},
+			{Method: "DELETE", Path: "/api/admin/preferences/audit-subscription", Description: "Delete audit event subscription", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/notifications", Description: "List audit notifications", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/notifications/read-all", Description: "Mark all audit notifications read", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/notifications/:id/read", Description: "Mark audit notification read", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/profile-fields/required", Description: "List required profile fields", Auth: "Admin"},
+			{Method: "PUT", Path: "/api/admin/profile-fields/required", Description: "Set required profile fields", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/security/rate-limits", Description: "Inspect rate limiters and throttled visitors", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/api-keys", Description: "List API keys", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/api-keys", Description: "Create an API key", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/api-keys/:id/rotate", Description: "Rotate an API key with an overlap window", Auth: "Admin"},
+			{Method: "DELETE", Path: "/api/admin/api-keys/:id", Description: "Revoke an API key", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/users/:id/ip-allowlist", Description: "Get the networks an account may be used from", Auth: "Admin"},
+			{Method: "PUT", Path: "/api/admin/users/:id/ip-allowlist", Description: "Set the networks an account may be used from", Auth: "Admin"},
+			{Method: "PUT", Path: "/api/admin/api-keys/:id/ip-allowlist", Description: "Set the networks an API key may be used from", Auth: "Admin"},
+			{Method: "GET", Path: "/api/admin/config/export", Description: "Export the configuration with secrets redacted", Auth: "Admin"},
+			{Method: "POST", Path: "/api/admin/config/import", Description: "Apply a configuration snapshot's runtime settings (?dry_run=true to preview)", Auth: "Admin"},
+		},
+		"OpenID Connect Provider": {
+			{Method: "GET", Path: oidc.DiscoveryPath, Description: "Discovery document (when oidc.enabled)", Auth: "Public"},
+			{Method: "GET", Path: oidc.JWKSPath, Description: "Token signing keys", Auth: "Public"},
+			{Method: "GET", Path: oidc.AuthorizePath, Description: "Authorization code flow, using the login session when there is one", Auth: "Optional"},
+			{Method: "POST", Path: oidc.TokenPath, Description: "Exchange code for tokens (client credentials)", Auth: "Public"},
+			{Method: "GET", Path: oidc.UserInfoPath, Description: "User claims (OIDC access token)", Auth: "Public"},
+			{Method: "POST", Path: oidc.UserInfoPath, Description: "User claims (OIDC access token)", Auth: "Public"},
 		},
 		"Logs & Monitoring": {
 			{Method: "GET", Path: "/api/logs/my-activity", Description: "User's activity logs", Auth: "Required"},
 			{Method: "GET", Path: "/api/logs/my-activity/summary", Description: "Activity summary", Auth: "Required"},
+			{Method: "GET", Path: "/api/logs/my-activity/export", Description: "Download own activity as CSV or JSON", Auth: "Required"},
 			{Method: "GET", Path: "/api/logs/search", Description: "Search logs", Auth: "Admin"},
 			{Method: "GET", Path: "/api/logs/stats", Description: "Event statistics", Auth: "Admin"},
-			{Method: "GET", Path: "/api/logs/:id", Description: "Log details", Auth: "Required"},
+			{Method: "GET", Path: "/api/logs/:id", Description: "Log details", Auth: "Admin"},
 			{Method: "GET", Path: "/api/logs/event-types", Description: "Available event types", Auth: "Required"},
 		},
 		"Utilities": {
 			{Method: "GET", Path: "/api/ping", Description: "Simple ping", Auth: "Public"},
 			{Method: "GET", Path: "/api/version", Description: "Version info", Auth: "Public"},
+			{Method: "GET", Path: "/api/maintenance", Description: "Current or upcoming maintenance window banner", Auth: "Public"},
 			{Method: "GET", Path: "/health", Description: "Health check", Auth: "Public"},
+			{Method: "GET", Path: "/health/ready", Description: "Readiness for load balancers, with degraded subsystems", Auth: "Public"},
 			{Method: "GET", Path: "/api/health/detailed", Description: "Detailed health", Auth: "Required"},
+			{Method: "GET", Path: "/api/docs/routes", Description: "Route manifest generated from the registered routes", Auth: "Public"},
 		},
 	}
 }
@@ -253,10 +622,11 @@ type RouteInfo struct {
 	Auth        string `json:"auth"`
 }
 
-// SetupDocumentationRoute adds a route that shows all available endpoints
+// SetupDocumentationRoute adds a route that shows all available endpoints,
+// as found by BuildRouteManifest
 func (hm *HandlerManager) SetupDocumentationRoute(router *gin.Engine) {
-	router.GET("/api/docs/routes", func(c *gin.Context) {
-		routes := hm.GetRouteSummary()
+	router.GET("/api/docs/routes", hm.middlewareManager.ResponseCache().Public(), func(c *gin.Context) {
+		routes := hm.RouteManifestByCategory()
 		c.JSON(200, gin.H{
 			"title":       "User Management API",
 			"version":     "1.0.0",
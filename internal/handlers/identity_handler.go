@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdentityHandler handles linking external identities to local accounts
+type IdentityHandler struct {
+	userRepo     repository.UserRepository
+	identityRepo repository.IdentityRepository
+	logRepo      repository.UserLogRepository
+	providers    map[string]*utils.IDTokenVerifier // Provider name -> verifier
+}
+
+// NewIdentityHandler creates a new identity handler
+func NewIdentityHandler(
+	userRepo repository.UserRepository,
+	identityRepo repository.IdentityRepository,
+	logRepo repository.UserLogRepository,
+	providers map[string]*utils.IDTokenVerifier,
+) *IdentityHandler {
+	return &IdentityHandler{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		logRepo:      logRepo,
+		providers:    providers,
+	}
+}
+
+// GetIdentities godoc
+// @Summary List linked identities
+// @Description List the external identities linked to the current account and the providers available for linking
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/identities [get]
+func (h *IdentityHandler) GetIdentities(c *gin.Context) {
+	userClaims, _ := middleware.GetUserFromContext(c)
+	identities, err := h.identityRepo.ListForUser(c.Request.Context(), userClaims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Identities Retrieval Failed",
+			"Failed to retrieve linked identities",
+			err.Error(),
+		))
+		return
+	}
+
+	providers := make([]string, 0, len(h.providers))
+	for name := range h.providers {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+
+	c.JSON(http.StatusOK, gin.H{
+		"identities": identities,
+		"providers":  providers,
+	})
+}
+
+// LinkIdentity godoc
+// @Summary Link an external identity
+// @Description Link an external identity to the current account. The ID token, issued by the provider to this application, proves control of the external account; the password confirms the local one.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.LinkIdentityRequest true "Identity to link"
+// @Success 201 {object} models.UserIdentity
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/identities [post]
+func (h *IdentityHandler) LinkIdentity(c *gin.Context) {
+	var req models.LinkIdentityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Request",
+			"Please provide a provider, ID token and your password",
+			err.Error(),
+		))
+		return
+	}
+
+	verifier, ok := h.providers[req.Provider]
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Unknown Provider",
+			"The identity provider is not configured",
+			nil,
+		))
+		return
+	}
+
+	user, ok := h.confirmPassword(c, req.Password)
+	if !ok {
+		return
+	}
+
+	// Verify the ID token
+	external, err := verifier.Verify(c.Request.Context(), req.IDToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse(
+			http.StatusUnauthorized,
+			"Invalid ID Token",
+			"The ID token could not be verified",
+			err.Error(),
+		))
+		return
+	}
+
+	identity := &models.UserIdentity{
+		UserID:   user.ID,
+		Provider: req.Provider,
+		Subject:  external.Subject,
+		Email:    external.Email,
+	}
+	if err := h.identityRepo.Create(c.Request.Context(), identity); err != nil {
+		if errors.Is(err, repository.ErrIdentityAlreadyLinked) {
+			c.JSON(http.StatusConflict, models.NewErrorResponse(
+				http.StatusConflict,
+				"Identity Already Linked",
+				"This identity is linked to an account already, or you have linked one from this provider",
+				nil,
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Link Failed",
+			"Failed to link identity",
+			err.Error(),
+		))
+		return
+	}
+
+	// Log identity link
+	h.logIdentityChange(c, user, "IDENTITY_LINKED", identity.Provider)
+
+	c.JSON(http.StatusCreated, identity)
+}
+
+// UnlinkIdentity godoc
+// @Summary Unlink an external identity
+// @Description Unlink the current account's identity from a provider. Requires the account password.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param request body models.UnlinkIdentityRequest true "Password confirmation"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/identities/{provider} [delete]
+func (h *IdentityHandler) UnlinkIdentity(c *gin.Context) {
+	var req models.UnlinkIdentityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Request",
+			"Please provide your password",
+			err.Error(),
+		))
+		return
+	}
+
+	user, ok := h.confirmPassword(c, req.Password)
+	if !ok {
+		return
+	}
+
+	provider := c.Param("provider")
+	if err := h.identityRepo.Delete(c.Request.Context(), user.ID, provider); err != nil {
+		if errors.Is(err, repository.ErrIdentityNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse(
+				http.StatusNotFound,
+				"Identity Not Found",
+				"No identity from this provider is linked to your account",
+				nil,
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Unlink Failed",
+			"Failed to unlink identity",
+			err.Error(),
+		))
+		return
+	}
+
+	// Log identity unlink
+	h.logIdentityChange(c, user, "IDENTITY_UNLINKED", provider)
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Identity unlinked", nil))
+}
+
+// confirmPassword loads the current user and checks their password, writing
+// the error response when it does not match
+func (h *IdentityHandler) confirmPassword(c *gin.Context, password string) (*models.User, bool) {
+	userClaims, _ := middleware.GetUserFromContext(c)
+	user, err := h.userRepo.GetByID(c.Request.Context(), userClaims.UserID)
+	if err != nil {
+		respondRepositoryError(c, err, "User Not Found", "User not found")
+		return nil, false
+	}
+
+	if err := utils.VerifyPassword(user.Password, password); err != nil {
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse(
+			http.StatusUnauthorized,
+			"Invalid Password",
+			"Password is incorrect",
+			nil,
+		))
+		return nil, false
+	}
+
+	return user, true
+}
+
+// Helper methods for logging
+
+func (h *IdentityHandler) logIdentityChange(c *gin.Context, user *models.User, action, provider string) {
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID: &user.ID,
+		Event:  models.UserUpdated,
+		Action: action,
+		Details: map[string]interface{}{
+			"provider":   provider,
+			"ip_address": c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+		},
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	h.logRepo.CreateAsync(logEntry)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// IPAllowlistHandler handles the networks accounts and API keys may be used
+// from, typically to pin service accounts to known networks
+type IPAllowlistHandler struct {
+	userRepo   repository.UserRepository
+	keyRepo    repository.APIKeyRepository
+	logRepo    repository.UserLogRepository
+	allowlists *middleware.IPAllowlists
+}
+
+// NewIPAllowlistHandler creates a new IP allowlist handler
+func NewIPAllowlistHandler(
+	userRepo repository.UserRepository,
+	keyRepo repository.APIKeyRepository,
+	logRepo repository.UserLogRepository,
+	allowlists *middleware.IPAllowlists,
+) *IPAllowlistHandler {
+	return &IPAllowlistHandler{
+		userRepo:   userRepo,
+		keyRepo:    keyRepo,
+		logRepo:    logRepo,
+		allowlists: allowlists,
+	}
+}
+
+// GetUserIPAllowlist godoc
+// @Summary Get user IP allowlist
+// @Description Get the networks a user account may be used from. An empty list allows any address.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.IPAllowlistResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/users/{id}/ip-allowlist [get]
+func (h *IPAllowlistHandler) GetUserIPAllowlist(c *gin.Context) {
+	// Parse user ID
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid User ID",
+			"Please provide a valid user ID",
+			err.Error(),
+		))
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		respondRepositoryError(c, err, "User Not Found", "The requested user could not be found")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.IPAllowlistResponse{AllowedIPs: user.AllowedIPs})
+}
+
+// SetUserIPAllowlist godoc
+// @Summary Set user IP allowlist
+// @Description Replace the networks a user account may be used from, as addresses or CIDR ranges. Logins and requests from other networks are rejected, including with the account's API keys. An empty list removes the restriction.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.IPAllowlistRequest true "Allowed networks"
+// @Success 200 {object} models.IPAllowlistResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/users/{id}/ip-allowlist [put]
+func (h *IPAllowlistHandler) SetUserIPAllowlist(c *gin.Context) {
+	// Parse user ID
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid User ID",
+			"Please provide a valid user ID",
+			err.Error(),
+		))
+		return
+	}
+
+	allowlist, ok := h.bindAllowlist(c)
+	if !ok {
+		return
+	}
+
+	// Refuse to lock the admin out of their own session
+	userClaims, _ := middleware.GetUserFromContext(c)
+	if userClaims.UserID == userID && !allowlist.Allows(c.ClientIP()) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Allowlist",
+			"The allowlist must include your current address",
+			map[string]string{"ip_address": c.ClientIP()},
+		))
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		respondRepositoryError(c, err, "User Not Found", "The requested user could not be found")
+		return
+	}
+
+	if err := h.userRepo.Update(c.Request.Context(), userID, map[string]interface{}{"allowed_ips": allowlist}); err != nil {
+		respondRepositoryError(c, err, "Allowlist Update Failed", "Failed to update IP allowlist")
+		return
+	}
+	if h.allowlists != nil {
+		h.allowlists.Invalidate(userID)
+	}
+
+	// Log allowlist change
+	h.logAllowlistChange(c, models.UserUpdated, "IP_ALLOWLIST_UPDATED", map[string]interface{}{
+		"target_user_id": userID,
+		"previous":       user.AllowedIPs,
+		"allowed_ips":    allowlist,
+	})
+
+	c.JSON(http.StatusOK, models.IPAllowlistResponse{AllowedIPs: allowlist})
+}
+
+// SetAPIKeyIPAllowlist godoc
+// @Summary Set API key IP allowlist
+// @Description Replace the networks an API key may be used from, as addresses or CIDR ranges. The owner's allowlist applies as well. An empty list removes the restriction.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Param request body models.IPAllowlistRequest true "Allowed networks"
+// @Success 200 {object} models.IPAllowlistResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/api-keys/{id}/ip-allowlist [put]
+func (h *IPAllowlistHandler) SetAPIKeyIPAllowlist(c *gin.Context) {
+	// Parse key ID
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid API Key ID",
+			"Please provide a valid API key ID",
+			err.Error(),
+		))
+		return
+	}
+
+	allowlist, ok := h.bindAllowlist(c)
+	if !ok {
+		return
+	}
+
+	if err := h.keyRepo.SetAllowedIPs(c.Request.Context(), keyID, allowlist); err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse(
+				http.StatusNotFound,
+				"API Key Not Found",
+				"No active API key with the specified ID was found",
+				err.Error(),
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Allowlist Update Failed",
+			"Failed to update IP allowlist",
+			err.Error(),
+		))
+		return
+	}
+
+	// Log allowlist change
+	h.logAllowlistChange(c, models.APIKeyChange, "API_KEY_ALLOWLIST_UPDATED", map[string]interface{}{
+		"api_key_id":  keyID,
+		"allowed_ips": allowlist,
+	})
+
+	c.JSON(http.StatusOK, models.IPAllowlistResponse{AllowedIPs: allowlist})
+}
+
+// bindAllowlist binds and validates the allowlist in the request body
+func (h *IPAllowlistHandler) bindAllowlist(c *gin.Context) (models.IPAllowlist, bool) {
+	var req models.IPAllowlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Request",
+			"Please provide a list of addresses or CIDR ranges",
+			err.Error(),
+		))
+		return nil, false
+	}
+
+	allowlist, err := models.ParseIPAllowlist(req.AllowedIPs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Allowlist",
+			"Please provide valid addresses or CIDR ranges",
+			err.Error(),
+		))
+		return nil, false
+	}
+	return allowlist, true
+}
+
+// Helper methods for logging
+
+func (h *IPAllowlistHandler) logAllowlistChange(c *gin.Context, event models.LogEventType, action string, details map[string]interface{}) {
+	// Get admin from context
+	var adminID *uuid.UUID
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		adminID = &userClaims.UserID
+	}
+
+	details["ip_address"] = c.ClientIP()
+	details["user_agent"] = c.Request.UserAgent()
+
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID:    adminID,
+		Event:     event,
+		Action:    action,
+		Details:   details,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	h.logRepo.CreateAsync(logEntry)
+}
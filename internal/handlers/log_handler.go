@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"user_mgmt_go/internal/middleware"
@@ -15,13 +19,17 @@ import (
 
 // LogHandler handles log-related requests
 type LogHandler struct {
-	logRepo repository.UserLogRepository
+	logRepo      repository.UserLogRepository
+	userRepo     repository.UserRepository
+	logRedaction models.LogRedactionPolicy
 }
 
 // NewLogHandler creates a new log handler
-func NewLogHandler(logRepo repository.UserLogRepository) *LogHandler {
+func NewLogHandler(logRepo repository.UserLogRepository, userRepo repository.UserRepository, logRedaction models.LogRedactionPolicy) *LogHandler {
 	return &LogHandler{
-		logRepo: logRepo,
+		logRepo:      logRepo,
+		userRepo:     userRepo,
+		logRedaction: logRedaction,
 	}
 }
 
@@ -80,6 +88,11 @@ func (h *LogHandler) GetUserLogs(c *gin.Context) {
 		return
 	}
 
+	// Redact details for non-admin viewers
+	for i := range logs.Logs {
+		logs.Logs[i] = logs.Logs[i].Redact(userClaims.Role, h.logRedaction)
+	}
+
 	c.JSON(http.StatusOK, logs)
 }
 
@@ -138,6 +151,11 @@ func (h *LogHandler) GetUserActivity(c *gin.Context) {
 		return
 	}
 
+	// Redact details for non-admin viewers
+	for i := range activities {
+		activities[i] = activities[i].Redact(userClaims.Role, h.logRedaction)
+	}
+
 	response := UserActivityResponse{
 		UserID:         userClaims.UserID,
 		DaysRequested:  days,
@@ -149,6 +167,133 @@ func (h *LogHandler) GetUserActivity(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// Limits for user activity exports
+const (
+	defaultActivityExportDays = 30
+	maxActivityExportRange    = 365 * 24 * time.Hour
+	maxActivityExportEntries  = 10000
+)
+
+// ExportUserLogs godoc
+// @Summary Export user activity logs
+// @Description Download the authenticated user's activity history over a date range as CSV or JSON, latest first. Details are redacted as in the activity list. At most 10000 entries are exported; X-Export-Truncated is set when more matched.
+// @Tags logs
+// @Security BearerAuth
+// @Produce json
+// @Produce text/csv
+// @Param format query string false "Export format" Enums(csv, json) default(json)
+// @Param start_date query string false "Start date (RFC3339 or YYYY-MM-DD), defaults to 30 days ago"
+// @Param end_date query string false "End date (RFC3339 or YYYY-MM-DD), defaults to now"
+// @Success 200 {array} models.UserLogResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /logs/my-activity/export [get]
+func (h *LogHandler) ExportUserLogs(c *gin.Context) {
+	// Get user from context
+	userClaims, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse(
+			http.StatusUnauthorized,
+			"Unauthorized",
+			"Authentication required",
+			nil,
+		))
+		return
+	}
+
+	// Parse export format
+	format := c.DefaultQuery("format", models.ActivityExportJSON)
+	if format != models.ActivityExportCSV && format != models.ActivityExportJSON {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Format",
+			"format must be csv or json",
+			nil,
+		))
+		return
+	}
+
+	// Parse date range
+	endDate := time.Now().UTC()
+	if value := c.Query("end_date"); value != "" {
+		parsed, ok := parseLogFilterDate(value, true)
+		if !ok {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid End Date",
+				"end_date must be RFC3339 or YYYY-MM-DD",
+				nil,
+			))
+			return
+		}
+		endDate = parsed
+	}
+	startDate := endDate.AddDate(0, 0, -defaultActivityExportDays)
+	if value := c.Query("start_date"); value != "" {
+		parsed, ok := parseLogFilterDate(value, false)
+		if !ok {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Start Date",
+				"start_date must be RFC3339 or YYYY-MM-DD",
+				nil,
+			))
+			return
+		}
+		startDate = parsed
+	}
+	if endDate.Before(startDate) || endDate.Sub(startDate) > maxActivityExportRange {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Date Range",
+			"The date range must not be reversed or longer than 365 days",
+			nil,
+		))
+		return
+	}
+
+	// Get one entry past the limit to detect truncation
+	logs, err := h.logRepo.Export(c.Request.Context(), models.LogFilterRequest{
+		UserID:    &userClaims.UserID,
+		StartDate: &startDate,
+		EndDate:   &endDate,
+	}, maxActivityExportEntries+1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Export Failed",
+			"Failed to retrieve user activity logs",
+			err.Error(),
+		))
+		return
+	}
+	if len(logs) > maxActivityExportEntries {
+		logs = logs[:maxActivityExportEntries]
+		c.Header("X-Export-Truncated", "true")
+	}
+
+	// Redact details for non-admin viewers
+	for i := range logs {
+		logs[i] = logs[i].Redact(userClaims.Role, h.logRedaction)
+	}
+
+	filename := fmt.Sprintf("activity-%s-to-%s.%s", startDate.Format(time.DateOnly), endDate.Format(time.DateOnly), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if format == models.ActivityExportJSON {
+		c.JSON(http.StatusOK, logs)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := models.WriteActivityCSV(c.Writer, logs); err != nil {
+		log.Printf("Failed to write activity export: %v", err)
+	}
+}
+
 // SearchLogs godoc
 // @Summary Search logs
 // @Description Search through activity logs (admin only)
@@ -163,6 +308,7 @@ func (h *LogHandler) GetUserActivity(c *gin.Context) {
 // @Param event query string false "Filter by event type"
 // @Param start_date query string false "Start date (RFC3339)"
 // @Param end_date query string false "End date (RFC3339)"
+// @Param expand query string false "Set to user to add actor and target names and emails"
 // @Success 200 {object} models.UserLogsListResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
@@ -244,6 +390,7 @@ func (h *LogHandler) SearchLogs(c *gin.Context) {
 		))
 		return
 	}
+	expandLogUsers(c, h.userRepo, logs.Logs)
 
 	c.JSON(http.StatusOK, logs)
 }
@@ -324,6 +471,7 @@ func (h *LogHandler) GetEventStats(c *gin.Context) {
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Log entry ID"
+// @Param expand query string false "Set to user to add actor and target names and emails (admins only)"
 // @Success 200 {object} models.UserLogResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
@@ -345,12 +493,7 @@ func (h *LogHandler) GetLogDetails(c *gin.Context) {
 	// Get log entry
 	logEntry, err := h.logRepo.GetByID(c.Request.Context(), logID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.NewErrorResponse(
-			http.StatusNotFound,
-			"Log Not Found",
-			"Log entry with the specified ID was not found",
-			err.Error(),
-		))
+		respondRepositoryError(c, err, "Log Not Found", "Log entry with the specified ID was not found")
 		return
 	}
 
@@ -377,7 +520,39 @@ func (h *LogHandler) GetLogDetails(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, logEntry.ToResponse())
+	response := []models.UserLogResponse{logEntry.ToResponseForRole(userClaims.Role, h.logRedaction)}
+	if middleware.IsAdmin(c) {
+		expandLogUsers(c, h.userRepo, response)
+	}
+
+	c.JSON(http.StatusOK, response[0])
+}
+
+// expandLogUsers names the users of log entries when the request asks for
+// it with ?expand=user
+func expandLogUsers(c *gin.Context, users repository.UserRepository, logs []models.UserLogResponse) {
+	for _, expansion := range strings.Split(c.Query("expand"), ",") {
+		if strings.TrimSpace(expansion) == "user" {
+			nameLogUsers(c.Request.Context(), users, logs)
+			return
+		}
+	}
+}
+
+// nameLogUsers fills in the actor and target names of log entries with one
+// user lookup. Entries stay unnamed if the lookup fails.
+func nameLogUsers(ctx context.Context, users repository.UserRepository, logs []models.UserLogResponse) {
+	ids := models.UserLogUserIDs(logs)
+	if len(ids) == 0 {
+		return
+	}
+
+	found, err := users.GetByIDs(ctx, ids)
+	if err != nil {
+		log.Printf("⚠️  Failed to look up log entry users: %v", err)
+		return
+	}
+	models.SetUserLogNames(logs, found)
 }
 
 // Response types
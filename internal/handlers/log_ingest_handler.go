@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Retry-After for batches refused by the log pipeline: briefly while its
+// queue drains, longer while it is degraded and spooling to disk
+const (
+	logIngestBusyRetryAfter     = 5 * time.Second
+	logIngestDegradedRetryAfter = time.Minute
+)
+
+// maxLogIngestEventErrors caps the rejected events listed in a response
+const maxLogIngestEventErrors = 100
+
+// LogIngestHandler accepts audit events from trusted services into this
+// service's log pipeline. Entries are timestamped on receipt, with the
+// source's own time kept as occurred_at, so exports and audit subscriptions,
+// which read forward from a timestamp checkpoint, pick them up like its own.
+type LogIngestHandler struct {
+	logRepo repository.UserLogRepository
+	config  config.LogIngestConfig
+}
+
+// NewLogIngestHandler creates a new log ingestion handler
+func NewLogIngestHandler(logRepo repository.UserLogRepository, cfg config.LogIngestConfig) *LogIngestHandler {
+	return &LogIngestHandler{
+		logRepo: logRepo,
+		config:  cfg,
+	}
+}
+
+// IngestLogs godoc
+// @Summary Submit a batch of audit events
+// @Description Submit audit events from a trusted service (typically with an admin API key). The batch is validated against the event taxonomy and accepted or rejected as a whole; accepted events share a batch ID and are written asynchronously. While the log pipeline is degraded or its queue is full the batch is refused with 503 and Retry-After, and should be resubmitted later.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.LogIngestRequest true "Audit events"
+// @Success 202 {object} models.LogIngestResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /admin/logs/bulk [post]
+func (h *LogIngestHandler) IngestLogs(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.config.MaxBodyBytes)
+
+	var req models.LogIngestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, models.NewErrorResponse(
+				http.StatusRequestEntityTooLarge,
+				"Batch Too Large",
+				fmt.Sprintf("Batches must not exceed %d bytes; split the events over several requests", h.config.MaxBodyBytes),
+				map[string]interface{}{"max_size_bytes": h.config.MaxBodyBytes},
+			))
+			return
+		}
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Request",
+			"Please provide a source and a list of events",
+			err.Error(),
+		))
+		return
+	}
+
+	eventErrors, err := req.Validate(h.config.MaxEvents, h.config.MaxEventAge, time.Now())
+	if err != nil {
+		var details interface{}
+		if len(eventErrors) > maxLogIngestEventErrors {
+			eventErrors = eventErrors[:maxLogIngestEventErrors]
+		}
+		if eventErrors != nil {
+			details = map[string]interface{}{"events": eventErrors}
+		}
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Events",
+			err.Error(),
+			details,
+		))
+		return
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	batchID := uuid.New().String()
+	if err := h.logRepo.CreateAsyncBatch(req.ToUserLogs(batchID, userClaims.UserID)); err != nil {
+		if errors.Is(err, repository.ErrLogPipelineBusy) {
+			retryAfter := logIngestBusyRetryAfter
+			if h.logRepo.PipelineStatus().Degraded {
+				retryAfter = logIngestDegradedRetryAfter
+			}
+			seconds := int(retryAfter / time.Second)
+			c.Header("Retry-After", fmt.Sprintf("%d", seconds))
+			c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse(
+				http.StatusServiceUnavailable,
+				"Log Pipeline Busy",
+				"The log pipeline cannot take this batch right now, please resubmit it later",
+				map[string]interface{}{"retry_after_seconds": seconds},
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Ingestion Failed",
+			"Failed to accept the events",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.LogIngestResponse{
+		BatchID:  batchID,
+		Accepted: len(req.Events),
+	})
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// NotificationHandler handles the admin panel inbox of audit events the
+// admin subscribed to
+type NotificationHandler struct {
+	notificationRepo repository.AdminNotificationRepository
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationRepo repository.AdminNotificationRepository) *NotificationHandler {
+	return &NotificationHandler{notificationRepo: notificationRepo}
+}
+
+// NotificationsResponse represents an admin's latest notifications
+type NotificationsResponse struct {
+	Notifications []models.AdminNotification `json:"notifications"`
+	Unread        int64                      `json:"unread"`
+}
+
+// GetNotifications godoc
+// @Summary List audit notifications
+// @Description List the current admin's latest notifications of subscribed audit events, newest first
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param limit query int false "Maximum notifications (1-200)" default(50)
+// @Success 200 {object} NotificationsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/notifications [get]
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Limit",
+			"Limit must be between 1 and 200",
+			nil,
+		))
+		return
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	ctx := c.Request.Context()
+	notifications, err := h.notificationRepo.ListForAdmin(ctx, userClaims.UserID, c.Query("unread") == "true", limit)
+	if err == nil {
+		var unread int64
+		unread, err = h.notificationRepo.CountUnread(ctx, userClaims.UserID)
+		if err == nil {
+			c.JSON(http.StatusOK, NotificationsResponse{Notifications: notifications, Unread: unread})
+			return
+		}
+	}
+
+	c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+		http.StatusInternalServerError,
+		"Notifications Retrieval Failed",
+		"Failed to retrieve notifications",
+		err.Error(),
+	))
+}
+
+// MarkNotificationRead godoc
+// @Summary Mark an audit notification read
+// @Description Mark one of the current admin's notifications as read
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/notifications/{id}/read [post]
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	notificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Notification ID",
+			"Please provide a valid notification ID",
+			err.Error(),
+		))
+		return
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	if err := h.notificationRepo.MarkRead(c.Request.Context(), userClaims.UserID, notificationID); err != nil {
+		if errors.Is(err, repository.ErrAdminNotificationNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse(
+				http.StatusNotFound,
+				"Notification Not Found",
+				"You have no notification with the specified ID",
+				nil,
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Notification Update Failed",
+			"Failed to mark notification read",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Notification marked read", nil))
+}
+
+// MarkAllNotificationsRead godoc
+// @Summary Mark all audit notifications read
+// @Description Mark all of the current admin's notifications as read
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.SuccessResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/notifications/read-all [post]
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userClaims, _ := middleware.GetUserFromContext(c)
+	marked, err := h.notificationRepo.MarkAllRead(c.Request.Context(), userClaims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Notification Update Failed",
+			"Failed to mark notifications read",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Notifications marked read", map[string]interface{}{"marked": marked}))
+}
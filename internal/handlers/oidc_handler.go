@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/oidc"
+	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OIDCHandler implements a minimal OpenID Connect provider: the authorization
+// code flow with optional PKCE, backed by the existing users and login sessions
+type OIDCHandler struct {
+	provider *oidc.Provider
+	userRepo repository.UserRepository
+	codeRepo repository.OIDCCodeRepository
+	logRepo  repository.UserLogRepository
+}
+
+// NewOIDCHandler creates a new OpenID Connect provider handler
+func NewOIDCHandler(
+	provider *oidc.Provider,
+	userRepo repository.UserRepository,
+	codeRepo repository.OIDCCodeRepository,
+	logRepo repository.UserLogRepository,
+) *OIDCHandler {
+	return &OIDCHandler{
+		provider: provider,
+		userRepo: userRepo,
+		codeRepo: codeRepo,
+		logRepo:  logRepo,
+	}
+}
+
+// Discovery godoc
+// @Summary OpenID Connect discovery
+// @Description Return the OpenID Provider metadata
+// @Tags oidc
+// @Produce json
+// @Success 200 {object} oidc.DiscoveryDocument
+// @Router /.well-known/openid-configuration [get]
+func (h *OIDCHandler) Discovery(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.provider.Discovery())
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Return the public keys that verify ID and access tokens
+// @Tags oidc
+// @Produce json
+// @Success 200 {object} oidc.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (h *OIDCHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.provider.JWKS())
+}
+
+// Authorize godoc
+// @Summary OpenID Connect authorization endpoint
+// @Description Start the authorization code flow. Signed-out users are sent to the login page and returned here afterwards; signed-in users are redirected to the client with a single-use code.
+// @Tags oidc
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "Registered redirect URI"
+// @Param scope query string true "Space-separated scopes, must include openid"
+// @Param state query string false "Opaque value returned to the client"
+// @Param nonce query string false "Value copied into the ID token"
+// @Param code_challenge query string false "PKCE code challenge, required for public clients"
+// @Param code_challenge_method query string false "S256 or plain"
+// @Success 302 "Redirect to the client or the login page"
+// @Failure 400 {object} models.ErrorResponse
+// @Router /oauth2/authorize [get]
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	clientID := c.Query("client_id")
+	redirectURI := c.Query("redirect_uri")
+
+	// Never redirect to an unverified URI; report these errors directly
+	client, ok := h.provider.Client(clientID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Client",
+			"Unknown client_id",
+			nil,
+		))
+		return
+	}
+	if !oidc.ValidRedirectURI(client, redirectURI) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Redirect URI",
+			"redirect_uri is not registered for this client",
+			nil,
+		))
+		return
+	}
+
+	state := c.Query("state")
+	scope := c.Query("scope")
+	if c.Query("response_type") != "code" {
+		redirectWithError(c, redirectURI, state, "unsupported_response_type", "Only the code response type is supported")
+		return
+	}
+	if !oidc.HasScope(scope, oidc.ScopeOpenID) {
+		redirectWithError(c, redirectURI, state, "invalid_scope", "The openid scope is required")
+		return
+	}
+
+	// Public clients cannot keep a secret, so they must prove possession of
+	// the code with PKCE
+	challenge := c.Query("code_challenge")
+	challengeMethod := c.DefaultQuery("code_challenge_method", oidc.ChallengeMethodPlain)
+	if challenge == "" && client.Secret == "" {
+		redirectWithError(c, redirectURI, state, "invalid_request", "code_challenge is required for public clients")
+		return
+	}
+	if challenge != "" && challengeMethod != oidc.ChallengeMethodS256 && challengeMethod != oidc.ChallengeMethodPlain {
+		redirectWithError(c, redirectURI, state, "invalid_request", "Unsupported code_challenge_method")
+		return
+	}
+
+	// Sign in first, then come back to this exact request
+	userClaims, exists := middleware.GetUserFromContext(c)
+	if !exists {
+		if c.Query("prompt") == "none" {
+			redirectWithError(c, redirectURI, state, "login_required", "User is not signed in")
+			return
+		}
+		c.Redirect(http.StatusFound, "/admin/login?return_to="+url.QueryEscape(c.Request.URL.RequestURI()))
+		return
+	}
+	if userClaims.PasswordChangeRequired {
+		redirectWithError(c, redirectURI, state, "access_denied", "User must change their password first")
+		return
+	}
+
+	code, codeHash, err := oidc.NewAuthorizationCode()
+	if err != nil {
+		redirectWithError(c, redirectURI, state, "server_error", "Failed to issue authorization code")
+		return
+	}
+
+	var authTime time.Time
+	if userClaims.IssuedAt != nil {
+		authTime = userClaims.IssuedAt.Time
+	}
+	if err := h.codeRepo.Create(c.Request.Context(), &models.OIDCAuthorizationCode{
+		CodeHash:            codeHash,
+		ClientID:            client.ID,
+		UserID:              userClaims.UserID,
+		RedirectURI:         redirectURI,
+		Scope:               scope,
+		Nonce:               c.Query("nonce"),
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: challengeMethod,
+		AuthTime:            authTime,
+		ExpiresAt:           time.Now().Add(h.provider.CodeExpiry()),
+	}); err != nil {
+		redirectWithError(c, redirectURI, state, "server_error", "Failed to issue authorization code")
+		return
+	}
+
+	params := url.Values{"code": {code}}
+	if state != "" {
+		params.Set("state", state)
+	}
+	c.Redirect(http.StatusFound, appendQuery(redirectURI, params))
+}
+
+// Token godoc
+// @Summary OpenID Connect token endpoint
+// @Description Exchange an authorization code for an ID token and an access token. Confidential clients authenticate with HTTP Basic or client_secret in the form; public clients send code_verifier.
+// @Tags oidc
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "Must be authorization_code"
+// @Param code formData string true "Authorization code"
+// @Param redirect_uri formData string true "Redirect URI used in the authorization request"
+// @Param client_id formData string false "Client ID, unless sent with HTTP Basic"
+// @Param client_secret formData string false "Client secret, unless sent with HTTP Basic"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Success 200 {object} models.OIDCTokenResponse
+// @Failure 400 {object} models.OIDCError
+// @Failure 401 {object} models.OIDCError
+// @Failure 500 {object} models.OIDCError
+// @Router /oauth2/token [post]
+func (h *OIDCHandler) Token(c *gin.Context) {
+	// Token responses must never be cached
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	if c.PostForm("grant_type") != "authorization_code" {
+		respondOIDCError(c, http.StatusBadRequest, "unsupported_grant_type", "Only the authorization_code grant is supported")
+		return
+	}
+
+	// Authenticate the client
+	clientID, clientSecret, basic := c.Request.BasicAuth()
+	if !basic {
+		clientID = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+	client, ok := h.provider.Client(clientID)
+	if !ok || !oidc.AuthenticateClient(client, clientSecret) {
+		if basic {
+			c.Header("WWW-Authenticate", `Basic realm="oidc"`)
+		}
+		respondOIDCError(c, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+		return
+	}
+
+	// Consume the code; it cannot be used again even if the checks below fail
+	code, err := h.codeRepo.Consume(c.Request.Context(), oidc.HashAuthorizationCode(c.PostForm("code")))
+	if err != nil {
+		if errors.Is(err, repository.ErrAuthorizationCodeInvalid) {
+			respondOIDCError(c, http.StatusBadRequest, "invalid_grant", "Authorization code is invalid or expired")
+			return
+		}
+		respondOIDCError(c, http.StatusInternalServerError, "server_error", "Failed to verify authorization code")
+		return
+	}
+	if code.ClientID != client.ID || code.RedirectURI != c.PostForm("redirect_uri") {
+		respondOIDCError(c, http.StatusBadRequest, "invalid_grant", "Authorization code was issued to another client or redirect_uri")
+		return
+	}
+	if !oidc.VerifyCodeChallenge(code.CodeChallenge, code.CodeChallengeMethod, c.PostForm("code_verifier")) {
+		respondOIDCError(c, http.StatusBadRequest, "invalid_grant", "code_verifier does not match the code challenge")
+		return
+	}
+
+	// The user may have been deleted since signing in
+	user, err := h.userRepo.GetByID(c.Request.Context(), code.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			respondOIDCError(c, http.StatusBadRequest, "invalid_grant", "User no longer exists")
+			return
+		}
+		respondOIDCError(c, http.StatusInternalServerError, "server_error", "Failed to retrieve user")
+		return
+	}
+
+	response, err := h.provider.IssueTokens(user, code)
+	if err != nil {
+		respondOIDCError(c, http.StatusInternalServerError, "server_error", "Failed to issue tokens")
+		return
+	}
+
+	// Log token issuance
+	h.logTokenIssued(c, user, code)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UserInfo godoc
+// @Summary OpenID Connect userinfo endpoint
+// @Description Return claims about the user an access token was issued for, limited to the granted scopes
+// @Tags oidc
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.OIDCUserInfo
+// @Failure 401 {object} models.OIDCError
+// @Failure 500 {object} models.OIDCError
+// @Router /oauth2/userinfo [get]
+func (h *OIDCHandler) UserInfo(c *gin.Context) {
+	token, err := utils.ExtractTokenFromHeader(c.GetHeader("Authorization"))
+	if err != nil {
+		h.respondInvalidToken(c, err.Error())
+		return
+	}
+
+	claims, err := h.provider.ValidateAccessToken(token)
+	if err != nil {
+		h.respondInvalidToken(c, "Access token is invalid or expired")
+		return
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		h.respondInvalidToken(c, "Access token subject is invalid")
+		return
+	}
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			h.respondInvalidToken(c, "User no longer exists")
+			return
+		}
+		respondOIDCError(c, http.StatusInternalServerError, "server_error", "Failed to retrieve user")
+		return
+	}
+
+	info := models.OIDCUserInfo{Subject: user.ID.String()}
+	if oidc.HasScope(claims.Scope, oidc.ScopeProfile) {
+		info.Name = user.Name
+	}
+	if oidc.HasScope(claims.Scope, oidc.ScopeEmail) {
+		info.Email = user.Email
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+func (h *OIDCHandler) respondInvalidToken(c *gin.Context, description string) {
+	c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+	respondOIDCError(c, http.StatusUnauthorized, "invalid_token", description)
+}
+
+// respondOIDCError writes an OAuth 2.0 error response
+func respondOIDCError(c *gin.Context, status int, code, description string) {
+	c.JSON(status, models.OIDCError{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
+
+// redirectWithError returns an authorization error to the client's
+// verified redirect URI
+func redirectWithError(c *gin.Context, redirectURI, state, code, description string) {
+	params := url.Values{
+		"error":             {code},
+		"error_description": {description},
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	c.Redirect(http.StatusFound, appendQuery(redirectURI, params))
+}
+
+// appendQuery adds params to a URI that may already have a query string
+func appendQuery(uri string, params url.Values) string {
+	if strings.Contains(uri, "?") {
+		return uri + "&" + params.Encode()
+	}
+	return uri + "?" + params.Encode()
+}
+
+// Helper methods for logging
+
+func (h *OIDCHandler) logTokenIssued(c *gin.Context, user *models.User, code *models.OIDCAuthorizationCode) {
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID: &user.ID,
+		Event:  models.OIDCTokenIssued,
+		Action: "OIDC_TOKEN_ISSUED",
+		Details: map[string]interface{}{
+			"client_id":  code.ClientID,
+			"scope":      code.Scope,
+			"ip_address": c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+		},
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	h.logRepo.CreateAsync(logEntry)
+}
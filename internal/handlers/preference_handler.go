@@ -0,0 +1,463 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PreferenceHandler handles per-admin preferences such as saved log filters,
+// saved user searches and audit event subscriptions
+type PreferenceHandler struct {
+	presetRepo       repository.LogFilterPresetRepository
+	savedSearchRepo  repository.SavedUserSearchRepository
+	subscriptionRepo repository.AuditSubscriptionRepository
+	emailEnabled     bool // Whether subscriptions may be delivered by email
+}
+
+// NewPreferenceHandler creates a new preference handler
+func NewPreferenceHandler(
+	presetRepo repository.LogFilterPresetRepository,
+	savedSearchRepo repository.SavedUserSearchRepository,
+	subscriptionRepo repository.AuditSubscriptionRepository,
+	emailEnabled bool,
+) *PreferenceHandler {
+	return &PreferenceHandler{
+		presetRepo:       presetRepo,
+		savedSearchRepo:  savedSearchRepo,
+		subscriptionRepo: subscriptionRepo,
+		emailEnabled:     emailEnabled,
+	}
+}
+
+// GetLogFilterPresets godoc
+// @Summary List saved log filters
+// @Description List the current admin's saved log filter presets, ordered by name
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.LogFilterPreset
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/preferences/log-filters [get]
+func (h *PreferenceHandler) GetLogFilterPresets(c *gin.Context) {
+	userClaims, _ := middleware.GetUserFromContext(c)
+	presets, err := h.presetRepo.ListForAdmin(c.Request.Context(), userClaims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Presets Retrieval Failed",
+			"Failed to retrieve log filter presets",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, presets)
+}
+
+// SaveLogFilterPreset godoc
+// @Summary Save a log filter
+// @Description Save the event, user and date range filters under a name. Saving under an existing name replaces that preset.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.LogFilterPresetRequest true "Preset"
+// @Success 200 {object} models.LogFilterPreset
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/preferences/log-filters [put]
+func (h *PreferenceHandler) SaveLogFilterPreset(c *gin.Context) {
+	var req models.LogFilterPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Request",
+			"Please provide a preset name and valid filters",
+			err.Error(),
+		))
+		return
+	}
+
+	// Validate filters
+	if req.Event != nil && !models.IsValidEventType(*req.Event) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Event Type",
+			"Please provide a valid event type",
+			map[string]interface{}{"valid_types": models.GetValidEventTypes()},
+		))
+		return
+	}
+	if req.StartDate != nil && req.EndDate != nil && req.EndDate.Before(*req.StartDate) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Date Range",
+			"End date must not be before start date",
+			nil,
+		))
+		return
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	preset := &models.LogFilterPreset{
+		AdminID:   userClaims.UserID,
+		Name:      req.Name,
+		Event:     req.Event,
+		UserID:    req.UserID,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	}
+	if err := h.presetRepo.Save(c.Request.Context(), preset); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Preset Save Failed",
+			"Failed to save log filter preset",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, preset)
+}
+
+// DeleteLogFilterPreset godoc
+// @Summary Delete a saved log filter
+// @Description Delete one of the current admin's log filter presets
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Preset ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/preferences/log-filters/{id} [delete]
+func (h *PreferenceHandler) DeleteLogFilterPreset(c *gin.Context) {
+	// Parse preset ID
+	presetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Preset ID",
+			"Please provide a valid preset ID",
+			err.Error(),
+		))
+		return
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	if err := h.presetRepo.Delete(c.Request.Context(), userClaims.UserID, presetID); err != nil {
+		if errors.Is(err, repository.ErrLogFilterPresetNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse(
+				http.StatusNotFound,
+				"Preset Not Found",
+				"You have no log filter preset with the specified ID",
+				nil,
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Preset Deletion Failed",
+			"Failed to delete log filter preset",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Log filter preset deleted", nil))
+}
+
+// GetSavedUserSearches godoc
+// @Summary List saved user searches
+// @Description List the current admin's saved users list searches, ordered by name
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.SavedUserSearch
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/preferences/user-searches [get]
+func (h *PreferenceHandler) GetSavedUserSearches(c *gin.Context) {
+	userClaims, _ := middleware.GetUserFromContext(c)
+	searches, err := h.savedSearchRepo.ListForAdmin(c.Request.Context(), userClaims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Saved Searches Retrieval Failed",
+			"Failed to retrieve saved user searches",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, searches)
+}
+
+// SaveUserSearch godoc
+// @Summary Save a user search
+// @Description Save users list search, role, region, signup window and sort order under a name. Saving under an existing name replaces that search. Apply it with GET /users?saved_search={id}.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.SavedUserSearchRequest true "Saved search"
+// @Success 200 {object} models.SavedUserSearch
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/preferences/user-searches [put]
+func (h *PreferenceHandler) SaveUserSearch(c *gin.Context) {
+	var req models.SavedUserSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Request",
+			"Please provide a search name and valid filters",
+			err.Error(),
+		))
+		return
+	}
+
+	// Validate filters and sort order
+	if req.Role != "" && !models.IsValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Role",
+			"Please provide a valid role",
+			map[string]interface{}{"valid_roles": []string{models.RoleUser, models.RoleAdmin}},
+		))
+		return
+	}
+	if req.SortBy == "" {
+		req.SortBy = "created_at"
+	}
+	if !repository.IsValidUserSortField(req.SortBy) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Sort Field",
+			"Please provide a valid sort field",
+			map[string]interface{}{"valid_fields": []string{"id", "name", "email", "created_at", "updated_at"}},
+		))
+		return
+	}
+	if req.SortDir == "" {
+		req.SortDir = "desc"
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	search := &models.SavedUserSearch{
+		AdminID:           userClaims.UserID,
+		Name:              req.Name,
+		Search:            req.Search,
+		Role:              req.Role,
+		Region:            req.Region,
+		CreatedWithinDays: req.CreatedWithinDays,
+		SortBy:            req.SortBy,
+		SortDir:           req.SortDir,
+	}
+	if err := h.savedSearchRepo.Save(c.Request.Context(), search); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Saved Search Save Failed",
+			"Failed to save user search",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// DeleteSavedUserSearch godoc
+// @Summary Delete a saved user search
+// @Description Delete one of the current admin's saved user searches
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Saved search ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/preferences/user-searches/{id} [delete]
+func (h *PreferenceHandler) DeleteSavedUserSearch(c *gin.Context) {
+	// Parse saved search ID
+	searchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Saved Search ID",
+			"Please provide a valid saved search ID",
+			err.Error(),
+		))
+		return
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	if err := h.savedSearchRepo.Delete(c.Request.Context(), userClaims.UserID, searchID); err != nil {
+		if errors.Is(err, repository.ErrSavedUserSearchNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse(
+				http.StatusNotFound,
+				"Saved Search Not Found",
+				"You have no saved user search with the specified ID",
+				nil,
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Saved Search Deletion Failed",
+			"Failed to delete saved user search",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Saved user search deleted", nil))
+}
+
+// GetAuditSubscription godoc
+// @Summary Get audit event subscription
+// @Description Get the event types, actions and minimum severity the current admin is notified of
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.AuditSubscription
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/preferences/audit-subscription [get]
+func (h *PreferenceHandler) GetAuditSubscription(c *gin.Context) {
+	userClaims, _ := middleware.GetUserFromContext(c)
+	subscription, err := h.subscriptionRepo.Get(c.Request.Context(), userClaims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Subscription Retrieval Failed",
+			"Failed to retrieve audit subscription",
+			err.Error(),
+		))
+		return
+	}
+	if subscription == nil {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			http.StatusNotFound,
+			"Subscription Not Found",
+			"You are not subscribed to any audit events",
+			nil,
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// SaveAuditSubscription godoc
+// @Summary Subscribe to audit events
+// @Description Choose the event types or actions (e.g. PERMANENT_DELETE_USER) and/or the minimum severity (info, warning, critical) to be notified of, in the admin panel, by email or both. Replaces the previous subscription.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.AuditSubscriptionRequest true "Subscription"
+// @Success 200 {object} models.AuditSubscription
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/preferences/audit-subscription [put]
+func (h *PreferenceHandler) SaveAuditSubscription(c *gin.Context) {
+	var req models.AuditSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Request",
+			"Please provide the events to subscribe to",
+			err.Error(),
+		))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Subscription",
+			err.Error(),
+			map[string]interface{}{"valid_types": models.GetValidEventTypes(), "valid_actions": models.GetAuditActions()},
+		))
+		return
+	}
+	if req.Email && !h.emailEnabled {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Email Not Available",
+			"Email is not enabled on this server; subscribe in the panel instead",
+			nil,
+		))
+		return
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	subscription := &models.AuditSubscription{
+		AdminID:     userClaims.UserID,
+		Events:      models.StringList(req.Events),
+		MinSeverity: req.MinSeverity,
+		Email:       req.Email,
+		Panel:       req.Panel,
+	}
+	if err := h.subscriptionRepo.Save(c.Request.Context(), subscription); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Subscription Save Failed",
+			"Failed to save audit subscription",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteAuditSubscription godoc
+// @Summary Unsubscribe from audit events
+// @Description Stop all audit event notifications of the current admin
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.SuccessResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/preferences/audit-subscription [delete]
+func (h *PreferenceHandler) DeleteAuditSubscription(c *gin.Context) {
+	userClaims, _ := middleware.GetUserFromContext(c)
+	if err := h.subscriptionRepo.Delete(c.Request.Context(), userClaims.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Subscription Deletion Failed",
+			"Failed to delete audit subscription",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Audit subscription deleted", nil))
+}
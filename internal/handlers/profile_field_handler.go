@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileFieldHandler handles the profile fields users are required to fill in
+type ProfileFieldHandler struct {
+	fieldRepo repository.ProfileFieldRepository
+	logRepo   repository.UserLogRepository
+	profiles  *middleware.ProfileRequirements
+}
+
+// NewProfileFieldHandler creates a new profile field handler
+func NewProfileFieldHandler(
+	fieldRepo repository.ProfileFieldRepository,
+	logRepo repository.UserLogRepository,
+	profiles *middleware.ProfileRequirements,
+) *ProfileFieldHandler {
+	return &ProfileFieldHandler{
+		fieldRepo: fieldRepo,
+		logRepo:   logRepo,
+		profiles:  profiles,
+	}
+}
+
+// GetRequiredProfileFields godoc
+// @Summary List required profile fields
+// @Description List the profile fields users must fill in before using the API
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.RequiredProfileField
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/profile-fields/required [get]
+func (h *ProfileFieldHandler) GetRequiredProfileFields(c *gin.Context) {
+	fields, err := h.fieldRepo.ListRequired(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Profile Fields Retrieval Failed",
+			"Failed to retrieve required profile fields",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, fields)
+}
+
+// SetRequiredProfileFields godoc
+// @Summary Set required profile fields
+// @Description Replace the set of required profile fields. Users missing any of them at their next login can only complete their profile until they fill them in.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.RequiredProfileFieldsRequest true "Required fields"
+// @Success 200 {array} models.RequiredProfileField
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/profile-fields/required [put]
+func (h *ProfileFieldHandler) SetRequiredProfileFields(c *gin.Context) {
+	var req models.RequiredProfileFieldsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Request",
+			"Please provide a list of at most 20 field names",
+			err.Error(),
+		))
+		return
+	}
+
+	// Validate field names
+	for _, field := range req.Fields {
+		if !models.IsValidProfileField(field) {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Profile Field",
+				"Profile field names must be lowercase identifiers",
+				map[string]string{"field": field},
+			))
+			return
+		}
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	if err := h.fieldRepo.SetRequired(c.Request.Context(), req.Fields, userClaims.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Profile Fields Update Failed",
+			"Failed to update required profile fields",
+			err.Error(),
+		))
+		return
+	}
+	if h.profiles != nil {
+		h.profiles.Invalidate()
+	}
+
+	fields, err := h.fieldRepo.ListRequired(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Profile Fields Retrieval Failed",
+			"Failed to retrieve required profile fields",
+			err.Error(),
+		))
+		return
+	}
+
+	// Log required field change
+	h.logRequiredFieldsChange(c, userClaims, req.Fields)
+
+	c.JSON(http.StatusOK, fields)
+}
+
+// Helper methods for logging
+
+func (h *ProfileFieldHandler) logRequiredFieldsChange(c *gin.Context, admin *models.JWTClaims, fields []string) {
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID: &admin.UserID,
+		Event:  models.UserUpdated,
+		Action: "REQUIRED_PROFILE_FIELDS_UPDATED",
+		Details: map[string]interface{}{
+			"fields":     fields,
+			"ip_address": c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+		},
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	h.logRepo.CreateAsync(logEntry)
+}
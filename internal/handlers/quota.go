@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// quotaPrincipal identifies who creation quotas are counted against
+func quotaPrincipal(c *gin.Context) string {
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		if userClaims.APIKeyID != nil {
+			return "apikey:" + userClaims.APIKeyID.String()
+		}
+		return "admin:" + userClaims.UserID.String()
+	}
+	return "anonymous"
+}
+
+// respondQuotaExceeded writes a 429 response describing the exhausted quota
+func respondQuotaExceeded(c *gin.Context, status models.QuotaStatus, requested int) {
+	retryAfter := int(time.Until(status.ResetsAt).Seconds()) + 1
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, models.NewErrorResponse(
+		http.StatusTooManyRequests,
+		"Quota Exceeded",
+		fmt.Sprintf("Daily %s creation quota exceeded (%d of %d used)", status.Resource, status.Used, status.Limit),
+		map[string]interface{}{
+			"resource":  status.Resource,
+			"limit":     status.Limit,
+			"used":      status.Used,
+			"remaining": status.Remaining,
+			"requested": requested,
+			"resets_at": status.ResetsAt,
+		},
+	))
+}
+
+// respondUserLimitReached writes a 403 response for a creation that would
+// take the active users past the hard limit
+func respondUserLimitReached(c *gin.Context, status models.UsageLimitStatus, requested int) {
+	c.JSON(http.StatusForbidden, models.NewErrorResponse(
+		http.StatusForbidden,
+		"User Limit Reached",
+		fmt.Sprintf("Creating %d user(s) would exceed the limit of %d active users (%d in use)", requested, status.HardLimit, status.Used),
+		map[string]interface{}{
+			"resource":   status.Resource,
+			"used":       status.Used,
+			"hard_limit": status.HardLimit,
+			"requested":  requested,
+		},
+	))
+}
+
+// warnUserSoftLimit flags a response whose creation took the active users
+// past the soft limit
+func warnUserSoftLimit(c *gin.Context, status models.UsageLimitStatus) {
+	if status.OverSoftLimit {
+		c.Header("X-User-Limit-Warning", fmt.Sprintf("active users are past the soft limit of %d", status.SoftLimit))
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/reports"
+	"user_mgmt_go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler lists and serves the scheduled reports
+type ReportHandler struct {
+	repoManager *repository.RepositoryManager
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(repoManager *repository.RepositoryManager) *ReportHandler {
+	return &ReportHandler{repoManager: repoManager}
+}
+
+// ListReports godoc
+// @Summary List scheduled reports
+// @Description List the configured scheduled reports with the files generated for their current period. Files of earlier periods stay downloadable by name.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.ReportsListResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/reports [get]
+func (h *ReportHandler) ListReports(c *gin.Context) {
+	list, err := h.repoManager.Reports(c.Request.Context())
+	if err != nil {
+		respondRepositoryError(c, err, "Report Retrieval Failed", "Failed to list reports")
+		return
+	}
+	c.JSON(http.StatusOK, models.ReportsListResponse{Reports: list})
+}
+
+// DownloadReport godoc
+// @Summary Download a report file
+// @Description Download a generated report file, named by its period and format, e.g. 2024-W07.csv or 2024-02.pdf
+// @Tags admin
+// @Security BearerAuth
+// @Produce text/csv,application/pdf
+// @Param name path string true "Report name"
+// @Param file path string true "Report file name"
+// @Success 200 {file} file
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/reports/{name}/{file} [get]
+func (h *ReportHandler) DownloadReport(c *gin.Context) {
+	name, file := c.Param("name"), c.Param("file")
+	content, object, err := h.repoManager.OpenReport(c.Request.Context(), name, file)
+	if err != nil {
+		respondRepositoryError(c, err, "Report Retrieval Failed", "Failed to retrieve report")
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Type", reports.ContentType(reports.FormatOf(file)))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"-"+file))
+	c.Header("Cache-Control", "private, no-cache")
+	http.ServeContent(c.Writer, c.Request, "", object.ModTime, content)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// repositoryErrorStatus maps a repository error to an HTTP status by its
+// generic kind. Errors of no known kind are server errors.
+func repositoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrDuplicate), errors.Is(err, repository.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrForeignKey):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondRepositoryError writes an error response for a failed repository
+// call, with the status chosen by repositoryErrorStatus
+func respondRepositoryError(c *gin.Context, err error, title, message string) {
+	status := repositoryErrorStatus(err)
+	c.JSON(status, models.NewErrorResponse(
+		status,
+		title,
+		message,
+		err.Error(),
+	))
+}
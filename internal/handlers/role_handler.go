@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RoleHandler handles role change requests and their approval
+type RoleHandler struct {
+	logRepo     repository.UserLogRepository
+	repoManager *repository.RepositoryManager
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(logRepo repository.UserLogRepository, repoManager *repository.RepositoryManager) *RoleHandler {
+	return &RoleHandler{
+		logRepo:     logRepo,
+		repoManager: repoManager,
+	}
+}
+
+// RequestRoleChange godoc
+// @Summary Change a user's role
+// @Description Request a role change. Granting admin may require a second admin's approval and a cooldown before it activates; other changes apply immediately. The user is signed out of all sessions once the change is applied.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.RoleChangeRequest true "New role"
+// @Success 201 {object} models.RoleChange
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/users/{id}/role [post]
+func (h *RoleHandler) RequestRoleChange(c *gin.Context) {
+	// Parse user ID
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid User ID",
+			"Please provide a valid user ID",
+			err.Error(),
+		))
+		return
+	}
+
+	var req models.RoleChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Request",
+			"Please provide a valid role",
+			err.Error(),
+		))
+		return
+	}
+
+	if !models.IsValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Role",
+			"Role must be one of: user, admin",
+			nil,
+		))
+		return
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	change, err := h.repoManager.RequestRoleChange(c.Request.Context(), userID, req.Role, req.Reason, userClaims.UserID)
+	if err != nil {
+		h.respondRoleChangeError(c, err)
+		return
+	}
+
+	// Log role change request
+	h.logRoleChange(c, "ROLE_CHANGE_REQUESTED", change, req.Reason)
+
+	c.JSON(http.StatusCreated, change)
+}
+
+// GetRoleChanges godoc
+// @Summary List role changes
+// @Description List the most recent role changes, optionally filtered by status
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status (pending_approval, scheduled, applied, rejected)"
+// @Success 200 {array} models.RoleChange
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/role-changes [get]
+func (h *RoleHandler) GetRoleChanges(c *gin.Context) {
+	changes, err := h.repoManager.Repos.RoleChange.List(c.Request.Context(), models.RoleChangeStatus(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Role Changes Retrieval Failed",
+			"Failed to retrieve role changes",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// ApproveRoleChange godoc
+// @Summary Approve a role change
+// @Description Approve a pending role change. The approver must be a different admin from the requester; the change activates after the configured cooldown.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Role change ID"
+// @Success 200 {object} models.RoleChange
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/role-changes/{id}/approve [post]
+func (h *RoleHandler) ApproveRoleChange(c *gin.Context) {
+	// Parse role change ID
+	changeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Role Change ID",
+			"Please provide a valid role change ID",
+			err.Error(),
+		))
+		return
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	change, err := h.repoManager.ApproveRoleChange(c.Request.Context(), changeID, userClaims.UserID)
+	if err != nil {
+		h.respondRoleChangeError(c, err)
+		return
+	}
+
+	// Log role change approval
+	h.logRoleChange(c, "ROLE_CHANGE_APPROVED", change, "")
+
+	c.JSON(http.StatusOK, change)
+}
+
+// RejectRoleChange godoc
+// @Summary Reject a role change
+// @Description Reject a pending role change, or cancel an approved one that is still in its cooldown
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Role change ID"
+// @Param request body models.RoleChangeReviewRequest false "Rejection reason"
+// @Success 200 {object} models.RoleChange
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /admin/role-changes/{id}/reject [post]
+func (h *RoleHandler) RejectRoleChange(c *gin.Context) {
+	// Parse role change ID
+	changeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Role Change ID",
+			"Please provide a valid role change ID",
+			err.Error(),
+		))
+		return
+	}
+
+	// Reason is optional
+	var req models.RoleChangeReviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Request",
+				"Please provide a valid rejection reason",
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	userClaims, _ := middleware.GetUserFromContext(c)
+	change, err := h.repoManager.RejectRoleChange(c.Request.Context(), changeID, userClaims.UserID)
+	if err != nil {
+		h.respondRoleChangeError(c, err)
+		return
+	}
+
+	// Log role change rejection
+	h.logRoleChange(c, "ROLE_CHANGE_REJECTED", change, req.Reason)
+
+	c.JSON(http.StatusOK, change)
+}
+
+// respondRoleChangeError maps role change errors to HTTP responses
+func (h *RoleHandler) respondRoleChangeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			http.StatusNotFound,
+			"User Not Found",
+			"User with the specified ID does not exist",
+			nil,
+		))
+	case errors.Is(err, repository.ErrRoleChangeNotFound):
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			http.StatusNotFound,
+			"Role Change Not Found",
+			"Role change with the specified ID does not exist",
+			nil,
+		))
+	case errors.Is(err, repository.ErrRoleChangeSelfReview):
+		c.JSON(http.StatusForbidden, models.NewErrorResponse(
+			http.StatusForbidden,
+			"Second Admin Required",
+			"You cannot change your own role or approve a change you requested",
+			nil,
+		))
+	case errors.Is(err, repository.ErrRoleUnchanged):
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Role Unchanged",
+			"User already has the requested role",
+			nil,
+		))
+	case errors.Is(err, repository.ErrRoleChangeConflict), errors.Is(err, repository.ErrRoleChangeNotPending):
+		c.JSON(http.StatusConflict, models.NewErrorResponse(
+			http.StatusConflict,
+			"Role Change Conflict",
+			err.Error(),
+			nil,
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Role Change Failed",
+			"Failed to process role change",
+			err.Error(),
+		))
+	}
+}
+
+// Helper methods for logging
+
+func (h *RoleHandler) logRoleChange(c *gin.Context, action string, change *models.RoleChange, reason string) {
+	// Get admin from context
+	var adminID *uuid.UUID
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		adminID = &userClaims.UserID
+	}
+
+	details := map[string]interface{}{
+		"role_change_id": change.ID,
+		"target_user_id": change.UserID,
+		"from_role":      change.FromRole,
+		"to_role":        change.ToRole,
+		"status":         change.Status,
+		"ip_address":     c.ClientIP(),
+		"user_agent":     c.Request.UserAgent(),
+	}
+	if change.EffectiveAt != nil {
+		details["effective_at"] = change.EffectiveAt
+	}
+	if reason != "" {
+		details["reason"] = reason
+	}
+
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID:    adminID,
+		Event:     models.RoleChangeEvent,
+		Action:    action,
+		Details:   details,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	h.logRepo.CreateAsync(logEntry)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"sort"
+	"strings"
+
+	"user_mgmt_go/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// undocumentedCategory groups manifest routes missing from GetRouteSummary
+const undocumentedCategory = "Other"
+
+// RouteManifestEntry describes a route as registered with gin: the handler
+// it runs, the middleware in front of it and the auth level that middleware
+// enforces. Category and description come from GetRouteSummary.
+type RouteManifestEntry struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Description string   `json:"description,omitempty"`
+	Auth        string   `json:"auth"`
+	Handler     string   `json:"handler"`
+	Middleware  []string `json:"middleware"`
+	Category    string   `json:"-"`
+}
+
+// routeProbeKey marks the requests BuildRouteManifest sends through the
+// router. Being a context value, it cannot be set by clients.
+type routeProbeKey struct{}
+
+// routeProbe receives the handler chain a probe request was routed to
+type routeProbe struct {
+	fullPath string
+	handlers []string
+}
+
+// routeProbeMiddleware answers manifest probes with the matched route's
+// handler chain instead of running it. It must be the first middleware.
+func routeProbeMiddleware(c *gin.Context) {
+	if probe, ok := c.Request.Context().Value(routeProbeKey{}).(*routeProbe); ok {
+		probe.fullPath = c.FullPath()
+		probe.handlers = c.HandlerNames()
+		c.Abort()
+	}
+}
+
+// BuildRouteManifest builds the route manifest served at /api/docs/routes
+// from the routes registered on router, probing each for its handler chain.
+// Call it once all routes are registered.
+func (hm *HandlerManager) BuildRouteManifest(router *gin.Engine) error {
+	documented := make(map[string]struct{ category, description string })
+	for category, routes := range hm.GetRouteSummary() {
+		for _, route := range routes {
+			documented[route.Method+" "+route.Path] = struct{ category, description string }{category, route.Description}
+		}
+	}
+
+	probeName := middleware.HandlerName(routeProbeMiddleware)
+	var manifest []RouteManifestEntry
+	var unresolved []string
+	for _, route := range router.Routes() {
+		probe := &routeProbe{}
+		req := httptest.NewRequest(route.Method, probePath(route.Path), nil)
+		req = req.WithContext(context.WithValue(req.Context(), routeProbeKey{}, probe))
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		if probe.fullPath != route.Path || len(probe.handlers) == 0 {
+			unresolved = append(unresolved, route.Method+" "+route.Path)
+			continue
+		}
+
+		middlewareNames := []string{}
+		for _, name := range probe.handlers[:len(probe.handlers)-1] {
+			if name != probeName {
+				middlewareNames = append(middlewareNames, shortHandlerName(name))
+			}
+		}
+
+		entry := RouteManifestEntry{
+			Method:     route.Method,
+			Path:       route.Path,
+			Auth:       middleware.AuthLevel(probe.handlers),
+			Handler:    shortHandlerName(route.Handler),
+			Middleware: middlewareNames,
+			Category:   undocumentedCategory,
+		}
+		if doc, ok := documented[route.Method+" "+route.Path]; ok {
+			entry.Category = doc.category
+			entry.Description = doc.description
+		}
+		manifest = append(manifest, entry)
+	}
+
+	sort.Slice(manifest, func(i, j int) bool {
+		if manifest[i].Path != manifest[j].Path {
+			return manifest[i].Path < manifest[j].Path
+		}
+		return manifest[i].Method < manifest[j].Method
+	})
+	hm.routeManifest = manifest
+
+	if len(unresolved) > 0 {
+		return fmt.Errorf("could not resolve the handler chain of %s", strings.Join(unresolved, ", "))
+	}
+	return nil
+}
+
+// RouteManifest returns the manifest built by BuildRouteManifest
+func (hm *HandlerManager) RouteManifest() []RouteManifestEntry {
+	return hm.routeManifest
+}
+
+// probePath returns a request path matching a route path, filling in its
+// parameters
+func probePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "probe"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// shortHandlerName drops the module path from a handler name
+func shortHandlerName(name string) string {
+	return strings.TrimPrefix(name, "user_mgmt_go/internal/")
+}
+
+// RouteManifestByCategory returns the manifest grouped by documentation
+// category, with undocumented routes under "Other"
+func (hm *HandlerManager) RouteManifestByCategory() map[string][]RouteManifestEntry {
+	routes := make(map[string][]RouteManifestEntry)
+	for _, entry := range hm.routeManifest {
+		routes[entry.Category] = append(routes[entry.Category], entry)
+	}
+	return routes
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits for the number of throttled visitors reported per limiter
+const (
+	defaultTopThrottled = 10
+	maxTopThrottled     = 100
+)
+
+// SecurityHandler handles introspection of the server's security controls
+type SecurityHandler struct {
+	limiters map[string]*middleware.RateLimiter
+}
+
+// NewSecurityHandler creates a new security handler
+func NewSecurityHandler(limiters map[string]*middleware.RateLimiter) *SecurityHandler {
+	return &SecurityHandler{
+		limiters: limiters,
+	}
+}
+
+// RateLimitsResponse represents the state of every request rate limiter
+type RateLimitsResponse struct {
+	Limiters    map[string]middleware.RateLimiterStats `json:"limiters"`
+	GeneratedAt time.Time                              `json:"generated_at"`
+}
+
+// GetRateLimits godoc
+// @Summary Inspect rate limiters
+// @Description Show current visitor counts, the most throttled visitors and rejection rates for each request rate limiter. Counts are kept in memory by this instance since it started.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param top query int false "Throttled visitors to list per limiter" default(10)
+// @Success 200 {object} RateLimitsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /admin/security/rate-limits [get]
+func (h *SecurityHandler) GetRateLimits(c *gin.Context) {
+	// Parse number of throttled visitors to list
+	top := defaultTopThrottled
+	if topStr := c.Query("top"); topStr != "" {
+		parsed, err := strconv.Atoi(topStr)
+		if err != nil || parsed < 1 || parsed > maxTopThrottled {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Top",
+				"top must be a number between 1 and 100",
+				nil,
+			))
+			return
+		}
+		top = parsed
+	}
+
+	response := RateLimitsResponse{
+		Limiters:    make(map[string]middleware.RateLimiterStats, len(h.limiters)),
+		GeneratedAt: time.Now(),
+	}
+	for name, limiter := range h.limiters {
+		response.Limiters[name] = limiter.Stats(top)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupHandler handles first-boot admin setup requests
+type SetupHandler struct {
+	repoManager *repository.RepositoryManager
+}
+
+// NewSetupHandler creates a new setup handler
+func NewSetupHandler(repoManager *repository.RepositoryManager) *SetupHandler {
+	return &SetupHandler{
+		repoManager: repoManager,
+	}
+}
+
+// GetSetupStatus godoc
+// @Summary Admin setup status
+// @Description Report whether the one-time admin setup is pending
+// @Tags setup
+// @Produce json
+// @Success 200 {object} SetupStatusResponse
+// @Router /setup/status [get]
+func (h *SetupHandler) GetSetupStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, SetupStatusResponse{
+		SetupRequired: h.repoManager.SetupPending(),
+	})
+}
+
+// CompleteSetup godoc
+// @Summary Complete admin setup
+// @Description Create the admin user using the one-time setup token printed at first boot
+// @Tags setup
+// @Accept json
+// @Produce json
+// @Param request body AdminSetupRequest true "Setup token and admin password"
+// @Success 201 {object} models.UserResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /setup/admin [post]
+func (h *SetupHandler) CompleteSetup(c *gin.Context) {
+	var req AdminSetupRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Request",
+			"Please provide the setup token and a password",
+			err.Error(),
+		))
+		return
+	}
+
+	// Validate password
+	if !utils.IsValidPassword(req.Password) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			http.StatusBadRequest,
+			"Invalid Password",
+			"Password does not meet requirements",
+			nil,
+		))
+		return
+	}
+
+	adminUser, err := h.repoManager.CompleteAdminSetup(c.Request.Context(), req.SetupToken, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrSetupNotAvailable):
+			c.JSON(http.StatusConflict, models.NewErrorResponse(
+				http.StatusConflict,
+				"Setup Not Available",
+				"Admin setup is not pending",
+				nil,
+			))
+		case errors.Is(err, repository.ErrInvalidSetupToken):
+			c.JSON(http.StatusUnauthorized, models.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Invalid Setup Token",
+				"The setup token is invalid",
+				nil,
+			))
+		default:
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				http.StatusInternalServerError,
+				"Setup Failed",
+				"Failed to create admin user",
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, adminUser.ToResponse())
+}
+
+// SetupStatusResponse represents the admin setup status
+type SetupStatusResponse struct {
+	SetupRequired bool `json:"setup_required"`
+}
+
+// AdminSetupRequest represents the one-time admin setup request
+type AdminSetupRequest struct {
+	SetupToken string `json:"setup_token" binding:"required"`
+	Password   string `json:"password" binding:"required,min=6" example:"a-strong-password"`
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/internal/scanner"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// scanUpload scans an upload before it is stored or processed. Infected
+// uploads are rejected with 422 and logged as UPLOAD_REJECTED; uploads that
+// cannot be scanned are rejected with 503. It reports whether the upload may
+// be used, having responded if not.
+func scanUpload(c *gin.Context, uploads scanner.Scanner, logRepo repository.UserLogRepository, kind, name string, r io.Reader, details map[string]interface{}) bool {
+	verdict, err := uploads.Scan(c.Request.Context(), name, r)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse(
+			http.StatusServiceUnavailable,
+			"Scan Unavailable",
+			"The upload could not be scanned, please try again later",
+			err.Error(),
+		))
+		return false
+	}
+	if !verdict.Infected {
+		return true
+	}
+
+	logUploadRejected(c, logRepo, kind, name, verdict, details)
+	c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse(
+		http.StatusUnprocessableEntity,
+		"Upload Rejected",
+		"The uploaded file was flagged as malicious",
+		nil,
+	))
+	return false
+}
+
+// logUploadRejected records an infected upload as a security event
+func logUploadRejected(c *gin.Context, logRepo repository.UserLogRepository, kind, name string, verdict scanner.Verdict, details map[string]interface{}) {
+	var uploaderID *uuid.UUID
+	if userClaims, exists := middleware.GetUserFromContext(c); exists {
+		uploaderID = &userClaims.UserID
+	}
+
+	logDetails := map[string]interface{}{
+		"upload_kind": kind,
+		"file_name":   name,
+		"threat":      verdict.Threat,
+		"scanner":     verdict.Scanner,
+		"ip_address":  c.ClientIP(),
+		"user_agent":  c.Request.UserAgent(),
+	}
+	for key, value := range details {
+		logDetails[key] = value
+	}
+
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		UserID:    uploaderID,
+		Event:     models.UploadRejected,
+		Action:    "REJECT_INFECTED_UPLOAD",
+		Details:   logDetails,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	logRepo.CreateAsync(logEntry)
+}
@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"reflect"
 	"strconv"
+	"time"
 
+	"user_mgmt_go/internal/mailer"
 	"user_mgmt_go/internal/middleware"
 	"user_mgmt_go/internal/models"
 	"user_mgmt_go/internal/repository"
@@ -15,24 +21,32 @@ import (
 
 // UserHandler handles user-related requests
 type UserHandler struct {
-	userRepo repository.UserRepository
-	logRepo  repository.UserLogRepository
+	userRepo    repository.UserRepository
+	logRepo     repository.UserLogRepository
+	repoManager *repository.RepositoryManager
+
+	// accountNotifier is nil when account change notices are disabled
+	accountNotifier *mailer.AccountNotifier
 }
 
 // NewUserHandler creates a new user handler
 func NewUserHandler(
 	userRepo repository.UserRepository,
 	logRepo repository.UserLogRepository,
+	repoManager *repository.RepositoryManager,
+	accountNotifier *mailer.AccountNotifier,
 ) *UserHandler {
 	return &UserHandler{
-		userRepo: userRepo,
-		logRepo:  logRepo,
+		userRepo:        userRepo,
+		logRepo:         logRepo,
+		repoManager:     repoManager,
+		accountNotifier: accountNotifier,
 	}
 }
 
 // ListUsers godoc
 // @Summary List users
-// @Description Get paginated list of users with optional filtering and search
+// @Description Get paginated list of users with optional filtering and search. With saved_search, the current admin's saved search supplies the parameters not given explicitly.
 // @Tags users
 // @Security BearerAuth
 // @Accept json
@@ -42,12 +56,65 @@ func NewUserHandler(
 // @Param sort_by query string false "Sort by field" default("created_at")
 // @Param sort_dir query string false "Sort direction" default("desc") Enums(asc, desc)
 // @Param search query string false "Search term"
+// @Param role query string false "Role" Enums(user, admin)
+// @Param region query string false "Data residency region"
+// @Param created_within_days query int false "Only users created in the last N days"
+// @Param saved_search query string false "Saved user search ID"
 // @Success 200 {object} models.UsersListResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /users [get]
 func (h *UserHandler) ListUsers(c *gin.Context) {
+	query := c.Request.URL.Query()
+
+	// Apply a saved search under the explicit parameters
+	if savedSearchID := query.Get("saved_search"); savedSearchID != "" {
+		id, err := uuid.Parse(savedSearchID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Saved Search ID",
+				"Please provide a valid saved search ID",
+				err.Error(),
+			))
+			return
+		}
+
+		userClaims, _ := middleware.GetUserFromContext(c)
+		saved, err := h.repoManager.Repos.SavedUserSearch.GetForAdmin(c.Request.Context(), userClaims.UserID, id)
+		if err != nil {
+			respondRepositoryError(c, err, "Saved Search Retrieval Failed", "Failed to retrieve saved user search")
+			return
+		}
+
+		values := saved.QueryValues()
+		for key, value := range query {
+			values[key] = value
+		}
+		query = values
+	}
+
+	searchTerm, filter, params := parseUserListQuery(query, time.Now())
+
+	response, err := h.userRepo.ListFiltered(c.Request.Context(), searchTerm, filter, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"List Failed",
+			"Failed to retrieve users",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// parseUserListQuery reads the users list search term, filters and
+// pagination from query parameters, ignoring invalid values
+func parseUserListQuery(query url.Values, now time.Time) (string, repository.UserFilter, repository.ListParams) {
 	// Parse pagination parameters
 	params := repository.ListParams{
 		Page:     1,
@@ -56,47 +123,34 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 		SortDir:  "desc",
 	}
 
-	if page, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && page > 0 {
+	if page, err := strconv.Atoi(query.Get("page")); err == nil && page > 0 {
 		params.Page = page
 	}
 
-	if pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10")); err == nil && pageSize > 0 && pageSize <= 100 {
+	if pageSize, err := strconv.Atoi(query.Get("page_size")); err == nil && pageSize > 0 && pageSize <= 100 {
 		params.PageSize = pageSize
 	}
 
-	if sortBy := c.Query("sort_by"); sortBy != "" && repository.IsValidUserSortField(sortBy) {
+	if sortBy := query.Get("sort_by"); sortBy != "" && repository.IsValidUserSortField(sortBy) {
 		params.SortBy = sortBy
 	}
 
-	if sortDir := c.Query("sort_dir"); sortDir == "asc" || sortDir == "desc" {
+	if sortDir := query.Get("sort_dir"); sortDir == "asc" || sortDir == "desc" {
 		params.SortDir = sortDir
 	}
 
-	// Check for search parameter
-	searchTerm := c.Query("search")
-	
-	var response *models.UsersListResponse
-	var err error
-
-	if searchTerm != "" {
-		// Perform search
-		response, err = h.userRepo.Search(c.Request.Context(), searchTerm, params)
-	} else {
-		// Regular list
-		response, err = h.userRepo.List(c.Request.Context(), params)
+	// Parse filters
+	filter := repository.UserFilter{
+		Role:   query.Get("role"),
+		Region: query.Get("region"),
 	}
 
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			http.StatusInternalServerError,
-			"List Failed",
-			"Failed to retrieve users",
-			err.Error(),
-		))
-		return
+	if days, err := strconv.Atoi(query.Get("created_within_days")); err == nil && days > 0 {
+		from := now.AddDate(0, 0, -days).Format(time.RFC3339)
+		filter.CreatedAt = &repository.TimeRange{From: &from}
 	}
 
-	c.JSON(http.StatusOK, response)
+	return query.Get("search"), filter, params
 }
 
 // GetUser godoc
@@ -128,16 +182,64 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	// Get user from database
 	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.NewErrorResponse(
-			http.StatusNotFound,
-			"User Not Found",
-			"User with the specified ID was not found",
-			err.Error(),
-		))
+		respondRepositoryError(c, err, "User Not Found", "User with the specified ID was not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, user.ToResponse())
+	response := user.ToResponse()
+	if identities, err := h.repoManager.Repos.Identity.ListForUser(c.Request.Context(), user.ID); err == nil {
+		response.LinkedIdentities = identities
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUserChanges godoc
+// @Summary List user changes
+// @Description List users created, updated, deleted or permanently deleted after a cursor, oldest first, so downstream systems can sync incrementally instead of exporting every user. Start without since and pass next_cursor on the following request; has_more means another page is ready now.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param since query string false "Cursor from a previous response's next_cursor"
+// @Param limit query int false "Maximum changes to return (max 1000)" default(100)
+// @Success 200 {object} models.UserChangesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/changes [get]
+func (h *UserHandler) GetUserChanges(c *gin.Context) {
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Limit",
+				"limit must be a positive whole number",
+				nil,
+			))
+			return
+		}
+		limit = parsed
+	}
+
+	response, err := h.repoManager.UserChanges(c.Request.Context(), c.Query("since"), limit)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidChangesCursor) {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid Cursor",
+				"since must be a next_cursor returned by this endpoint",
+				err.Error(),
+			))
+			return
+		}
+		respondRepositoryError(c, err, "Changes Retrieval Failed", "Failed to retrieve user changes")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // CreateUser godoc
@@ -151,7 +253,9 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 // @Success 201 {object} models.UserResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 409 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
@@ -179,6 +283,12 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	// Check the email domain against the registration rules
+	if err := h.repoManager.CheckEmailDomain(req.Email); err != nil {
+		respondEmailDomainNotAllowed(c, err)
+		return
+	}
+
 	// Check if user already exists
 	exists, err := h.userRepo.Exists(c.Request.Context(), req.Email)
 	if err != nil {
@@ -201,9 +311,43 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	// Check active user limits
+	userLimit, err := h.repoManager.CheckUserLimit(c.Request.Context(), 1)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserLimitReached) {
+			respondUserLimitReached(c, userLimit, 1)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Database Error",
+			"Failed to check user limits",
+			err.Error(),
+		))
+		return
+	}
+
+	// Consume creation quota
+	principal := quotaPrincipal(c)
+	quota, err := h.repoManager.ConsumeQuota(c.Request.Context(), principal, models.QuotaResourceUsers, 1)
+	if err != nil {
+		if errors.Is(err, repository.ErrQuotaExceeded) {
+			respondQuotaExceeded(c, quota, 1)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			http.StatusInternalServerError,
+			"Database Error",
+			"Failed to check creation quota",
+			err.Error(),
+		))
+		return
+	}
+
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
+		h.repoManager.ReleaseQuota(c.Request.Context(), principal, models.QuotaResourceUsers, 1)
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
 			http.StatusInternalServerError,
 			"Password Hashing Failed",
@@ -218,22 +362,24 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: hashedPassword,
+		Region:   req.Region,
 	}
 
 	// Save to database
 	if err := h.userRepo.Create(c.Request.Context(), user); err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			http.StatusInternalServerError,
-			"Creation Failed",
-			"Failed to create user",
-			err.Error(),
-		))
+		h.repoManager.ReleaseQuota(c.Request.Context(), principal, models.QuotaResourceUsers, 1)
+		if errors.Is(err, repository.ErrRegionNotAllowed) {
+			respondRegionNotAllowed(c, err)
+			return
+		}
+		respondRepositoryError(c, err, "Creation Failed", "Failed to create user")
 		return
 	}
 
 	// Log user creation
 	h.logUserCreation(c, user)
 
+	warnUserSoftLimit(c, userLimit)
 	c.JSON(http.StatusCreated, user.ToResponse())
 }
 
@@ -283,12 +429,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	// Get existing user for logging old values
 	existingUser, err := h.userRepo.GetByID(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.NewErrorResponse(
-			http.StatusNotFound,
-			"User Not Found",
-			"User with the specified ID was not found",
-			err.Error(),
-		))
+		respondRepositoryError(c, err, "User Not Found", "User with the specified ID was not found")
 		return
 	}
 
@@ -304,6 +445,13 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	}
 
 	if req.Email != nil && *req.Email != existingUser.Email {
+		// The registration rules apply to changed emails too, so an account
+		// cannot move to a domain it could not have been created with
+		if err := h.repoManager.CheckEmailDomain(*req.Email); err != nil {
+			respondEmailDomainNotAllowed(c, err)
+			return
+		}
+
 		// Check if new email already exists
 		exists, err := h.userRepo.Exists(c.Request.Context(), *req.Email)
 		if err != nil {
@@ -360,6 +508,46 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		newValues["password"] = "[REDACTED]"
 	}
 
+	if req.Profile != nil {
+		// Merge profile fields; an empty value removes the field
+		profile := models.Profile{}
+		for field, value := range existingUser.Profile {
+			profile[field] = value
+		}
+		for field, value := range req.Profile {
+			if !models.IsValidProfileField(field) || len(value) > models.MaxProfileValueLength {
+				c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+					http.StatusBadRequest,
+					"Invalid Profile Field",
+					fmt.Sprintf("Profile field names must be lowercase identifiers and values at most %d characters", models.MaxProfileValueLength),
+					map[string]string{"field": field},
+				))
+				return
+			}
+			if value == "" {
+				delete(profile, field)
+			} else {
+				profile[field] = value
+			}
+		}
+
+		if len(profile) > models.MaxProfileFields {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				http.StatusBadRequest,
+				"Too Many Profile Fields",
+				fmt.Sprintf("A profile can have at most %d fields", models.MaxProfileFields),
+				nil,
+			))
+			return
+		}
+
+		if !reflect.DeepEqual(profile, existingUser.Profile) {
+			updates["profile"] = profile
+			oldValues["profile"] = existingUser.Profile
+			newValues["profile"] = profile
+		}
+	}
+
 	// Check if there are any updates
 	if len(updates) == 0 {
 		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
@@ -373,36 +561,29 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 	// Perform update
 	if err := h.userRepo.Update(c.Request.Context(), userID, updates); err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			http.StatusInternalServerError,
-			"Update Failed",
-			"Failed to update user",
-			err.Error(),
-		))
+		respondRepositoryError(c, err, "Update Failed", "Failed to update user")
 		return
 	}
 
 	// Get updated user
 	updatedUser, err := h.userRepo.GetByID(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			http.StatusInternalServerError,
-			"Retrieval Failed",
-			"Failed to retrieve updated user",
-			err.Error(),
-		))
+		respondRepositoryError(c, err, "Retrieval Failed", "Failed to retrieve updated user")
 		return
 	}
 
-	// Log user update
-	h.logUserUpdate(c, updatedUser, oldValues, newValues)
+	// Log user update and tell the user when an admin changed their account
+	logEntry := h.logUserUpdate(c, updatedUser, oldValues, newValues)
+	if isAdminActingOnOther(c, userID) && h.accountNotifier != nil {
+		h.accountNotifier.AccountChanged(existingUser, mailer.AccountUpdated, logEntry)
+	}
 
 	c.JSON(http.StatusOK, updatedUser.ToResponse())
 }
 
 // DeleteUser godoc
 // @Summary Delete user
-// @Description Soft delete a user account
+// @Description Soft delete a user account. Deleting an already-deleted user is idempotent and returns status "already_deleted"
 // @Tags users
 // @Security BearerAuth
 // @Produce json
@@ -427,18 +608,6 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	// Get user before deletion for logging
-	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, models.NewErrorResponse(
-			http.StatusNotFound,
-			"User Not Found",
-			"User with the specified ID was not found",
-			err.Error(),
-		))
-		return
-	}
-
 	// Check if user is trying to delete themselves
 	if userClaims, exists := middleware.GetUserFromContext(c); exists {
 		if userClaims.UserID == userID {
@@ -452,29 +621,88 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		}
 	}
 
+	// Get user before deletion for logging (missing if already soft-deleted)
+	user, _ := h.userRepo.GetByID(c.Request.Context(), userID)
+
 	// Perform soft delete
 	if err := h.userRepo.Delete(c.Request.Context(), userID); err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			http.StatusInternalServerError,
-			"Deletion Failed",
-			"Failed to delete user",
-			err.Error(),
-		))
+		switch {
+		case errors.Is(err, repository.ErrUserAlreadyDeleted):
+			// Deleting an already-deleted user is a successful no-op so retries are safe
+			c.JSON(http.StatusOK, models.NewSuccessResponse(
+				"User already deleted",
+				map[string]interface{}{
+					"deleted_user_id": userID,
+					"status":          "already_deleted",
+				},
+			))
+		case errors.Is(err, repository.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, models.NewErrorResponse(
+				http.StatusNotFound,
+				"User Not Found",
+				"User with the specified ID was not found",
+				err.Error(),
+			))
+		default:
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+				http.StatusInternalServerError,
+				"Deletion Failed",
+				"Failed to delete user",
+				err.Error(),
+			))
+		}
 		return
 	}
 
-	// Log user deletion
-	h.logUserDeletion(c, user)
+	if user == nil {
+		user = &models.User{ID: userID}
+	}
+
+	// Log user deletion and tell the user their account was deactivated
+	logEntry := h.logUserDeletion(c, user)
+	if h.accountNotifier != nil {
+		h.accountNotifier.AccountChanged(user, mailer.AccountDeactivated, logEntry)
+	}
 
 	c.JSON(http.StatusOK, models.NewSuccessResponse(
 		"User deleted successfully",
 		map[string]interface{}{
 			"deleted_user_id": userID,
 			"deleted_email":   user.Email,
+			"status":          "deleted",
 		},
 	))
 }
 
+// isAdminActingOnOther reports whether the caller is an admin acting on
+// another user's account
+func isAdminActingOnOther(c *gin.Context, userID uuid.UUID) bool {
+	userClaims, exists := middleware.GetUserFromContext(c)
+	return exists && userClaims.Role == models.RoleAdmin && userClaims.UserID != userID
+}
+
+// respondRegionNotAllowed writes a 400 response for a user region this
+// instance may not store
+func respondRegionNotAllowed(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+		http.StatusBadRequest,
+		"Region Not Allowed",
+		"Users cannot be created in the requested region on this instance",
+		err.Error(),
+	))
+}
+
+// respondEmailDomainNotAllowed rejects an account whose email domain the
+// registration rules do not allow
+func respondEmailDomainNotAllowed(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+		http.StatusBadRequest,
+		"Email Domain Not Allowed",
+		"Accounts cannot use this email domain",
+		err.Error(),
+	))
+}
+
 // Helper methods for logging
 
 func (h *UserHandler) logUserCreation(c *gin.Context, user *models.User) {
@@ -507,7 +735,7 @@ func (h *UserHandler) logUserCreation(c *gin.Context, user *models.User) {
 	h.logRepo.CreateAsync(logEntry)
 }
 
-func (h *UserHandler) logUserUpdate(c *gin.Context, user *models.User, oldValues, newValues map[string]interface{}) {
+func (h *UserHandler) logUserUpdate(c *gin.Context, user *models.User, oldValues, newValues map[string]interface{}) *models.UserLog {
 	// Get updater from context
 	var updaterID *uuid.UUID
 	if userClaims, exists := middleware.GetUserFromContext(c); exists {
@@ -532,9 +760,10 @@ func (h *UserHandler) logUserUpdate(c *gin.Context, user *models.User, oldValues
 	})
 
 	h.logRepo.CreateAsync(logEntry)
+	return logEntry
 }
 
-func (h *UserHandler) logUserDeletion(c *gin.Context, user *models.User) {
+func (h *UserHandler) logUserDeletion(c *gin.Context, user *models.User) *models.UserLog {
 	// Get deleter from context
 	var deleterID *uuid.UUID
 	if userClaims, exists := middleware.GetUserFromContext(c); exists {
@@ -562,4 +791,5 @@ func (h *UserHandler) logUserDeletion(c *gin.Context, user *models.User) {
 	})
 
 	h.logRepo.CreateAsync(logEntry)
+	return logEntry
 } 
\ No newline at end of file
@@ -0,0 +1,266 @@
+// Package importer transforms user exports from external identity providers
+// into records that can be fed to the bulk user creation pipeline.
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"user_mgmt_go/internal/config"
+)
+
+// Format identifies a supported export format
+type Format string
+
+const (
+	FormatAzureADCSV         Format = "azure_ad_csv"
+	FormatGoogleWorkspaceCSV Format = "google_workspace_csv"
+	FormatGenericJSON        Format = "generic_json"
+)
+
+// FieldMapping maps user fields to source columns (CSV headers or JSON keys).
+// Name columns are joined with a space; the first non-empty Email column wins.
+// JSON keys may use dot notation for nested objects, e.g. "name.fullName".
+type FieldMapping struct {
+	Name  []string
+	Email []string
+}
+
+// Mappings holds the field mapping used for each format
+type Mappings map[Format]FieldMapping
+
+// Record is a user extracted from an export
+type Record struct {
+	Row   int // 1-based data row (CSV) or array element (JSON)
+	Name  string
+	Email string
+}
+
+// RowError describes a source row that could not be transformed
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// DefaultMappings returns the built-in field mappings for each format
+func DefaultMappings() Mappings {
+	return Mappings{
+		FormatAzureADCSV: {
+			Name:  []string{"displayName"},
+			Email: []string{"mail", "userPrincipalName"},
+		},
+		FormatGoogleWorkspaceCSV: {
+			Name:  []string{"First Name [Required]", "Last Name [Required]"},
+			Email: []string{"Email Address [Required]"},
+		},
+		FormatGenericJSON: {
+			Name:  []string{"name"},
+			Email: []string{"email"},
+		},
+	}
+}
+
+// NewMappings returns the default mappings with configured overrides applied.
+// An override replaces only the fields it sets.
+func NewMappings(overrides map[string]config.ImportFieldMapping) (Mappings, error) {
+	mappings := DefaultMappings()
+	for name, override := range overrides {
+		format := Format(name)
+		mapping, ok := mappings[format]
+		if !ok {
+			return nil, fmt.Errorf("unknown import format %q", name)
+		}
+		if len(override.Name) > 0 {
+			mapping.Name = override.Name
+		}
+		if len(override.Email) > 0 {
+			mapping.Email = override.Email
+		}
+		mappings[format] = mapping
+	}
+	return mappings, nil
+}
+
+// Transform reads an export in the given format and returns the extracted
+// records along with per-row errors. Duplicate emails within the export are
+// reported as row errors.
+func Transform(format Format, r io.Reader, mapping FieldMapping) ([]Record, []RowError, error) {
+	var rows []map[string]string
+	var err error
+
+	switch format {
+	case FormatAzureADCSV, FormatGoogleWorkspaceCSV:
+		rows, err = readCSV(r)
+	case FormatGenericJSON:
+		rows, err = readJSON(r)
+	default:
+		return nil, nil, fmt.Errorf("unsupported import format %q", format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []Record
+	var rowErrors []RowError
+	seen := make(map[string]int)
+
+	for i, row := range rows {
+		rowNumber := i + 1
+		record, err := mapRow(row, mapping)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: rowNumber, Error: err.Error()})
+			continue
+		}
+
+		if firstRow, duplicate := seen[record.Email]; duplicate {
+			rowErrors = append(rowErrors, RowError{
+				Row:   rowNumber,
+				Error: fmt.Sprintf("duplicate email, first seen in row %d", firstRow),
+			})
+			continue
+		}
+		seen[record.Email] = rowNumber
+
+		record.Row = rowNumber
+		records = append(records, record)
+	}
+
+	return records, rowErrors, nil
+}
+
+// mapRow applies the field mapping to a single row
+func mapRow(row map[string]string, mapping FieldMapping) (Record, error) {
+	var email string
+	for _, column := range mapping.Email {
+		if value := strings.TrimSpace(row[strings.ToLower(column)]); value != "" {
+			email = strings.ToLower(value)
+			break
+		}
+	}
+	if email == "" {
+		return Record{}, fmt.Errorf("missing email")
+	}
+	if at := strings.Index(email, "@"); at < 1 || at == len(email)-1 {
+		return Record{}, fmt.Errorf("invalid email %q", email)
+	}
+
+	var nameParts []string
+	for _, column := range mapping.Name {
+		if value := strings.TrimSpace(row[strings.ToLower(column)]); value != "" {
+			nameParts = append(nameParts, value)
+		}
+	}
+
+	// Fall back to the email local part so every imported user has a name
+	name := strings.Join(nameParts, " ")
+	if name == "" {
+		name = email[:strings.Index(email, "@")]
+	}
+
+	return Record{Name: name, Email: email}, nil
+}
+
+// readCSV reads a CSV export into rows keyed by lower-cased header
+func readCSV(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	lines, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+
+	// Azure AD templates start with a "version:v1.0" line before the header
+	if len(lines) > 0 && len(lines[0]) > 0 && strings.HasPrefix(strings.ToLower(lines[0][0]), "version:") {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("CSV export is empty")
+	}
+
+	header := make([]string, len(lines[0]))
+	for i, column := range lines[0] {
+		column = strings.TrimPrefix(column, "\ufeff") // Excel byte order mark
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+	}
+
+	rows := make([]map[string]string, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		if isBlankLine(line) {
+			continue
+		}
+		row := make(map[string]string, len(header))
+		for i, value := range line {
+			if i < len(header) {
+				row[header[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// readJSON reads a JSON array of user objects, or an object with a "users"
+// array, into rows keyed by lower-cased dot-notation paths
+func readJSON(r io.Reader) ([]map[string]string, error) {
+	var raw interface{}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	if wrapper, ok := raw.(map[string]interface{}); ok {
+		raw = wrapper["users"]
+	}
+
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("JSON export must be an array of users or an object with a \"users\" array")
+	}
+
+	rows := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		row := make(map[string]string)
+		if object, ok := item.(map[string]interface{}); ok {
+			flattenJSON("", object, row)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// flattenJSON flattens nested objects into dot-notation keys
+func flattenJSON(prefix string, object map[string]interface{}, row map[string]string) {
+	for key, value := range object {
+		path := strings.ToLower(key)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flattenJSON(path, v, row)
+		case string:
+			row[path] = v
+		case json.Number, bool:
+			row[path] = fmt.Sprint(v)
+		}
+	}
+}
+
+// isBlankLine reports whether every field of a CSV line is empty
+func isBlankLine(line []string) bool {
+	for _, value := range line {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
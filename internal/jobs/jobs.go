@@ -0,0 +1,169 @@
+// Package jobs tracks the progress of long-running admin bulk operations so
+// clients can poll processed, succeeded and failed counts by job ID while
+// the operation runs.
+package jobs
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Job statuses
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// ErrJobExists is returned when starting a job with the ID of a job that is
+// still tracked
+var ErrJobExists = errors.New("job ID already in use")
+
+// Progress is a snapshot of a job's progress
+type Progress struct {
+	ID         string     `json:"job_id"`
+	Operation  string     `json:"operation"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Succeeded  int        `json:"succeeded"`
+	Failed     int        `json:"failed"`
+	Percent    int        `json:"percent"`
+	Error      string     `json:"error,omitempty"`
+	StartedBy  string     `json:"started_by"`
+	StartedAt  time.Time  `json:"started_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Job reports progress of one running operation. A nil Job ignores all calls.
+type Job struct {
+	mu       sync.Mutex
+	progress Progress
+}
+
+// Advance records processed items, of which succeeded and failed are known
+// outcomes. Items processed without an outcome yet (validated but not
+// written) are settled by a later call with processed 0.
+func (j *Job) Advance(processed, succeeded, failed int) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.progress.Processed += processed
+	j.progress.Succeeded += succeeded
+	j.progress.Failed += failed
+	j.progress.UpdatedAt = time.Now()
+}
+
+// Finish marks the job completed, or failed when err is not nil
+func (j *Job) Finish(err error) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	j.progress.Status = StatusCompleted
+	if err != nil {
+		j.progress.Status = StatusFailed
+		j.progress.Error = err.Error()
+	}
+	j.progress.UpdatedAt = now
+	j.progress.FinishedAt = &now
+}
+
+// ID returns the job's ID
+func (j *Job) ID() string {
+	if j == nil {
+		return ""
+	}
+	return j.progress.ID
+}
+
+// Progress returns a snapshot of the job's progress
+func (j *Job) Progress() Progress {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	progress := j.progress
+	if progress.Total > 0 {
+		progress.Percent = progress.Processed * 100 / progress.Total
+	} else if progress.FinishedAt != nil {
+		progress.Percent = 100
+	}
+	return progress
+}
+
+// finishedBefore reports whether the job finished before t
+func (j *Job) finishedBefore(t time.Time) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.progress.FinishedAt != nil && j.progress.FinishedAt.Before(t)
+}
+
+// Tracker holds running jobs, and finished ones for a retention period so
+// a final poll sees the outcome
+type Tracker struct {
+	mu        sync.Mutex
+	jobs      map[string]*Job
+	retention time.Duration
+}
+
+// NewTracker creates a tracker keeping finished jobs for retention
+func NewTracker(retention time.Duration) *Tracker {
+	return &Tracker{
+		jobs:      make(map[string]*Job),
+		retention: retention,
+	}
+}
+
+// Start begins tracking a job of total items
+func (t *Tracker) Start(id, operation, startedBy string, total int) (*Job, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pruneLocked()
+	if _, exists := t.jobs[id]; exists {
+		return nil, ErrJobExists
+	}
+
+	now := time.Now()
+	job := &Job{progress: Progress{
+		ID:        id,
+		Operation: operation,
+		Status:    StatusRunning,
+		Total:     total,
+		StartedBy: startedBy,
+		StartedAt: now,
+		UpdatedAt: now,
+	}}
+	t.jobs[id] = job
+	return job, nil
+}
+
+// Get returns the progress of a tracked job
+func (t *Tracker) Get(id string) (Progress, bool) {
+	t.mu.Lock()
+	t.pruneLocked()
+	job, ok := t.jobs[id]
+	t.mu.Unlock()
+	if !ok {
+		return Progress{}, false
+	}
+	return job.Progress(), true
+}
+
+// pruneLocked drops jobs that finished longer ago than the retention period
+func (t *Tracker) pruneLocked() {
+	cutoff := time.Now().Add(-t.retention)
+	for id, job := range t.jobs {
+		if job.finishedBefore(cutoff) {
+			delete(t.jobs, id)
+		}
+	}
+}
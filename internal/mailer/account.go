@@ -0,0 +1,126 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"user_mgmt_go/internal/models"
+)
+
+// AccountChange is a kind of admin action on a user's account
+type AccountChange string
+
+const (
+	AccountUpdated     AccountChange = "updated"
+	AccountDeactivated AccountChange = "deactivated"
+	AccountRestored    AccountChange = "restored"
+)
+
+// accountNoticeTimeout bounds sending one account notice
+const accountNoticeTimeout = 30 * time.Second
+
+// AccountNotifier emails users when an admin changes their account. Changed
+// values come from the audit entry, redacted as for non-admin log viewers.
+type AccountNotifier struct {
+	mailer         Mailer
+	supportContact string
+	redaction      models.LogRedactionPolicy
+}
+
+// NewAccountNotifier creates an account notifier
+func NewAccountNotifier(mailer Mailer, supportContact string, redaction models.LogRedactionPolicy) *AccountNotifier {
+	return &AccountNotifier{
+		mailer:         mailer,
+		supportContact: supportContact,
+		redaction:      redaction,
+	}
+}
+
+// AccountChanged emails the user about the change in the background. user
+// is the account as it was before the change, so an email change is
+// reported to the previous address.
+func (n *AccountNotifier) AccountChanged(user *models.User, change AccountChange, entry *models.UserLog) {
+	if user == nil || user.Email == "" {
+		return
+	}
+
+	msg := n.Message(user, change, entry)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), accountNoticeTimeout)
+		defer cancel()
+		if err := n.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Failed to send account %s notice to user %s: %v", change, user.ID, err)
+		}
+	}()
+}
+
+// Message builds the notice for an account change
+func (n *AccountNotifier) Message(user *models.User, change AccountChange, entry *models.UserLog) Message {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hello %s,\n\n", user.Name)
+
+	switch change {
+	case AccountDeactivated:
+		body.WriteString("An administrator has deactivated your account. You can no longer sign in.\n")
+	case AccountRestored:
+		body.WriteString("An administrator has restored your account. You can sign in again.\n")
+	default:
+		body.WriteString("An administrator has changed your account.\n")
+	}
+
+	if entry != nil {
+		response := entry.ToResponseForRole(models.RoleUser, n.redaction)
+		fmt.Fprintf(&body, "\nWhen: %s\n", response.Timestamp.UTC().Format(time.RFC1123))
+
+		if len(response.Data.NewValues) > 0 {
+			body.WriteString("\nChanged fields:\n")
+			fields := make([]string, 0, len(response.Data.NewValues))
+			for field := range response.Data.NewValues {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			for _, field := range fields {
+				fmt.Fprintf(&body, "  - %s: %s -> %s\n", field,
+					noticeValue(response.Data.OldValues[field]),
+					noticeValue(response.Data.NewValues[field]))
+			}
+		}
+	}
+
+	body.WriteString("\nIf you did not expect this change")
+	if n.supportContact != "" {
+		fmt.Fprintf(&body, ", please contact %s.\n", n.supportContact)
+	} else {
+		body.WriteString(", please contact your administrator.\n")
+	}
+
+	return Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Your account was %s", change),
+		Body:    body.String(),
+	}
+}
+
+// noticeValue formats an audit value for a notice
+func noticeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		if v == "" {
+			return "(empty)"
+		}
+		return v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
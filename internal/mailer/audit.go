@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"user_mgmt_go/internal/models"
+)
+
+// maxDigestEntries caps the audit entries listed in one digest
+const maxDigestEntries = 100
+
+// AuditDigest builds the email listing the audit entries that matched an
+// admin's subscription in one run, oldest first. Only event names and IDs
+// are included; the details stay in the admin panel.
+func AuditDigest(admin *models.User, entries []models.UserLog) Message {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hello %s,\n\n", admin.Name)
+	fmt.Fprintf(&body, "%d audit events matched your subscription:\n\n", len(entries))
+
+	for i, entry := range entries {
+		if i == maxDigestEntries {
+			fmt.Fprintf(&body, "  ... and %d more\n", len(entries)-maxDigestEntries)
+			break
+		}
+		fmt.Fprintf(&body, "  - %s [%s] %s", entry.Timestamp.UTC().Format(time.RFC1123), models.AuditSeverity(entry), entry.Event)
+		if entry.Data.Action != "" {
+			fmt.Fprintf(&body, " / %s", entry.Data.Action)
+		}
+		if entry.UserID != nil {
+			fmt.Fprintf(&body, " (user %s)", *entry.UserID)
+		}
+		body.WriteString("\n")
+	}
+
+	body.WriteString("\nSee the Notifications page of the admin panel for details, or change your subscription there.\n")
+
+	subject := "1 audit event matched your subscription"
+	if len(entries) != 1 {
+		subject = fmt.Sprintf("%d audit events matched your subscription", len(entries))
+	}
+	return Message{
+		To:      admin.Email,
+		Subject: subject,
+		Body:    body.String(),
+	}
+}
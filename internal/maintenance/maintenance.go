@@ -0,0 +1,132 @@
+// Package maintenance tracks scheduled maintenance windows. During a window
+// alerts are muted, internal networks bypass the IP rate limits, and
+// clients are told to show a banner.
+package maintenance
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"user_mgmt_go/internal/config"
+)
+
+// Window is a scheduled maintenance window
+type Window struct {
+	Start   time.Time `json:"starts_at"`
+	End     time.Time `json:"ends_at"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Contains reports whether t falls inside the window
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// Banner tells clients about a current or upcoming window
+type Banner struct {
+	Active   bool    `json:"active"`
+	Upcoming bool    `json:"upcoming"`
+	Window   *Window `json:"window,omitempty"`
+}
+
+// Schedule holds the configured maintenance windows
+type Schedule struct {
+	windows  []Window // Sorted by start
+	internal []*net.IPNet
+	leadTime time.Duration
+}
+
+// NewSchedule creates a schedule from the configuration
+func NewSchedule(cfg config.MaintenanceConfig) (*Schedule, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	schedule := &Schedule{leadTime: cfg.BannerLeadTime}
+	for _, window := range cfg.Windows {
+		start, _ := time.Parse(time.RFC3339, window.Start)
+		end, _ := time.Parse(time.RFC3339, window.End)
+		schedule.windows = append(schedule.windows, Window{Start: start, End: end, Message: window.Message})
+	}
+	sort.Slice(schedule.windows, func(i, j int) bool {
+		return schedule.windows[i].Start.Before(schedule.windows[j].Start)
+	})
+
+	for _, cidr := range cfg.InternalNetworks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("internal network %q: %w", cidr, err)
+		}
+		schedule.internal = append(schedule.internal, network)
+	}
+	return schedule, nil
+}
+
+// Active returns the window in progress at now, or nil
+func (s *Schedule) Active(now time.Time) *Window {
+	if s == nil {
+		return nil
+	}
+	for i := range s.windows {
+		if s.windows[i].Contains(now) {
+			return &s.windows[i]
+		}
+	}
+	return nil
+}
+
+// InMaintenance reports whether a window is in progress at now
+func (s *Schedule) InMaintenance(now time.Time) bool {
+	return s.Active(now) != nil
+}
+
+// Next returns the earliest window starting within the banner lead time
+// after now, or nil
+func (s *Schedule) Next(now time.Time) *Window {
+	if s == nil {
+		return nil
+	}
+	for i := range s.windows {
+		start := s.windows[i].Start
+		if start.After(now) && !start.After(now.Add(s.leadTime)) {
+			return &s.windows[i]
+		}
+	}
+	return nil
+}
+
+// Internal reports whether ip belongs to one of the internal networks
+func (s *Schedule) Internal(ip string) bool {
+	if s == nil {
+		return false
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, network := range s.internal {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// RelaxRateLimits reports whether requests from ip bypass the IP rate
+// limits at now: only internal networks, and only during a window
+func (s *Schedule) RelaxRateLimits(ip string, now time.Time) bool {
+	return s.InMaintenance(now) && s.Internal(ip)
+}
+
+// Banner returns what clients should show at now
+func (s *Schedule) Banner(now time.Time) Banner {
+	if window := s.Active(now); window != nil {
+		return Banner{Active: true, Window: window}
+	}
+	if window := s.Next(now); window != nil {
+		return Banner{Upcoming: true, Window: window}
+	}
+	return Banner{}
+}
@@ -1,20 +1,78 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"reflect"
+	"runtime"
+	"time"
 
+	"user_mgmt_go/internal/config"
 	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
 	"user_mgmt_go/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
-// AuthMiddleware creates authentication middleware
-func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
+// passwordChangeAllowedRoutes are the only routes reachable with a token
+// that still requires a password change
+var passwordChangeAllowedRoutes = map[string]bool{
+	"/api/auth/change-password": true,
+	"/api/auth/logout":          true,
+	"/api/auth/profile":         true,
+}
+
+// profileCompletionRoute is where users complete their profile; it is
+// reachable for their own ID while required profile fields are missing
+const profileCompletionRoute = "/api/users/:id"
+
+// sessionTouchInterval limits how often session activity is written
+const sessionTouchInterval = time.Minute
+
+// AuthMiddleware creates authentication middleware. Tokens bound to a session
+// are rejected once the session is revoked or expired, and tokens bound to a
+// DPoP key are rejected without a proof signed by that key. Tokens issued
+// while required profile fields were missing only reach the profile routes
+// until the fields are filled in. Requests may instead carry an API key in
+// the X-API-Key header. Accounts and keys with an IP allowlist are only
+// accepted from the listed networks. During soft launch, users it does not
+// admit are turned away. Responses to sandbox users carry the sandbox
+// headers.
+func AuthMiddleware(jwtManager *utils.JWTManager, sessions repository.SessionRepository, dpop *utils.DPoPVerifier, profiles *ProfileRequirements, apiKeys *APIKeyAuthenticator, allowlists *IPAllowlists, softLaunch *config.SoftLaunchConfig, sandbox *config.SandboxConfig) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		var token string
 		var err error
 
+		// Authenticate with an API key when one is sent
+		if rawKey := c.GetHeader(utils.APIKeyHeader); rawKey != "" && apiKeys != nil {
+			claims, err := apiKeys.Authenticate(c.Request.Context(), rawKey, c.ClientIP())
+			if errors.Is(err, ErrIPNotAllowed) {
+				respondIPNotAllowed(c)
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, models.NewErrorResponse(
+					http.StatusUnauthorized,
+					"Unauthorized",
+					"Invalid API key",
+					map[string]string{"error": err.Error()},
+				))
+				c.Abort()
+				return
+			}
+			if !softLaunchAdmits(softLaunch, claims) {
+				RespondNotYetEnabled(c, softLaunch)
+				return
+			}
+
+			setUserContext(c, claims)
+			SetSandboxHeaders(c, sandbox, claims.Email)
+			c.Next()
+			return
+		}
+
 		// Extract token from Authorization header first
 		authHeader := c.GetHeader("Authorization")
 		if authHeader != "" {
@@ -58,38 +116,210 @@ func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		// Check proof of possession for bound tokens
+		if err := verifyDPoPProof(c, dpop, token, claims); err != nil {
+			c.Header("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+			c.JSON(http.StatusUnauthorized, models.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Invalid DPoP Proof",
+				"This token is bound to a key; send a DPoP proof signed by it",
+				map[string]string{"error": err.Error()},
+			))
+			c.Abort()
+			return
+		}
+
+		// Check the login session is still active
+		if status, err := ValidateSession(c, sessions, claims); err != nil {
+			c.JSON(status, models.NewErrorResponse(
+				status,
+				http.StatusText(status),
+				err.Error(),
+				nil,
+			))
+			c.Abort()
+			return
+		}
+
+		// Check the account may be used from this network
+		if allowlists != nil {
+			allowed, err := allowlists.Allowed(c.Request.Context(), claims.UserID, c.ClientIP())
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, models.NewErrorResponse(
+					http.StatusUnauthorized,
+					"Unauthorized",
+					"Unable to verify the account",
+					map[string]string{"error": err.Error()},
+				))
+				c.Abort()
+				return
+			}
+			if !allowed {
+				respondIPNotAllowed(c)
+				return
+			}
+		}
+
+		// Only admit selected users during soft launch
+		if !softLaunchAdmits(softLaunch, claims) {
+			RespondNotYetEnabled(c, softLaunch)
+			return
+		}
+
+		// Block everything but password change until the required change is made
+		if claims.PasswordChangeRequired && !passwordChangeAllowedRoutes[c.FullPath()] {
+			c.JSON(http.StatusForbidden, models.NewErrorResponse(
+				http.StatusForbidden,
+				"Password Change Required",
+				"You must change your password before continuing",
+				map[string]string{"change_password_url": "/api/auth/change-password"},
+			))
+			c.Abort()
+			return
+		}
+
+		// Block everything but profile completion while required fields are missing
+		if claims.ProfileCompletionRequired && profiles != nil && !profileCompletionAllowed(c, claims) {
+			missing, err := profiles.Missing(c.Request.Context(), claims.UserID)
+			if err == nil && len(missing) > 0 {
+				c.JSON(http.StatusPreconditionRequired, models.NewErrorResponse(
+					http.StatusPreconditionRequired,
+					"Profile Completion Required",
+					"You must complete your profile before continuing",
+					map[string]interface{}{
+						"missing_fields":       missing,
+						"complete_profile_url": "/api/users/" + claims.UserID.String(),
+					},
+				))
+				c.Abort()
+				return
+			}
+		}
+
 		// Store user information in context for use in handlers
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_name", claims.Name)
-		c.Set("user_role", claims.Role)
-		c.Set("jwt_claims", claims)
+		setUserContext(c, claims)
+		SetSandboxHeaders(c, sandbox, claims.Email)
 
 		// Continue to next handler
 		c.Next()
 	})
 }
 
+// setUserContext stores the authenticated user's information in the context
+// for use in handlers
+func setUserContext(c *gin.Context, claims *models.JWTClaims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("user_name", claims.Name)
+	c.Set("user_role", claims.Role)
+	c.Set("jwt_claims", claims)
+}
+
+// respondIPNotAllowed rejects a request from outside the account's or API
+// key's allowlist
+func respondIPNotAllowed(c *gin.Context) {
+	c.JSON(http.StatusForbidden, models.NewErrorResponse(
+		http.StatusForbidden,
+		"IP Not Allowed",
+		"This account cannot be used from your network",
+		map[string]string{"ip_address": c.ClientIP()},
+	))
+	c.Abort()
+}
+
+// profileCompletionAllowed reports whether a request is allowed while the
+// user's profile is incomplete: reading their profile, updating it, signing
+// out and changing their password
+func profileCompletionAllowed(c *gin.Context, claims *models.JWTClaims) bool {
+	if passwordChangeAllowedRoutes[c.FullPath()] {
+		return true
+	}
+	return c.FullPath() == profileCompletionRoute && c.Param("id") == claims.UserID.String()
+}
+
+// ValidateSession checks that the session the token belongs to is active and
+// records activity on it. Tokens issued without a session are accepted. It
+// returns the HTTP status to reject the request with.
+func ValidateSession(c *gin.Context, sessions repository.SessionRepository, claims *models.JWTClaims) (int, error) {
+	if sessions == nil || claims.SessionID == "" {
+		return http.StatusOK, nil
+	}
+
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return http.StatusUnauthorized, errors.New("invalid session")
+	}
+
+	session, err := sessions.GetByID(c.Request.Context(), sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return http.StatusUnauthorized, errors.New("session not found")
+		}
+		return http.StatusServiceUnavailable, errors.New("unable to verify session")
+	}
+	if !session.IsActive() {
+		return http.StatusUnauthorized, errors.New("session has been revoked or has expired")
+	}
+
+	// Activity only needs minute precision; avoid a write per request
+	if time.Since(session.LastActivityAt) > sessionTouchInterval || session.IPAddress != c.ClientIP() {
+		sessions.Touch(c.Request.Context(), sessionID, c.ClientIP())
+	}
+
+	return http.StatusOK, nil
+}
+
+// verifyDPoPProof checks the request's DPoP proof when the token is bound to
+// a key. Bearer tokens need no proof.
+func verifyDPoPProof(c *gin.Context, dpop *utils.DPoPVerifier, token string, claims *models.JWTClaims) error {
+	jkt := claims.BoundKey()
+	if jkt == "" {
+		return nil
+	}
+
+	proofJKT, err := dpop.Verify(c.GetHeader(utils.DPoPHeader), c.Request.Method, utils.DPoPTargetURI(c.Request), token)
+	if err != nil {
+		return err
+	}
+	if proofJKT != jkt {
+		return errors.New("DPoP proof is signed by a different key than the token is bound to")
+	}
+	return nil
+}
+
 // OptionalAuthMiddleware creates optional authentication middleware
 // If token is present, it validates and sets user context
 // If token is missing, it continues without authentication
-func OptionalAuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
+func OptionalAuthMiddleware(jwtManager *utils.JWTManager, sessions repository.SessionRepository, dpop *utils.DPoPVerifier) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
+		var token string
+		var err error
+
+		// Like AuthMiddleware, fall back to the admin_token cookie so
+		// browser flows such as OIDC authorization see the login session
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		if authHeader != "" {
+			token, err = utils.ExtractTokenFromHeader(authHeader)
+		} else {
+			token, err = c.Cookie("admin_token")
+		}
+		if err != nil || token == "" {
 			c.Next()
 			return
 		}
 
-		// Extract and validate token
-		token, err := utils.ExtractTokenFromHeader(authHeader)
+		claims, err := jwtManager.ValidateToken(token)
 		if err != nil {
 			c.Next()
 			return
 		}
 
-		claims, err := jwtManager.ValidateToken(token)
-		if err != nil {
+		if err := verifyDPoPProof(c, dpop, token, claims); err != nil {
+			c.Next()
+			return
+		}
+
+		if _, err := ValidateSession(c, sessions, claims); err != nil {
 			c.Next()
 			return
 		}
@@ -242,7 +472,7 @@ func SelfOrAdminMiddleware(userIDParam string) gin.HandlerFunc {
 			requestedUserID = c.Query(userIDParam)
 		}
 
-		if requestedUserID == userID.(string) || requestedUserID == userID.(*models.User).ID.String() {
+		if id, ok := userID.(uuid.UUID); ok && requestedUserID == id.String() {
 			c.Next()
 			return
 		}
@@ -321,4 +551,48 @@ func GetUserRole(c *gin.Context) (string, bool) {
 
 	userRole, ok := role.(string)
 	return userRole, ok
-} 
\ No newline at end of file
+}
+
+// Auth levels enforced by the auth middleware in a route's handler chain
+const (
+	AuthPublic      = "Public"
+	AuthOptional    = "Optional"
+	AuthRequired    = "Required"
+	AuthSelfOrAdmin = "Self or Admin"
+	AuthAdmin       = "Admin"
+)
+
+// authLevelRanks orders the auth levels from least to most restrictive
+var authLevelRanks = map[string]int{
+	AuthPublic:      0,
+	AuthOptional:    1,
+	AuthRequired:    2,
+	AuthSelfOrAdmin: 3,
+	AuthAdmin:       4,
+}
+
+// authMiddlewareLevels maps the handler names of the auth middleware, as
+// reported by gin, to the level each enforces
+var authMiddlewareLevels = map[string]string{
+	HandlerName(AuthMiddleware(nil, nil, nil, nil, nil, nil, nil, nil)): AuthRequired,
+	HandlerName(OptionalAuthMiddleware(nil, nil, nil)):                  AuthOptional,
+	HandlerName(SelfOrAdminMiddleware("")):                              AuthSelfOrAdmin,
+	HandlerName(AdminRequiredMiddleware()):                              AuthAdmin,
+}
+
+// HandlerName returns the name gin reports for a handler
+func HandlerName(handler gin.HandlerFunc) string {
+	return runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+}
+
+// AuthLevel returns the most restrictive auth level enforced by a handler
+// chain, given as handler names as from gin.Context.HandlerNames
+func AuthLevel(handlerNames []string) string {
+	level := AuthPublic
+	for _, name := range handlerNames {
+		if l, ok := authMiddlewareLevels[name]; ok && authLevelRanks[l] > authLevelRanks[level] {
+			level = l
+		}
+	}
+	return level
+}
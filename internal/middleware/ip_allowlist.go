@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// allowlistCacheTTL bounds how long a change to an account's allowlist
+// takes to reach requests served by other instances
+const allowlistCacheTTL = 30 * time.Second
+
+// IPAllowlists checks requests against the networks each account may be
+// used from
+type IPAllowlists struct {
+	users repository.UserRepository
+
+	mutex   sync.Mutex
+	entries map[uuid.UUID]cachedAllowlist
+}
+
+type cachedAllowlist struct {
+	allowlist models.IPAllowlist
+	loadedAt  time.Time
+}
+
+// NewIPAllowlists creates an account allowlist checker
+func NewIPAllowlists(users repository.UserRepository) *IPAllowlists {
+	return &IPAllowlists{
+		users:   users,
+		entries: make(map[uuid.UUID]cachedAllowlist),
+	}
+}
+
+// Allowed reports whether the user may be used from ip. Allowlists are
+// cached briefly so requests do not each load the user.
+func (a *IPAllowlists) Allowed(ctx context.Context, userID uuid.UUID, ip string) (bool, error) {
+	a.mutex.Lock()
+	entry, ok := a.entries[userID]
+	a.mutex.Unlock()
+
+	if !ok || time.Since(entry.loadedAt) >= allowlistCacheTTL {
+		user, err := a.users.GetByID(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+		entry = cachedAllowlist{allowlist: user.AllowedIPs, loadedAt: time.Now()}
+
+		a.mutex.Lock()
+		a.pruneLocked()
+		a.entries[userID] = entry
+		a.mutex.Unlock()
+	}
+
+	return entry.allowlist.Allows(ip), nil
+}
+
+// Invalidate drops a user's cached allowlist after it is changed
+func (a *IPAllowlists) Invalidate(userID uuid.UUID) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	delete(a.entries, userID)
+}
+
+// pruneLocked drops expired entries; the caller must hold the mutex
+func (a *IPAllowlists) pruneLocked() {
+	for userID, entry := range a.entries {
+		if time.Since(entry.loadedAt) >= allowlistCacheTTL {
+			delete(a.entries, userID)
+		}
+	}
+}
@@ -16,6 +16,29 @@ type MiddlewareManager struct {
 	jwtManager  *utils.JWTManager
 	rateLimiter *RateLimiter
 	repoManager *repository.RepositoryManager
+
+	loginLimiter *LoginLimiter
+	dpop         *utils.DPoPVerifier
+	profiles     *ProfileRequirements
+	apiKeys      *APIKeyAuthenticator
+	allowlists   *IPAllowlists
+	cache        *ResponseCache
+
+	// directoryLimiter is nil when the directory rate limit is disabled
+	directoryLimiter *RateLimiter
+	exportLimiter    *RateLimiter
+
+	// ingestLimiter is nil when bulk log ingestion is disabled
+	ingestLimiter *RateLimiter
+
+	// concurrencyLimiter is nil when max_in_flight_requests is 0
+	concurrencyLimiter *ConcurrencyLimiter
+
+	// softLaunch is nil when soft launch is disabled
+	softLaunch *config.SoftLaunchConfig
+
+	// sandbox is nil when the sandbox tenant is disabled
+	sandbox *config.SandboxConfig
 }
 
 // NewMiddlewareManager creates a new middleware manager
@@ -27,11 +50,78 @@ func NewMiddlewareManager(
 	// Create rate limiter (100 requests per minute with burst of 20)
 	rateLimiter := NewRateLimiter(time.Minute/100, 20)
 
+	// Create login limiter for account lockout
+	loginLimiter := NewLoginLimiter(cfg.Login.MaxAttempts, cfg.Login.AttemptWindow, cfg.Login.LockoutDuration)
+
+	// Create DPoP proof verifier for key-bound tokens
+	dpop := utils.NewDPoPVerifier(cfg.DPoP.ProofMaxAge)
+
+	// Create required profile field checker
+	profiles := NewProfileRequirements(repoManager.Repos.User, repoManager.Repos.ProfileField)
+
+	// Create API key authenticator for machine clients
+	apiKeys := NewAPIKeyAuthenticator(repoManager.Repos.APIKey, repoManager.Repos.User)
+
+	// Create per-account network allowlist checker
+	allowlists := NewIPAllowlists(repoManager.Repos.User)
+
+	// Create response cache for metadata endpoints (clients may reuse for 5 minutes)
+	cache := NewResponseCache(5 * time.Minute)
+
+	// Create per-user rate limiter for the user directory
+	var directoryLimiter *RateLimiter
+	if cfg.Directory.RequestsPerMinute > 0 {
+		directoryLimiter = NewRateLimiter(time.Minute/time.Duration(cfg.Directory.RequestsPerMinute), cfg.Directory.Burst)
+	}
+
+	// Create per-user rate limiter for activity exports (5 per hour)
+	exportLimiter := NewRateLimiter(time.Hour, 5)
+
+	// Create per-caller rate limiter for bulk log ingestion
+	var ingestLimiter *RateLimiter
+	if cfg.LogIngest.Enabled {
+		ingestLimiter = NewRateLimiter(time.Minute/time.Duration(cfg.LogIngest.RequestsPerMinute), cfg.LogIngest.Burst)
+	}
+
+	// Create concurrency limiter for load shedding
+	var concurrencyLimiter *ConcurrencyLimiter
+	if cfg.Server.MaxInFlightRequests > 0 {
+		concurrencyLimiter = NewConcurrencyLimiter(
+			cfg.Server.MaxInFlightRequests,
+			cfg.Server.MaxQueueWait,
+			cfg.Server.ShedRetryAfter,
+		)
+	}
+
+	// Restrict sign-in to admitted users during soft launch
+	var softLaunch *config.SoftLaunchConfig
+	if cfg.SoftLaunch.Enabled {
+		softLaunch = &cfg.SoftLaunch
+	}
+
+	// Flag responses to sandbox users
+	var sandbox *config.SandboxConfig
+	if cfg.Sandbox.Enabled {
+		sandbox = &cfg.Sandbox
+	}
+
 	return &MiddlewareManager{
-		config:      cfg,
-		jwtManager:  jwtManager,
-		rateLimiter: rateLimiter,
-		repoManager: repoManager,
+		config:             cfg,
+		jwtManager:         jwtManager,
+		rateLimiter:        rateLimiter,
+		repoManager:        repoManager,
+		loginLimiter:       loginLimiter,
+		dpop:               dpop,
+		profiles:           profiles,
+		apiKeys:            apiKeys,
+		allowlists:         allowlists,
+		cache:              cache,
+		directoryLimiter:   directoryLimiter,
+		exportLimiter:      exportLimiter,
+		ingestLimiter:      ingestLimiter,
+		concurrencyLimiter: concurrencyLimiter,
+		softLaunch:         softLaunch,
+		sandbox:            sandbox,
 	}
 }
 
@@ -40,6 +130,17 @@ func (mm *MiddlewareManager) SetupGlobalMiddleware(router *gin.Engine) {
 	// Health check middleware (should be first)
 	router.Use(mm.HealthCheckMiddleware())
 
+	// Trace IDs, so every later response and request log carries one
+	router.Use(TraceIDMiddleware())
+
+	// Degradation status headers, including on shed requests
+	router.Use(DegradationHeadersMiddleware(mm.repoManager))
+
+	// Load shedding (after health checks so probes are never shed)
+	if mm.concurrencyLimiter != nil {
+		router.Use(LoadSheddingMiddleware(mm.concurrencyLimiter))
+	}
+
 	// Security headers
 	router.Use(SecurityHeadersMiddleware())
 
@@ -47,13 +148,17 @@ func (mm *MiddlewareManager) SetupGlobalMiddleware(router *gin.Engine) {
 	router.Use(CORSMiddleware(mm.config.CORS.AllowedOrigins))
 
 	// Rate limiting
-	router.Use(RateLimitMiddleware(mm.rateLimiter))
+	router.Use(RelaxDuringMaintenance(mm.repoManager.Maintenance(), RateLimitMiddleware(mm.rateLimiter)))
 
 	// Request size limit (10MB)
 	router.Use(RequestSizeLimitMiddleware(10 * 1024 * 1024))
 
-	// Request timeout (30 seconds)
-	router.Use(TimeoutMiddleware(30 * time.Second))
+	// Request timeout
+	requestTimeout := mm.config.Server.RequestTimeout
+	if requestTimeout <= 0 {
+		requestTimeout = 30 * time.Second
+	}
+	router.Use(TimeoutMiddleware(requestTimeout))
 
 	// Recovery with logging
 	router.Use(RecoveryMiddleware(mm.repoManager.Repos.Log))
@@ -64,12 +169,12 @@ func (mm *MiddlewareManager) SetupGlobalMiddleware(router *gin.Engine) {
 
 // AuthMiddleware returns the authentication middleware
 func (mm *MiddlewareManager) AuthMiddleware() gin.HandlerFunc {
-	return AuthMiddleware(mm.jwtManager)
+	return AuthMiddleware(mm.jwtManager, mm.repoManager.Repos.Session, mm.dpop, mm.profiles, mm.apiKeys, mm.allowlists, mm.softLaunch, mm.sandbox)
 }
 
 // OptionalAuthMiddleware returns the optional authentication middleware
 func (mm *MiddlewareManager) OptionalAuthMiddleware() gin.HandlerFunc {
-	return OptionalAuthMiddleware(mm.jwtManager)
+	return OptionalAuthMiddleware(mm.jwtManager, mm.repoManager.Repos.Session, mm.dpop)
 }
 
 // AdminRequiredMiddleware returns the admin required middleware
@@ -100,7 +205,78 @@ func (mm *MiddlewareManager) IPWhitelistMiddleware(whitelist []string) gin.Handl
 // StrictRateLimitMiddleware returns a stricter rate limiter for sensitive endpoints
 func (mm *MiddlewareManager) StrictRateLimitMiddleware() gin.HandlerFunc {
 	strictLimiter := NewRateLimiter(time.Minute/10, 5) // 10 requests per minute, burst of 5
-	return RateLimitMiddleware(strictLimiter)
+	return RelaxDuringMaintenance(mm.repoManager.Maintenance(), RateLimitMiddleware(strictLimiter))
+}
+
+// DirectoryRateLimitMiddleware returns the per-user rate limiter for the user directory
+func (mm *MiddlewareManager) DirectoryRateLimitMiddleware() gin.HandlerFunc {
+	if mm.directoryLimiter == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return UserRateLimitMiddleware(mm.directoryLimiter)
+}
+
+// ExportRateLimitMiddleware returns the per-user rate limiter for activity exports
+func (mm *MiddlewareManager) ExportRateLimitMiddleware() gin.HandlerFunc {
+	return UserRateLimitMiddleware(mm.exportLimiter)
+}
+
+// LogIngestRateLimitMiddleware returns the per-caller rate limiter for bulk log ingestion
+func (mm *MiddlewareManager) LogIngestRateLimitMiddleware() gin.HandlerFunc {
+	if mm.ingestLimiter == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return UserRateLimitMiddleware(mm.ingestLimiter)
+}
+
+// LoginLimiter returns the limiter that locks out accounts after failed logins
+func (mm *MiddlewareManager) LoginLimiter() *LoginLimiter {
+	return mm.loginLimiter
+}
+
+// DPoPVerifier returns the verifier for DPoP proofs
+func (mm *MiddlewareManager) DPoPVerifier() *utils.DPoPVerifier {
+	return mm.dpop
+}
+
+// RateLimiters returns the request rate limiters by name, for introspection
+func (mm *MiddlewareManager) RateLimiters() map[string]*RateLimiter {
+	limiters := map[string]*RateLimiter{
+		"global":          mm.rateLimiter,
+		"activity_export": mm.exportLimiter,
+	}
+	if mm.directoryLimiter != nil {
+		limiters["directory"] = mm.directoryLimiter
+	}
+	if mm.ingestLimiter != nil {
+		limiters["log_ingest"] = mm.ingestLimiter
+	}
+	return limiters
+}
+
+// ProfileRequirements returns the checker for required profile fields
+func (mm *MiddlewareManager) ProfileRequirements() *ProfileRequirements {
+	return mm.profiles
+}
+
+// ResponseCache returns the cache for metadata endpoints
+func (mm *MiddlewareManager) ResponseCache() *ResponseCache {
+	return mm.cache
+}
+
+// SoftLaunch returns the soft launch rules, or nil when soft launch is disabled
+func (mm *MiddlewareManager) SoftLaunch() *config.SoftLaunchConfig {
+	return mm.softLaunch
+}
+
+// Sandbox returns the sandbox tenant, or nil when the sandbox is disabled
+func (mm *MiddlewareManager) Sandbox() *config.SandboxConfig {
+	return mm.sandbox
+}
+
+// IPAllowlists returns the checker for per-account network allowlists
+func (mm *MiddlewareManager) IPAllowlists() *IPAllowlists {
+	return mm.allowlists
 }
 
 // LoggingOnlyMiddleware returns a middleware that only logs without other security measures
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"user_mgmt_go/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// requiredFieldsCacheTTL bounds how long a change to the required profile
+// fields takes to reach every request
+const requiredFieldsCacheTTL = 30 * time.Second
+
+// ProfileRequirements reports which required profile fields users are missing
+type ProfileRequirements struct {
+	users  repository.UserRepository
+	fields repository.ProfileFieldRepository
+
+	mutex    sync.Mutex
+	required []string
+	loadedAt time.Time
+}
+
+// NewProfileRequirements creates a profile requirement checker
+func NewProfileRequirements(users repository.UserRepository, fields repository.ProfileFieldRepository) *ProfileRequirements {
+	return &ProfileRequirements{
+		users:  users,
+		fields: fields,
+	}
+}
+
+// Required returns the required profile field names, cached briefly
+func (p *ProfileRequirements) Required(ctx context.Context) ([]string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.required != nil && time.Since(p.loadedAt) < requiredFieldsCacheTTL {
+		return p.required, nil
+	}
+
+	fields, err := p.fields.ListRequired(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.required = make([]string, len(fields))
+	for i, field := range fields {
+		p.required[i] = field.Field
+	}
+	p.loadedAt = time.Now()
+	return p.required, nil
+}
+
+// Invalidate drops the cached required fields after they are changed
+func (p *ProfileRequirements) Invalidate() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.required = nil
+}
+
+// Missing returns the required profile fields the user has not filled in
+func (p *ProfileRequirements) Missing(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	required, err := p.Required(ctx)
+	if err != nil || len(required) == 0 {
+		return []string{}, err
+	}
+
+	user, err := p.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return user.Profile.Missing(required), nil
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxResponseCacheEntries bounds the cache; responses past it are served
+// without being kept
+const maxResponseCacheEntries = 64
+
+// ResponseCache keeps the responses of endpoints whose output only changes
+// with the build or configuration, such as version and route metadata, so
+// frequently polled endpoints are served from memory. Responses are keyed by
+// route, so cached endpoints must not vary with the query string.
+type ResponseCache struct {
+	maxAge time.Duration
+
+	mutex   sync.RWMutex
+	entries map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	contentType string
+	body        []byte
+	etag        string
+}
+
+// NewResponseCache creates a response cache; clients may reuse responses
+// for maxAge
+func NewResponseCache(maxAge time.Duration) *ResponseCache {
+	return &ResponseCache{
+		maxAge:  maxAge,
+		entries: make(map[string]cachedResponse),
+	}
+}
+
+// Public caches responses that are the same for every client
+func (rc *ResponseCache) Public() gin.HandlerFunc {
+	return rc.middleware("public")
+}
+
+// Private caches responses of authenticated endpoints that are the same for
+// every user; shared caches must not store them. It must run after
+// AuthMiddleware.
+func (rc *ResponseCache) Private() gin.HandlerFunc {
+	return rc.middleware("private")
+}
+
+// Invalidate drops every cached response, e.g. after configuration changes
+func (rc *ResponseCache) Invalidate() {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	rc.entries = make(map[string]cachedResponse)
+}
+
+func (rc *ResponseCache) middleware(scope string) gin.HandlerFunc {
+	cacheControl := fmt.Sprintf("%s, max-age=%d", scope, int(rc.maxAge.Seconds()))
+
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		// Keyed by route pattern, not the request URI, so random query
+		// strings cannot grow the cache
+		key := c.FullPath()
+		if key == "" {
+			key = c.Request.URL.Path
+		}
+
+		rc.mutex.RLock()
+		entry, ok := rc.entries[key]
+		rc.mutex.RUnlock()
+
+		if !ok {
+			// Buffer the response the first time; only successes are kept
+			original := c.Writer
+			writer := &bufferingWriter{ResponseWriter: original}
+			c.Writer = writer
+			c.Next()
+			c.Writer = original
+
+			if writer.Status() != http.StatusOK {
+				original.WriteHeader(writer.Status())
+				original.Write(writer.body.Bytes())
+				return
+			}
+			sum := sha256.Sum256(writer.body.Bytes())
+			entry = cachedResponse{
+				contentType: original.Header().Get("Content-Type"),
+				body:        writer.body.Bytes(),
+				etag:        `"` + hex.EncodeToString(sum[:8]) + `"`,
+			}
+			rc.mutex.Lock()
+			if len(rc.entries) < maxResponseCacheEntries {
+				rc.entries[key] = entry
+			}
+			rc.mutex.Unlock()
+		}
+
+		c.Header("Cache-Control", cacheControl)
+		c.Header("ETag", entry.etag)
+		if c.GetHeader("If-None-Match") == entry.etag {
+			c.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+		c.Data(http.StatusOK, entry.contentType, entry.body)
+		c.Abort()
+	})
+}
+
+// bufferingWriter holds the response body back so it can be cached before
+// it is sent
+type bufferingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferingWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferingWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"time"
+
+	"user_mgmt_go/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sandbox headers set on responses to sandbox users, so integrators can
+// tell test traffic apart and know when its data goes away
+const (
+	SandboxHeader        = "X-Sandbox"          // "true" for sandbox users only
+	SandboxPurgeAtHeader = "X-Sandbox-Purge-At" // RFC 3339 time of the next nightly purge
+)
+
+// SetSandboxHeaders flags the response when email belongs to the sandbox;
+// sandbox is nil when the sandbox is disabled
+func SetSandboxHeaders(c *gin.Context, sandbox *config.SandboxConfig, email string) {
+	if sandbox == nil || !sandbox.Contains(email) {
+		return
+	}
+	nextPurge := sandbox.LastPurge(time.Now()).AddDate(0, 0, 1)
+	c.Header(SandboxHeader, "true")
+	c.Header(SandboxPurgeAtHeader, nextPurge.Format(time.RFC3339))
+}
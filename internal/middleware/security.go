@@ -5,15 +5,19 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"user_mgmt_go/internal/buildinfo"
+	"user_mgmt_go/internal/maintenance"
 	"user_mgmt_go/internal/models"
 	"user_mgmt_go/internal/repository"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // RateLimiter implements a simple in-memory rate limiter
@@ -22,20 +26,51 @@ type RateLimiter struct {
 	mutex    sync.RWMutex
 	rate     time.Duration
 	burst    int
+
+	// Totals since the limiter started, for introspection
+	startedAt time.Time
+	allowed   int64
+	rejected  int64
 }
 
 // Visitor holds rate limiting information for each visitor
 type Visitor struct {
-	limiter   chan struct{}
-	lastSeen  time.Time
+	tokens   []time.Time // When each token in use was taken; freed after rate
+	lastSeen time.Time
+	allowed  int64
+	rejected int64
+}
+
+// RateLimiterStats is a snapshot of a rate limiter's visitors and decisions
+type RateLimiterStats struct {
+	RequestsPerMinute float64            `json:"requests_per_minute"`
+	Burst             int                `json:"burst"`
+	Visitors          int                `json:"visitors"`           // Visitors seen in the last hour
+	ThrottledVisitors int                `json:"throttled_visitors"` // Visitors with no free tokens right now
+	Allowed           int64              `json:"allowed"`
+	Rejected          int64              `json:"rejected"`
+	RejectionRate     float64            `json:"rejection_rate"` // Rejected share of all requests, 0 to 1
+	Since             time.Time          `json:"since"`
+	TopThrottled      []ThrottledVisitor `json:"top_throttled"`
+}
+
+// ThrottledVisitor describes a visitor that has had requests rejected
+type ThrottledVisitor struct {
+	Key           string    `json:"key"` // IP address, or "user:"/"ip:" key for per-user limiters
+	Allowed       int64     `json:"allowed"`
+	Rejected      int64     `json:"rejected"`
+	RejectionRate float64   `json:"rejection_rate"`
+	Throttled     bool      `json:"throttled"` // No free tokens right now
+	LastSeen      time.Time `json:"last_seen"`
 }
 
 // NewRateLimiter creates a new rate limiter
 func NewRateLimiter(rate time.Duration, burst int) *RateLimiter {
 	rl := &RateLimiter{
-		visitors: make(map[string]*Visitor),
-		rate:     rate,
-		burst:    burst,
+		visitors:  make(map[string]*Visitor),
+		rate:      rate,
+		burst:     burst,
+		startedAt: time.Now(),
 	}
 
 	// Start cleanup goroutine
@@ -46,31 +81,105 @@ func NewRateLimiter(rate time.Duration, burst int) *RateLimiter {
 
 // Allow checks if a request should be allowed
 func (rl *RateLimiter) Allow(ip string) bool {
+	allowed, _ := rl.Reserve(ip)
+	return allowed
+}
+
+// Reserve takes a token if one is free. When none is, it returns how long
+// until the next token is freed.
+func (rl *RateLimiter) Reserve(ip string) (bool, time.Duration) {
 	rl.mutex.Lock()
 	defer rl.mutex.Unlock()
 
+	now := time.Now()
 	visitor, exists := rl.visitors[ip]
 	if !exists {
-		visitor = &Visitor{
-			limiter:  make(chan struct{}, rl.burst),
-			lastSeen: time.Now(),
-		}
+		visitor = &Visitor{}
 		rl.visitors[ip] = visitor
 	}
 
-	visitor.lastSeen = time.Now()
+	visitor.lastSeen = now
 
-	select {
-	case visitor.limiter <- struct{}{}:
-		// Release token after rate duration
-		go func() {
-			time.Sleep(rl.rate)
-			<-visitor.limiter
-		}()
-		return true
-	default:
-		return false
+	// Free tokens taken more than rate ago; tokens are in the order taken
+	freed := 0
+	for freed < len(visitor.tokens) && now.Sub(visitor.tokens[freed]) >= rl.rate {
+		freed++
+	}
+	visitor.tokens = visitor.tokens[freed:]
+
+	if len(visitor.tokens) >= rl.burst {
+		visitor.rejected++
+		rl.rejected++
+		return false, visitor.tokens[0].Add(rl.rate).Sub(now)
 	}
+	visitor.tokens = append(visitor.tokens, now)
+	visitor.allowed++
+	rl.allowed++
+	return true, 0
+}
+
+// Stats returns a snapshot of the limiter with up to top visitors, ordered
+// by rejected requests, that have been throttled
+func (rl *RateLimiter) Stats(top int) RateLimiterStats {
+	rl.mutex.RLock()
+	defer rl.mutex.RUnlock()
+
+	now := time.Now()
+	stats := RateLimiterStats{
+		RequestsPerMinute: float64(time.Minute) / float64(rl.rate),
+		Burst:             rl.burst,
+		Visitors:          len(rl.visitors),
+		Allowed:           rl.allowed,
+		Rejected:          rl.rejected,
+		RejectionRate:     rejectionRate(rl.allowed, rl.rejected),
+		Since:             rl.startedAt,
+		TopThrottled:      []ThrottledVisitor{},
+	}
+
+	for key, visitor := range rl.visitors {
+		// Count tokens still in use without freeing them
+		inUse := 0
+		for _, taken := range visitor.tokens {
+			if now.Sub(taken) < rl.rate {
+				inUse++
+			}
+		}
+		throttled := inUse >= rl.burst
+		if throttled {
+			stats.ThrottledVisitors++
+		}
+
+		if visitor.rejected > 0 {
+			stats.TopThrottled = append(stats.TopThrottled, ThrottledVisitor{
+				Key:           key,
+				Allowed:       visitor.allowed,
+				Rejected:      visitor.rejected,
+				RejectionRate: rejectionRate(visitor.allowed, visitor.rejected),
+				Throttled:     throttled,
+				LastSeen:      visitor.lastSeen,
+			})
+		}
+	}
+
+	sort.Slice(stats.TopThrottled, func(i, j int) bool {
+		if stats.TopThrottled[i].Rejected != stats.TopThrottled[j].Rejected {
+			return stats.TopThrottled[i].Rejected > stats.TopThrottled[j].Rejected
+		}
+		return stats.TopThrottled[i].Key < stats.TopThrottled[j].Key
+	})
+	if len(stats.TopThrottled) > top {
+		stats.TopThrottled = stats.TopThrottled[:top]
+	}
+
+	return stats
+}
+
+// rejectionRate returns the rejected share of all requests
+func rejectionRate(allowed, rejected int64) float64 {
+	if allowed+rejected == 0 {
+		return 0
+	}
+	return float64(rejected) / float64(allowed+rejected)
 }
 
 // cleanupVisitors removes old visitors to prevent memory leaks
@@ -91,20 +200,319 @@ func (rl *RateLimiter) cleanupVisitors() {
 func RateLimitMiddleware(rateLimiter *RateLimiter) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		ip := c.ClientIP()
-		
-		if !rateLimiter.Allow(ip) {
+
+		allowed, retryAfter := rateLimiter.Reserve(ip)
+		if !allowed {
+			seconds := retryAfterSeconds(retryAfter)
+			c.Header("Retry-After", fmt.Sprintf("%d", seconds))
 			c.JSON(http.StatusTooManyRequests, models.NewErrorResponse(
 				http.StatusTooManyRequests,
 				"Rate Limit Exceeded",
 				"Too many requests from this IP address",
 				map[string]interface{}{
-					"retry_after": "60 seconds",
-					"ip":          ip,
+					"retry_after_seconds": seconds,
+					"ip":                  ip,
+				},
+			))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	})
+}
+
+// RelaxDuringMaintenance skips an IP rate limit for requests from internal
+// networks while a maintenance window is in progress, so operators are not
+// throttled while working on the system
+func RelaxDuringMaintenance(schedule *maintenance.Schedule, limit gin.HandlerFunc) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if schedule.RelaxRateLimits(c.ClientIP(), time.Now()) {
+			c.Next()
+			return
+		}
+		limit(c)
+	})
+}
+
+// UserRateLimitMiddleware rate limits per authenticated user, so users behind
+// a shared IP do not exhaust each other's allowance. It must run after
+// AuthMiddleware; unauthenticated requests are limited by IP.
+func UserRateLimitMiddleware(rateLimiter *RateLimiter) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		key := "ip:" + c.ClientIP()
+		if userClaims, exists := GetUserFromContext(c); exists {
+			key = "user:" + userClaims.UserID.String()
+		}
+
+		allowed, retryAfter := rateLimiter.Reserve(key)
+		if !allowed {
+			seconds := retryAfterSeconds(retryAfter)
+			c.Header("Retry-After", fmt.Sprintf("%d", seconds))
+			c.JSON(http.StatusTooManyRequests, models.NewErrorResponse(
+				http.StatusTooManyRequests,
+				"Rate Limit Exceeded",
+				"Too many requests, please slow down",
+				map[string]interface{}{
+					"retry_after_seconds": seconds,
+				},
+			))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	})
+}
+
+// retryAfterSeconds rounds a wait up to whole seconds, as Retry-After
+// requires, so clients never retry early
+func retryAfterSeconds(wait time.Duration) int {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// LoginLimiter locks out an account after repeated failed logins. Failures
+// are counted per account within a sliding window, in memory per instance.
+type LoginLimiter struct {
+	accounts        map[string]*loginAttempts
+	mutex           sync.Mutex
+	maxAttempts     int
+	window          time.Duration
+	lockoutDuration time.Duration
+}
+
+// loginAttempts holds the recent failures of one account
+type loginAttempts struct {
+	failures    []time.Time
+	lockedUntil time.Time
+}
+
+// LoginThrottleStatus describes an account's login throttling state, with the
+// fields clients need to render accurate countdowns
+type LoginThrottleStatus struct {
+	Locked            bool
+	AttemptsRemaining int
+	LockExpiresAt     time.Time
+}
+
+// RetryAfter returns how long until the lock expires
+func (s LoginThrottleStatus) RetryAfter() time.Duration {
+	return time.Until(s.LockExpiresAt)
+}
+
+// Details returns the status as error response details
+func (s LoginThrottleStatus) Details() map[string]interface{} {
+	details := map[string]interface{}{
+		"attempts_remaining": s.AttemptsRemaining,
+	}
+	if s.Locked {
+		details["retry_after_seconds"] = retryAfterSeconds(s.RetryAfter())
+		details["lock_expires_at"] = s.LockExpiresAt
+	}
+	return details
+}
+
+// NewLoginLimiter creates a login limiter allowing maxAttempts failures per
+// window before locking the account for lockoutDuration. A maxAttempts of 0
+// disables lockout.
+func NewLoginLimiter(maxAttempts int, window, lockoutDuration time.Duration) *LoginLimiter {
+	ll := &LoginLimiter{
+		accounts:        make(map[string]*loginAttempts),
+		maxAttempts:     maxAttempts,
+		window:          window,
+		lockoutDuration: lockoutDuration,
+	}
+
+	// Start cleanup goroutine
+	if maxAttempts > 0 {
+		go ll.cleanupAccounts()
+	}
+
+	return ll
+}
+
+// Enabled reports whether failed logins lead to lockout
+func (ll *LoginLimiter) Enabled() bool {
+	return ll != nil && ll.maxAttempts > 0
+}
+
+// Status returns the current throttling state of an account
+func (ll *LoginLimiter) Status(account string) LoginThrottleStatus {
+	if !ll.Enabled() {
+		return LoginThrottleStatus{}
+	}
+
+	ll.mutex.Lock()
+	defer ll.mutex.Unlock()
+
+	return ll.status(loginAccountKey(account), time.Now())
+}
+
+// RecordFailure counts a failed login and returns the resulting state
+func (ll *LoginLimiter) RecordFailure(account string) LoginThrottleStatus {
+	if !ll.Enabled() {
+		return LoginThrottleStatus{}
+	}
+
+	ll.mutex.Lock()
+	defer ll.mutex.Unlock()
+
+	key := loginAccountKey(account)
+	now := time.Now()
+	if status := ll.status(key, now); status.Locked {
+		return status
+	}
+
+	attempts := ll.accounts[key]
+	if attempts == nil {
+		attempts = &loginAttempts{}
+		ll.accounts[key] = attempts
+	}
+	attempts.failures = append(attempts.failures, now)
+	if len(attempts.failures) >= ll.maxAttempts {
+		attempts.failures = nil
+		attempts.lockedUntil = now.Add(ll.lockoutDuration)
+	}
+
+	return ll.status(key, now)
+}
+
+// Reset clears the failures of an account after a successful login
+func (ll *LoginLimiter) Reset(account string) {
+	if !ll.Enabled() {
+		return
+	}
+
+	ll.mutex.Lock()
+	defer ll.mutex.Unlock()
+
+	delete(ll.accounts, loginAccountKey(account))
+}
+
+// status prunes failures outside the window and reports the state; the
+// caller must hold the mutex
+func (ll *LoginLimiter) status(key string, now time.Time) LoginThrottleStatus {
+	attempts := ll.accounts[key]
+	if attempts == nil {
+		return LoginThrottleStatus{AttemptsRemaining: ll.maxAttempts}
+	}
+
+	if now.Before(attempts.lockedUntil) {
+		return LoginThrottleStatus{Locked: true, LockExpiresAt: attempts.lockedUntil}
+	}
+
+	recent := attempts.failures[:0]
+	for _, failure := range attempts.failures {
+		if now.Sub(failure) < ll.window {
+			recent = append(recent, failure)
+		}
+	}
+	attempts.failures = recent
+
+	return LoginThrottleStatus{AttemptsRemaining: ll.maxAttempts - len(attempts.failures)}
+}
+
+// cleanupAccounts removes accounts with no recent failures and no active lock
+func (ll *LoginLimiter) cleanupAccounts() {
+	for {
+		time.Sleep(time.Minute)
+		ll.mutex.Lock()
+		now := time.Now()
+		for key := range ll.accounts {
+			if status := ll.status(key, now); !status.Locked && status.AttemptsRemaining == ll.maxAttempts {
+				delete(ll.accounts, key)
+			}
+		}
+		ll.mutex.Unlock()
+	}
+}
+
+// loginAccountKey normalizes an account identifier so "User@Example.com" and
+// "user@example.com" share one counter
+func loginAccountKey(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
+
+// ConcurrencyLimiter caps the number of requests handled at once
+type ConcurrencyLimiter struct {
+	slots      chan struct{}
+	maxWait    time.Duration
+	retryAfter time.Duration
+}
+
+// NewConcurrencyLimiter creates a limiter allowing maxInFlight concurrent
+// requests, each waiting at most maxWait for a free slot
+func NewConcurrencyLimiter(maxInFlight int, maxWait, retryAfter time.Duration) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		slots:      make(chan struct{}, maxInFlight),
+		maxWait:    maxWait,
+		retryAfter: retryAfter,
+	}
+}
+
+// Acquire takes a slot, waiting up to maxWait; it reports whether a slot was obtained
+func (cl *ConcurrencyLimiter) Acquire(ctx context.Context) bool {
+	select {
+	case cl.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if cl.maxWait <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(cl.maxWait)
+	defer timer.Stop()
+
+	select {
+	case cl.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (cl *ConcurrencyLimiter) Release() {
+	<-cl.slots
+}
+
+// InFlight returns the number of requests currently holding a slot
+func (cl *ConcurrencyLimiter) InFlight() int {
+	return len(cl.slots)
+}
+
+// LoadSheddingMiddleware rejects requests with 503 and Retry-After when the
+// instance is already handling its maximum number of concurrent requests,
+// instead of queueing them until they time out
+func LoadSheddingMiddleware(limiter *ConcurrencyLimiter) gin.HandlerFunc {
+	retryAfterSeconds := int(limiter.retryAfter.Seconds())
+	if retryAfterSeconds < 1 {
+		retryAfterSeconds = 1
+	}
+
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if !limiter.Acquire(c.Request.Context()) {
+			c.Header("Retry-After", fmt.Sprintf("%d", retryAfterSeconds))
+			c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse(
+				http.StatusServiceUnavailable,
+				"Service Overloaded",
+				"The server is handling too many requests, please retry later",
+				map[string]interface{}{
+					"retry_after_seconds": retryAfterSeconds,
 				},
 			))
 			c.Abort()
 			return
 		}
+		defer limiter.Release()
 
 		c.Next()
 	})
@@ -126,24 +534,32 @@ func RequestLoggingMiddleware(logRepo repository.UserLogRepository) gin.HandlerF
 		duration := time.Since(start)
 		statusCode := c.Writer.Status()
 
-		// Get user ID from context if authenticated (for potential future use)
+		details := map[string]interface{}{
+			"method":      method,
+			"path":        path,
+			"status_code": statusCode,
+			"duration_ms": duration.Milliseconds(),
+			"user_agent":  userAgent,
+			"ip_address":  clientIP,
+		}
+		if traceID := GetTraceID(c); traceID != "" {
+			details["trace_id"] = traceID
+		}
+
+		// Record the caller in the details, so the request does not show up
+		// in the user's own activity log
 		if userIDRaw, exists := c.Get("user_id"); exists {
-			_ = userIDRaw // Placeholder for future logging enhancement
+			if userID, ok := userIDRaw.(uuid.UUID); ok {
+				details["user_id"] = userID.String()
+			}
 		}
 
 		// Create log entry asynchronously
 		logEntry := &models.UserLog{
 			Event:     models.SystemError, // Using as general system event
 			Data: models.LogData{
-				Action: "HTTP_REQUEST",
-				Details: map[string]interface{}{
-					"method":      method,
-					"path":        path,
-					"status_code": statusCode,
-					"duration_ms": duration.Milliseconds(),
-					"user_agent":  userAgent,
-					"ip_address":  clientIP,
-				},
+				Action:     "HTTP_REQUEST",
+				Details:    details,
 				StatusCode: statusCode,
 				Duration:   duration.Milliseconds(),
 			},
@@ -214,8 +630,8 @@ func CORSMiddleware(allowedOrigins []string) gin.HandlerFunc {
 	}
 	
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Requested-With"}
-	config.ExposeHeaders = []string{"Content-Length"}
+	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Requested-With", "traceparent", TraceIDHeader}
+	config.ExposeHeaders = []string{"Content-Length", TraceIDHeader, ServiceStatusHeader, DegradedSubsystemsHeader, SandboxHeader, SandboxPurgeAtHeader}
 	config.AllowCredentials = true
 	config.MaxAge = 12 * time.Hour
 
@@ -244,12 +660,14 @@ func RequestSizeLimitMiddleware(maxSize int64) gin.HandlerFunc {
 	})
 }
 
-// TimeoutMiddleware adds request timeout
+// TimeoutMiddleware adds request timeout. The request context is marked so
+// each database call made for it gets its own budget within the timeout.
 func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		// Create a context with timeout
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 		defer cancel()
+		ctx = repository.WithCallBudgets(ctx)
 		
 		// Set the new context with timeout
 		c.Request = c.Request.WithContext(ctx)
@@ -320,32 +738,91 @@ func RecoveryMiddleware(logRepo repository.UserLogRepository) gin.HandlerFunc {
 	})
 }
 
-// HealthCheckMiddleware provides a simple health check endpoint
+// Degradation headers set on every response, so clients and load balancers
+// can adapt, e.g. by hiding activity feeds or sending less traffic
+const (
+	ServiceStatusHeader      = "X-Service-Status"      // "ok" or "degraded"
+	DegradedSubsystemsHeader = "X-Degraded-Subsystems" // Comma-separated, only while degraded
+)
+
+// DegradationHeadersMiddleware sets the degradation headers on every response
+func DegradationHeadersMiddleware(repoManager *repository.RepositoryManager) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		setDegradationHeaders(c, repoManager.Degradation())
+		c.Next()
+	})
+}
+
+// setDegradationHeaders sets the degradation headers from a status
+func setDegradationHeaders(c *gin.Context, status models.DegradationStatus) {
+	if !status.Degraded {
+		c.Header(ServiceStatusHeader, "ok")
+		return
+	}
+	c.Header(ServiceStatusHeader, "degraded")
+	c.Header(DegradedSubsystemsHeader, strings.Join(status.Subsystems, ","))
+}
+
+// HealthCheckMiddleware provides the health check endpoints: /health with
+// the state of each service, and /health/ready for load balancers, which is
+// ready while PostgreSQL is up, including when degraded
 func HealthCheckMiddleware(repoManager *repository.RepositoryManager) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
+		if c.Request.URL.Path == "/health/ready" && c.Request.Method == "GET" {
+			degradation := repoManager.Degradation()
+			setDegradationHeaders(c, degradation)
+
+			response := models.ReadinessResponse{
+				Status:      "ready",
+				Timestamp:   time.Now(),
+				Degradation: degradation,
+			}
+			httpStatus := http.StatusOK
+			if !repoManager.Database.PostgreSQLHealthy() {
+				response.Status = "not_ready"
+				httpStatus = http.StatusServiceUnavailable
+			} else if degradation.Degraded {
+				response.Status = "degraded"
+			}
+
+			c.JSON(httpStatus, response)
+			c.Abort()
+			return
+		}
+
 		if c.Request.URL.Path == "/health" && c.Request.Method == "GET" {
 			// Check database health
 			health := repoManager.HealthCheck()
+			pipeline := repoManager.LogPipelineStatus()
 			
 			status := "healthy"
 			httpStatus := http.StatusOK
 			
-			// If any database is unhealthy, mark as unhealthy
-			for _, isHealthy := range health {
-				if !isHealthy {
-					status = "unhealthy"
-					httpStatus = http.StatusServiceUnavailable
-					break
-				}
+			// PostgreSQL is required to serve requests; MongoDB only backs the
+			// audit log, which spools to disk while it is unavailable
+			if !health["postgresql"] {
+				status = "unhealthy"
+				httpStatus = http.StatusServiceUnavailable
+			} else if !health["mongodb"] || pipeline.Degraded {
+				status = "degraded"
+			}
+
+			logPipeline := "ok"
+			if pipeline.Degraded {
+				logPipeline = "degraded"
 			}
 
 			response := models.HealthCheckResponse{
 				Status:    status,
 				Timestamp: time.Now(),
-				Version:   "1.0.0",
+				Version:   buildinfo.Version,
+				Build:     buildinfo.Get(),
 			}
 			response.Services.Database = health["postgresql"]
 			response.Services.MongoDB = health["mongodb"]
+			response.Services.LogPipeline = logPipeline
+			response.Degradation = repoManager.Degradation()
+			setDegradationHeaders(c, response.Degradation)
 
 			c.JSON(httpStatus, response)
 			c.Abort()
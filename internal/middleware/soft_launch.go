@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// softLaunchAdmits reports whether the authenticated user may proceed while
+// soft launch is enabled; softLaunch is nil when it is disabled
+func softLaunchAdmits(softLaunch *config.SoftLaunchConfig, claims *models.JWTClaims) bool {
+	return softLaunch == nil || softLaunch.Admits(claims.UserID.String(), claims.Email, claims.Role)
+}
+
+// RespondNotYetEnabled rejects a user that soft launch does not admit yet
+func RespondNotYetEnabled(c *gin.Context, softLaunch *config.SoftLaunchConfig) {
+	c.JSON(http.StatusForbidden, models.NewErrorResponse(
+		http.StatusForbidden,
+		"Not Yet Enabled",
+		softLaunch.Message,
+		map[string]string{"reason": "soft_launch"},
+	))
+	c.Abort()
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TraceIDHeader carries the trace ID of a request, both from callers that
+// do not send traceparent and back to every client in the response
+const TraceIDHeader = "X-Trace-ID"
+
+// traceIDKey is the gin context key holding the trace ID of the request
+const traceIDKey = "trace_id"
+
+var (
+	// traceparentPattern matches a W3C traceparent header and captures its trace ID
+	traceparentPattern = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-[0-9a-f]{16}-[0-9a-f]{2}$`)
+	// traceIDPattern bounds the IDs accepted from X-Trace-ID, as they end up in logs and links
+	traceIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+)
+
+// TraceIDMiddleware gives every request a trace ID: the one of the W3C
+// traceparent header set by a tracing proxy or SDK, else a valid X-Trace-ID
+// header, else a new random one in the W3C format. The ID is returned in the
+// X-Trace-ID response header and recorded in the request log.
+func TraceIDMiddleware() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		traceID := incomingTraceID(c)
+		if traceID == "" {
+			traceID = newTraceID()
+		}
+
+		c.Set(traceIDKey, traceID)
+		c.Header(TraceIDHeader, traceID)
+		c.Next()
+	})
+}
+
+// GetTraceID returns the trace ID of the request, or "" outside TraceIDMiddleware
+func GetTraceID(c *gin.Context) string {
+	return c.GetString(traceIDKey)
+}
+
+// incomingTraceID returns the trace ID sent by the caller, if any is valid
+func incomingTraceID(c *gin.Context) string {
+	if match := traceparentPattern.FindStringSubmatch(strings.TrimSpace(c.GetHeader("traceparent"))); match != nil {
+		// An all-zero trace ID is invalid per the W3C spec
+		if strings.Trim(match[1], "0") != "" {
+			return match[1]
+		}
+	}
+	if traceID := strings.TrimSpace(c.GetHeader(TraceIDHeader)); traceIDPattern.MatchString(traceID) {
+		return traceID
+	}
+	return ""
+}
+
+// newTraceID returns a random 16-byte trace ID as 32 hex characters
+func newTraceID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
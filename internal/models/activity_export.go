@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Activity export formats
+const (
+	ActivityExportCSV  = "csv"
+	ActivityExportJSON = "json"
+)
+
+// activityCSVHeader lists the columns of a CSV activity export
+var activityCSVHeader = []string{
+	"timestamp", "event", "action", "ip_address", "user_agent",
+	"status_code", "error", "details", "old_values", "new_values",
+}
+
+// WriteActivityCSV writes log entries as CSV, one row per entry. Details and
+// changed values are written as JSON objects.
+func WriteActivityCSV(w io.Writer, logs []UserLogResponse) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(activityCSVHeader); err != nil {
+		return err
+	}
+
+	for _, entry := range logs {
+		statusCode := ""
+		if entry.Data.StatusCode != 0 {
+			statusCode = strconv.Itoa(entry.Data.StatusCode)
+		}
+		record := []string{
+			entry.Timestamp.UTC().Format(time.RFC3339),
+			string(entry.Event),
+			entry.Data.Action,
+			entry.IPAddress,
+			entry.UserAgent,
+			statusCode,
+			entry.Data.Error,
+			csvJSON(entry.Data.Details),
+			csvJSON(entry.Data.OldValues),
+			csvJSON(entry.Data.NewValues),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvJSON encodes a map for a CSV cell, leaving empty maps blank
+func csvJSON(values map[string]interface{}) string {
+	if len(values) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey is a credential for machine clients. Keys are shared by the
+// organization's admins and act with the permissions of their owner; only
+// a hash of the key is stored.
+type APIKey struct {
+	ID                 uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name               string      `json:"name" gorm:"not null;size:100" example:"CI provisioning"`
+	Prefix             string      `json:"prefix" gorm:"not null;size:16" example:"umk_3q2-7wEr"` // Leading characters of the key
+	KeyHash            string      `json:"-" gorm:"not null;size:64"`
+	OwnerID            uuid.UUID   `json:"owner_id" gorm:"type:uuid;not null"`
+	ExpiresAt          *time.Time  `json:"expires_at,omitempty"`
+	LastUsedAt         *time.Time  `json:"last_used_at,omitempty"`
+	LastUsedIP         string      `json:"last_used_ip,omitempty" gorm:"size:45"`
+	RotationDueAt      *time.Time  `json:"rotation_due_at,omitempty"`
+	RotationRemindedAt *time.Time  `json:"rotation_reminded_at,omitempty"`
+	RotatedTo          *uuid.UUID  `json:"rotated_to,omitempty" gorm:"type:uuid"` // Replacement key; this key expires after the overlap
+	RevokedAt          *time.Time  `json:"revoked_at,omitempty"`
+	AllowedIPs         IPAllowlist `json:"allowed_ips" gorm:"type:jsonb;not null;default:'[]'"` // Networks the key may be used from
+	CreatedAt          time.Time   `json:"created_at"`
+}
+
+// TableName returns the table name for the APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsActive reports whether the key can still be used
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// APIKeyCreateRequest represents the request payload for creating an API key
+type APIKeyCreateRequest struct {
+	Name      string     `json:"name" binding:"required,max=100" example:"CI provisioning"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2024-12-31T00:00:00Z"`
+	// Networks the key may be used from; empty allows any address
+	AllowedIPs []string `json:"allowed_ips,omitempty" example:"10.0.0.0/8"`
+}
+
+// APIKeyRotateRequest represents the request payload for rotating an API
+// key. The old key keeps working for the overlap so clients can switch over.
+type APIKeyRotateRequest struct {
+	OverlapHours *int `json:"overlap_hours,omitempty" binding:"omitempty,min=0,max=720" example:"24"`
+}
+
+// APIKeySecretResponse returns a new key; the key itself is only shown once
+type APIKeySecretResponse struct {
+	APIKey
+	Key string `json:"key" example:"umk_3q2-7wErXk..."`
+}
@@ -0,0 +1,243 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit event severities, lowest first
+const (
+	AuditSeverityInfo     = "info"
+	AuditSeverityWarning  = "warning"
+	AuditSeverityCritical = "critical"
+)
+
+// auditSeverityRank orders the severities for MinSeverity comparisons
+var auditSeverityRank = map[string]int{
+	AuditSeverityInfo:     1,
+	AuditSeverityWarning:  2,
+	AuditSeverityCritical: 3,
+}
+
+// criticalAuditActions are the actions that cannot be undone or that change
+// who can do what
+var criticalAuditActions = map[string]bool{
+	"PERMANENT_DELETE_USER": true,
+	"BULK_DELETE_USERS":     true,
+	"CREATE_ADMIN_USER":     true,
+	"ROLE_CHANGE_APPLIED":   true,
+	"PANIC_RECOVERY":        true,
+}
+
+// AuditSeverity rates an audit entry: critical for irreversible changes and
+// changes to roles, keys and configuration, warning for failures, deletions
+// and revocations, info for everything else
+func AuditSeverity(entry UserLog) string {
+	switch {
+	case criticalAuditActions[entry.Data.Action],
+		entry.Event == RoleChangeEvent, entry.Event == APIKeyChange, entry.Event == ConfigChange:
+		return AuditSeverityCritical
+	case entry.Event == LoginFailed, entry.Event == UserDeleted, entry.Event == SessionRevoked,
+		entry.Event == UploadRejected, entry.Event == SystemError, entry.Event == ValidationLogError:
+		return AuditSeverityWarning
+	default:
+		return AuditSeverityInfo
+	}
+}
+
+// IsValidAuditSeverity reports whether severity is a known severity
+func IsValidAuditSeverity(severity string) bool {
+	return auditSeverityRank[severity] > 0
+}
+
+// MaxAuditSubscriptionEvents caps the event types and actions of one subscription
+const MaxAuditSubscriptionEvents = 50
+
+// auditEventName matches the names of event types and actions
+var auditEventName = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,99}$`)
+
+// auditActions are the actions this service logs, which a subscription may
+// name besides the event types. Events submitted through log ingestion carry
+// their own actions and are subscribed to by event type.
+var auditActions = map[string]bool{
+	"ADMIN_PANEL_LOGIN":               true,
+	"ADMIN_PANEL_LOGOUT":              true,
+	"API_KEY_ALLOWLIST_UPDATED":       true,
+	"API_KEY_CREATED":                 true,
+	"API_KEY_REVOKED":                 true,
+	"API_KEY_ROTATED":                 true,
+	"API_KEY_ROTATION_DUE":            true,
+	"BULK_CREATE_USERS":               true,
+	"BULK_DELETE_USERS":               true,
+	"CONFIG_EXPORTED":                 true,
+	"CONFIG_IMPORTED":                 true,
+	"CREATE_ADMIN_USER":               true,
+	"CREATE_TEST_USER":                true,
+	"CREATE_USER":                     true,
+	"DELETE_AVATAR":                   true,
+	"DELETE_USER":                     true,
+	"IDENTITY_LINKED":                 true,
+	"IDENTITY_UNLINKED":               true,
+	"IP_ALLOWLIST_UPDATED":            true,
+	"LOGIN_FAILED":                    true,
+	"LOGIN_SUCCESS":                   true,
+	"LOG_VOLUME_ANOMALY":              true,
+	"OIDC_TOKEN_ISSUED":               true,
+	"PANIC_RECOVERY":                  true,
+	"PASSWORD_CHANGE":                 true,
+	"PERMANENT_DELETE_USER":           true,
+	"PURGE_SANDBOX_USERS":             true,
+	"REJECT_INFECTED_UPLOAD":          true,
+	"REQUIRED_PROFILE_FIELDS_UPDATED": true,
+	"RESTORE_USER":                    true,
+	"REVOKE_SESSION":                  true,
+	"ROLE_CHANGE_APPLIED":             true,
+	"ROLE_CHANGE_APPROVED":            true,
+	"ROLE_CHANGE_REJECTED":            true,
+	"ROLE_CHANGE_REQUESTED":           true,
+	"SYSTEM_MAINTENANCE":              true,
+	"TEMPLATE_RENDER_FAILED":          true,
+	"TOKEN_REFRESH":                   true,
+	"UPDATE_AVATAR":                   true,
+	"UPDATE_USER":                     true,
+	"USER_LOGOUT":                     true,
+}
+
+// GetAuditActions returns the actions a subscription may name, sorted
+func GetAuditActions() []string {
+	actions := make([]string, 0, len(auditActions))
+	for action := range auditActions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// AuditSubscription is an admin's choice of audit events to be notified
+// about, by email, in the admin panel or both. An entry matches when its
+// event type or action is listed, or when it is at least MinSeverity.
+type AuditSubscription struct {
+	AdminID     uuid.UUID  `json:"admin_id" gorm:"type:uuid;primaryKey"`
+	Events      StringList `json:"events" gorm:"type:jsonb;not null;default:'[]'" example:"PERMANENT_DELETE_USER,LOGIN_FAILED"`
+	MinSeverity string     `json:"min_severity,omitempty" gorm:"size:20" example:"critical"` // Empty matches by Events only
+	Email       bool       `json:"email" gorm:"not null;default:false"`
+	Panel       bool       `json:"panel" gorm:"not null;default:true"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for the AuditSubscription model
+func (AuditSubscription) TableName() string {
+	return "audit_subscriptions"
+}
+
+// Matches reports whether the entry is one the admin subscribed to
+func (s AuditSubscription) Matches(entry UserLog, severity string) bool {
+	for _, event := range s.Events {
+		if event == string(entry.Event) || event == entry.Data.Action {
+			return true
+		}
+	}
+	return s.MinSeverity != "" && auditSeverityRank[severity] >= auditSeverityRank[s.MinSeverity]
+}
+
+// AuditSubscriptionRequest represents the request payload for saving the
+// current admin's subscription, replacing the previous one
+type AuditSubscriptionRequest struct {
+	Events      []string `json:"events" example:"PERMANENT_DELETE_USER,LOGIN_FAILED"` // Event types or actions
+	MinSeverity string   `json:"min_severity,omitempty" example:"critical"`
+	Email       bool     `json:"email"`
+	Panel       bool     `json:"panel" example:"true"`
+}
+
+// Validate checks the event names, severity and channels
+func (r AuditSubscriptionRequest) Validate() error {
+	if len(r.Events) == 0 && r.MinSeverity == "" {
+		return fmt.Errorf("subscribe to at least one event or a minimum severity")
+	}
+	if len(r.Events) > MaxAuditSubscriptionEvents {
+		return fmt.Errorf("at most %d events can be subscribed to", MaxAuditSubscriptionEvents)
+	}
+	for _, event := range r.Events {
+		if !IsValidEventType(LogEventType(event)) && !auditActions[event] {
+			return fmt.Errorf("%q is not a known event type or action", event)
+		}
+	}
+	if r.MinSeverity != "" && !IsValidAuditSeverity(r.MinSeverity) {
+		return fmt.Errorf("min_severity must be %s, %s or %s", AuditSeverityInfo, AuditSeverityWarning, AuditSeverityCritical)
+	}
+	if !r.Email && !r.Panel {
+		return fmt.Errorf("choose email, panel or both")
+	}
+	return nil
+}
+
+// AdminNotification is an audit entry delivered to an admin's panel inbox
+type AdminNotification struct {
+	ID        uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	AdminID   uuid.UUID    `json:"admin_id" gorm:"type:uuid;not null"`
+	LogID     string       `json:"log_id" gorm:"not null;size:24"` // Hex ObjectID of the audit entry
+	Event     LogEventType `json:"event" gorm:"not null;size:50"`
+	Action    string       `json:"action" gorm:"size:100"`
+	Severity  string       `json:"severity" gorm:"not null;size:20"`
+	UserID    *string      `json:"user_id,omitempty" gorm:"size:36"` // Actor or subject of the entry
+	LoggedAt  time.Time    `json:"logged_at" gorm:"not null"`
+	ReadAt    *time.Time   `json:"read_at,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// TableName returns the table name for the AdminNotification model
+func (AdminNotification) TableName() string {
+	return "admin_notifications"
+}
+
+// NewAdminNotification builds an admin's notification of an audit entry
+func NewAdminNotification(adminID uuid.UUID, entry UserLog, severity string) AdminNotification {
+	return AdminNotification{
+		AdminID:  adminID,
+		LogID:    entry.ID.Hex(),
+		Event:    entry.Event,
+		Action:   entry.Data.Action,
+		Severity: severity,
+		UserID:   entry.UserID,
+		LoggedAt: entry.Timestamp,
+	}
+}
+
+// StringList is a list of strings stored as a JSONB array
+type StringList []string
+
+// Value implements driver.Valuer
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *StringList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = StringList{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
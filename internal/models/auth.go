@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"user_mgmt_go/internal/buildinfo"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
@@ -15,10 +17,14 @@ type LoginRequest struct {
 
 // LoginResponse represents the response payload for successful login
 type LoginResponse struct {
-	Token        string       `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	RefreshToken string       `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	ExpiresAt    time.Time    `json:"expires_at" example:"2023-12-31T23:59:59Z"`
-	User         UserResponse `json:"user"`
+	Token                  string       `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType              string       `json:"token_type" example:"Bearer"` // "DPoP" when the tokens are bound to the client's key
+	RefreshToken           string       `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresAt              time.Time    `json:"expires_at" example:"2023-12-31T23:59:59Z"`
+	User                   UserResponse `json:"user"`
+	PasswordChangeRequired bool         `json:"password_change_required,omitempty" example:"false"`    // Other endpoints are blocked until the password is changed
+	MissingProfileFields   []string     `json:"missing_profile_fields,omitempty" example:"department"` // Other endpoints are blocked until these are filled in
+	Sandbox                bool         `json:"sandbox,omitempty" example:"false"`                     // The account belongs to the sandbox and is purged nightly
 }
 
 // RefreshTokenRequest represents the request payload for token refresh
@@ -34,13 +40,38 @@ type RefreshTokenResponse struct {
 
 // JWTClaims represents the JWT claims structure
 type JWTClaims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
-	Name   string    `json:"name"`
-	Role   string    `json:"role"` // "admin" or "user"
+	UserID                 uuid.UUID `json:"user_id"`
+	Email                  string    `json:"email"`
+	Name                   string    `json:"name"`
+	Role                   string    `json:"role"`                               // "admin" or "user"
+	PasswordChangeRequired bool      `json:"password_change_required,omitempty"` // Only password change is allowed until cleared
+	SessionID              string    `json:"sid,omitempty"`                      // Login session shared by the access and refresh token
+	// ProfileCompletionRequired is set when required profile fields were
+	// missing at login; only profile routes are allowed until they are filled in
+	ProfileCompletionRequired bool `json:"profile_completion_required,omitempty"`
+	// APIKeyID is set when the request authenticated with an API key rather
+	// than a token; it is never part of an issued token
+	APIKeyID *uuid.UUID `json:"-"`
+	// Confirmation binds the token to a client-held key; requests must then
+	// carry a DPoP proof signed by that key
+	Confirmation *TokenConfirmation `json:"cnf,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// TokenConfirmation holds the thumbprint of the key a token is bound to
+type TokenConfirmation struct {
+	JWKThumbprint string `json:"jkt"`
+}
+
+// BoundKey returns the thumbprint of the key the token is bound to, or an
+// empty string for bearer tokens
+func (c *JWTClaims) BoundKey() string {
+	if c.Confirmation == nil {
+		return ""
+	}
+	return c.Confirmation.JWKThumbprint
+}
+
 // TokenType represents different types of tokens
 type TokenType string
 
@@ -81,13 +112,40 @@ type PaginationResponse struct {
 
 // HealthCheckResponse represents the health check response
 type HealthCheckResponse struct {
-	Status    string    `json:"status" example:"healthy"`
-	Timestamp time.Time `json:"timestamp"`
-	Version   string    `json:"version" example:"1.0.0"`
+	Status    string         `json:"status" example:"healthy"` // healthy, degraded or unhealthy
+	Timestamp time.Time      `json:"timestamp"`
+	Version   string         `json:"version" example:"v1.2.3"`
+	Build     buildinfo.Info `json:"build"`
 	Services  struct {
-		Database bool `json:"database" example:"true"`
-		MongoDB  bool `json:"mongodb" example:"true"`
+		Database    bool   `json:"database" example:"true"`
+		MongoDB     bool   `json:"mongodb" example:"true"`
+		LogPipeline string `json:"log_pipeline" example:"ok"`
 	} `json:"services"`
+	Degradation DegradationStatus `json:"degradation"`
+}
+
+// Degraded subsystems reported in DegradationStatus. There is no cache or
+// read replica to report: the response cache lives in process memory, and
+// database.read_only is a second login on the same PostgreSQL server, so
+// its reads cannot lag behind writes.
+const (
+	SubsystemLogPipeline = "log_pipeline" // Audit log entries are spooled to disk until MongoDB takes them
+	SubsystemMongoDB     = "mongodb"      // Audit log reads, activity feeds and log exports fail
+)
+
+// DegradationStatus lists the subsystems running degraded. The service keeps
+// answering requests while degraded, but the features they back are limited.
+type DegradationStatus struct {
+	Degraded   bool       `json:"degraded"`
+	Subsystems []string   `json:"subsystems"`
+	Since      *time.Time `json:"since,omitempty"` // When the earliest current degradation was noticed
+}
+
+// ReadinessResponse represents the readiness check response for load balancers
+type ReadinessResponse struct {
+	Status      string            `json:"status" example:"ready"` // ready, degraded or not_ready
+	Timestamp   time.Time         `json:"timestamp"`
+	Degradation DegradationStatus `json:"degradation"`
 }
 
 // ValidationError represents validation error details
@@ -140,14 +198,15 @@ func (c *JWTClaims) IsAdmin() bool {
 	return c.Role == "admin"
 }
 
-// IsValid checks if the token claims are valid
-func (c *JWTClaims) IsValid() bool {
+// IsValid checks if the token claims are valid, tolerating leeway of clock
+// skew on the expiry
+func (c *JWTClaims) IsValid(leeway time.Duration) bool {
 	if c.UserID == uuid.Nil || c.Email == "" {
 		return false
 	}
 	
 	// Check if token is expired
-	if time.Now().Unix() > c.ExpiresAt.Unix() {
+	if c.ExpiresAt == nil || time.Now().After(c.ExpiresAt.Add(leeway)) {
 		return false
 	}
 	
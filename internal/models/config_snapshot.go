@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ConfigSnapshotVersion is the format version of exported snapshots
+const ConfigSnapshotVersion = 1
+
+// ConfigSnapshot is an exported copy of the system configuration. Config
+// holds the effective static configuration with secrets redacted; Settings
+// holds the settings stored in the database, which can be applied at runtime.
+type ConfigSnapshot struct {
+	Version    int                    `json:"version" example:"1"`
+	ExportedAt time.Time              `json:"exported_at"`
+	AppVersion string                 `json:"app_version,omitempty" example:"1.4.0"`
+	Config     map[string]interface{} `json:"config"`
+	Settings   ConfigSnapshotSettings `json:"settings"`
+}
+
+// ConfigSnapshotSettings are the runtime settings carried in a snapshot.
+// Omitted settings are left unchanged on import.
+type ConfigSnapshotSettings struct {
+	RequiredProfileFields []string `json:"required_profile_fields" example:"department,phone"`
+}
+
+// ConfigImportResponse reports the outcome of importing a snapshot
+type ConfigImportResponse struct {
+	DryRun bool `json:"dry_run"`
+	// Applied lists the runtime settings that were (or would be) changed
+	Applied []string `json:"applied"`
+	// RestartRequired lists the static configuration keys that differ from
+	// the snapshot; they take effect once changed in config.yaml or the
+	// environment and the server is restarted
+	RestartRequired []string `json:"restart_required"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity is an external (social) identity linked to a local account
+type UserIdentity struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID    uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	Provider  string    `json:"provider" gorm:"not null;size:50" example:"google"`
+	Subject   string    `json:"-" gorm:"not null;size:255"` // The provider's stable user ID
+	Email     string    `json:"email,omitempty" gorm:"size:255" example:"john.doe@gmail.com"`
+	CreatedAt time.Time `json:"linked_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// TableName returns the table name for the UserIdentity model
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// LinkIdentityRequest represents the request payload for linking an identity.
+// The ID token proves control of the external account, the password control
+// of the local one.
+type LinkIdentityRequest struct {
+	Provider string `json:"provider" binding:"required" example:"google"`
+	IDToken  string `json:"id_token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// UnlinkIdentityRequest represents the request payload for unlinking an identity
+type UnlinkIdentityRequest struct {
+	Password string `json:"password" binding:"required"`
+}
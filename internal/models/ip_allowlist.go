@@ -0,0 +1,114 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// MaxIPAllowlistEntries caps the networks in one allowlist
+const MaxIPAllowlistEntries = 50
+
+// IPAllowlist holds the networks an account or API key may be used from,
+// stored as JSONB CIDR strings. An empty allowlist allows any address.
+type IPAllowlist []string
+
+// Value implements driver.Valuer
+func (a IPAllowlist) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(a))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (a *IPAllowlist) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*a = IPAllowlist{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into IPAllowlist", value)
+	}
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+// ParseIPAllowlist validates allowlist entries and returns them in CIDR
+// form; single addresses become /32 or /128 networks
+func ParseIPAllowlist(entries []string) (IPAllowlist, error) {
+	if len(entries) > MaxIPAllowlistEntries {
+		return nil, fmt.Errorf("at most %d networks are allowed", MaxIPAllowlistEntries)
+	}
+
+	allowlist := IPAllowlist{}
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+
+		var network *net.IPNet
+		if strings.Contains(entry, "/") {
+			_, parsed, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid network %q", entry)
+			}
+			network = parsed
+		} else {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			network = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+		}
+
+		cidr := network.String()
+		if !seen[cidr] {
+			seen[cidr] = true
+			allowlist = append(allowlist, cidr)
+		}
+	}
+	return allowlist, nil
+}
+
+// Allows reports whether ip is inside one of the allowlisted networks
+func (a IPAllowlist) Allows(ip string) bool {
+	if len(a) == 0 {
+		return true
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, entry := range a {
+		if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// IPAllowlistRequest represents the request payload for replacing an
+// allowlist; an empty list removes the restriction
+type IPAllowlistRequest struct {
+	AllowedIPs []string `json:"allowed_ips" example:"10.0.0.0/8,203.0.113.7"`
+}
+
+// IPAllowlistResponse represents an account's or API key's allowlist
+type IPAllowlistResponse struct {
+	AllowedIPs IPAllowlist `json:"allowed_ips"`
+}
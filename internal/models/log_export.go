@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// LogExportCheckpoint records how far audit logs have been exported to a
+// warehouse table. Logs are exported in (timestamp, ID) order, so the last
+// exported pair is enough to resume without sending rows twice.
+type LogExportCheckpoint struct {
+	Target        string    `gorm:"primaryKey;size:200"` // Provider and table, e.g. "clickhouse:audit_logs"
+	LastTimestamp time.Time `gorm:"not null"`
+	LastLogID     string    `gorm:"not null;size:24"` // Hex ObjectID of the last exported log
+	ExportedRows  int64     `gorm:"not null;default:0"`
+	UpdatedAt     time.Time
+}
+
+// TableName returns the table name for the LogExportCheckpoint model
+func (LogExportCheckpoint) TableName() string {
+	return "log_export_checkpoints"
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LogFilterPreset is a named set of log filters saved by an admin
+type LogFilterPreset struct {
+	ID        uuid.UUID     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" example:"550e8400-e29b-41d4-a716-446655440000"`
+	AdminID   uuid.UUID     `json:"admin_id" gorm:"type:uuid;not null" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name      string        `json:"name" gorm:"not null;size:100" example:"Deletions this week"`
+	Event     *LogEventType `json:"event,omitempty" gorm:"size:50" example:"USER_DELETED"`
+	UserID    *uuid.UUID    `json:"user_id,omitempty" gorm:"type:uuid"`
+	StartDate *time.Time    `json:"start_date,omitempty"`
+	EndDate   *time.Time    `json:"end_date,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// TableName returns the table name for the LogFilterPreset model
+func (LogFilterPreset) TableName() string {
+	return "log_filter_presets"
+}
+
+// LogsPageURL returns the admin panel Logs page with the preset's filters applied
+func (p LogFilterPreset) LogsPageURL() string {
+	values := url.Values{}
+	if p.Event != nil {
+		values.Set("event", string(*p.Event))
+	}
+	if p.UserID != nil {
+		values.Set("user_id", p.UserID.String())
+	}
+	if p.StartDate != nil {
+		values.Set("start_date", p.StartDate.Format(time.RFC3339))
+	}
+	if p.EndDate != nil {
+		values.Set("end_date", p.EndDate.Format(time.RFC3339))
+	}
+	return "/admin/logs?" + values.Encode()
+}
+
+// LogFilterPresetRequest represents the request payload for saving a preset.
+// Saving under an existing name replaces that preset.
+type LogFilterPresetRequest struct {
+	Name      string        `json:"name" binding:"required,max=100" example:"Deletions this week"`
+	Event     *LogEventType `json:"event,omitempty" example:"USER_DELETED"`
+	UserID    *uuid.UUID    `json:"user_id,omitempty"`
+	StartDate *time.Time    `json:"start_date,omitempty" example:"2023-01-01T00:00:00Z"`
+	EndDate   *time.Time    `json:"end_date,omitempty" example:"2023-01-31T23:59:59Z"`
+}
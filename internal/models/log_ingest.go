@@ -0,0 +1,134 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxIngestClockSkew is how far in the future a submitted event may be
+// timestamped, to allow for clock differences between services
+const maxIngestClockSkew = 5 * time.Minute
+
+// ingestSourceName matches the service names submitting events
+var ingestSourceName = regexp.MustCompile(`^[a-z][a-z0-9_.-]{1,63}$`)
+
+// reservedIngestEvents are event types only this service writes: lifecycle
+// entries are read back at startup and must not be forged
+var reservedIngestEvents = map[LogEventType]bool{
+	SystemStartup:  true,
+	SystemShutdown: true,
+}
+
+// LogIngestEvent is an audit event submitted by another service
+type LogIngestEvent struct {
+	Event     LogEventType           `json:"event" example:"USER_UPDATED"`
+	Action    string                 `json:"action" example:"UPDATE_BILLING_ADDRESS"`
+	UserID    *uuid.UUID             `json:"user_id,omitempty"` // Actor of the event
+	Details   map[string]interface{} `json:"details,omitempty"`
+	OldValues map[string]interface{} `json:"old_values,omitempty"`
+	NewValues map[string]interface{} `json:"new_values,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	IPAddress string                 `json:"ip_address,omitempty"`
+	UserAgent string                 `json:"user_agent,omitempty"`
+	Timestamp *time.Time             `json:"timestamp,omitempty"` // When the event happened; stored as occurred_at
+}
+
+// LogIngestRequest represents the request payload for submitting a batch of
+// audit events from a trusted service
+type LogIngestRequest struct {
+	Source string           `json:"source" example:"billing-service"` // Submitting service
+	Events []LogIngestEvent `json:"events"`
+}
+
+// LogIngestEventError describes why a submitted event was rejected
+type LogIngestEventError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// LogIngestResponse represents an accepted batch
+type LogIngestResponse struct {
+	BatchID  string `json:"batch_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Accepted int    `json:"accepted" example:"250"`
+}
+
+// Validate checks the source and every event against the event taxonomy. A
+// batch is accepted or rejected as a whole; the errors list each rejected
+// event so the submitter can fix them.
+func (r LogIngestRequest) Validate(maxEvents int, maxAge time.Duration, now time.Time) ([]LogIngestEventError, error) {
+	if !ingestSourceName.MatchString(r.Source) {
+		return nil, fmt.Errorf("source must be a lowercase service name")
+	}
+	if len(r.Events) == 0 || len(r.Events) > maxEvents {
+		return nil, fmt.Errorf("submit between 1 and %d events", maxEvents)
+	}
+
+	var eventErrors []LogIngestEventError
+	for i, event := range r.Events {
+		if err := event.validate(maxAge, now); err != nil {
+			eventErrors = append(eventErrors, LogIngestEventError{Index: i, Error: err.Error()})
+		}
+	}
+	if len(eventErrors) > 0 {
+		return eventErrors, fmt.Errorf("%d of %d events are invalid", len(eventErrors), len(r.Events))
+	}
+	return nil, nil
+}
+
+func (e LogIngestEvent) validate(maxAge time.Duration, now time.Time) error {
+	if !IsValidEventType(e.Event) || reservedIngestEvents[e.Event] {
+		return fmt.Errorf("unknown event type %q", e.Event)
+	}
+	if !auditEventName.MatchString(e.Action) {
+		return fmt.Errorf("action must be an upper case name like UPDATE_BILLING_ADDRESS")
+	}
+	if e.Action == "HTTP_REQUEST" {
+		return fmt.Errorf("action HTTP_REQUEST is reserved for request logs")
+	}
+	if e.IPAddress != "" && net.ParseIP(e.IPAddress) == nil {
+		return fmt.Errorf("invalid ip_address")
+	}
+	if e.Timestamp != nil {
+		if e.Timestamp.After(now.Add(maxIngestClockSkew)) {
+			return fmt.Errorf("timestamp is in the future")
+		}
+		if e.Timestamp.Before(now.Add(-maxAge)) {
+			return fmt.Errorf("timestamp is older than %s", maxAge)
+		}
+	}
+	return nil
+}
+
+// ToUserLogs converts the batch into log entries sharing batchID, each
+// recording the source and the principal who submitted it
+func (r LogIngestRequest) ToUserLogs(batchID string, submittedBy uuid.UUID) []*UserLog {
+	logs := make([]*UserLog, len(r.Events))
+	for i, event := range r.Events {
+		details := make(map[string]interface{}, len(event.Details)+2)
+		for key, value := range event.Details {
+			details[key] = value
+		}
+		details["ingest_source"] = r.Source
+		details["ingested_by"] = submittedBy.String()
+
+		entry := NewUserLog(UserLogCreateRequest{
+			UserID:    event.UserID,
+			Event:     event.Event,
+			Action:    event.Action,
+			Details:   details,
+			OldValues: event.OldValues,
+			NewValues: event.NewValues,
+			Error:     event.Error,
+			IPAddress: event.IPAddress,
+			UserAgent: event.UserAgent,
+			BatchID:   batchID,
+		})
+		entry.OccurredAt = event.Timestamp
+		logs[i] = entry
+	}
+	return logs
+}
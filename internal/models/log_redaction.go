@@ -0,0 +1,137 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// RedactionMode describes how a log field is redacted for non-admin viewers
+type RedactionMode string
+
+const (
+	RedactionMaskIP    RedactionMode = "mask_ip"    // Keep the network prefix only
+	RedactionMaskEmail RedactionMode = "mask_email" // Keep the first character and domain
+	RedactionMask      RedactionMode = "mask"       // Replace the whole value
+	RedactionDrop      RedactionMode = "drop"       // Remove the field entirely
+)
+
+// redactedValue replaces values that cannot be partially masked
+const redactedValue = "[redacted]"
+
+// LogRedactionPolicy maps log field names and detail keys to the redaction
+// applied when the viewer is not an admin
+type LogRedactionPolicy map[string]RedactionMode
+
+// NewLogRedactionPolicy builds a policy from configured field modes,
+// rejecting unknown modes
+func NewLogRedactionPolicy(fields map[string]string) (LogRedactionPolicy, error) {
+	policy := make(LogRedactionPolicy, len(fields))
+	for field, mode := range fields {
+		switch RedactionMode(mode) {
+		case RedactionMaskIP, RedactionMaskEmail, RedactionMask, RedactionDrop:
+			policy[field] = RedactionMode(mode)
+		default:
+			return nil, fmt.Errorf("invalid redaction mode %q for field %q", mode, field)
+		}
+	}
+	return policy, nil
+}
+
+// ToResponseForRole converts UserLog model to UserLogResponse, redacted for
+// the given viewer role
+func (ul *UserLog) ToResponseForRole(role string, policy LogRedactionPolicy) UserLogResponse {
+	return ul.ToResponse().Redact(role, policy)
+}
+
+// Redact returns a copy of the response with fields redacted according to
+// the policy. Admins always see the full entry.
+func (r UserLogResponse) Redact(role string, policy LogRedactionPolicy) UserLogResponse {
+	if role == "admin" || len(policy) == 0 {
+		return r
+	}
+
+	if mode, ok := policy["ip_address"]; ok {
+		r.IPAddress = redactString(mode, r.IPAddress)
+	}
+	if mode, ok := policy["user_agent"]; ok {
+		r.UserAgent = redactString(mode, r.UserAgent)
+	}
+
+	// Copy the maps so the original entry is left untouched
+	r.Data.Details = redactMap(r.Data.Details, policy)
+	r.Data.OldValues = redactMap(r.Data.OldValues, policy)
+	r.Data.NewValues = redactMap(r.Data.NewValues, policy)
+
+	return r
+}
+
+// MaskIP keeps the /16 prefix of IPv4 and the /48 prefix of IPv6 addresses
+func MaskIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return redactedValue
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.x.x", v4[0], v4[1])
+	}
+
+	return parsed.Mask(net.CIDRMask(48, 128)).String() + "x"
+}
+
+// MaskEmail keeps the first character of the local part and the domain
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return redactedValue
+	}
+
+	return email[:1] + "***" + email[at:]
+}
+
+// redactMap returns a redacted copy of a details map
+func redactMap(values map[string]interface{}, policy LogRedactionPolicy) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		mode, ok := policy[key]
+		if !ok {
+			redacted[key] = value
+			continue
+		}
+
+		if mode == RedactionDrop {
+			continue
+		}
+
+		if str, ok := value.(string); ok {
+			redacted[key] = redactString(mode, str)
+		} else {
+			redacted[key] = redactedValue
+		}
+	}
+
+	return redacted
+}
+
+// redactString applies a redaction mode to a single string value
+func redactString(mode RedactionMode, value string) string {
+	if value == "" {
+		return value
+	}
+
+	switch mode {
+	case RedactionMaskIP:
+		return MaskIP(value)
+	case RedactionMaskEmail:
+		return MaskEmail(value)
+	case RedactionDrop:
+		return ""
+	default:
+		return redactedValue
+	}
+}
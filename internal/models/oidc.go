@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OIDCAuthorizationCode is a pending authorization code of the OpenID Connect
+// provider. Only the SHA-256 of the code is stored, and a code is deleted when
+// it is exchanged so it can be used once.
+type OIDCAuthorizationCode struct {
+	CodeHash            string    `gorm:"primaryKey;size:64"`
+	ClientID            string    `gorm:"not null;size:100"`
+	UserID              uuid.UUID `gorm:"type:uuid;not null"`
+	RedirectURI         string    `gorm:"not null"`
+	Scope               string    `gorm:"not null"`
+	Nonce               string
+	CodeChallenge       string `gorm:"size:128"`
+	CodeChallengeMethod string `gorm:"size:10"`
+	AuthTime            time.Time
+	ExpiresAt           time.Time `gorm:"not null"`
+	CreatedAt           time.Time
+}
+
+// TableName returns the table name for the OIDCAuthorizationCode model
+func (OIDCAuthorizationCode) TableName() string {
+	return "oidc_authorization_codes"
+}
+
+// OIDCTokenResponse represents the token endpoint response
+type OIDCTokenResponse struct {
+	AccessToken string `json:"access_token" example:"eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType   string `json:"token_type" example:"Bearer"`
+	ExpiresIn   int    `json:"expires_in" example:"3600"`
+	IDToken     string `json:"id_token" example:"eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Scope       string `json:"scope" example:"openid profile email"`
+}
+
+// OIDCError represents an OAuth 2.0 error response, whose format is fixed by
+// the specification rather than ErrorResponse
+type OIDCError struct {
+	Error            string `json:"error" example:"invalid_grant"`
+	ErrorDescription string `json:"error_description,omitempty" example:"Authorization code is invalid or expired"`
+}
+
+// OIDCUserInfo represents the userinfo endpoint response; profile and email
+// claims are only included when their scope was granted
+type OIDCUserInfo struct {
+	Subject string `json:"sub" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name    string `json:"name,omitempty" example:"John Doe"`
+	Email   string `json:"email,omitempty" example:"john.doe@example.com"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Profile field limits
+const (
+	MaxProfileFields      = 20
+	MaxProfileValueLength = 255
+)
+
+// profileFieldPattern restricts profile field names to snake_case identifiers
+var profileFieldPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// IsValidProfileField checks if a profile field name is well-formed
+func IsValidProfileField(name string) bool {
+	return profileFieldPattern.MatchString(name)
+}
+
+// Profile holds a user's free-form profile fields, stored as JSONB
+type Profile map[string]string
+
+// Value implements driver.Valuer
+func (p Profile) Value() (driver.Value, error) {
+	if p == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (p *Profile) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*p = Profile{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Profile", value)
+	}
+	return json.Unmarshal(data, p)
+}
+
+// Missing returns the required fields that are not filled in
+func (p Profile) Missing(required []string) []string {
+	missing := []string{}
+	for _, field := range required {
+		if p[field] == "" {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// RequiredProfileField marks a profile field every user must fill in
+type RequiredProfileField struct {
+	Field     string     `json:"field" gorm:"primaryKey;size:50" example:"department"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// TableName returns the table name for the RequiredProfileField model
+func (RequiredProfileField) TableName() string {
+	return "required_profile_fields"
+}
+
+// RequiredProfileFieldsRequest represents the request payload for setting
+// the required profile fields; it replaces the current list
+type RequiredProfileFieldsRequest struct {
+	Fields []string `json:"fields" binding:"max=20" example:"department,phone"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// Resources subject to creation quotas
+const (
+	QuotaResourceUsers = "users"
+)
+
+// QuotaUsage counts how many resources a principal created in one quota window
+type QuotaUsage struct {
+	Principal   string    `json:"principal" gorm:"primaryKey;size:100"`
+	Resource    string    `json:"resource" gorm:"primaryKey;size:50"`
+	WindowStart time.Time `json:"window_start" gorm:"primaryKey"`
+	Used        int       `json:"used"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the QuotaUsage model
+func (QuotaUsage) TableName() string {
+	return "quota_usage"
+}
+
+// QuotaStatus describes a principal's usage of a quota in the current window
+type QuotaStatus struct {
+	Principal string    `json:"principal" example:"admin:550e8400-e29b-41d4-a716-446655440000"`
+	Resource  string    `json:"resource" example:"users"`
+	Limit     int       `json:"limit" example:"500"` // 0 means unlimited
+	Used      int       `json:"used" example:"42"`
+	Remaining int       `json:"remaining" example:"458"`
+	ResetsAt  time.Time `json:"resets_at" example:"2023-01-02T00:00:00Z"`
+}
+
+// QuotaUsageResponse represents the quota limits and usage of all principals
+type QuotaUsageResponse struct {
+	Limits map[string]int `json:"limits"`
+	Usage  []QuotaStatus  `json:"usage"`
+}
+
+// UsageLimitStatus describes usage of a resource against its soft and hard limits
+type UsageLimitStatus struct {
+	Resource      string `json:"resource" example:"users"`
+	Used          int64  `json:"used" example:"950"`
+	SoftLimit     int64  `json:"soft_limit" example:"900"`  // 0 means none
+	HardLimit     int64  `json:"hard_limit" example:"1000"` // 0 means none
+	OverSoftLimit bool   `json:"over_soft_limit" example:"true"`
+}
+
+// LogVolume counts audit log entries
+type LogVolume struct {
+	Total       int64 `json:"total" example:"125000"`
+	Last24Hours int64 `json:"last_24_hours" example:"1800"`
+	Last30Days  int64 `json:"last_30_days" example:"52000"`
+}
+
+// UsageReport summarizes the deployment's usage for billing and capacity planning
+type UsageReport struct {
+	Region       string              `json:"region,omitempty" example:"eu"` // Region served by this instance, if any
+	Users        UsageLimitStatus    `json:"users"`                         // Active users
+	DeletedUsers int64               `json:"deleted_users" example:"12"`    // Soft-deleted, not yet purged
+	Logs         LogVolume           `json:"logs"`
+	Quotas       *QuotaUsageResponse `json:"quotas"` // Daily creation quotas
+	GeneratedAt  time.Time           `json:"generated_at" example:"2023-01-01T12:00:00Z"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Report describes a configured scheduled report and the files stored for
+// its current period
+type Report struct {
+	Name    string       `json:"name" example:"weekly_user_growth"`
+	Widget  string       `json:"widget" example:"user_growth"`
+	Every   string       `json:"every" example:"weekly"`
+	Days    int          `json:"days" example:"7"`
+	Formats []string     `json:"formats" example:"csv,pdf"`
+	Period  string       `json:"period" example:"2024-W07"` // Current period
+	Files   []ReportFile `json:"files"`                     // Empty until generated for the period
+}
+
+// ReportFile is a stored report file
+type ReportFile struct {
+	Name        string    `json:"name" example:"2024-W07.csv"`
+	Size        int64     `json:"size" example:"512"`
+	GeneratedAt time.Time `json:"generated_at" example:"2024-02-12T00:00:00Z"`
+	URL         string    `json:"url" example:"/api/admin/reports/weekly_user_growth/2024-W07.csv"`
+}
+
+// ReportsListResponse represents the configured scheduled reports
+type ReportsListResponse struct {
+	Reports []Report `json:"reports"`
+}
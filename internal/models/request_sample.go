@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// RequestSample is a slow or failed request taken from the request logs,
+// as listed on the admin panel's Traces page
+type RequestSample struct {
+	LogID      string    `json:"log_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"status_code"`
+	DurationMs int64     `json:"duration_ms"`
+	UserID     string    `json:"user_id,omitempty"`
+	UserEmail  string    `json:"user_email,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	TraceID    string    `json:"trace_id,omitempty"` // Empty for requests logged before trace IDs were recorded
+	TraceURL   string    `json:"trace_url,omitempty"`
+	Failed     bool      `json:"failed"` // Answered with a 5xx status
+}
+
+// NewRequestSample builds a sample from an HTTP_REQUEST log entry
+func NewRequestSample(entry UserLog) RequestSample {
+	detail := func(key string) string {
+		value, _ := entry.Data.Details[key].(string)
+		return value
+	}
+
+	return RequestSample{
+		LogID:      entry.ID.Hex(),
+		Timestamp:  entry.Timestamp,
+		Method:     detail("method"),
+		Path:       detail("path"),
+		StatusCode: entry.Data.StatusCode,
+		DurationMs: entry.Data.Duration,
+		UserID:     detail("user_id"),
+		IPAddress:  entry.IPAddress,
+		TraceID:    detail("trace_id"),
+		Failed:     entry.Data.StatusCode >= 500,
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// IsValidRole checks if a role is supported
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
+// RoleChangeStatus represents the lifecycle state of a role change
+type RoleChangeStatus string
+
+const (
+	RoleChangePendingApproval RoleChangeStatus = "pending_approval" // Waiting for a second admin
+	RoleChangeScheduled       RoleChangeStatus = "scheduled"        // Approved, waiting out the cooldown
+	RoleChangeApplied         RoleChangeStatus = "applied"
+	RoleChangeRejected        RoleChangeStatus = "rejected"
+)
+
+// RoleChange represents a requested change of a user's role stored in PostgreSQL
+type RoleChange struct {
+	ID          uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID      uuid.UUID        `json:"user_id" gorm:"type:uuid;not null;index" example:"550e8400-e29b-41d4-a716-446655440000"`
+	FromRole    string           `json:"from_role" gorm:"not null;size:20" example:"user"`
+	ToRole      string           `json:"to_role" gorm:"not null;size:20" example:"admin"`
+	Reason      string           `json:"reason,omitempty" example:"Joining the support team"`
+	Status      RoleChangeStatus `json:"status" gorm:"not null;size:20" example:"pending_approval"`
+	RequestedBy uuid.UUID        `json:"requested_by" gorm:"type:uuid;not null" example:"550e8400-e29b-41d4-a716-446655440000"`
+	RequestedAt time.Time        `json:"requested_at" example:"2023-01-01T00:00:00Z"`
+	ReviewedBy  *uuid.UUID       `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewedAt  *time.Time       `json:"reviewed_at,omitempty"`
+	EffectiveAt *time.Time       `json:"effective_at,omitempty"` // When a scheduled change is applied
+	AppliedAt   *time.Time       `json:"applied_at,omitempty"`
+}
+
+// TableName returns the table name for the RoleChange model
+func (RoleChange) TableName() string {
+	return "role_changes"
+}
+
+// IsOpen reports whether the change has not been applied or rejected yet
+func (rc *RoleChange) IsOpen() bool {
+	return rc.Status == RoleChangePendingApproval || rc.Status == RoleChangeScheduled
+}
+
+// RoleChangeRequest represents the request payload for changing a user's role
+type RoleChangeRequest struct {
+	Role   string `json:"role" binding:"required" example:"admin"`
+	Reason string `json:"reason" binding:"max=500" example:"Joining the support team"`
+}
+
+// RoleChangeReviewRequest represents the request payload for rejecting a role change
+type RoleChangeReviewRequest struct {
+	Reason string `json:"reason" binding:"max=500" example:"Not approved by security"`
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session represents a login session stored in PostgreSQL. Every token pair
+// issued at login carries the session ID, so revoking the session
+// invalidates both tokens.
+type Session struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Role           string     `json:"role" gorm:"not null;size:20"`
+	IPAddress      string     `json:"ip_address" gorm:"size:45"`
+	UserAgent      string     `json:"user_agent"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy      *uuid.UUID `json:"revoked_by,omitempty" gorm:"type:uuid"`
+
+	User *User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// TableName returns the table name for the Session model
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// IsActive reports whether the session can still be used
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// SessionResponse represents an active session for display
+type SessionResponse struct {
+	ID             uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID         uuid.UUID `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserName       string    `json:"user_name" example:"Administrator"`
+	UserEmail      string    `json:"user_email" example:"admin@example.com"`
+	Device         string    `json:"device" example:"Chrome on macOS"`
+	IPAddress      string    `json:"ip_address" example:"192.168.1.1"`
+	CreatedAt      time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	LastActivityAt time.Time `json:"last_activity_at" example:"2023-01-01T00:00:00Z"`
+	ExpiresAt      time.Time `json:"expires_at" example:"2023-01-08T00:00:00Z"`
+	Current        bool      `json:"current" example:"false"` // The session making the request
+}
+
+// ToResponse converts a Session to SessionResponse
+func (s *Session) ToResponse(currentSessionID string) SessionResponse {
+	response := SessionResponse{
+		ID:             s.ID,
+		UserID:         s.UserID,
+		Device:         DescribeUserAgent(s.UserAgent),
+		IPAddress:      s.IPAddress,
+		CreatedAt:      s.CreatedAt,
+		LastActivityAt: s.LastActivityAt,
+		ExpiresAt:      s.ExpiresAt,
+		Current:        s.ID.String() == currentSessionID,
+	}
+	if s.User != nil {
+		response.UserName = s.User.Name
+		response.UserEmail = s.User.Email
+	}
+	return response
+}
+
+// DescribeUserAgent returns a short "Browser on OS" description of a
+// User-Agent header, falling back to the raw value for unknown clients
+func DescribeUserAgent(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	ua := strings.ToLower(userAgent)
+
+	// Order matters: Edge and Opera identify as Chrome, Chrome identifies as Safari
+	browser := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"edg/", "Edge"},
+		{"opr/", "Opera"},
+		{"firefox/", "Firefox"},
+		{"chrome/", "Chrome"},
+		{"safari/", "Safari"},
+		{"curl/", "curl"},
+		{"postman", "Postman"},
+	} {
+		if strings.Contains(ua, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+
+	os := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"iphone", "iOS"},
+		{"ipad", "iPadOS"},
+		{"android", "Android"},
+		{"windows", "Windows"},
+		{"mac os x", "macOS"},
+		{"linux", "Linux"},
+	} {
+		if strings.Contains(ua, candidate.token) {
+			os = candidate.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	}
+
+	if len(userAgent) > 60 {
+		return userAgent[:60] + "..."
+	}
+	return userAgent
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Stats widget types, telling clients how to render a widget
+const (
+	StatsWidgetCounter  = "counter"  // One number per label
+	StatsWidgetStatus   = "status"   // One value per label, 1 healthy and 0 not for checks
+	StatsWidgetDoughnut = "doughnut" // Shares of a whole
+	StatsWidgetLine     = "line"     // Series over time, labels are dates
+)
+
+// StatsWidgetInfo describes a stats widget available from the widgets API
+type StatsWidgetInfo struct {
+	Name        string `json:"name" example:"event_distribution"`
+	Title       string `json:"title" example:"Event Distribution"`
+	Description string `json:"description" example:"Audit log events by type"`
+	Type        string `json:"type" example:"doughnut"`
+	// DefaultDays is the period covered when days is not given; 0 means the
+	// widget shows current values and takes no days
+	DefaultDays    int `json:"default_days,omitempty" example:"30"`
+	RefreshSeconds int `json:"refresh_seconds" example:"300"` // Suggested polling interval
+}
+
+// StatsWidget is one chart dataset for the admin Stats page and external
+// dashboards. Every dataset has one value per label.
+type StatsWidget struct {
+	StatsWidgetInfo
+	Days        int                  `json:"days,omitempty" example:"30"`
+	Labels      []string             `json:"labels" example:"LOGIN_SUCCESS,LOGIN_FAILED"`
+	Datasets    []StatsWidgetDataset `json:"datasets"`
+	GeneratedAt time.Time            `json:"generated_at" example:"2023-01-01T12:00:00Z"`
+}
+
+// StatsWidgetDataset is one series of a stats widget
+type StatsWidgetDataset struct {
+	Label string    `json:"label" example:"Events"`
+	Data  []float64 `json:"data" example:"120,8"`
+}
@@ -9,13 +9,23 @@ import (
 
 // User represents the user entity stored in PostgreSQL
 type User struct {
-	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Name      string         `json:"name" gorm:"not null;size:255" binding:"required" example:"John Doe"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null;size:255" binding:"required,email" example:"john.doe@example.com"`
-	Password  string         `json:"-" gorm:"not null;size:255" binding:"required,min=6"` // "-" means exclude from JSON
-	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"` // Soft delete support
+	ID                 uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name               string         `json:"name" gorm:"not null;size:255" binding:"required" example:"John Doe"`
+	Email              string         `json:"email" gorm:"uniqueIndex;not null;size:255" binding:"required,email" example:"john.doe@example.com"`
+	Password           string         `json:"-" gorm:"not null;size:255" binding:"required,min=6"` // "-" means exclude from JSON
+	CreatedAt          time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`                                     // Soft delete support
+	MustChangePassword bool           `json:"must_change_password" gorm:"not null;default:false"` // Set for bootstrapped admins until first password change
+	Role               string         `json:"role" gorm:"not null;size:20;default:user"`          // Changed through role change requests only
+	AvatarKey          string         `json:"-" gorm:"size:255"`                                  // Storage key, served under /assets/
+	Region             string         `json:"region" gorm:"not null;size:16;default:''"`          // Data residency region, fixed at creation
+	Profile            Profile        `json:"profile" gorm:"type:jsonb;not null;default:'{}'"`    // Free-form profile fields
+	AllowedIPs         IPAllowlist    `json:"-" gorm:"type:jsonb;not null;default:'[]'"`          // Networks the account may be used from
+
+	// ProfileIncomplete is set at login when required profile fields are
+	// missing; it is carried in the tokens, not stored
+	ProfileIncomplete bool `json:"-" gorm:"-"`
 }
 
 // UserCreateRequest represents the request payload for creating a user
@@ -23,6 +33,7 @@ type UserCreateRequest struct {
 	Name     string `json:"name" binding:"required" example:"John Doe"`
 	Email    string `json:"email" binding:"required,email" example:"john.doe@example.com"`
 	Password string `json:"password" binding:"required,min=6" example:"password123"`
+	Region   string `json:"region,omitempty" binding:"omitempty,max=16" example:"eu"` // Defaults to the configured region
 }
 
 // UserUpdateRequest represents the request payload for updating a user
@@ -30,15 +41,41 @@ type UserUpdateRequest struct {
 	Name     *string `json:"name,omitempty" example:"John Doe"`
 	Email    *string `json:"email,omitempty" binding:"omitempty,email" example:"john.doe@example.com"`
 	Password *string `json:"password,omitempty" binding:"omitempty,min=6" example:"newpassword123"`
+	// Profile fields to set; an empty value removes the field
+	Profile map[string]string `json:"profile,omitempty"`
 }
 
 // UserResponse represents the response payload for user data
 type UserResponse struct {
-	ID        uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Name      string    `json:"name" example:"John Doe"`
-	Email     string    `json:"email" example:"john.doe@example.com"`
-	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
-	UpdatedAt time.Time `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+	ID                 uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name               string    `json:"name" example:"John Doe"`
+	Email              string    `json:"email" example:"john.doe@example.com"`
+	CreatedAt          time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt          time.Time `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+	MustChangePassword bool      `json:"must_change_password,omitempty" example:"false"`
+	Role               string    `json:"role" example:"user"`
+	Region             string    `json:"region,omitempty" example:"eu"`
+	AvatarURL          string    `json:"avatar_url,omitempty" example:"/assets/avatars/550e8400-e29b-41d4-a716-446655440000-1672531200.png"`
+	Profile            Profile   `json:"profile,omitempty"`
+	// LinkedIdentities is only filled in by the profile and user detail endpoints
+	LinkedIdentities []UserIdentity `json:"linked_identities,omitempty"`
+}
+
+// DeletedUserResponse represents a soft-deleted user with deletion context
+type DeletedUserResponse struct {
+	UserResponse
+	DeletedAt time.Time      `json:"deleted_at" example:"2023-01-01T00:00:00Z"`
+	DeletedBy *DeletionActor `json:"deleted_by,omitempty"` // Missing if no audit entry was found
+}
+
+// DeletionActor identifies who deleted a user, taken from the audit log
+type DeletionActor struct {
+	UserID    *uuid.UUID `json:"user_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Email     string     `json:"email,omitempty" example:"admin@example.com"`
+	Name      string     `json:"name,omitempty" example:"Administrator"`
+	LogID     string     `json:"log_id" example:"64b7f0c2e4b0a1a2b3c4d5e6"`
+	Action    string     `json:"action" example:"DELETE_USER"`
+	Timestamp time.Time  `json:"timestamp" example:"2023-01-01T00:00:00Z"`
 }
 
 // UsersListResponse represents the response payload for paginated user list
@@ -50,15 +87,58 @@ type UsersListResponse struct {
 	TotalPages int            `json:"total_pages" example:"10"`
 }
 
+// DirectoryEntry is the restricted view of a user shown in the user directory
+type DirectoryEntry struct {
+	ID        uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name      string    `json:"name" example:"John Doe"`
+	Email     string    `json:"email,omitempty" example:"john.doe@example.com"` // Only when directory.show_email is set
+	AvatarURL string    `json:"avatar_url,omitempty" example:"/assets/avatars/550e8400-e29b-41d4-a716-446655440000-1672531200.png"`
+}
+
+// DirectoryResponse represents a page of the user directory
+type DirectoryResponse struct {
+	Users      []DirectoryEntry `json:"users"`
+	Total      int64            `json:"total" example:"100"`
+	Page       int              `json:"page" example:"1"`
+	PageSize   int              `json:"page_size" example:"20"`
+	TotalPages int              `json:"total_pages" example:"5"`
+}
+
+// ToDirectoryEntry converts a UserResponse to a directory entry
+func (r UserResponse) ToDirectoryEntry(includeEmail bool) DirectoryEntry {
+	entry := DirectoryEntry{
+		ID:        r.ID,
+		Name:      r.Name,
+		AvatarURL: r.AvatarURL,
+	}
+	if includeEmail {
+		entry.Email = r.Email
+	}
+	return entry
+}
+
 // ToResponse converts User model to UserResponse
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Name:      u.Name,
-		Email:     u.Email,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:                 u.ID,
+		Name:               u.Name,
+		Email:              u.Email,
+		CreatedAt:          u.CreatedAt,
+		UpdatedAt:          u.UpdatedAt,
+		MustChangePassword: u.MustChangePassword,
+		Role:               u.Role,
+		Region:             u.Region,
+		AvatarURL:          u.AvatarURL(),
+		Profile:            u.Profile,
+	}
+}
+
+// AvatarURL returns the path the user's avatar is served from, or "" if unset
+func (u *User) AvatarURL() string {
+	if u.AvatarKey == "" {
+		return ""
 	}
+	return "/assets/" + u.AvatarKey
 }
 
 // BeforeCreate is a GORM hook that runs before creating a user
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User change types reported by the changes feed
+const (
+	UserChangeCreated = "created" // Created after the cursor
+	UserChangeUpdated = "updated" // Created before the cursor and modified or restored since
+	UserChangeDeleted = "deleted" // Soft-deleted, still restorable
+	UserChangePurged  = "purged"  // Permanently deleted, known only from the audit trail
+)
+
+// UserChange is one user that changed since a changes feed cursor. Only the
+// latest change of each user is reported; clients fetch the current record
+// for created and updated users.
+type UserChange struct {
+	UserID    uuid.UUID `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Change    string    `json:"change" example:"updated"`
+	ChangedAt time.Time `json:"changed_at" example:"2023-01-01T12:00:00Z"`
+}
+
+// UserChangesResponse is one page of the user changes feed, oldest change
+// first. NextCursor resumes after the last change and is returned even when
+// the page is empty, so clients can keep polling with it.
+type UserChangesResponse struct {
+	Changes    []UserChange `json:"changes"`
+	NextCursor string       `json:"next_cursor" example:"MjAyMy0wMS0wMVQxMjowMDowMFp8NTUwZTg0MDAtZTI5Yi00MWQ0LWE3MTYtNDQ2NjU1NDQwMDAw"`
+	HasMore    bool         `json:"has_more" example:"false"` // More changes are available right away
+}
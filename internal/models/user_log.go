@@ -16,30 +16,54 @@ const (
 	UserUpdated LogEventType = "USER_UPDATED"
 	UserDeleted LogEventType = "USER_DELETED"
 	UserLogin   LogEventType = "USER_LOGIN"
+	UserLogout  LogEventType = "USER_LOGOUT"
 	
 	// Admin-related events
-	AdminLogin     LogEventType = "ADMIN_LOGIN"
-	AdminLogout    LogEventType = "ADMIN_LOGOUT"
+	AdminLogin      LogEventType = "ADMIN_LOGIN"
+	AdminLogout     LogEventType = "ADMIN_LOGOUT"
+	SessionRevoked  LogEventType = "SESSION_REVOKED"
+	RoleChangeEvent LogEventType = "ROLE_CHANGE"
+	APIKeyChange    LogEventType = "API_KEY_CHANGE"
+	ConfigChange    LogEventType = "CONFIG_CHANGE"
 	
 	// Authentication events
-	LoginSuccess   LogEventType = "LOGIN_SUCCESS"
-	LoginFailed    LogEventType = "LOGIN_FAILED"
-	TokenRefresh   LogEventType = "TOKEN_REFRESH"
+	LoginSuccess    LogEventType = "LOGIN_SUCCESS"
+	LoginFailed     LogEventType = "LOGIN_FAILED"
+	TokenRefresh    LogEventType = "TOKEN_REFRESH"
+	OIDCTokenIssued LogEventType = "OIDC_TOKEN_ISSUED"
+	
+	// Security events
+	UploadRejected LogEventType = "UPLOAD_REJECTED"
 	
 	// System events
 	SystemError         LogEventType = "SYSTEM_ERROR"
 	ValidationLogError  LogEventType = "VALIDATION_ERROR"
+	SystemStartup       LogEventType = "SYSTEM_STARTUP"
+	SystemShutdown      LogEventType = "SYSTEM_SHUTDOWN"
 )
 
 // UserLog represents the log entry stored in MongoDB
 type UserLog struct {
 	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	UserID    *string            `json:"user_id,omitempty" bson:"user_id,omitempty"` // UUID as string, nullable for system events
+	UserID    *string            `json:"user_id,omitempty" bson:"user_id,omitempty"` // Canonical UUID string (normalized by the repository), nullable for system events
 	Event     LogEventType       `json:"event" bson:"event"`
 	Data      LogData            `json:"data" bson:"data"`
 	Timestamp time.Time          `json:"timestamp" bson:"timestamp"`
 	IPAddress string             `json:"ip_address,omitempty" bson:"ip_address,omitempty"`
 	UserAgent string             `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+
+	// When an ingested event happened, as reported by its source. Timestamp
+	// is always when this service recorded the entry.
+	OccurredAt *time.Time `json:"occurred_at,omitempty" bson:"occurred_at,omitempty"`
+
+	// Bulk operation linkage: every entry written by one bulk operation shares a
+	// BatchID; the aggregate entry is flagged as the batch summary
+	BatchID      string `json:"batch_id,omitempty" bson:"batch_id,omitempty"`
+	BatchSummary bool   `json:"batch_summary,omitempty" bson:"batch_summary,omitempty"`
+
+	// Build of the instance that wrote the entry
+	AppVersion string `json:"app_version,omitempty" bson:"app_version,omitempty"`
+	GitCommit  string `json:"git_commit,omitempty" bson:"git_commit,omitempty"`
 }
 
 // LogData contains the actual log data with flexible structure
@@ -55,26 +79,41 @@ type LogData struct {
 
 // UserLogCreateRequest represents the request to create a log entry
 type UserLogCreateRequest struct {
-	UserID    *uuid.UUID             `json:"user_id,omitempty"`
-	Event     LogEventType           `json:"event"`
-	Action    string                 `json:"action"`
-	Details   map[string]interface{} `json:"details,omitempty"`
-	OldValues map[string]interface{} `json:"old_values,omitempty"`
-	NewValues map[string]interface{} `json:"new_values,omitempty"`
-	Error     string                 `json:"error,omitempty"`
-	IPAddress string                 `json:"ip_address,omitempty"`
-	UserAgent string                 `json:"user_agent,omitempty"`
+	UserID       *uuid.UUID             `json:"user_id,omitempty"`
+	Event        LogEventType           `json:"event"`
+	Action       string                 `json:"action"`
+	Details      map[string]interface{} `json:"details,omitempty"`
+	OldValues    map[string]interface{} `json:"old_values,omitempty"`
+	NewValues    map[string]interface{} `json:"new_values,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+	IPAddress    string                 `json:"ip_address,omitempty"`
+	UserAgent    string                 `json:"user_agent,omitempty"`
+	BatchID      string                 `json:"batch_id,omitempty"`
+	BatchSummary bool                   `json:"batch_summary,omitempty"`
 }
 
 // UserLogResponse represents the response payload for log data
 type UserLogResponse struct {
-	ID        string       `json:"id"`
-	UserID    *uuid.UUID   `json:"user_id,omitempty"`
-	Event     LogEventType `json:"event"`
-	Data      LogData      `json:"data"`
-	Timestamp time.Time    `json:"timestamp"`
-	IPAddress string       `json:"ip_address,omitempty"`
-	UserAgent string       `json:"user_agent,omitempty"`
+	ID           string       `json:"id"`
+	UserID       *uuid.UUID   `json:"user_id,omitempty"`
+	Event        LogEventType `json:"event"`
+	Data         LogData      `json:"data"`
+	Timestamp    time.Time    `json:"timestamp"`
+	IPAddress    string       `json:"ip_address,omitempty"`
+	UserAgent    string       `json:"user_agent,omitempty"`
+	OccurredAt   *time.Time   `json:"occurred_at,omitempty"`
+	BatchID      string       `json:"batch_id,omitempty"`
+	BatchSummary bool         `json:"batch_summary,omitempty"`
+	AppVersion   string       `json:"app_version,omitempty"`
+	GitCommit    string       `json:"git_commit,omitempty"`
+
+	// Current names of the users involved, filled in for admin views and
+	// with ?expand=user; see SetUserLogNames
+	ActorName    string     `json:"actor_name,omitempty"`
+	ActorEmail   string     `json:"actor_email,omitempty"`
+	TargetUserID *uuid.UUID `json:"target_user_id,omitempty"`
+	TargetName   string     `json:"target_name,omitempty"`
+	TargetEmail  string     `json:"target_email,omitempty"`
 }
 
 // UserLogsListResponse represents the response payload for paginated log list
@@ -88,14 +127,16 @@ type UserLogsListResponse struct {
 
 // LogFilterRequest represents the request payload for filtering logs
 type LogFilterRequest struct {
-	UserID     *uuid.UUID    `json:"user_id,omitempty" form:"user_id"`
-	Event      *LogEventType `json:"event,omitempty" form:"event"`
-	StartDate  *time.Time    `json:"start_date,omitempty" form:"start_date"`
-	EndDate    *time.Time    `json:"end_date,omitempty" form:"end_date"`
-	IPAddress  string        `json:"ip_address,omitempty" form:"ip_address"`
-	Action     string        `json:"action,omitempty" form:"action"`
-	Page       int           `json:"page" form:"page" binding:"omitempty,min=1"`
-	PageSize   int           `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+	UserID         *uuid.UUID    `json:"user_id,omitempty" form:"user_id"`
+	Event          *LogEventType `json:"event,omitempty" form:"event"`
+	StartDate      *time.Time    `json:"start_date,omitempty" form:"start_date"`
+	EndDate        *time.Time    `json:"end_date,omitempty" form:"end_date"`
+	IPAddress      string        `json:"ip_address,omitempty" form:"ip_address"`
+	Action         string        `json:"action,omitempty" form:"action"`
+	BatchID        string        `json:"batch_id,omitempty" form:"batch_id"`
+	BatchSummaries bool          `json:"batch_summaries,omitempty" form:"batch_summaries"` // Only bulk operation summary entries
+	Page           int           `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize       int           `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
 }
 
 // NewUserLog creates a new UserLog instance
@@ -116,9 +157,11 @@ func NewUserLog(req UserLogCreateRequest) *UserLog {
 			NewValues: req.NewValues,
 			Error:     req.Error,
 		},
-		Timestamp: time.Now(),
-		IPAddress: req.IPAddress,
-		UserAgent: req.UserAgent,
+		Timestamp:    time.Now(),
+		IPAddress:    req.IPAddress,
+		UserAgent:    req.UserAgent,
+		BatchID:      req.BatchID,
+		BatchSummary: req.BatchSummary,
 	}
 }
 
@@ -132,13 +175,92 @@ func (ul *UserLog) ToResponse() UserLogResponse {
 	}
 
 	return UserLogResponse{
-		ID:        ul.ID.Hex(),
-		UserID:    userID,
-		Event:     ul.Event,
-		Data:      ul.Data,
-		Timestamp: ul.Timestamp,
-		IPAddress: ul.IPAddress,
-		UserAgent: ul.UserAgent,
+		ID:           ul.ID.Hex(),
+		UserID:       userID,
+		Event:        ul.Event,
+		Data:         ul.Data,
+		Timestamp:    ul.Timestamp,
+		IPAddress:    ul.IPAddress,
+		UserAgent:    ul.UserAgent,
+		OccurredAt:   ul.OccurredAt,
+		BatchID:      ul.BatchID,
+		BatchSummary: ul.BatchSummary,
+		AppVersion:   ul.AppVersion,
+		GitCommit:    ul.GitCommit,
+	}
+}
+
+// TargetUserDetailKeys are the details keys naming the user an entry is about
+var TargetUserDetailKeys = []string{
+	"target_user_id",
+	"created_user_id",
+	"updated_user_id",
+	"deleted_user_id",
+	"restored_user_id",
+	"session_user_id",
+}
+
+// targetUser returns the user the entry is about, read from its details.
+// IDs are stored as strings or, for uuid.UUID values, as 16-byte binaries.
+func (r UserLogResponse) targetUser() *uuid.UUID {
+	for _, key := range TargetUserDetailKeys {
+		var id uuid.UUID
+		var err error
+		switch v := r.Data.Details[key].(type) {
+		case string:
+			id, err = uuid.Parse(v)
+		case uuid.UUID:
+			id = v
+		case primitive.Binary:
+			id, err = uuid.FromBytes(v.Data)
+		case []byte:
+			id, err = uuid.FromBytes(v)
+		default:
+			continue
+		}
+		if err == nil {
+			return &id
+		}
+	}
+	return nil
+}
+
+// UserLogUserIDs returns the distinct actor and target user IDs of the
+// entries, for loading their names in one query
+func UserLogUserIDs(logs []UserLogResponse) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool)
+	ids := []uuid.UUID{}
+	add := func(id *uuid.UUID) {
+		if id != nil && !seen[*id] {
+			seen[*id] = true
+			ids = append(ids, *id)
+		}
+	}
+	for _, entry := range logs {
+		add(entry.UserID)
+		add(entry.targetUser())
+	}
+	return ids
+}
+
+// SetUserLogNames fills in the actor and target names and emails of the
+// entries from users keyed by ID. Users missing from the map, such as deleted ones, are
+// left unnamed.
+func SetUserLogNames(logs []UserLogResponse, users map[uuid.UUID]*User) {
+	for i := range logs {
+		if logs[i].UserID != nil {
+			if user, ok := users[*logs[i].UserID]; ok {
+				logs[i].ActorName = user.Name
+				logs[i].ActorEmail = user.Email
+			}
+		}
+		if target := logs[i].targetUser(); target != nil {
+			logs[i].TargetUserID = target
+			if user, ok := users[*target]; ok {
+				logs[i].TargetName = user.Name
+				logs[i].TargetEmail = user.Email
+			}
+		}
 	}
 }
 
@@ -154,13 +276,22 @@ func GetValidEventTypes() []LogEventType {
 		UserUpdated,
 		UserDeleted,
 		UserLogin,
+		UserLogout,
 		AdminLogin,
 		AdminLogout,
+		SessionRevoked,
+		RoleChangeEvent,
+		APIKeyChange,
+		ConfigChange,
 		LoginSuccess,
 		LoginFailed,
 		TokenRefresh,
+		OIDCTokenIssued,
+		UploadRejected,
 		SystemError,
 		ValidationLogError,
+		SystemStartup,
+		SystemShutdown,
 	}
 }
 
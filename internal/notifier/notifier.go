@@ -0,0 +1,138 @@
+// Package notifier delivers operational alerts to operators. Alerts are
+// always written to the application log and, when configured, POSTed as
+// JSON to a webhook.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"user_mgmt_go/internal/config"
+)
+
+// Severity levels
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Alert is a notification about something an operator should look at
+type Alert struct {
+	Kind     string                 `json:"kind"` // Machine-readable alert type, e.g. "log_volume_anomaly"
+	Severity string                 `json:"severity"`
+	Title    string                 `json:"title"`
+	Message  string                 `json:"message"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	Time     time.Time              `json:"time"`
+}
+
+// ErrMuted is returned for alerts raised while muted. They are only logged,
+// so callers that track delivery must not record them as delivered.
+var ErrMuted = errors.New("alert muted")
+
+// Notifier delivers alerts
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// New creates the notifier described by the configuration
+func New(cfg config.NotifierConfig) Notifier {
+	notifiers := multiNotifier{LogNotifier{}}
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(cfg.WebhookURL, cfg.Timeout))
+	}
+	return notifiers
+}
+
+// Muted wraps a notifier so alerts raised while muted returns true are only
+// logged, e.g. during maintenance windows
+func Muted(next Notifier, muted func(time.Time) bool) Notifier {
+	return mutedNotifier{next: next, muted: muted}
+}
+
+// mutedNotifier drops alerts while muted, failing with ErrMuted
+type mutedNotifier struct {
+	next  Notifier
+	muted func(time.Time) bool
+}
+
+// Notify delivers the alert unless alerts are muted at its time
+func (m mutedNotifier) Notify(ctx context.Context, alert Alert) error {
+	if m.muted(alert.Time) {
+		log.Printf("🔕 [%s] %s (muted): %s %v", alert.Severity, alert.Title, alert.Message, alert.Details)
+		return ErrMuted
+	}
+	return m.next.Notify(ctx, alert)
+}
+
+// LogNotifier writes alerts to the application log
+type LogNotifier struct{}
+
+// Notify logs the alert
+func (LogNotifier) Notify(ctx context.Context, alert Alert) error {
+	log.Printf("🚨 [%s] %s: %s %v", alert.Severity, alert.Title, alert.Message, alert.Details)
+	return nil
+}
+
+// WebhookNotifier POSTs alerts as JSON to a URL
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier; a zero timeout means 10s
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Notify posts the alert, failing on any non-2xx response
+func (w *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// multiNotifier sends each alert to every notifier, returning the first error
+type multiNotifier []Notifier
+
+// Notify delivers the alert to all notifiers
+func (m multiNotifier) Notify(ctx context.Context, alert Alert) error {
+	var firstErr error
+	for _, n := range m {
+		if err := n.Notify(ctx, alert); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
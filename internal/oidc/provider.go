@@ -0,0 +1,361 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Scopes supported by the provider
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// PKCE code challenge methods
+const (
+	ChallengeMethodS256  = "S256"
+	ChallengeMethodPlain = "plain"
+)
+
+// Endpoint paths, relative to the issuer
+const (
+	DiscoveryPath = "/.well-known/openid-configuration"
+	JWKSPath      = "/.well-known/jwks.json"
+	AuthorizePath = "/oauth2/authorize"
+	TokenPath     = "/oauth2/token"
+	UserInfoPath  = "/oauth2/userinfo"
+)
+
+// accessTokenAudience marks access tokens so they cannot be used as ID tokens
+const accessTokenAudience = "userinfo"
+
+// Provider issues and verifies the tokens of the OpenID Connect provider.
+// Tokens are signed with RS256 so relying parties can verify them with the
+// published JWKS without sharing a secret.
+type Provider struct {
+	issuer      string
+	key         *rsa.PrivateKey
+	keyID       string
+	clients     map[string]config.OIDCClientConfig
+	codeExpiry  time.Duration
+	tokenExpiry time.Duration
+}
+
+// DiscoveryDocument is the OpenID Provider metadata
+type DiscoveryDocument struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+}
+
+// JSONWebKey is a public RSA signing key
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JSONWebKeySet is the JWKS document
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// IDTokenClaims are the claims of an ID token
+type IDTokenClaims struct {
+	Nonce    string `json:"nonce,omitempty"`
+	AuthTime int64  `json:"auth_time"`
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// AccessTokenClaims are the claims of an access token for the userinfo endpoint
+type AccessTokenClaims struct {
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope"`
+	jwt.RegisteredClaims
+}
+
+// NewProvider creates a provider from configuration, loading the signing key
+// from SigningKeyPath or generating one when it is empty
+func NewProvider(cfg config.OIDCConfig) (*Provider, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("oidc issuer is required")
+	}
+
+	key, err := loadSigningKey(cfg.SigningKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make(map[string]config.OIDCClientConfig, len(cfg.Clients))
+	for _, client := range cfg.Clients {
+		if client.ID == "" || len(client.RedirectURIs) == 0 {
+			return nil, fmt.Errorf("oidc client %q needs a client_id and at least one redirect_uri", client.ID)
+		}
+		clients[client.ID] = client
+	}
+
+	// The key ID is derived from the public key so it changes with the key
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode oidc public key: %w", err)
+	}
+	thumbprint := sha256.Sum256(publicDER)
+
+	return &Provider{
+		issuer:      strings.TrimSuffix(cfg.Issuer, "/"),
+		key:         key,
+		keyID:       base64.RawURLEncoding.EncodeToString(thumbprint[:8]),
+		clients:     clients,
+		codeExpiry:  cfg.CodeExpiry,
+		tokenExpiry: cfg.TokenExpiry,
+	}, nil
+}
+
+// loadSigningKey reads a PEM RSA private key (PKCS#1 or PKCS#8)
+func loadSigningKey(path string) (*rsa.PrivateKey, error) {
+	if path == "" {
+		log.Println("⚠️  No OIDC signing key configured; generating one, tokens will not survive a restart")
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate oidc signing key: %w", err)
+		}
+		return key, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read oidc signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("oidc signing key %s is not PEM encoded", path)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse oidc signing key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("oidc signing key %s is not an RSA key", path)
+	}
+	return key, nil
+}
+
+// Issuer returns the issuer identifier
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// CodeExpiry returns the authorization code lifetime
+func (p *Provider) CodeExpiry() time.Duration {
+	return p.codeExpiry
+}
+
+// TokenExpiry returns the ID and access token lifetime
+func (p *Provider) TokenExpiry() time.Duration {
+	return p.tokenExpiry
+}
+
+// Discovery returns the provider metadata
+func (p *Provider) Discovery() DiscoveryDocument {
+	return DiscoveryDocument{
+		Issuer:                            p.issuer,
+		AuthorizationEndpoint:             p.issuer + AuthorizePath,
+		TokenEndpoint:                     p.issuer + TokenPath,
+		UserInfoEndpoint:                  p.issuer + UserInfoPath,
+		JWKSURI:                           p.issuer + JWKSPath,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{"RS256"},
+		ScopesSupported:                   []string{ScopeOpenID, ScopeProfile, ScopeEmail},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "name", "email"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{ChallengeMethodS256, ChallengeMethodPlain},
+	}
+}
+
+// JWKS returns the public signing keys
+func (p *Provider) JWKS() JSONWebKeySet {
+	return JSONWebKeySet{Keys: []JSONWebKey{{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: "RS256",
+		KeyID:     p.keyID,
+		Modulus:   base64.RawURLEncoding.EncodeToString(p.key.PublicKey.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.PublicKey.E)).Bytes()),
+	}}}
+}
+
+// Client returns a registered client
+func (p *Provider) Client(clientID string) (config.OIDCClientConfig, bool) {
+	client, ok := p.clients[clientID]
+	return client, ok
+}
+
+// ValidRedirectURI checks a redirect URI against the client's registered URIs
+func ValidRedirectURI(client config.OIDCClientConfig, redirectURI string) bool {
+	for _, uri := range client.RedirectURIs {
+		if uri == redirectURI {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthenticateClient checks a client's secret. Public clients have no secret
+// and must present none.
+func AuthenticateClient(client config.OIDCClientConfig, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(client.Secret), []byte(secret)) == 1
+}
+
+// VerifyCodeChallenge checks a PKCE code verifier against the challenge sent
+// to the authorization endpoint
+func VerifyCodeChallenge(challenge, method, verifier string) bool {
+	if challenge == "" {
+		return verifier == ""
+	}
+
+	expected := verifier
+	if method == ChallengeMethodS256 {
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(expected)) == 1
+}
+
+// HasScope reports whether a space-separated scope string contains scope
+func HasScope(scopes, scope string) bool {
+	for _, s := range strings.Fields(scopes) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// NewAuthorizationCode generates a random authorization code and the hash
+// under which it is stored
+func NewAuthorizationCode() (code, hash string, err error) {
+	codeBytes := make([]byte, 32)
+	if _, err := rand.Read(codeBytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate authorization code: %w", err)
+	}
+	code = base64.RawURLEncoding.EncodeToString(codeBytes)
+	return code, HashAuthorizationCode(code), nil
+}
+
+// HashAuthorizationCode returns the stored form of an authorization code
+func HashAuthorizationCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueTokens creates the ID token and access token for an exchanged code
+func (p *Provider) IssueTokens(user *models.User, code *models.OIDCAuthorizationCode) (*models.OIDCTokenResponse, error) {
+	now := time.Now()
+	expiresAt := now.Add(p.tokenExpiry)
+
+	idClaims := IDTokenClaims{
+		Nonce:            code.Nonce,
+		AuthTime:         code.AuthTime.Unix(),
+		RegisteredClaims: p.registeredClaims(user, code.ClientID, now, expiresAt),
+	}
+	if HasScope(code.Scope, ScopeProfile) {
+		idClaims.Name = user.Name
+	}
+	if HasScope(code.Scope, ScopeEmail) {
+		idClaims.Email = user.Email
+	}
+	idToken, err := p.sign(idClaims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign id token: %w", err)
+	}
+
+	accessToken, err := p.sign(AccessTokenClaims{
+		ClientID:         code.ClientID,
+		Scope:            code.Scope,
+		RegisteredClaims: p.registeredClaims(user, accessTokenAudience, now, expiresAt),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return &models.OIDCTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(p.tokenExpiry.Seconds()),
+		IDToken:     idToken,
+		Scope:       code.Scope,
+	}, nil
+}
+
+// ValidateAccessToken verifies an access token issued by IssueTokens
+func (p *Provider) ValidateAccessToken(tokenString string) (*AccessTokenClaims, error) {
+	claims := &AccessTokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return &p.key.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(accessTokenAudience),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %w", err)
+	}
+	return claims, nil
+}
+
+func (p *Provider) registeredClaims(user *models.User, audience string, now, expiresAt time.Time) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Issuer:    p.issuer,
+		Subject:   user.ID.String(),
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+}
+
+func (p *Provider) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.keyID
+	return token.SignedString(p.key)
+}
@@ -0,0 +1,141 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"user_mgmt_go/internal/models"
+)
+
+// PDF page layout: A4 in points, set in 9pt Courier so the table lines up
+// without font metrics
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFontSize     = 9
+	pdfLeading      = 12
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+	pdfMaxColumns   = 95 // Characters per line at 9pt Courier
+	pdfMaxCellWidth = 40
+)
+
+// renderPDF lays the widget out as a text table over as many pages as needed
+func renderPDF(widget *models.StatsWidget) []byte {
+	lines := []string{
+		widget.Title,
+		widget.Description,
+		fmt.Sprintf("%s, generated %s", periodText(widget), widget.GeneratedAt.UTC().Format(time.RFC1123)),
+		"",
+	}
+	lines = append(lines, tableLines(rows(widget))...)
+
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+	return writePDF(pages)
+}
+
+// tableLines pads the table into fixed-width columns, labels left aligned
+// and values right aligned
+func tableLines(table [][]string) []string {
+	widths := make([]int, len(table[0]))
+	for _, row := range table {
+		for i, cell := range row {
+			if n := len(cell); n > widths[i] {
+				widths[i] = min(n, pdfMaxCellWidth)
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(table)+1)
+	for r, row := range table {
+		var line strings.Builder
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				cell = cell[:widths[i]-1] + "~"
+			}
+			if i == 0 {
+				fmt.Fprintf(&line, "%-*s", widths[i], cell)
+			} else {
+				fmt.Fprintf(&line, "  %*s", widths[i], cell)
+			}
+		}
+		text := line.String()
+		if len(text) > pdfMaxColumns {
+			text = text[:pdfMaxColumns]
+		}
+		lines = append(lines, text)
+		if r == 0 {
+			lines = append(lines, strings.Repeat("-", len(text)))
+		}
+	}
+	return lines
+}
+
+// writePDF writes a minimal PDF 1.4 document with one content stream per
+// page, all set in the standard Courier font
+func writePDF(pages [][]string) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1-3 are the catalog, the page tree and the font; each page
+	// then takes a page object and a content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range pages {
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range lines {
+			fmt.Fprintf(&content, "(%s) '\n", pdfEscape(line))
+		}
+		content.WriteString("ET")
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// pdfEscape escapes a line for a PDF string, replacing characters outside
+// printable ASCII, which the standard fonts cannot show reliably
+func pdfEscape(line string) string {
+	var escaped strings.Builder
+	for _, r := range line {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			escaped.WriteByte('?')
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
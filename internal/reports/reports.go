@@ -0,0 +1,130 @@
+// Package reports renders stats widgets into report files for scheduled
+// delivery: CSV for spreadsheets and a plain single-font PDF for reading.
+package reports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"time"
+
+	"user_mgmt_go/internal/models"
+)
+
+// Report formats
+const (
+	FormatCSV = "csv"
+	FormatPDF = "pdf"
+)
+
+// Report schedules
+const (
+	Weekly  = "weekly"  // Generated from Monday 00:00 UTC
+	Monthly = "monthly" // Generated from the 1st, 00:00 UTC
+)
+
+// fileName matches report file names, a period key and a format
+var fileName = regexp.MustCompile(`^[0-9]{4}-(W[0-9]{2}|[0-9]{2})\.(csv|pdf)$`)
+
+// PeriodKey names the schedule period containing now, e.g. "2024-W07" for
+// weekly and "2024-02" for monthly reports. A report is generated once per
+// period.
+func PeriodKey(every string, now time.Time) string {
+	now = now.UTC()
+	if every == Monthly {
+		return now.Format("2006-01")
+	}
+	year, week := now.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// FileName names the file of a report for a period, e.g. "2024-W07.csv"
+func FileName(period, format string) string {
+	return period + "." + format
+}
+
+// IsValidFileName reports whether file is a report file name
+func IsValidFileName(file string) bool {
+	return fileName.MatchString(file)
+}
+
+// FormatOf returns the format of a valid report file name
+func FormatOf(file string) string {
+	return path.Ext(file)[1:]
+}
+
+// Key returns the storage key of a scheduled report's file
+func Key(schedule, file string) string {
+	return path.Join("reports", schedule, file)
+}
+
+// Render renders the widget in the given format
+func Render(widget *models.StatsWidget, format string) ([]byte, error) {
+	switch format {
+	case FormatCSV:
+		return renderCSV(widget)
+	case FormatPDF:
+		return renderPDF(widget), nil
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// ContentType returns the media type of a report format
+func ContentType(format string) string {
+	if format == FormatPDF {
+		return "application/pdf"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// labelHeader names the label column: dates for line widgets
+func labelHeader(widget *models.StatsWidget) string {
+	if widget.Type == models.StatsWidgetLine {
+		return "Date"
+	}
+	return "Label"
+}
+
+// rows returns the widget as a table, header first
+func rows(widget *models.StatsWidget) [][]string {
+	header := []string{labelHeader(widget)}
+	for _, dataset := range widget.Datasets {
+		header = append(header, dataset.Label)
+	}
+
+	table := [][]string{header}
+	for i, label := range widget.Labels {
+		row := []string{label}
+		for _, dataset := range widget.Datasets {
+			value := ""
+			if i < len(dataset.Data) {
+				value = strconv.FormatFloat(dataset.Data[i], 'f', -1, 64)
+			}
+			row = append(row, value)
+		}
+		table = append(table, row)
+	}
+	return table
+}
+
+// renderCSV writes one row per label with a column per dataset
+func renderCSV(widget *models.StatsWidget) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(rows(widget)); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// periodText describes the period a widget covers
+func periodText(widget *models.StatsWidget) string {
+	if widget.Days == 0 {
+		return "Current values"
+	}
+	return fmt.Sprintf("Last %d days", widget.Days)
+}
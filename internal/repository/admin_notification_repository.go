@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// adminNotificationRepository implements AdminNotificationRepository interface
+type adminNotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewAdminNotificationRepository creates a new admin notification repository
+func NewAdminNotificationRepository(db *gorm.DB) AdminNotificationRepository {
+	return &adminNotificationRepository{db: db}
+}
+
+// CreateBatch stores notifications, skipping audit entries an admin was
+// already notified of, so a re-evaluated entry is delivered once
+func (r *adminNotificationRepository) CreateBatch(ctx context.Context, notifications []models.AdminNotification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "admin_id"}, {Name: "log_id"}},
+			DoNothing: true,
+		}).
+		Create(&notifications).Error
+	if err != nil {
+		return fmt.Errorf("failed to create admin notifications: %w", err)
+	}
+	return nil
+}
+
+// ListForAdmin returns an admin's latest notifications, newest first
+func (r *adminNotificationRepository) ListForAdmin(ctx context.Context, adminID uuid.UUID, unreadOnly bool, limit int) ([]models.AdminNotification, error) {
+	query := r.db.WithContext(ctx).Where("admin_id = ?", adminID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []models.AdminNotification
+	if err := query.Order("logged_at DESC").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to list admin notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+// CountUnread counts an admin's unread notifications
+func (r *adminNotificationRepository) CountUnread(ctx context.Context, adminID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.AdminNotification{}).
+		Where("admin_id = ? AND read_at IS NULL", adminID).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread admin notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks one of an admin's notifications as read
+func (r *adminNotificationRepository) MarkRead(ctx context.Context, adminID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.AdminNotification{}).
+		Where("id = ? AND admin_id = ?", id, adminID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", time.Now()))
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark admin notification read: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("admin notification %s: %w", id, ErrAdminNotificationNotFound)
+	}
+	return nil
+}
+
+// MarkAllRead marks all of an admin's notifications as read and returns how many were unread
+func (r *adminNotificationRepository) MarkAllRead(ctx context.Context, adminID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.AdminNotification{}).
+		Where("admin_id = ? AND read_at IS NULL", adminID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark admin notifications read: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/notifier"
+)
+
+// LogAnomaly is an event type whose volume in the latest window deviates
+// from its baseline
+type LogAnomaly struct {
+	Event    models.LogEventType `json:"event"`
+	Count    int64               `json:"count"`
+	Baseline float64             `json:"baseline"` // Average count of the preceding windows
+	Factor   float64             `json:"factor"`   // Count divided by baseline; 0 when the baseline is 0
+}
+
+// FindLogAnomalies compares the last window of each event type with the
+// average of the windows before it. counts holds BaselineWindows+1 counts per
+// type, oldest first. A type is anomalous when its last window has at least
+// MinEvents events and more than Factor times the baseline, so a type never
+// seen before is reported once it reaches MinEvents.
+func FindLogAnomalies(counts map[models.LogEventType][]int64, cfg config.AnomalyConfig) []LogAnomaly {
+	var anomalies []LogAnomaly
+	for event, windows := range counts {
+		if len(windows) < 2 {
+			continue
+		}
+		current := windows[len(windows)-1]
+		if current < cfg.MinEvents {
+			continue
+		}
+
+		var total int64
+		for _, count := range windows[:len(windows)-1] {
+			total += count
+		}
+		baseline := float64(total) / float64(len(windows)-1)
+		if float64(current) <= baseline*cfg.Factor {
+			continue
+		}
+
+		anomaly := LogAnomaly{Event: event, Count: current, Baseline: baseline}
+		if baseline > 0 {
+			anomaly.Factor = float64(current) / baseline
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool { return anomalies[i].Event < anomalies[j].Event })
+	return anomalies
+}
+
+// DetectLogAnomalies checks the latest window of audit log volume against the
+// baseline and alerts about each anomalous event type. It runs on the
+// scheduler every anomaly window.
+func (rm *RepositoryManager) DetectLogAnomalies(ctx context.Context) error {
+	cfg := rm.config.Anomaly
+	windows := cfg.BaselineWindows + 1
+	now := time.Now()
+	from := now.Add(-cfg.Window * time.Duration(windows))
+
+	counts, err := rm.Repos.Log.GetEventCountsByWindow(ctx, from, cfg.Window, windows)
+	if err != nil {
+		return err
+	}
+
+	anomalies := FindLogAnomalies(counts, cfg)
+	var failed int
+	for _, anomaly := range anomalies {
+		alert := notifier.Alert{
+			Kind:     "log_volume_anomaly",
+			Severity: notifier.SeverityWarning,
+			Title:    fmt.Sprintf("Unusual volume of %s events", anomaly.Event),
+			Message:  fmt.Sprintf("%d events in the last %s against a baseline of %.1f", anomaly.Count, cfg.Window, anomaly.Baseline),
+			Details: map[string]interface{}{
+				"event":    anomaly.Event,
+				"count":    anomaly.Count,
+				"baseline": anomaly.Baseline,
+				"window":   cfg.Window.String(),
+			},
+			Time: now,
+		}
+		if err := rm.notifier.Notify(ctx, alert); err != nil && !errors.Is(err, notifier.ErrMuted) {
+			log.Printf("Failed to send anomaly alert for %s: %v", anomaly.Event, err)
+			failed++
+		}
+
+		logEntry := models.NewUserLog(models.UserLogCreateRequest{
+			Event:   models.SystemError,
+			Action:  "LOG_VOLUME_ANOMALY",
+			Details: alert.Details,
+		})
+		if err := rm.Repos.Log.CreateAsync(logEntry); err != nil {
+			log.Printf("Failed to log anomaly: %v", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d of %d anomaly alerts", failed, len(anomalies))
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// apiKeyRepository implements APIKeyRepository interface
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Create stores a new API key
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves an API key by ID
+func (r *apiKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("API key %s: %w", id, ErrAPIKeyNotFound)
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return &key, nil
+}
+
+// GetByHash retrieves an API key by the hash of the key
+func (r *apiKeyRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).Where("key_hash = ?", hash).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return &key, nil
+}
+
+// List returns all API keys, newest first
+func (r *apiKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	if err := r.db.WithContext(ctx).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Rotate stores the replacement key and lets the old one expire at
+// oldExpiresAt, or earlier if it was going to expire sooner. Only active
+// keys that have not been rotated already can be rotated.
+func (r *apiKeyRepository) Rotate(ctx context.Context, oldID uuid.UUID, replacement *models.APIKey, oldExpiresAt time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var old models.APIKey
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", oldID).First(&old).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("API key %s: %w", oldID, ErrAPIKeyNotFound)
+			}
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+		if !old.IsActive(time.Now()) || old.RotatedTo != nil {
+			return fmt.Errorf("API key %s: %w", oldID, ErrAPIKeyInactive)
+		}
+
+		if err := tx.Create(replacement).Error; err != nil {
+			return fmt.Errorf("failed to create API key: %w", err)
+		}
+
+		expiresAt := oldExpiresAt
+		if old.ExpiresAt != nil && old.ExpiresAt.Before(expiresAt) {
+			expiresAt = *old.ExpiresAt
+		}
+		err = tx.Model(&models.APIKey{}).Where("id = ?", oldID).Updates(map[string]interface{}{
+			"rotated_to": replacement.ID,
+			"expires_at": expiresAt,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update rotated API key: %w", err)
+		}
+		return nil
+	})
+}
+
+// Revoke disables an API key immediately
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke API key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("API key %s: %w", id, ErrAPIKeyNotFound)
+	}
+	return nil
+}
+
+// SetAllowedIPs replaces the networks a key may be used from
+func (r *apiKeyRepository) SetAllowedIPs(ctx context.Context, id uuid.UUID, allowlist models.IPAllowlist) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("allowed_ips", allowlist)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update API key allowlist: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("API key %s: %w", id, ErrAPIKeyNotFound)
+	}
+	return nil
+}
+
+// Touch records use of an API key
+func (r *apiKeyRepository) Touch(ctx context.Context, id uuid.UUID, ip string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_used_at": time.Now(),
+			"last_used_ip": ip,
+		}).Error
+}
+
+// ListRotationDue returns usable keys due for rotation by before that no
+// reminder has been sent for yet
+func (r *apiKeyRepository) ListRotationDue(ctx context.Context, before time.Time) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	err := r.db.WithContext(ctx).
+		Where("rotation_due_at <= ? AND revoked_at IS NULL AND rotated_to IS NULL", before).
+		Where("rotation_reminded_at IS NULL").
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("rotation_due_at").
+		Find(&keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys due for rotation: %w", err)
+	}
+	return keys, nil
+}
+
+// MarkRotationReminded records that a rotation reminder was sent
+func (r *apiKeyRepository) MarkRotationReminded(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id = ?", id).
+		Update("rotation_reminded_at", time.Now()).Error
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/notifier"
+)
+
+// RemindAPIKeyRotations alerts about API keys coming due for rotation and
+// records each reminder in the audit log, once per key. Reminders muted by
+// a maintenance window are sent on a later run. It runs on the scheduler.
+func (rm *RepositoryManager) RemindAPIKeyRotations(ctx context.Context) error {
+	now := time.Now()
+	keys, err := rm.Repos.APIKey.ListRotationDue(ctx, now.Add(rm.config.APIKeys.ReminderBefore))
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, key := range keys {
+		alert := notifier.Alert{
+			Kind:     "api_key_rotation_due",
+			Severity: notifier.SeverityInfo,
+			Title:    fmt.Sprintf("API key %q is due for rotation", key.Name),
+			Message:  fmt.Sprintf("API key %s (%s) is due for rotation on %s", key.Name, key.Prefix, key.RotationDueAt.Format(time.DateOnly)),
+			Details: map[string]interface{}{
+				"api_key_id":      key.ID,
+				"name":            key.Name,
+				"prefix":          key.Prefix,
+				"owner_id":        key.OwnerID,
+				"rotation_due_at": key.RotationDueAt,
+			},
+			Time: now,
+		}
+		if err := rm.notifier.Notify(ctx, alert); errors.Is(err, notifier.ErrMuted) {
+			// Remind again once the maintenance window is over
+			continue
+		} else if err != nil {
+			log.Printf("Failed to send rotation reminder for API key %s: %v", key.ID, err)
+			failed++
+			continue
+		}
+
+		if err := rm.Repos.APIKey.MarkRotationReminded(ctx, key.ID); err != nil {
+			log.Printf("Failed to record rotation reminder for API key %s: %v", key.ID, err)
+		}
+
+		logEntry := models.NewUserLog(models.UserLogCreateRequest{
+			UserID:  &key.OwnerID,
+			Event:   models.APIKeyChange,
+			Action:  "API_KEY_ROTATION_DUE",
+			Details: alert.Details,
+		})
+		if err := rm.Repos.Log.CreateAsync(logEntry); err != nil {
+			log.Printf("Failed to log rotation reminder: %v", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d of %d rotation reminders", failed, len(keys))
+	}
+	return nil
+}
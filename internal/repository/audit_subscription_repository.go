@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// auditSubscriptionRepository implements AuditSubscriptionRepository interface
+type auditSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditSubscriptionRepository creates a new audit subscription repository
+func NewAuditSubscriptionRepository(db *gorm.DB) AuditSubscriptionRepository {
+	return &auditSubscriptionRepository{db: db}
+}
+
+// Get returns an admin's subscription, or nil if the admin has none
+func (r *auditSubscriptionRepository) Get(ctx context.Context, adminID uuid.UUID) (*models.AuditSubscription, error) {
+	var subscription models.AuditSubscription
+	err := r.db.WithContext(ctx).Where("admin_id = ?", adminID).First(&subscription).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit subscription: %w", err)
+	}
+	return &subscription, nil
+}
+
+// Save stores an admin's subscription, replacing the previous one
+func (r *auditSubscriptionRepository) Save(ctx context.Context, subscription *models.AuditSubscription) error {
+	err := r.db.WithContext(ctx).
+		Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "admin_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"events", "min_severity", "email", "panel", "updated_at"}),
+			},
+			clause.Returning{},
+		).
+		Create(subscription).Error
+	if err != nil {
+		return fmt.Errorf("failed to save audit subscription: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an admin's subscription; deleting a missing one is not an error
+func (r *auditSubscriptionRepository) Delete(ctx context.Context, adminID uuid.UUID) error {
+	err := r.db.WithContext(ctx).
+		Where("admin_id = ?", adminID).
+		Delete(&models.AuditSubscription{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete audit subscription: %w", err)
+	}
+	return nil
+}
+
+// List returns every admin's subscription
+func (r *auditSubscriptionRepository) List(ctx context.Context) ([]models.AuditSubscription, error) {
+	var subscriptions []models.AuditSubscription
+	if err := r.db.WithContext(ctx).Order("admin_id").Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list audit subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"user_mgmt_go/internal/mailer"
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// auditSubscriptionsTarget names the checkpoint of the subscription
+// evaluator, stored alongside the log export checkpoints
+const auditSubscriptionsTarget = "audit_subscriptions"
+
+// auditSettleDelay holds back the most recent entries, which async writes
+// may still be adding with slightly earlier timestamps
+const auditSettleDelay = 15 * time.Second
+
+// auditDigestTimeout bounds sending one subscription digest
+const auditDigestTimeout = 30 * time.Second
+
+// NotifyAuditSubscribers delivers the audit entries written since the last
+// run to the admins subscribed to them: into their panel inbox as each batch
+// is read, and by email as one digest per admin at the end of the run.
+// Panel notifications are delivered at least once and deduplicated; digests
+// at most once, and also when a later batch of the run fails. Users who are
+// no longer admins are skipped. It runs on the scheduler every
+// audit_subscriptions interval.
+func (rm *RepositoryManager) NotifyAuditSubscribers(ctx context.Context) error {
+	checkpoint, err := rm.Repos.LogExport.GetCheckpoint(ctx, auditSubscriptionsTarget)
+	if err != nil {
+		return err
+	}
+	if checkpoint == nil {
+		// Start from now rather than notifying admins of the whole history
+		return rm.Repos.LogExport.SaveCheckpoint(ctx, &models.LogExportCheckpoint{
+			Target:        auditSubscriptionsTarget,
+			LastTimestamp: time.Now().Add(-auditSettleDelay),
+			UpdatedAt:     time.Now(),
+		})
+	}
+
+	subscriptions, admins, err := rm.activeAuditSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	digests := make(map[uuid.UUID][]models.UserLog)
+	err = rm.deliverAuditBatches(ctx, checkpoint, subscriptions, digests)
+
+	// The batches read so far are checkpointed and will not be read again,
+	// so their digests go out even when a later batch failed
+	if sendErr := rm.sendAuditDigests(ctx, admins, digests); err == nil {
+		err = sendErr
+	}
+	return err
+}
+
+// deliverAuditBatches reads the entries after the checkpoint batch by batch,
+// creating the panel notifications and saving the checkpoint after each one.
+// Email matches are added to digests once their batch is checkpointed.
+func (rm *RepositoryManager) deliverAuditBatches(ctx context.Context, checkpoint *models.LogExportCheckpoint, subscriptions []models.AuditSubscription, digests map[uuid.UUID][]models.UserLog) error {
+	cfg := rm.config.AuditSubscriptions
+
+	// Fixed for the run, so the run ends even while new entries keep arriving
+	before := time.Now().Add(-auditSettleDelay)
+
+	for batch := 0; batch < cfg.MaxBatches; batch++ {
+		logs, err := rm.Repos.Log.ListForExport(ctx, checkpoint.LastTimestamp, checkpoint.LastLogID, before, cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+
+		var notifications []models.AdminNotification
+		batchDigests := make(map[uuid.UUID][]models.UserLog)
+		for _, entry := range logs {
+			// Per-request entries of the request logging middleware are not audit events
+			if entry.Data.Action == "HTTP_REQUEST" {
+				continue
+			}
+			severity := models.AuditSeverity(entry)
+			for _, subscription := range subscriptions {
+				if !subscription.Matches(entry, severity) {
+					continue
+				}
+				if subscription.Panel {
+					notifications = append(notifications, models.NewAdminNotification(subscription.AdminID, entry, severity))
+				}
+				if subscription.Email && rm.mailer != nil {
+					batchDigests[subscription.AdminID] = append(batchDigests[subscription.AdminID], entry)
+				}
+			}
+		}
+		if err := rm.Repos.AdminNotification.CreateBatch(ctx, notifications); err != nil {
+			return err
+		}
+
+		last := logs[len(logs)-1]
+		checkpoint.LastTimestamp = last.Timestamp
+		checkpoint.LastLogID = last.ID.Hex()
+		checkpoint.ExportedRows += int64(len(logs))
+		checkpoint.UpdatedAt = time.Now()
+		if err := rm.Repos.LogExport.SaveCheckpoint(ctx, checkpoint); err != nil {
+			return err
+		}
+		for adminID, entries := range batchDigests {
+			digests[adminID] = append(digests[adminID], entries...)
+		}
+		if len(logs) < cfg.BatchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// sendAuditDigests emails each admin the entries collected for them
+func (rm *RepositoryManager) sendAuditDigests(ctx context.Context, admins map[uuid.UUID]*models.User, digests map[uuid.UUID][]models.UserLog) error {
+	var failed int
+	for adminID, entries := range digests {
+		sendCtx, cancel := context.WithTimeout(ctx, auditDigestTimeout)
+		err := rm.mailer.Send(sendCtx, mailer.AuditDigest(admins[adminID], entries))
+		cancel()
+		if err != nil {
+			log.Printf("Failed to send audit subscription digest to admin %s: %v", adminID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d of %d audit subscription digests", failed, len(digests))
+	}
+	return nil
+}
+
+// activeAuditSubscriptions returns the subscriptions of users who are still
+// admins, with those admins by ID
+func (rm *RepositoryManager) activeAuditSubscriptions(ctx context.Context) ([]models.AuditSubscription, map[uuid.UUID]*models.User, error) {
+	subscriptions, err := rm.Repos.AuditSubscription.List(ctx)
+	if err != nil || len(subscriptions) == 0 {
+		return nil, nil, err
+	}
+
+	ids := make([]uuid.UUID, len(subscriptions))
+	for i, subscription := range subscriptions {
+		ids[i] = subscription.AdminID
+	}
+	admins, err := rm.Repos.User.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	active := subscriptions[:0]
+	for _, subscription := range subscriptions {
+		if admin := admins[subscription.AdminID]; admin != nil && admin.Role == models.RoleAdmin {
+			active = append(active, subscription)
+		}
+	}
+	return active, admins, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// callBudgetKey marks request contexts whose downstream calls get their own
+// deadlines
+type callBudgetKey struct{}
+
+// WithCallBudgets marks ctx as a request context. Each PostgreSQL statement
+// and MongoDB operation made with it is then limited to the configured
+// per-call timeout within what is left of ctx's deadline, so one slow
+// dependency cannot use up the whole request. Background work leaves its
+// contexts unmarked and is bounded only by their own deadlines.
+func WithCallBudgets(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callBudgetKey{}, true)
+}
+
+// withoutCallBudgets unmarks ctx, for calls made while handling a request
+// that are expected to outlast a per-call budget
+func withoutCallBudgets(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callBudgetKey{}, nil)
+}
+
+// callContext returns ctx limited to budget when it is a marked request
+// context. The deadline is the earlier of the budget and ctx's own.
+func callContext(ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	if budget <= 0 || ctx == nil || ctx.Value(callBudgetKey{}) == nil {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, budget)
+}
+
+// queryBudgetKey stores a statement's budget while its callbacks run
+const queryBudgetKey = "call_budget:query"
+
+// queryBudget is the statement context replaced by a budgeted one
+type queryBudget struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// applyQueryBudget limits every statement made through db with a marked
+// request context to budget. Create, update and delete include the implicit
+// transaction around them. Row and Rows statements are left to the request
+// deadline: their results are read after the callbacks return, so there is
+// no point at which a per-statement deadline could be released.
+func applyQueryBudget(db *gorm.DB, budget time.Duration) error {
+	start := func(db *gorm.DB) {
+		ctx, cancel := callContext(db.Statement.Context, budget)
+		db.InstanceSet(queryBudgetKey, queryBudget{parent: db.Statement.Context, cancel: cancel})
+		db.Statement.Context = ctx
+	}
+	// Restore the parent too, as a chained query reuses its statement
+	finish := func(db *gorm.DB) {
+		if value, ok := db.InstanceGet(queryBudgetKey); ok {
+			spent := value.(queryBudget)
+			spent.cancel()
+			db.Statement.Context = spent.parent
+		}
+	}
+
+	callbacks := db.Callback()
+	err := errors.Join(
+		callbacks.Create().Before("*").Register("call_budget:create_start", start),
+		callbacks.Create().After("*").Register("call_budget:create_finish", finish),
+		callbacks.Query().Before("*").Register("call_budget:query_start", start),
+		callbacks.Query().After("*").Register("call_budget:query_finish", finish),
+		callbacks.Update().Before("*").Register("call_budget:update_start", start),
+		callbacks.Update().After("*").Register("call_budget:update_finish", finish),
+		callbacks.Delete().Before("*").Register("call_budget:delete_start", start),
+		callbacks.Delete().After("*").Register("call_budget:delete_finish", finish),
+		callbacks.Raw().Before("*").Register("call_budget:raw_start", start),
+		callbacks.Raw().After("*").Register("call_budget:raw_finish", finish),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register query budget: %w", err)
+	}
+	return nil
+}
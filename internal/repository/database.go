@@ -8,6 +8,7 @@ import (
 
 	"user_mgmt_go/internal/config"
 	"user_mgmt_go/internal/models"
+	"user_mgmt_go/migrations"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -17,11 +18,24 @@ import (
 	"gorm.io/gorm/logger"
 )
 
+// postgresMaxIdleConns is how many PostgreSQL connections the pool keeps open
+// while idle, and so the most that warmup can usefully pre-establish
+const postgresMaxIdleConns = 10
+
 // Database holds both PostgreSQL and MongoDB connections
 type Database struct {
-	PostgreSQL *gorm.DB
+	PostgreSQL *gorm.DB // Server reads and writes, with reads routed to the read-only user when configured
 	MongoDB    *mongo.Database
 	Config     *config.Config
+
+	// TrigramEnabled reports whether the pg_trgm extension is available for fuzzy search
+	TrigramEnabled bool
+
+	// owner is the schema owner's connection, used for migrations and index
+	// maintenance; it is PostgreSQL unless a read-write user is configured
+	owner *gorm.DB
+	// reader is the read-only user's connection, nil unless configured
+	reader *gorm.DB
 }
 
 // NewDatabase creates a new database instance with both connections
@@ -45,22 +59,95 @@ func NewDatabase(cfg *config.Config) (*Database, error) {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	// Switch to the least-privilege server users once the schema is in place
+	if err := db.connectServerUsers(); err != nil {
+		return nil, fmt.Errorf("failed to connect PostgreSQL server users: %w", err)
+	}
+
 	// Create MongoDB indexes
 	if err := db.createMongoIndexes(); err != nil {
 		return nil, fmt.Errorf("failed to create MongoDB indexes: %w", err)
 	}
 
+	// Migrate log user IDs written in other representations. Entries are
+	// normalized on write, so a failed run only delays the rest to the next start.
+	if err := db.migrateLogUserIDs(); err != nil {
+		log.Printf("⚠️  Warning: Failed to migrate log user IDs, retrying on next start: %v", err)
+	}
+
 	log.Println("✅ Database connections established successfully")
 	return db, nil
 }
 
 // connectPostgreSQL establishes connection to PostgreSQL using GORM
 func (d *Database) connectPostgreSQL() error {
-	dsn := d.Config.GetDatabaseConnectionString()
+	var err error
+	d.owner, err = OpenPostgreSQL(d.Config)
+	d.PostgreSQL = d.owner
+	return err
+}
+
+// connectServerUsers connects as the configured read-write and read-only
+// users, verifying their privileges, and routes reads to the read-only one
+func (d *Database) connectServerUsers() error {
+	cfg := d.Config.Database
+
+	if cfg.ReadWrite.User != "" {
+		writer, err := openPostgreSQLAs(d.Config, cfg.ReadWrite)
+		if err != nil {
+			return fmt.Errorf("read-write user: %w", err)
+		}
+		if cfg.VerifyPrivileges {
+			if err := verifyPrivileges(writer, true); err != nil {
+				closeGormDB(writer)
+				return fmt.Errorf("read-write user %s: %w", cfg.ReadWrite.User, err)
+			}
+		}
+		d.PostgreSQL = writer
+	}
+
+	if cfg.ReadOnly.User != "" {
+		reader, err := openPostgreSQLAs(d.Config, cfg.ReadOnly)
+		if err != nil {
+			return fmt.Errorf("read-only user: %w", err)
+		}
+		if cfg.VerifyPrivileges {
+			if err := verifyPrivileges(reader, false); err != nil {
+				closeGormDB(reader)
+				return fmt.Errorf("read-only user %s: %w", cfg.ReadOnly.User, err)
+			}
+		}
+		if err := routeReads(d.PostgreSQL, reader); err != nil {
+			closeGormDB(reader)
+			return err
+		}
+		d.reader = reader
+		log.Printf("✅ PostgreSQL reads routed to read-only user %s", cfg.ReadOnly.User)
+	}
+
+	// Reads routed to the read-only user go through these callbacks too
+	if cfg.QueryTimeout > 0 {
+		if err := applyQueryBudget(d.PostgreSQL, cfg.QueryTimeout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OpenPostgreSQL opens and verifies a GORM PostgreSQL connection as the
+// schema owner without running migrations
+func OpenPostgreSQL(cfg *config.Config) (*gorm.DB, error) {
+	return openPostgreSQLAs(cfg, config.DatabaseCredentials{User: cfg.Database.User, Password: cfg.Database.Password})
+}
+
+// openPostgreSQLAs opens and verifies a GORM PostgreSQL connection logging in as creds
+func openPostgreSQLAs(cfg *config.Config, creds config.DatabaseCredentials) (*gorm.DB, error) {
+	dsn := cfg.GetDatabaseConnectionStringFor(creds)
 
 	// Configure GORM logger based on environment
 	var gormLogger logger.Interface
-	if d.Config.Server.GinMode == "debug" {
+	if cfg.Server.GinMode == "debug" {
 		gormLogger = logger.Default.LogMode(logger.Info)
 	} else {
 		gormLogger = logger.Default.LogMode(logger.Silent)
@@ -70,42 +157,45 @@ func (d *Database) connectPostgreSQL() error {
 	gormConfig := &gorm.Config{
 		Logger:                 gormLogger,
 		DisableForeignKeyConstraintWhenMigrating: true,
+		// Translate driver errors (unique and foreign key violations) into
+		// gorm sentinels so translateError can classify them
+		TranslateError: true,
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
 	}
 
-	var err error
-	d.PostgreSQL, err = gorm.Open(postgres.Open(dsn), gormConfig)
+	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
 	if err != nil {
-		return fmt.Errorf("failed to open PostgreSQL connection: %w", err)
+		return nil, fmt.Errorf("failed to open PostgreSQL connection: %w", err)
 	}
 
 	// Get underlying sql.DB to configure connection pool
-	sqlDB, err := d.PostgreSQL.DB()
+	sqlDB, err := db.DB()
 	if err != nil {
-		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
 	}
 
 	// Configure connection pool
-	sqlDB.SetMaxIdleConns(10)           // Maximum idle connections
-	sqlDB.SetMaxOpenConns(100)          // Maximum open connections
-	sqlDB.SetConnMaxLifetime(time.Hour) // Connection maximum lifetime
+	sqlDB.SetMaxIdleConns(postgresMaxIdleConns) // Maximum idle connections
+	sqlDB.SetMaxOpenConns(100)                  // Maximum open connections
+	sqlDB.SetConnMaxLifetime(time.Hour)         // Connection maximum lifetime
 
 	// Test the connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := sqlDB.PingContext(ctx); err != nil {
-		return fmt.Errorf("failed to ping PostgreSQL: %w", err)
+		return nil, fmt.Errorf("failed to ping PostgreSQL: %w", err)
 	}
 
-	log.Printf("✅ PostgreSQL connected to: %s:%s/%s", 
-		d.Config.Database.Host, 
-		d.Config.Database.Port, 
-		d.Config.Database.DBName)
+	log.Printf("✅ PostgreSQL connected to: %s:%s/%s as %s", 
+		cfg.Database.Host, 
+		cfg.Database.Port, 
+		cfg.Database.DBName,
+		creds.User)
 
-	return nil
+	return db, nil
 }
 
 // connectMongoDB establishes connection to MongoDB
@@ -143,15 +233,36 @@ func (d *Database) connectMongoDB() error {
 	return nil
 }
 
-// runMigrations runs PostgreSQL migrations
+// runMigrations verifies the schema version and applies pending versioned
+// migrations. In release mode pending migrations are refused so schema
+// changes only happen through `usermgmt migrate up`.
 func (d *Database) runMigrations() error {
-	log.Println("🔄 Running PostgreSQL migrations...")
+	log.Println("🔄 Checking PostgreSQL schema version...")
 
-	// Auto-migrate models
-	if err := d.PostgreSQL.AutoMigrate(
-		&models.User{},
-	); err != nil {
-		return fmt.Errorf("failed to run auto-migration: %w", err)
+	migrator, err := NewMigrator(d.owner, migrations.FS)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	pending, err := migrator.Pending()
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		if d.Config.Server.GinMode == "release" {
+			return fmt.Errorf("%d pending migration(s), run 'usermgmt migrate up' before starting the server", len(pending))
+		}
+
+		log.Printf("🔄 Applying %d pending migration(s)...", len(pending))
+		if _, err := migrator.Up(0); err != nil {
+			return err
+		}
+	}
+
+	version, err := migrator.CurrentVersion()
+	if err != nil {
+		return err
 	}
 
 	// Create custom indexes if needed
@@ -159,20 +270,37 @@ func (d *Database) runMigrations() error {
 		return fmt.Errorf("failed to create PostgreSQL indexes: %w", err)
 	}
 
-	log.Println("✅ PostgreSQL migrations completed")
+	log.Printf("✅ PostgreSQL schema at version %d", version)
 	return nil
 }
 
-// createPostgreSQLIndexes creates custom indexes for PostgreSQL
+// createPostgreSQLIndexes creates optional indexes that depend on database
+// privileges and are therefore not part of the versioned migrations
 func (d *Database) createPostgreSQLIndexes() error {
-	// Create index on email for faster lookups
-	if err := d.PostgreSQL.Exec("CREATE INDEX IF NOT EXISTS idx_users_email ON users(email)").Error; err != nil {
-		return fmt.Errorf("failed to create email index: %w", err)
+	// Trigram indexes are optional: creating the extension requires privileges
+	// the application user may not have, so fall back to plain LIKE search
+	if err := d.createTrigramIndexes(); err != nil {
+		log.Printf("⚠️  Warning: Fuzzy user search disabled: %v", err)
+		d.TrigramEnabled = false
+	} else {
+		d.TrigramEnabled = true
+	}
+
+	return nil
+}
+
+// createTrigramIndexes enables pg_trgm and creates GIN trigram indexes used by user search
+func (d *Database) createTrigramIndexes() error {
+	if err := d.owner.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		return fmt.Errorf("failed to enable pg_trgm extension: %w", err)
 	}
 
-	// Create index on created_at for faster date-based queries
-	if err := d.PostgreSQL.Exec("CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at)").Error; err != nil {
-		return fmt.Errorf("failed to create created_at index: %w", err)
+	if err := d.owner.Exec("CREATE INDEX IF NOT EXISTS idx_users_name_trgm ON users USING gin (LOWER(name) gin_trgm_ops)").Error; err != nil {
+		return fmt.Errorf("failed to create name trigram index: %w", err)
+	}
+
+	if err := d.owner.Exec("CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING gin (LOWER(email) gin_trgm_ops)").Error; err != nil {
+		return fmt.Errorf("failed to create email trigram index: %w", err)
 	}
 
 	return nil
@@ -188,7 +316,62 @@ func (d *Database) createMongoIndexes() error {
 	collection := d.MongoDB.Collection(models.UserLog{}.CollectionName())
 
 	// Create indexes
-	indexes := []mongo.IndexModel{
+	_, err := collection.Indexes().CreateMany(ctx, mongoIndexModels())
+	if err != nil {
+		return fmt.Errorf("failed to create MongoDB indexes: %w", err)
+	}
+
+	log.Println("✅ MongoDB indexes created")
+	return nil
+}
+
+// dataMigrationsCollection records the one-off MongoDB data migrations that
+// have completed, keyed by name
+const dataMigrationsCollection = "data_migrations"
+
+// logUserIDsMigration names the user_id normalization in dataMigrationsCollection
+const logUserIDsMigration = "normalize_log_user_ids"
+
+// migrateLogUserIDs rewrites log entries whose user_id is not a canonical
+// UUID string so user_id filters match them. It runs until it completes
+// once; new entries are normalized as they are written.
+func (d *Database) migrateLogUserIDs() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	migrations := d.MongoDB.Collection(dataMigrationsCollection)
+	err := migrations.FindOne(ctx, bson.M{"_id": logUserIDsMigration}).Err()
+	if err == nil {
+		return nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return fmt.Errorf("failed to check data migrations: %w", err)
+	}
+
+	collection := d.MongoDB.Collection(models.UserLog{}.CollectionName())
+	updated, skipped, err := normalizeLogUserIDs(ctx, collection)
+	if err != nil {
+		return err
+	}
+
+	if updated > 0 || skipped > 0 {
+		log.Printf("✅ Normalized user_id on %d log entries (%d unrecognized left unchanged)", updated, skipped)
+	}
+	_, err = migrations.InsertOne(ctx, bson.M{
+		"_id":          logUserIDsMigration,
+		"completed_at": time.Now(),
+		"updated":      updated,
+		"skipped":      skipped,
+	})
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("failed to record data migration: %w", err)
+	}
+	return nil
+}
+
+// mongoIndexModels returns the indexes of the user_logs collection
+func mongoIndexModels() []mongo.IndexModel {
+	return []mongo.IndexModel{
 		{
 			Keys: bson.D{
 				{Key: "user_id", Value: 1},
@@ -220,27 +403,33 @@ func (d *Database) createMongoIndexes() error {
 			},
 			Options: options.Index().SetName("idx_ip_address").SetSparse(true),
 		},
+		{
+			Keys: bson.D{
+				{Key: "batch_id", Value: 1},
+				{Key: "timestamp", Value: -1},
+			},
+			Options: options.Index().SetName("idx_batch_timestamp").SetSparse(true),
+		},
+		{
+			// Request samples of the admin panel's Traces page
+			Keys: bson.D{
+				{Key: "data.action", Value: 1},
+				{Key: "timestamp", Value: -1},
+			},
+			Options: options.Index().SetName("idx_action_timestamp"),
+		},
 	}
-
-	_, err := collection.Indexes().CreateMany(ctx, indexes)
-	if err != nil {
-		return fmt.Errorf("failed to create MongoDB indexes: %w", err)
-	}
-
-	log.Println("✅ MongoDB indexes created")
-	return nil
 }
 
 // Close closes all database connections
 func (d *Database) Close() error {
-	// Close PostgreSQL connection
-	if d.PostgreSQL != nil {
-		if sqlDB, err := d.PostgreSQL.DB(); err == nil {
-			if err := sqlDB.Close(); err != nil {
-				log.Printf("Error closing PostgreSQL connection: %v", err)
-			}
-		}
+	// Close PostgreSQL connections; PostgreSQL is the owner's connection
+	// unless a read-write user is configured
+	closeGormDB(d.reader)
+	if d.PostgreSQL != d.owner {
+		closeGormDB(d.PostgreSQL)
 	}
+	closeGormDB(d.owner)
 
 	// Close MongoDB connection
 	if d.MongoDB != nil {
@@ -257,20 +446,24 @@ func (d *Database) Close() error {
 	return nil
 }
 
+// closeGormDB closes a GORM connection's pool, if any
+func closeGormDB(db *gorm.DB) {
+	if db == nil {
+		return
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			log.Printf("Error closing PostgreSQL connection: %v", err)
+		}
+	}
+}
+
 // HealthCheck checks the health of both database connections
 func (d *Database) HealthCheck() (bool, bool) {
-	var pgHealthy, mongoHealthy bool
+	var mongoHealthy bool
 
 	// Check PostgreSQL
-	if d.PostgreSQL != nil {
-		if sqlDB, err := d.PostgreSQL.DB(); err == nil {
-			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-			defer cancel()
-			if err := sqlDB.PingContext(ctx); err == nil {
-				pgHealthy = true
-			}
-		}
-	}
+	pgHealthy := d.PostgreSQLHealthy()
 
 	// Check MongoDB
 	if d.MongoDB != nil {
@@ -282,4 +475,18 @@ func (d *Database) HealthCheck() (bool, bool) {
 	}
 
 	return pgHealthy, mongoHealthy
+}
+
+// PostgreSQLHealthy pings PostgreSQL, which is required to serve requests
+func (d *Database) PostgreSQLHealthy() bool {
+	if d == nil || d.PostgreSQL == nil {
+		return false
+	}
+	sqlDB, err := d.PostgreSQL.DB()
+	if err != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return sqlDB.PingContext(ctx) == nil
 } 
\ No newline at end of file
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"user_mgmt_go/internal/models"
+)
+
+// degradationProbeInterval is how long a MongoDB probe result is reused
+const degradationProbeInterval = 10 * time.Second
+
+// mongoProbe caches MongoDB pings, so checking for degradation on every
+// request never waits on MongoDB. A stale result triggers a ping in the
+// background and is returned meanwhile.
+type mongoProbe struct {
+	mu        sync.Mutex
+	failing   bool
+	since     time.Time
+	checkedAt time.Time
+	running   bool
+}
+
+// Degradation returns the subsystems currently running degraded: the log
+// pipeline and MongoDB, the only dependencies the service keeps serving
+// without. It is cheap enough to call on every request.
+func (rm *RepositoryManager) Degradation() models.DegradationStatus {
+	status := models.DegradationStatus{Subsystems: []string{}}
+	add := func(subsystem string, since time.Time) {
+		status.Degraded = true
+		status.Subsystems = append(status.Subsystems, subsystem)
+		if status.Since == nil || since.Before(*status.Since) {
+			status.Since = &since
+		}
+	}
+
+	if pipeline := rm.LogPipelineStatus(); pipeline.Degraded {
+		since := time.Now()
+		if pipeline.DegradedSince != nil {
+			since = *pipeline.DegradedSince
+		}
+		add(models.SubsystemLogPipeline, since)
+	}
+	if failing, since := rm.mongoFailing(); failing {
+		add(models.SubsystemMongoDB, since)
+	}
+	return status
+}
+
+// mongoFailing returns the last MongoDB probe result, starting a new probe
+// when it is stale
+func (rm *RepositoryManager) mongoFailing() (bool, time.Time) {
+	if rm.Database == nil || rm.Database.MongoDB == nil {
+		return false, time.Time{}
+	}
+
+	probe := &rm.mongoProbe
+	probe.mu.Lock()
+	defer probe.mu.Unlock()
+
+	if !probe.running && time.Since(probe.checkedAt) >= degradationProbeInterval {
+		probe.running = true
+		go rm.probeMongo()
+	}
+	return probe.failing, probe.since
+}
+
+// probeMongo pings MongoDB and records the result
+func (rm *RepositoryManager) probeMongo() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err := rm.Database.MongoDB.Client().Ping(ctx, nil)
+
+	probe := &rm.mongoProbe
+	probe.mu.Lock()
+	defer probe.mu.Unlock()
+
+	now := time.Now()
+	if err != nil && !probe.failing {
+		probe.since = now
+	}
+	probe.failing = err != nil
+	probe.checkedAt = now
+	probe.running = false
+}
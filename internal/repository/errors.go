@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+// Generic error kinds. Every typed repository error below wraps one of them
+// (where one applies), and driver errors are translated into them, so
+// handlers can map HTTP statuses with errors.Is instead of inspecting
+// messages
+var (
+	// ErrNotFound is returned when the requested record does not exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrDuplicate is returned when a write violates a unique constraint
+	ErrDuplicate = errors.New("duplicate")
+
+	// ErrConflict is returned when a write conflicts with the record's current state
+	ErrConflict = errors.New("conflict")
+
+	// ErrForeignKey is returned when a write references a record that does not exist
+	ErrForeignKey = errors.New("foreign key violation")
+)
+
+// kindError is a typed repository error that also matches its generic kind
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func (e *kindError) Error() string { return e.msg }
+func (e *kindError) Unwrap() error { return e.kind }
+
+// newError returns a typed error that satisfies errors.Is for kind as well as for itself
+func newError(msg string, kind error) error {
+	return &kindError{msg: msg, kind: kind}
+}
+
+// Typed errors returned by the user repository so callers can distinguish
+// failure modes without matching on error strings
+var (
+	// ErrUserNotFound is returned when no user (active or soft-deleted) has the given ID
+	ErrUserNotFound = newError("user not found", ErrNotFound)
+
+	// ErrUserAlreadyDeleted is returned when deleting a user that is already soft-deleted
+	ErrUserAlreadyDeleted = newError("user already deleted", ErrConflict)
+
+	// ErrSetupNotAvailable is returned when admin setup is attempted but no
+	// setup token is pending (bootstrap mode is not setup_token, or setup is done)
+	ErrSetupNotAvailable = errors.New("admin setup not available")
+
+	// ErrInvalidSetupToken is returned when the provided setup token does not match
+	ErrInvalidSetupToken = errors.New("invalid setup token")
+
+	// ErrSessionNotFound is returned when no session (or no unrevoked one, on revoke) has the given ID
+	ErrSessionNotFound = newError("session not found", ErrNotFound)
+
+	// ErrRoleChangeNotFound is returned when no role change has the given ID
+	ErrRoleChangeNotFound = newError("role change not found", ErrNotFound)
+
+	// ErrRoleChangeConflict is returned when the user already has an open role change
+	ErrRoleChangeConflict = newError("role change already pending for user", ErrConflict)
+
+	// ErrRoleChangeNotPending is returned when reviewing a change that is not awaiting approval
+	ErrRoleChangeNotPending = newError("role change is not awaiting approval", ErrConflict)
+
+	// ErrRoleChangeSelfReview is returned when an admin requests a change of
+	// their own role or reviews a change they requested
+	ErrRoleChangeSelfReview = errors.New("role change requires a different admin")
+
+	// ErrRoleUnchanged is returned when the requested role equals the current role
+	ErrRoleUnchanged = newError("user already has the requested role", ErrConflict)
+
+	// ErrQuotaExceeded is returned when a creation would exceed the principal's quota
+	ErrQuotaExceeded = errors.New("quota exceeded")
+
+	// ErrUserLimitReached is returned when a creation would take the active
+	// users past the configured hard limit
+	ErrUserLimitReached = errors.New("user limit reached")
+
+	// ErrAuthorizationCodeInvalid is returned when an authorization code does
+	// not exist, was already exchanged or has expired
+	ErrAuthorizationCodeInvalid = errors.New("authorization code invalid or expired")
+
+	// ErrRegionNotAllowed is returned when creating a user in a region that is
+	// not configured or not served by this instance
+	ErrRegionNotAllowed = errors.New("region not allowed")
+
+	// ErrEmailDomainNotAllowed is returned when creating an account with an
+	// email domain the registration rules do not allow
+	ErrEmailDomainNotAllowed = errors.New("email domain not allowed")
+
+	// ErrLogFilterPresetNotFound is returned when the admin has no preset with the given ID
+	ErrLogFilterPresetNotFound = newError("log filter preset not found", ErrNotFound)
+
+	// ErrSavedUserSearchNotFound is returned when the admin has no saved user search with the given ID
+	ErrSavedUserSearchNotFound = newError("saved user search not found", ErrNotFound)
+
+	// ErrAdminNotificationNotFound is returned when the admin has no notification with the given ID
+	ErrAdminNotificationNotFound = newError("admin notification not found", ErrNotFound)
+
+	// ErrStatsWidgetNotFound is returned for a stats widget name that does not exist
+	ErrStatsWidgetNotFound = newError("stats widget not found", ErrNotFound)
+
+	// ErrInvalidStatsPeriod is returned when a stats widget is asked for a
+	// period it cannot cover
+	ErrInvalidStatsPeriod = errors.New("invalid stats period")
+
+	// ErrInvalidChangesCursor is returned when a user changes feed cursor was
+	// not issued by the feed
+	ErrInvalidChangesCursor = errors.New("invalid changes cursor")
+
+	// ErrIdentityAlreadyLinked is returned when the external identity is linked
+	// to an account already, or the user already linked one from the provider
+	ErrIdentityAlreadyLinked = newError("identity already linked", ErrDuplicate)
+
+	// ErrIdentityNotFound is returned when the user has no identity from the provider
+	ErrIdentityNotFound = newError("linked identity not found", ErrNotFound)
+
+	// ErrAPIKeyNotFound is returned when an API key does not exist or was
+	// already revoked
+	ErrAPIKeyNotFound = newError("API key not found", ErrNotFound)
+
+	// ErrAPIKeyInactive is returned when rotating a key that is revoked,
+	// expired or already rotated
+	ErrAPIKeyInactive = newError("API key is not active", ErrConflict)
+
+	// ErrLogPipelineBusy is returned when the async log pipeline is degraded
+	// or lacks room for a batch of entries submitted from outside the service
+	ErrLogPipelineBusy = errors.New("log pipeline busy")
+
+	// ErrReportNotFound is returned for a report or report file that is not
+	// configured or not stored
+	ErrReportNotFound = newError("report not found", ErrNotFound)
+
+	// ErrLogNotFound is returned when no log entry has the given ID
+	ErrLogNotFound = newError("log entry not found", ErrNotFound)
+
+	// ErrEmailTaken is returned when creating or updating a user with an
+	// email another user already has
+	ErrEmailTaken = newError("email already exists", ErrDuplicate)
+)
+
+// translateError classifies a driver error by its generic kind, keeping the
+// original error in the chain. Errors of no known kind are returned as is.
+func translateError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, mongo.ErrNoDocuments):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case errors.Is(err, gorm.ErrDuplicatedKey), mongo.IsDuplicateKeyError(err):
+		return fmt.Errorf("%w: %w", ErrDuplicate, err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return fmt.Errorf("%w: %w", ErrForeignKey, err)
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// identityRepository implements IdentityRepository interface
+type identityRepository struct {
+	db *gorm.DB
+}
+
+// NewIdentityRepository creates a new linked identity repository
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+// Create links an identity. An external identity can be linked to one
+// account only, and an account can link one identity per provider.
+func (r *identityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	if err := r.db.WithContext(ctx).Create(identity).Error; err != nil {
+		if err = translateError(err); errors.Is(err, ErrDuplicate) {
+			return fmt.Errorf("%s identity: %w", identity.Provider, ErrIdentityAlreadyLinked)
+		}
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	return nil
+}
+
+// ListForUser returns the identities linked to a user, oldest first
+func (r *identityRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]models.UserIdentity, error) {
+	identities := []models.UserIdentity{}
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&identities).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list linked identities: %w", err)
+	}
+	return identities, nil
+}
+
+// Delete unlinks a user's identity from a provider
+func (r *identityRepository) Delete(ctx context.Context, userID uuid.UUID, provider string) error {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND provider = ?", userID, provider).
+		Delete(&models.UserIdentity{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to unlink identity: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%s identity: %w", provider, ErrIdentityNotFound)
+	}
+	return nil
+}
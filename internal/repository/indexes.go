@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"user_mgmt_go/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// postgresIndex describes an index the application expects in PostgreSQL
+type postgresIndex struct {
+	Name    string
+	Table   string
+	Unique  bool
+	Columns string // Everything after "ON <table>", e.g. "(user_id)" or "USING gin (...)"
+	Trigram bool   // Only expected when pg_trgm is available
+}
+
+// expectedPostgresIndexes lists the indexes created by the migrations and by
+// createPostgreSQLIndexes. Keep it in sync when a migration adds an index.
+var expectedPostgresIndexes = []postgresIndex{
+	{Name: "idx_users_email", Table: "users", Unique: true, Columns: "(email)"},
+	{Name: "idx_users_deleted_at", Table: "users", Columns: "(deleted_at)"},
+	{Name: "idx_users_created_at", Table: "users", Columns: "(created_at)"},
+	{Name: "idx_users_region", Table: "users", Columns: "(region)"},
+	{Name: "idx_users_changed_at", Table: "users", Columns: "((GREATEST(created_at, updated_at, deleted_at)), id)"},
+	{Name: "idx_users_name_trgm", Table: "users", Columns: "USING gin (LOWER(name) gin_trgm_ops)", Trigram: true},
+	{Name: "idx_users_email_trgm", Table: "users", Columns: "USING gin (LOWER(email) gin_trgm_ops)", Trigram: true},
+	{Name: "idx_sessions_user_id", Table: "sessions", Columns: "(user_id)"},
+	{Name: "idx_sessions_active", Table: "sessions", Columns: "(role, last_activity_at) WHERE revoked_at IS NULL"},
+	{Name: "idx_role_changes_user_id", Table: "role_changes", Columns: "(user_id)"},
+	{Name: "idx_role_changes_status", Table: "role_changes", Columns: "(status, effective_at)"},
+	{Name: "idx_quota_usage_window_start", Table: "quota_usage", Columns: "(window_start)"},
+	{Name: "idx_oidc_authorization_codes_expires_at", Table: "oidc_authorization_codes", Columns: "(expires_at)"},
+	{Name: "idx_log_filter_presets_admin_name", Table: "log_filter_presets", Unique: true, Columns: "(admin_id, name)"},
+	{Name: "idx_user_identities_provider_subject", Table: "user_identities", Unique: true, Columns: "(provider, subject)"},
+	{Name: "idx_user_identities_user_provider", Table: "user_identities", Unique: true, Columns: "(user_id, provider)"},
+	{Name: "idx_api_keys_key_hash", Table: "api_keys", Unique: true, Columns: "(key_hash)"},
+	{Name: "idx_saved_user_searches_admin_name", Table: "saved_user_searches", Unique: true, Columns: "(admin_id, name)"},
+	{Name: "idx_api_keys_rotation_due_at", Table: "api_keys", Columns: "(rotation_due_at) WHERE revoked_at IS NULL AND rotated_to IS NULL"},
+	{Name: "idx_admin_notifications_admin_log", Table: "admin_notifications", Unique: true, Columns: "(admin_id, log_id)"},
+	{Name: "idx_admin_notifications_admin_logged_at", Table: "admin_notifications", Columns: "(admin_id, logged_at DESC)"},
+}
+
+// createStatement returns a CREATE INDEX CONCURRENTLY statement, which builds
+// the index without blocking writes to the table
+func (i postgresIndex) createStatement() string {
+	unique := ""
+	if i.Unique {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX CONCURRENTLY IF NOT EXISTS %s ON %s %s", unique, i.Name, i.Table, i.Columns)
+}
+
+// IndexUsage describes an index that has not been used for lookups
+type IndexUsage struct {
+	Name      string `json:"name"`
+	Table     string `json:"table"`
+	Scans     int64  `json:"scans"`
+	SizeBytes int64  `json:"size_bytes,omitempty"`
+}
+
+// IndexStatus reports the indexes of one database
+type IndexStatus struct {
+	Missing []string     `json:"missing"`
+	Invalid []string     `json:"invalid,omitempty"` // Left behind by failed concurrent builds
+	Unused  []IndexUsage `json:"unused"`
+	Built   []string     `json:"built,omitempty"`
+	Errors  []string     `json:"errors,omitempty"`
+}
+
+// IndexReport reports the indexes of both databases
+type IndexReport struct {
+	PostgreSQL IndexStatus `json:"postgresql"`
+	MongoDB    IndexStatus `json:"mongodb"`
+}
+
+// CheckIndexes reports missing, invalid and unused indexes. With build set,
+// missing and invalid PostgreSQL indexes are built concurrently, missing
+// MongoDB indexes are created, and the report reflects the result.
+func (d *Database) CheckIndexes(ctx context.Context, build bool) (*IndexReport, error) {
+	report := &IndexReport{}
+
+	if build {
+		// Index builds may take far longer than an ordinary statement
+		ctx := withoutCallBudgets(ctx)
+
+		built, errs := d.buildPostgreSQLIndexes(ctx)
+		report.PostgreSQL.Built = built
+		report.PostgreSQL.Errors = append(report.PostgreSQL.Errors, errs...)
+
+		built, err := d.buildMongoIndexes(ctx)
+		report.MongoDB.Built = built
+		if err != nil {
+			report.MongoDB.Errors = append(report.MongoDB.Errors, err.Error())
+		}
+	}
+
+	if err := d.checkPostgreSQLIndexes(ctx, &report.PostgreSQL); err != nil {
+		return nil, err
+	}
+	if err := d.checkMongoIndexes(ctx, &report.MongoDB); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// checkPostgreSQLIndexes fills in missing, invalid and unused PostgreSQL indexes
+func (d *Database) checkPostgreSQLIndexes(ctx context.Context, status *IndexStatus) error {
+	var existing []struct {
+		Name  string
+		Valid bool
+	}
+	err := d.owner.WithContext(ctx).Raw(`
+		SELECT c.relname AS name, i.indisvalid AS valid
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = current_schema()`).
+		Scan(&existing).Error
+	if err != nil {
+		return fmt.Errorf("failed to list PostgreSQL indexes: %w", err)
+	}
+
+	present := make(map[string]bool, len(existing))
+	status.Invalid = nil
+	for _, index := range existing {
+		present[index.Name] = true
+		if !index.Valid {
+			status.Invalid = append(status.Invalid, index.Name)
+		}
+	}
+
+	status.Missing = []string{}
+	for _, index := range expectedPostgresIndexes {
+		if index.Trigram && !d.TrigramEnabled {
+			continue
+		}
+		if !present[index.Name] {
+			status.Missing = append(status.Missing, index.Name)
+		}
+	}
+
+	// Unique and primary key indexes enforce constraints, so never report them
+	status.Unused = []IndexUsage{}
+	err = d.owner.WithContext(ctx).Raw(`
+		SELECT s.indexrelname AS name, s.relname AS "table", s.idx_scan AS scans,
+			pg_relation_size(s.indexrelid) AS size_bytes
+		FROM pg_stat_user_indexes s
+		JOIN pg_index i ON i.indexrelid = s.indexrelid
+		WHERE s.idx_scan = 0 AND NOT i.indisunique AND NOT i.indisprimary
+		ORDER BY size_bytes DESC`).
+		Scan(&status.Unused).Error
+	if err != nil {
+		return fmt.Errorf("failed to get PostgreSQL index usage: %w", err)
+	}
+
+	return nil
+}
+
+// buildPostgreSQLIndexes builds missing indexes and rebuilds invalid ones
+// concurrently, then re-runs createPostgreSQLIndexes. Failures are reported
+// per index so one bad index does not stop the others.
+func (d *Database) buildPostgreSQLIndexes(ctx context.Context) ([]string, []string) {
+	var current IndexStatus
+	if err := d.checkPostgreSQLIndexes(ctx, &current); err != nil {
+		return nil, []string{err.Error()}
+	}
+
+	// Trigram indexes need the extension; retry it in case privileges changed
+	if err := d.owner.WithContext(ctx).Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err == nil && !d.TrigramEnabled {
+		d.TrigramEnabled = true
+		if err := d.checkPostgreSQLIndexes(ctx, &current); err != nil {
+			return nil, []string{err.Error()}
+		}
+	}
+
+	invalid := make(map[string]bool, len(current.Invalid))
+	for _, name := range current.Invalid {
+		invalid[name] = true
+	}
+	wanted := make(map[string]bool, len(current.Missing))
+	for _, name := range current.Missing {
+		wanted[name] = true
+	}
+
+	built := []string{}
+	var errs []string
+	for _, index := range expectedPostgresIndexes {
+		if !wanted[index.Name] && !invalid[index.Name] {
+			continue
+		}
+
+		// An invalid index blocks IF NOT EXISTS, so drop it first
+		if invalid[index.Name] {
+			if err := d.owner.WithContext(ctx).Exec("DROP INDEX CONCURRENTLY IF EXISTS " + index.Name).Error; err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", index.Name, err))
+				continue
+			}
+		}
+
+		log.Printf("🔄 Building index %s concurrently...", index.Name)
+		if err := d.owner.WithContext(ctx).Exec(index.createStatement()).Error; err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", index.Name, err))
+			continue
+		}
+		built = append(built, index.Name)
+	}
+
+	if err := d.createPostgreSQLIndexes(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	return built, errs
+}
+
+// checkMongoIndexes fills in missing and unused MongoDB indexes
+func (d *Database) checkMongoIndexes(ctx context.Context, status *IndexStatus) error {
+	collection := d.MongoDB.Collection(models.UserLog{}.CollectionName())
+
+	specs, err := collection.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list MongoDB indexes: %w", err)
+	}
+	present := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		present[spec.Name] = true
+	}
+
+	status.Missing = []string{}
+	for _, model := range mongoIndexModels() {
+		if name := *model.Options.Name; !present[name] {
+			status.Missing = append(status.Missing, name)
+		}
+	}
+
+	// $indexStats needs the clusterMonitor role; report instead of failing
+	status.Unused = []IndexUsage{}
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{{{Key: "$indexStats", Value: bson.M{}}}})
+	if err != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("index usage unavailable: %v", err))
+		return nil
+	}
+	defer cursor.Close(ctx)
+
+	var stats []struct {
+		Name     string `bson:"name"`
+		Accesses struct {
+			Ops int64 `bson:"ops"`
+		} `bson:"accesses"`
+	}
+	if err := cursor.All(ctx, &stats); err != nil {
+		return fmt.Errorf("failed to read MongoDB index usage: %w", err)
+	}
+	for _, stat := range stats {
+		if stat.Name != "_id_" && stat.Accesses.Ops == 0 {
+			status.Unused = append(status.Unused, IndexUsage{
+				Name:  stat.Name,
+				Table: collection.Name(),
+			})
+		}
+	}
+	sort.Slice(status.Unused, func(i, j int) bool { return status.Unused[i].Name < status.Unused[j].Name })
+
+	return nil
+}
+
+// buildMongoIndexes creates missing MongoDB indexes. MongoDB builds indexes
+// without holding an exclusive lock for the duration of the build.
+func (d *Database) buildMongoIndexes(ctx context.Context) ([]string, error) {
+	var current IndexStatus
+	if err := d.checkMongoIndexes(ctx, &current); err != nil {
+		return nil, err
+	}
+
+	missing := make(map[string]bool, len(current.Missing))
+	for _, name := range current.Missing {
+		missing[name] = true
+	}
+
+	var toCreate []mongo.IndexModel
+	for _, model := range mongoIndexModels() {
+		if missing[*model.Options.Name] {
+			toCreate = append(toCreate, model)
+		}
+	}
+	if len(toCreate) == 0 {
+		return []string{}, nil
+	}
+
+	collection := d.MongoDB.Collection(models.UserLog{}.CollectionName())
+	built, err := collection.Indexes().CreateMany(ctx, toCreate)
+	if err != nil {
+		return built, fmt.Errorf("failed to create MongoDB indexes: %w", err)
+	}
+	return built, nil
+}
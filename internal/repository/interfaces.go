@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"user_mgmt_go/internal/models"
 
@@ -13,6 +14,7 @@ type UserRepository interface {
 	// Basic CRUD operations
 	Create(ctx context.Context, user *models.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error
 	Delete(ctx context.Context, id uuid.UUID) error
@@ -27,43 +29,201 @@ type UserRepository interface {
 	
 	// Search and filtering
 	Search(ctx context.Context, query string, params ListParams) (*models.UsersListResponse, error)
+	ListFiltered(ctx context.Context, query string, filter UserFilter, params ListParams) (*models.UsersListResponse, error)
+	SearchByName(ctx context.Context, query string, params ListParams) (*models.UsersListResponse, error)
 	Exists(ctx context.Context, email string) (bool, error)
 	
 	// Admin operations
-	GetAllDeleted(ctx context.Context, params ListParams) (*models.UsersListResponse, error)
+	GetAllDeleted(ctx context.Context, params ListParams, filter DeletedUserFilter) (*models.UsersListResponse, error)
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	RestoreDeleted(ctx context.Context, id uuid.UUID) error
 	PermanentDelete(ctx context.Context, id uuid.UUID) error
+	ListIDsByEmailDomain(ctx context.Context, domain string, createdBefore time.Time, limit int) ([]uuid.UUID, error) // Soft-deleted users included
+
+	// Changes feed
+	ListChangedSince(ctx context.Context, since time.Time, afterID uuid.UUID, limit int) ([]models.UserChange, error)
+
+	// Reporting
+	CountCreatedAndDeletedByDay(ctx context.Context, from time.Time, days int) (created, deleted []int64, err error)
 }
 
 // UserLogRepository defines the interface for logging operations
 type UserLogRepository interface {
 	// Basic log operations
 	Create(ctx context.Context, log *models.UserLog) error
-	CreateAsync(log *models.UserLog) error // Asynchronous logging
+	CreateAsync(log *models.UserLog) error         // Asynchronous logging
+	CreateAsyncBatch(logs []*models.UserLog) error // All or none; ErrLogPipelineBusy when they would not fit
 	GetByID(ctx context.Context, id string) (*models.UserLog, error)
 	
 	// List operations with advanced filtering
 	List(ctx context.Context, filter models.LogFilterRequest) (*models.UserLogsListResponse, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID, params ListParams) (*models.UserLogsListResponse, error)
 	GetByEvent(ctx context.Context, event models.LogEventType, params ListParams) (*models.UserLogsListResponse, error)
+	Export(ctx context.Context, filter models.LogFilterRequest, limit int) ([]models.UserLogResponse, error) // Unpaginated, latest first
 	
 	// Analytics and reporting
 	Count(ctx context.Context, filter models.LogFilterRequest) (int64, error)
 	GetEventStats(ctx context.Context, userID *uuid.UUID, days int) (map[models.LogEventType]int64, error)
+	GetEventCountsByWindow(ctx context.Context, from time.Time, window time.Duration, windows int) (map[models.LogEventType][]int64, error)
+	GetActorEventStats(ctx context.Context, userIDs []uuid.UUID, since time.Time) (map[uuid.UUID]map[models.LogEventType]int64, error)
 	GetUserActivity(ctx context.Context, userID uuid.UUID, days int) ([]models.UserLogResponse, error)
+	GetLatestDeletion(ctx context.Context, userID uuid.UUID) (*models.UserLog, error)
+	GetLatestLifecycleEvent(ctx context.Context, instance string) (*models.UserLog, error)
+	ListPermanentDeletionsSince(ctx context.Context, since time.Time, limit int) ([]models.UserChange, error)
+	ListForExport(ctx context.Context, afterTimestamp time.Time, afterID string, before time.Time, limit int) ([]models.UserLog, error)
+	ListRequestSamples(ctx context.Context, slow time.Duration, userID string, limit int) ([]models.UserLog, error) // Failed or slow requests, latest first
 	
 	// Maintenance operations
 	DeleteOldLogs(ctx context.Context, olderThanDays int) (int64, error)
+	DeleteByUserIDs(ctx context.Context, userIDs []uuid.UUID) (int64, error)
 	BulkCreate(ctx context.Context, logs []*models.UserLog) error
 	
 	// Search operations
 	SearchLogs(ctx context.Context, searchTerm string, filter models.LogFilterRequest) (*models.UserLogsListResponse, error)
+
+	// Pipeline health
+	PipelineStatus() LogPipelineStatus
+}
+
+// LogPipelineStatus describes the async log pipeline. While degraded, entries
+// are spooled to disk instead of MongoDB and replayed once it recovers.
+type LogPipelineStatus struct {
+	Degraded       bool       `json:"degraded"`
+	DegradedSince  *time.Time `json:"degraded_since,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+	QueuedEntries  int        `json:"queued_entries"`
+	SpooledBytes   int64      `json:"spooled_bytes"`
+	DroppedEntries int64      `json:"dropped_entries"`
+}
+
+// JobRepository defines distributed locking and run bookkeeping for scheduled jobs
+type JobRepository interface {
+	TryLock(ctx context.Context, name string) (release func(), acquired bool, err error)
+	LastRun(ctx context.Context, name string) (time.Time, error)
+	RecordRun(ctx context.Context, name string, startedAt time.Time, duration time.Duration, runErr error) error
+}
+
+// LogExportRepository defines checkpoint storage for exporting logs to a warehouse
+type LogExportRepository interface {
+	GetCheckpoint(ctx context.Context, target string) (*models.LogExportCheckpoint, error)
+	SaveCheckpoint(ctx context.Context, checkpoint *models.LogExportCheckpoint) error
+}
+
+// AuditSubscriptionRepository defines storage for the audit events admins subscribed to
+type AuditSubscriptionRepository interface {
+	Get(ctx context.Context, adminID uuid.UUID) (*models.AuditSubscription, error) // nil when the admin has none
+	Save(ctx context.Context, subscription *models.AuditSubscription) error
+	Delete(ctx context.Context, adminID uuid.UUID) error
+	List(ctx context.Context) ([]models.AuditSubscription, error)
+}
+
+// AdminNotificationRepository defines storage for admins' panel notifications
+type AdminNotificationRepository interface {
+	CreateBatch(ctx context.Context, notifications []models.AdminNotification) error // Skips entries already delivered
+	ListForAdmin(ctx context.Context, adminID uuid.UUID, unreadOnly bool, limit int) ([]models.AdminNotification, error)
+	CountUnread(ctx context.Context, adminID uuid.UUID) (int64, error)
+	MarkRead(ctx context.Context, adminID, id uuid.UUID) error
+	MarkAllRead(ctx context.Context, adminID uuid.UUID) (int64, error)
+}
+
+// SessionRepository defines the interface for login session storage
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error)
+	Touch(ctx context.Context, id uuid.UUID, ipAddress string) error
+	ListActive(ctx context.Context, role string) ([]models.Session, error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedBy uuid.UUID) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, revokedBy uuid.UUID) (int64, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// RoleChangeRepository defines the interface for role change requests
+type RoleChangeRepository interface {
+	Create(ctx context.Context, change *models.RoleChange) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.RoleChange, error)
+	GetOpenForUser(ctx context.Context, userID uuid.UUID) (*models.RoleChange, error)
+	List(ctx context.Context, status models.RoleChangeStatus) ([]models.RoleChange, error)
+	Update(ctx context.Context, change *models.RoleChange) error
+	ListDue(ctx context.Context, before time.Time) ([]models.RoleChange, error)
+	Apply(ctx context.Context, change *models.RoleChange) error
+}
+
+// QuotaRepository defines the interface for creation quota counters
+type QuotaRepository interface {
+	Consume(ctx context.Context, principal, resource string, windowStart time.Time, amount, limit int) (int, error)
+	Release(ctx context.Context, principal, resource string, windowStart time.Time, amount int) error
+	GetUsage(ctx context.Context, principal, resource string, windowStart time.Time) (int, error)
+	ListUsage(ctx context.Context, windowStart time.Time) ([]models.QuotaUsage, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// OIDCCodeRepository defines the interface for OpenID Connect authorization codes
+type OIDCCodeRepository interface {
+	Create(ctx context.Context, code *models.OIDCAuthorizationCode) error
+	Consume(ctx context.Context, codeHash string) (*models.OIDCAuthorizationCode, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// LogFilterPresetRepository defines the interface for admins' saved log filters
+type LogFilterPresetRepository interface {
+	ListForAdmin(ctx context.Context, adminID uuid.UUID) ([]models.LogFilterPreset, error)
+	Save(ctx context.Context, preset *models.LogFilterPreset) error
+	Delete(ctx context.Context, adminID, id uuid.UUID) error
+}
+
+// SavedUserSearchRepository defines the interface for admins' saved users list filters
+type SavedUserSearchRepository interface {
+	ListForAdmin(ctx context.Context, adminID uuid.UUID) ([]models.SavedUserSearch, error)
+	GetForAdmin(ctx context.Context, adminID, id uuid.UUID) (*models.SavedUserSearch, error)
+	Save(ctx context.Context, search *models.SavedUserSearch) error
+	Delete(ctx context.Context, adminID, id uuid.UUID) error
+}
+
+// IdentityRepository defines the interface for linked external identities
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *models.UserIdentity) error
+	ListForUser(ctx context.Context, userID uuid.UUID) ([]models.UserIdentity, error)
+	Delete(ctx context.Context, userID uuid.UUID, provider string) error
+}
+
+// ProfileFieldRepository defines the interface for required profile fields
+type ProfileFieldRepository interface {
+	ListRequired(ctx context.Context) ([]models.RequiredProfileField, error)
+	SetRequired(ctx context.Context, fields []string, adminID uuid.UUID) error
+}
+
+// APIKeyRepository defines the interface for API key persistence
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error)
+	GetByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	List(ctx context.Context) ([]models.APIKey, error)
+	Rotate(ctx context.Context, oldID uuid.UUID, replacement *models.APIKey, oldExpiresAt time.Time) error
+	Revoke(ctx context.Context, id uuid.UUID) error
+	SetAllowedIPs(ctx context.Context, id uuid.UUID, allowlist models.IPAllowlist) error
+	Touch(ctx context.Context, id uuid.UUID, ip string) error
+	ListRotationDue(ctx context.Context, before time.Time) ([]models.APIKey, error)
+	MarkRotationReminded(ctx context.Context, id uuid.UUID) error
 }
 
 // Repository aggregates all repository interfaces
 type Repository struct {
-	User UserRepository
-	Log  UserLogRepository
+	User            UserRepository
+	Log             UserLogRepository
+	Job             JobRepository
+	Session         SessionRepository
+	RoleChange      RoleChangeRepository
+	Quota           QuotaRepository
+	OIDCCode        OIDCCodeRepository
+	LogFilterPreset LogFilterPresetRepository
+	SavedUserSearch SavedUserSearchRepository
+	Identity        IdentityRepository
+	ProfileField    ProfileFieldRepository
+	APIKey          APIKeyRepository
+	LogExport       LogExportRepository
+
+	AuditSubscription AuditSubscriptionRepository
+	AdminNotification AdminNotificationRepository
 }
 
 // ListParams defines common pagination and sorting parameters
@@ -78,11 +238,20 @@ type ListParams struct {
 type UserFilter struct {
 	Email     string    `json:"email" form:"email"`
 	Name      string    `json:"name" form:"name"`
+	Role      string    `json:"role" form:"role"`
+	Region    string    `json:"region" form:"region"`
 	CreatedAt *TimeRange `json:"created_at" form:"created_at"`
 	UpdatedAt *TimeRange `json:"updated_at" form:"updated_at"`
 	IsDeleted *bool     `json:"is_deleted" form:"is_deleted"`
 }
 
+// DeletedUserFilter defines filtering options for soft-deleted user queries
+type DeletedUserFilter struct {
+	Search        string     `json:"search" form:"search"` // Case-insensitive match on name or email
+	DeletedAfter  *time.Time `json:"deleted_after" form:"deleted_after"`
+	DeletedBefore *time.Time `json:"deleted_before" form:"deleted_before"`
+}
+
 // TimeRange defines a time range filter
 type TimeRange struct {
 	From *string `json:"from" form:"from"` // ISO 8601 format
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// jobRepository implements JobRepository with PostgreSQL advisory locks
+type jobRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRepository creates a new scheduled job repository
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+// TryLock takes a session-level advisory lock on a dedicated connection.
+// If the instance crashes the connection drops and PostgreSQL releases the
+// lock, letting another instance take over.
+func (r *jobRepository) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get connection for job lock: %w", err)
+	}
+
+	key := advisoryLockKey(name)
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to acquire job lock: %w", err)
+	}
+
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	release := func() {
+		// Closing the connection would also release the lock, but the pool
+		// keeps it open, so unlock explicitly first
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+		conn.Close()
+	}
+
+	return release, true, nil
+}
+
+// LastRun returns when the job last started, or the zero time if it never ran
+func (r *jobRepository) LastRun(ctx context.Context, name string) (time.Time, error) {
+	var lastRun sql.NullTime
+	err := r.db.WithContext(ctx).
+		Raw("SELECT last_run_at FROM scheduled_jobs WHERE name = ?", name).
+		Scan(&lastRun).Error
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get last job run: %w", err)
+	}
+	return lastRun.Time, nil
+}
+
+// RecordRun stores the outcome of a job run
+func (r *jobRepository) RecordRun(ctx context.Context, name string, startedAt time.Time, duration time.Duration, runErr error) error {
+	var lastError *string
+	if runErr != nil {
+		message := runErr.Error()
+		lastError = &message
+	}
+
+	err := r.db.WithContext(ctx).Exec(`
+		INSERT INTO scheduled_jobs (name, last_run_at, last_duration_ms, last_error, updated_at)
+		VALUES (?, ?, ?, ?, now())
+		ON CONFLICT (name) DO UPDATE SET
+			last_run_at = EXCLUDED.last_run_at,
+			last_duration_ms = EXCLUDED.last_duration_ms,
+			last_error = EXCLUDED.last_error,
+			updated_at = now()`,
+		name, startedAt, duration.Milliseconds(), lastError,
+	).Error
+	if err != nil {
+		return fmt.Errorf("failed to record job run: %w", err)
+	}
+	return nil
+}
+
+// advisoryLockKey maps a job name to a stable 64-bit advisory lock key
+func advisoryLockKey(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("scheduler:" + name))
+	return int64(hash.Sum64())
+}
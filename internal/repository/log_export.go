@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/warehouse"
+)
+
+// LogExportResult summarizes one run of the log export
+type LogExportResult struct {
+	Target   string    `json:"target"`
+	Batches  int       `json:"batches"`
+	Rows     int       `json:"rows"`
+	Through  time.Time `json:"through"`   // Timestamp of the last exported log
+	CaughtUp bool      `json:"caught_up"` // False when the run stopped at max_batches
+	Skipped  bool      `json:"skipped"`   // True when the log pipeline was spooling
+}
+
+// ExportLogs ships audit logs to the configured warehouse, oldest first, in
+// batches of batch_size and up to max_batches per run. The checkpoint is
+// saved after each batch, so a re-run resumes after the last stored batch;
+// a batch whose checkpoint was not saved is sent again with the same
+// deduplication token. Runs are skipped while the log pipeline is degraded
+// or replaying its spool. It runs on the scheduler every log_export interval.
+func (rm *RepositoryManager) ExportLogs(ctx context.Context) error {
+	result, err := rm.exportLogs(ctx)
+	if result.Rows > 0 {
+		log.Printf("📤 Exported %d logs in %d batches to %s, through %s", result.Rows, result.Batches, result.Target, result.Through.Format(time.RFC3339))
+	}
+	if result.Skipped {
+		log.Printf("⚠️  Log export to %s skipped until the log pipeline has replayed its spool", result.Target)
+		return nil
+	}
+	if err == nil && !result.CaughtUp {
+		log.Printf("⚠️  Log export to %s is behind; the remaining logs will be sent on the next runs", result.Target)
+	}
+	return err
+}
+
+// exportLogs exports batches until the logs older than the settle delay are
+// all sent or max_batches is reached
+func (rm *RepositoryManager) exportLogs(ctx context.Context) (LogExportResult, error) {
+	cfg := rm.config.LogExport
+	if rm.warehouse == nil {
+		return LogExportResult{}, fmt.Errorf("log export is not enabled")
+	}
+	result := LogExportResult{Target: rm.warehouse.Target(cfg.Table)}
+
+	// Spooled entries are replayed with their original timestamps, behind
+	// where the checkpoint would have moved, so wait until they are stored
+	if status := rm.Repos.Log.PipelineStatus(); status.Degraded || status.SpooledBytes > 0 {
+		result.Skipped = true
+		return result, nil
+	}
+
+	if err := rm.warehouse.EnsureTable(ctx, cfg.Table); err != nil {
+		return result, fmt.Errorf("failed to create export table: %w", err)
+	}
+
+	checkpoint, err := rm.Repos.LogExport.GetCheckpoint(ctx, result.Target)
+	if err != nil {
+		return result, err
+	}
+	if checkpoint == nil {
+		checkpoint = &models.LogExportCheckpoint{Target: result.Target}
+	}
+	result.Through = checkpoint.LastTimestamp
+
+	// Fixed for the run, so the run ends even while new logs keep arriving
+	before := time.Now().Add(-cfg.SettleDelay)
+
+	for result.Batches < cfg.MaxBatches {
+		logs, err := rm.Repos.Log.ListForExport(ctx, checkpoint.LastTimestamp, checkpoint.LastLogID, before, cfg.BatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(logs) == 0 {
+			result.CaughtUp = true
+			return result, nil
+		}
+
+		rows := make([]warehouse.Row, len(logs))
+		for i, entry := range logs {
+			rows[i] = warehouse.NewRow(entry)
+		}
+		if err := rm.warehouse.Insert(ctx, cfg.Table, exportBatchToken(result.Target, rows), rows); err != nil {
+			return result, fmt.Errorf("failed to insert batch after %s: %w", checkpoint.LastTimestamp.Format(time.RFC3339Nano), err)
+		}
+
+		last := logs[len(logs)-1]
+		checkpoint.LastTimestamp = last.Timestamp
+		checkpoint.LastLogID = last.ID.Hex()
+		checkpoint.ExportedRows += int64(len(logs))
+		checkpoint.UpdatedAt = time.Now()
+		if err := rm.Repos.LogExport.SaveCheckpoint(ctx, checkpoint); err != nil {
+			return result, err
+		}
+
+		result.Batches++
+		result.Rows += len(logs)
+		result.Through = last.Timestamp
+		if len(logs) < cfg.BatchSize {
+			result.CaughtUp = true
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// exportBatchToken identifies a batch by its target and the IDs of its first
+// and last rows, so the same batch sent again gets the same token
+func exportBatchToken(target string, rows []warehouse.Row) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%d", target, rows[0].ID, rows[len(rows)-1].ID, len(rows))))
+	return hex.EncodeToString(sum[:16])
+}
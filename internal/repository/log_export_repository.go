@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"user_mgmt_go/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// logExportRepository implements LogExportRepository interface
+type logExportRepository struct {
+	db *gorm.DB
+}
+
+// NewLogExportRepository creates a new log export checkpoint repository
+func NewLogExportRepository(db *gorm.DB) LogExportRepository {
+	return &logExportRepository{db: db}
+}
+
+// GetCheckpoint returns the checkpoint of an export target, or nil if
+// nothing has been exported to it yet
+func (r *logExportRepository) GetCheckpoint(ctx context.Context, target string) (*models.LogExportCheckpoint, error) {
+	var checkpoint models.LogExportCheckpoint
+	err := r.db.WithContext(ctx).Where("target = ?", target).First(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log export checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// SaveCheckpoint stores the checkpoint of an export target, replacing the previous one
+func (r *logExportRepository) SaveCheckpoint(ctx context.Context, checkpoint *models.LogExportCheckpoint) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "target"}},
+			DoUpdates: clause.AssignmentColumns([]string{"last_timestamp", "last_log_id", "exported_rows", "updated_at"}),
+		}).
+		Create(checkpoint).Error
+	if err != nil {
+		return fmt.Errorf("failed to save log export checkpoint: %w", err)
+	}
+	return nil
+}
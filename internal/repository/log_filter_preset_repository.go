@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// logFilterPresetRepository implements LogFilterPresetRepository interface
+type logFilterPresetRepository struct {
+	db *gorm.DB
+}
+
+// NewLogFilterPresetRepository creates a new log filter preset repository
+func NewLogFilterPresetRepository(db *gorm.DB) LogFilterPresetRepository {
+	return &logFilterPresetRepository{db: db}
+}
+
+// ListForAdmin returns an admin's presets ordered by name
+func (r *logFilterPresetRepository) ListForAdmin(ctx context.Context, adminID uuid.UUID) ([]models.LogFilterPreset, error) {
+	var presets []models.LogFilterPreset
+	err := r.db.WithContext(ctx).
+		Where("admin_id = ?", adminID).
+		Order("name").
+		Find(&presets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log filter presets: %w", err)
+	}
+	return presets, nil
+}
+
+// Save stores a preset, replacing the admin's preset with the same name
+func (r *logFilterPresetRepository) Save(ctx context.Context, preset *models.LogFilterPreset) error {
+	err := r.db.WithContext(ctx).
+		Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "admin_id"}, {Name: "name"}},
+				DoUpdates: clause.AssignmentColumns([]string{"event", "user_id", "start_date", "end_date", "updated_at"}),
+			},
+			clause.Returning{},
+		).
+		Create(preset).Error
+	if err != nil {
+		return fmt.Errorf("failed to save log filter preset: %w", err)
+	}
+	return nil
+}
+
+// Delete removes one of an admin's presets
+func (r *logFilterPresetRepository) Delete(ctx context.Context, adminID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND admin_id = ?", id, adminID).
+		Delete(&models.LogFilterPreset{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete log filter preset: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("log filter preset %s: %w", id, ErrLogFilterPresetNotFound)
+	}
+	return nil
+}
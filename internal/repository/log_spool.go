@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"user_mgmt_go/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// spoolReplayBatchSize is the number of spooled entries written to MongoDB per insert
+const spoolReplayBatchSize = 100
+
+// logSpool is an append-only disk buffer for log entries that could not be
+// written to MongoDB. Entries are stored one per line as canonical extended
+// JSON so BSON types (ObjectIDs, dates) survive the round trip.
+type logSpool struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	size     int64
+	dropped  int64
+}
+
+// newLogSpool opens (or creates) the spool at path. Entries are dropped once
+// the spool holds maxBytes; maxBytes <= 0 means unlimited.
+func newLogSpool(path string, maxBytes int64) (*logSpool, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+
+	spool := &logSpool{
+		path:     path,
+		maxBytes: maxBytes,
+	}
+
+	// Account for entries left over from a previous run or interrupted replay
+	for _, p := range []string{path, spool.replayPath()} {
+		if info, err := os.Stat(p); err == nil {
+			spool.size += info.Size()
+		}
+	}
+
+	return spool, nil
+}
+
+// Append writes entries to the end of the spool
+func (s *logSpool) Append(logs []*models.UserLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log spool: %w", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, logEntry := range logs {
+		line, err := bson.MarshalExtJSON(logEntry, true, false)
+		if err != nil {
+			return fmt.Errorf("failed to encode spooled log: %w", err)
+		}
+		if s.maxBytes > 0 && s.size+int64(len(line))+1 > s.maxBytes {
+			s.dropped++
+			continue
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
+		s.size += int64(len(line)) + 1
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write log spool: %w", err)
+	}
+	return nil
+}
+
+// Size returns the number of bytes waiting to be replayed
+func (s *logSpool) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Dropped returns the number of entries discarded because the spool was full
+func (s *logSpool) Dropped() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Replay hands spooled entries to write in batches. The spool is moved aside
+// first so Append is never blocked by a slow replay. If write fails, the
+// unwritten entries stay in the replay file and are retried next time.
+func (s *logSpool) Replay(write func([]*models.UserLog) error) error {
+	s.mu.Lock()
+	if _, err := os.Stat(s.replayPath()); errors.Is(err, os.ErrNotExist) {
+		if err := os.Rename(s.path, s.replayPath()); err != nil {
+			s.mu.Unlock()
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return fmt.Errorf("failed to rotate log spool: %w", err)
+		}
+	}
+	s.mu.Unlock()
+
+	file, err := os.Open(s.replayPath())
+	if err != nil {
+		return fmt.Errorf("failed to open log spool for replay: %w", err)
+	}
+
+	var written int64
+	batch := make([]*models.UserLog, 0, spoolReplayBatchSize)
+	batchBytes := int64(0)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := write(batch); err != nil {
+			return err
+		}
+		written += batchBytes
+		batch = batch[:0]
+		batchBytes = 0
+		return nil
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		batchBytes += int64(len(line)) + 1
+
+		var logEntry models.UserLog
+		if err := bson.UnmarshalExtJSON(line, true, &logEntry); err != nil {
+			// Skip corrupt lines (e.g. a write cut short by a crash)
+			continue
+		}
+		batch = append(batch, &logEntry)
+
+		if len(batch) >= spoolReplayBatchSize {
+			if err := flush(); err != nil {
+				file.Close()
+				return s.keepUnwritten(written, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return s.keepUnwritten(written, fmt.Errorf("failed to read log spool: %w", err))
+	}
+	if err := flush(); err != nil {
+		file.Close()
+		return s.keepUnwritten(written, err)
+	}
+	file.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.replayPath()); err != nil {
+		return fmt.Errorf("failed to remove replayed log spool: %w", err)
+	}
+	s.size -= written
+	if s.size < 0 {
+		s.size = 0
+	}
+	return nil
+}
+
+// keepUnwritten truncates the already written prefix from the replay file so
+// a later replay resumes where this one stopped
+func (s *logSpool) keepUnwritten(written int64, cause error) error {
+	if written == 0 {
+		return cause
+	}
+
+	content, err := os.ReadFile(s.replayPath())
+	if err != nil {
+		return cause
+	}
+	if err := os.WriteFile(s.replayPath(), content[written:], 0600); err != nil {
+		return cause
+	}
+
+	s.mu.Lock()
+	s.size -= written
+	s.mu.Unlock()
+	return cause
+}
+
+// replayPath is where the spool is moved while being replayed
+func (s *logSpool) replayPath() string {
+	return s.path + ".replay"
+}
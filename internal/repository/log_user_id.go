@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Log entries store user_id as the canonical lowercase, hyphenated UUID
+// string. Every write and every user_id filter goes through the helpers in
+// this file so stored values and queries always use the same representation.
+
+// canonicalUUIDPattern matches user_id values already in canonical form
+const canonicalUUIDPattern = `^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`
+
+// logUserIDValue returns the stored representation of a user ID
+func logUserIDValue(id uuid.UUID) string {
+	return id.String()
+}
+
+// normalizeLogUserID rewrites the entry's user_id into canonical form.
+// Values that are not UUIDs are kept as they are.
+func normalizeLogUserID(logEntry *models.UserLog) {
+	if logEntry.UserID == nil {
+		return
+	}
+	if id, err := uuid.Parse(*logEntry.UserID); err == nil {
+		canonical := logUserIDValue(id)
+		logEntry.UserID = &canonical
+	}
+}
+
+// parseStoredLogUserID converts a stored user_id of any representation written
+// by older versions or other tools (uppercase, braces, URN or BSON binary UUID)
+func parseStoredLogUserID(value interface{}) (uuid.UUID, bool) {
+	switch v := value.(type) {
+	case string:
+		id, err := uuid.Parse(v)
+		return id, err == nil
+	case primitive.Binary:
+		if len(v.Data) != 16 {
+			return uuid.Nil, false
+		}
+		id, err := uuid.FromBytes(v.Data)
+		return id, err == nil
+	default:
+		return uuid.Nil, false
+	}
+}
+
+// normalizeLogUserIDs migrates existing log documents whose user_id is not in
+// canonical form. It only touches non-canonical documents, so a run that was
+// interrupted can be repeated. Returns the number of documents updated and
+// skipped.
+func normalizeLogUserIDs(ctx context.Context, collection *mongo.Collection) (int64, int64, error) {
+	filter := bson.M{
+		"user_id": bson.M{
+			"$exists": true,
+			"$not":    primitive.Regex{Pattern: canonicalUUIDPattern},
+		},
+	}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to find non-canonical log user IDs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var updated, skipped int64
+	var writes []mongo.WriteModel
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+		result, err := collection.BulkWrite(ctx, writes)
+		if err != nil {
+			return fmt.Errorf("failed to normalize log user IDs: %w", err)
+		}
+		updated += result.ModifiedCount
+		writes = writes[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID     primitive.ObjectID `bson:"_id"`
+			UserID interface{}        `bson:"user_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return updated, skipped, fmt.Errorf("failed to decode log entry: %w", err)
+		}
+
+		id, ok := parseStoredLogUserID(doc.UserID)
+		if !ok {
+			log.Printf("⚠️  Log entry %s has unrecognized user_id %v, leaving it unchanged", doc.ID.Hex(), doc.UserID)
+			skipped++
+			continue
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"user_id": logUserIDValue(id)}}))
+		if len(writes) >= 500 {
+			if err := flush(); err != nil {
+				return updated, skipped, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return updated, skipped, fmt.Errorf("failed to iterate log entries: %w", err)
+	}
+
+	if err := flush(); err != nil {
+		return updated, skipped, err
+	}
+	return updated, skipped, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"user_mgmt_go/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// oidcCodeRepository implements OIDCCodeRepository interface
+type oidcCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewOIDCCodeRepository creates a new authorization code repository
+func NewOIDCCodeRepository(db *gorm.DB) OIDCCodeRepository {
+	return &oidcCodeRepository{db: db}
+}
+
+// Create stores a new authorization code
+func (r *oidcCodeRepository) Create(ctx context.Context, code *models.OIDCAuthorizationCode) error {
+	if err := r.db.WithContext(ctx).Create(code).Error; err != nil {
+		return fmt.Errorf("failed to create authorization code: %w", err)
+	}
+	return nil
+}
+
+// Consume deletes and returns an unexpired authorization code. Deleting and
+// reading in one statement ensures a code is exchanged at most once even when
+// requests race.
+func (r *oidcCodeRepository) Consume(ctx context.Context, codeHash string) (*models.OIDCAuthorizationCode, error) {
+	var codes []models.OIDCAuthorizationCode
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("code_hash = ?", codeHash).
+		Delete(&codes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+	if len(codes) == 0 || time.Now().After(codes[0].ExpiresAt) {
+		return nil, ErrAuthorizationCodeInvalid
+	}
+	return &codes[0], nil
+}
+
+// DeleteExpired removes authorization codes that expired before the given time
+func (r *oidcCodeRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&models.OIDCAuthorizationCode{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired authorization codes: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// routeReads sends plain SELECTs made through writer's shared pool to
+// reader. Queries in a transaction or on a pinned connection, locking reads
+// and raw statements that are not SELECTs stay on writer.
+func routeReads(writer, reader *gorm.DB) error {
+	writerPool := writer.ConnPool
+	readerPool := reader.ConnPool
+
+	route := func(db *gorm.DB) {
+		if db.Statement.ConnPool == writerPool && isPlainRead(db.Statement) {
+			db.Statement.ConnPool = readerPool
+		}
+	}
+
+	if err := writer.Callback().Query().Before("gorm:query").Register("read_routing:query", route); err != nil {
+		return fmt.Errorf("failed to register read routing: %w", err)
+	}
+	if err := writer.Callback().Row().Before("gorm:row").Register("read_routing:row", route); err != nil {
+		return fmt.Errorf("failed to register read routing: %w", err)
+	}
+	return nil
+}
+
+// isPlainRead reports whether a query statement only reads. Builder queries
+// have no SQL yet and are SELECTs; raw ones must start with SELECT, as a
+// data-modifying statement with RETURNING also runs as a query.
+func isPlainRead(stmt *gorm.Statement) bool {
+	if _, locking := stmt.Clauses[clause.Locking{}.Name()]; locking {
+		return false
+	}
+	sql := strings.TrimSpace(stmt.SQL.String())
+	return sql == "" || (len(sql) >= 6 && strings.EqualFold(sql[:6], "SELECT"))
+}
+
+// tablePrivileges holds the current user's privileges on one table
+type tablePrivileges struct {
+	Name        string
+	CanSelect   bool
+	CanInsert   bool
+	CanUpdate   bool
+	CanDelete   bool
+	CanTruncate bool
+}
+
+// verifyPrivileges checks that db's user can read every application table,
+// and write them when write is set, and can do nothing more: it must not be
+// a superuser, create roles or databases, own tables, create objects in the
+// schema, truncate tables or, when read-only, modify them.
+func verifyPrivileges(db *gorm.DB, write bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db = db.WithContext(ctx)
+
+	var problems []string
+
+	var role struct {
+		Super      bool
+		CreateRole bool
+		CreateDB   bool
+	}
+	err := db.Raw(`SELECT rolsuper AS super, rolcreaterole AS create_role, rolcreatedb AS create_db
+		FROM pg_roles WHERE rolname = current_user`).Scan(&role).Error
+	if err != nil {
+		return fmt.Errorf("failed to read role attributes: %w", err)
+	}
+	if role.Super {
+		problems = append(problems, "is a superuser")
+	}
+	if role.CreateRole {
+		problems = append(problems, "can create roles")
+	}
+	if role.CreateDB {
+		problems = append(problems, "can create databases")
+	}
+
+	var canCreate bool
+	if err := db.Raw(`SELECT has_schema_privilege(current_schema(), 'CREATE')`).Scan(&canCreate).Error; err != nil {
+		return fmt.Errorf("failed to read schema privileges: %w", err)
+	}
+	if canCreate {
+		problems = append(problems, "can create objects in the schema")
+	}
+
+	var tables []tablePrivileges
+	err = db.Raw(`SELECT c.relname AS name,
+			has_table_privilege(c.oid, 'SELECT') AS can_select,
+			has_table_privilege(c.oid, 'INSERT') AS can_insert,
+			has_table_privilege(c.oid, 'UPDATE') AS can_update,
+			has_table_privilege(c.oid, 'DELETE') AS can_delete,
+			has_table_privilege(c.oid, 'TRUNCATE') AS can_truncate
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = current_schema()
+			AND c.relkind IN ('r', 'p')
+			AND c.relname <> ?
+		ORDER BY c.relname`, schemaMigration{}.TableName()).Scan(&tables).Error
+	if err != nil {
+		return fmt.Errorf("failed to read table privileges: %w", err)
+	}
+	if len(tables) == 0 {
+		problems = append(problems, "sees no application tables")
+	}
+
+	for _, table := range tables {
+		var missing, extra []string
+		check := func(privilege string, has, want bool) {
+			if has && !want {
+				extra = append(extra, privilege)
+			} else if !has && want {
+				missing = append(missing, privilege)
+			}
+		}
+		check("SELECT", table.CanSelect, true)
+		check("INSERT", table.CanInsert, write)
+		check("UPDATE", table.CanUpdate, write)
+		check("DELETE", table.CanDelete, write)
+		check("TRUNCATE", table.CanTruncate, false)
+
+		if len(missing) > 0 {
+			problems = append(problems, fmt.Sprintf("lacks %s on %s", strings.Join(missing, ", "), table.Name))
+		}
+		if len(extra) > 0 {
+			problems = append(problems, fmt.Sprintf("has %s on %s", strings.Join(extra, ", "), table.Name))
+		}
+	}
+
+	var owned []string
+	err = db.Raw(`SELECT tablename FROM pg_tables
+		WHERE schemaname = current_schema() AND tableowner = current_user`).Scan(&owned).Error
+	if err != nil {
+		return fmt.Errorf("failed to read table owners: %w", err)
+	}
+	if len(owned) > 0 {
+		problems = append(problems, fmt.Sprintf("owns %s", strings.Join(owned, ", ")))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("unexpected privileges: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
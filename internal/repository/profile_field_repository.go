@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// profileFieldRepository implements ProfileFieldRepository interface
+type profileFieldRepository struct {
+	db *gorm.DB
+}
+
+// NewProfileFieldRepository creates a new required profile field repository
+func NewProfileFieldRepository(db *gorm.DB) ProfileFieldRepository {
+	return &profileFieldRepository{db: db}
+}
+
+// ListRequired returns the required profile fields ordered by name
+func (r *profileFieldRepository) ListRequired(ctx context.Context) ([]models.RequiredProfileField, error) {
+	fields := []models.RequiredProfileField{}
+	if err := r.db.WithContext(ctx).Order("field").Find(&fields).Error; err != nil {
+		return nil, fmt.Errorf("failed to list required profile fields: %w", err)
+	}
+	return fields, nil
+}
+
+// SetRequired replaces the required profile fields. Fields that stay
+// required keep when and by whom they were first required.
+func (r *profileFieldRepository) SetRequired(ctx context.Context, fields []string, adminID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		remove := tx.Model(&models.RequiredProfileField{})
+		if len(fields) > 0 {
+			remove = remove.Where("field NOT IN ?", fields)
+		} else {
+			remove = remove.Where("1 = 1")
+		}
+		if err := remove.Delete(&models.RequiredProfileField{}).Error; err != nil {
+			return fmt.Errorf("failed to remove required profile fields: %w", err)
+		}
+
+		for _, field := range fields {
+			err := tx.Exec(`
+				INSERT INTO required_profile_fields (field, created_by, created_at)
+				VALUES (?, ?, now())
+				ON CONFLICT (field) DO NOTHING`,
+				field, adminID,
+			).Error
+			if err != nil {
+				return fmt.Errorf("failed to add required profile field: %w", err)
+			}
+		}
+		return nil
+	})
+}
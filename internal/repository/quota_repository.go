@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"user_mgmt_go/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// quotaRepository implements QuotaRepository interface
+type quotaRepository struct {
+	db *gorm.DB
+}
+
+// NewQuotaRepository creates a new quota usage repository
+func NewQuotaRepository(db *gorm.DB) QuotaRepository {
+	return &quotaRepository{db: db}
+}
+
+// Consume adds amount to the principal's usage if it stays within limit. The
+// check and increment happen in one statement so concurrent requests cannot
+// both pass the limit. Returns the usage after consuming.
+func (r *quotaRepository) Consume(ctx context.Context, principal, resource string, windowStart time.Time, amount, limit int) (int, error) {
+	if amount > limit {
+		return 0, fmt.Errorf("%d %s for %s: %w", amount, resource, principal, ErrQuotaExceeded)
+	}
+
+	var used []int
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO quota_usage (principal, resource, window_start, used, updated_at)
+		VALUES (?, ?, ?, ?, now())
+		ON CONFLICT (principal, resource, window_start) DO UPDATE
+		SET used = quota_usage.used + EXCLUDED.used, updated_at = now()
+		WHERE quota_usage.used + EXCLUDED.used <= ?
+		RETURNING used`,
+		principal, resource, windowStart, amount, limit,
+	).Scan(&used).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to consume quota: %w", err)
+	}
+
+	// No row returned means the conflict update was skipped by the limit check
+	if len(used) == 0 {
+		return 0, fmt.Errorf("%d %s for %s: %w", amount, resource, principal, ErrQuotaExceeded)
+	}
+	return used[0], nil
+}
+
+// Release gives back usage that was consumed for resources that were not created
+func (r *quotaRepository) Release(ctx context.Context, principal, resource string, windowStart time.Time, amount int) error {
+	err := r.db.WithContext(ctx).Exec(`
+		UPDATE quota_usage
+		SET used = GREATEST(used - ?, 0), updated_at = now()
+		WHERE principal = ? AND resource = ? AND window_start = ?`,
+		amount, principal, resource, windowStart,
+	).Error
+	if err != nil {
+		return fmt.Errorf("failed to release quota: %w", err)
+	}
+	return nil
+}
+
+// GetUsage returns the principal's usage in a window, 0 if nothing was consumed
+func (r *quotaRepository) GetUsage(ctx context.Context, principal, resource string, windowStart time.Time) (int, error) {
+	var used []int
+	err := r.db.WithContext(ctx).Model(&models.QuotaUsage{}).
+		Where("principal = ? AND resource = ? AND window_start = ?", principal, resource, windowStart).
+		Pluck("used", &used).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get quota usage: %w", err)
+	}
+	if len(used) == 0 {
+		return 0, nil
+	}
+	return used[0], nil
+}
+
+// ListUsage returns all usage counters of a window, highest usage first
+func (r *quotaRepository) ListUsage(ctx context.Context, windowStart time.Time) ([]models.QuotaUsage, error) {
+	var usage []models.QuotaUsage
+	err := r.db.WithContext(ctx).
+		Where("window_start = ?", windowStart).
+		Order("used DESC").
+		Find(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quota usage: %w", err)
+	}
+	return usage, nil
+}
+
+// DeleteBefore removes counters of windows that started before the cutoff
+func (r *quotaRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("window_start < ?", before).
+		Delete(&models.QuotaUsage{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete old quota usage: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
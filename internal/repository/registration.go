@@ -0,0 +1,22 @@
+package repository
+
+import "fmt"
+
+// CheckEmailDomain checks that an account may be created with, or change
+// its email to, email under the configured email domain rules, failing with
+// ErrEmailDomainNotAllowed otherwise. Existing accounts keep working when the
+// rules change.
+func (rm *RepositoryManager) CheckEmailDomain(email string) error {
+	if !rm.config.Registration.IsAllowedEmail(email) {
+		return fmt.Errorf("email %s: %w", email, ErrEmailDomainNotAllowed)
+	}
+	return nil
+}
+
+// CheckRegion checks that a user requesting region can be created on this
+// instance, failing with ErrRegionNotAllowed otherwise. An empty region gets
+// the default region, as on creation.
+func (rm *RepositoryManager) CheckRegion(region string) error {
+	_, err := resolveRegion(rm.config.Residency, region)
+	return err
+}
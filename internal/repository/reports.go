@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/notifier"
+	"user_mgmt_go/internal/reports"
+	"user_mgmt_go/internal/storage"
+)
+
+// GenerateDueReports generates the configured reports not yet stored for
+// the current period and tells operators where to download them. Reports
+// are generated once per period, so it runs on the scheduler at any
+// interval shorter than a week. During maintenance windows, when the
+// announcements would be muted, reports wait for the window to end.
+func (rm *RepositoryManager) GenerateDueReports(ctx context.Context) error {
+	if rm.reportStorage == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if rm.maintenance.InMaintenance(now) {
+		return nil
+	}
+	var failed int
+	for _, schedule := range rm.config.Reports.Schedules {
+		if err := rm.generateReport(ctx, schedule, now); err != nil {
+			log.Printf("Failed to generate report %s: %v", schedule.Name, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to generate %d of %d reports", failed, len(rm.config.Reports.Schedules))
+	}
+	return nil
+}
+
+// generateReport stores the schedule's files missing for the period
+// containing now and delivers them, doing nothing when all are stored
+func (rm *RepositoryManager) generateReport(ctx context.Context, schedule config.ReportSchedule, now time.Time) error {
+	period := reports.PeriodKey(schedule.Every, now)
+
+	var missing []string
+	for _, format := range schedule.Formats {
+		content, _, err := rm.OpenReport(ctx, schedule.Name, reports.FileName(period, format))
+		if errors.Is(err, ErrReportNotFound) {
+			missing = append(missing, format)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		content.Close()
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	widget, err := rm.StatsWidget(ctx, schedule.Widget, schedule.Days)
+	if err != nil {
+		return err
+	}
+
+	files := make([]string, 0, len(schedule.Formats))
+	downloads := make([]string, 0, len(schedule.Formats))
+	for _, format := range missing {
+		content, err := reports.Render(widget, format)
+		if err != nil {
+			return err
+		}
+		file := reports.FileName(period, format)
+		if _, err := rm.reportStorage.Put(ctx, reports.Key(schedule.Name, file), bytes.NewReader(content)); err != nil {
+			return fmt.Errorf("failed to store %s: %w", file, err)
+		}
+		files = append(files, file)
+		downloads = append(downloads, reportURL(schedule.Name, file))
+	}
+	log.Printf("📊 Generated report %s for %s: %v", schedule.Name, period, files)
+
+	alert := notifier.Alert{
+		Kind:     "scheduled_report",
+		Severity: notifier.SeverityInfo,
+		Title:    fmt.Sprintf("Report %s for %s is ready", schedule.Name, period),
+		Message:  fmt.Sprintf("%s for %s is ready to download", widget.Title, period),
+		Details: map[string]interface{}{
+			"report":    schedule.Name,
+			"widget":    schedule.Widget,
+			"period":    period,
+			"files":     files,
+			"downloads": downloads,
+		},
+		Time: now,
+	}
+	err = rm.notifier.Notify(ctx, alert)
+	if errors.Is(err, notifier.ErrMuted) {
+		// A window started meanwhile; drop the files so the report is
+		// generated and announced again once it is over
+		for _, file := range files {
+			if err := rm.reportStorage.Delete(ctx, reports.Key(schedule.Name, file)); err != nil {
+				log.Printf("Failed to drop muted report %s/%s: %v", schedule.Name, file, err)
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		// The files are stored and listed by the reports API; delivery is
+		// not retried so a flaky webhook does not regenerate them
+		log.Printf("Failed to deliver report %s: %v", schedule.Name, err)
+	}
+	return nil
+}
+
+// Reports lists the configured reports with the files stored for their
+// current periods
+func (rm *RepositoryManager) Reports(ctx context.Context) ([]models.Report, error) {
+	now := time.Now()
+	list := make([]models.Report, 0, len(rm.config.Reports.Schedules))
+	for _, schedule := range rm.config.Reports.Schedules {
+		report := models.Report{
+			Name:    schedule.Name,
+			Widget:  schedule.Widget,
+			Every:   schedule.Every,
+			Days:    schedule.Days,
+			Formats: schedule.Formats,
+			Period:  reports.PeriodKey(schedule.Every, now),
+			Files:   []models.ReportFile{},
+		}
+		for _, format := range schedule.Formats {
+			file := reports.FileName(report.Period, format)
+			content, object, err := rm.OpenReport(ctx, schedule.Name, file)
+			if errors.Is(err, ErrReportNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			content.Close()
+			report.Files = append(report.Files, models.ReportFile{
+				Name:        file,
+				Size:        object.Size,
+				GeneratedAt: object.ModTime.UTC(),
+				URL:         reportURL(schedule.Name, file),
+			})
+		}
+		list = append(list, report)
+	}
+	return list, nil
+}
+
+// OpenReport opens a stored report file, named by its period and format as
+// in "2024-W07.csv". It fails with ErrReportNotFound for a report that is
+// not configured or a file that is not stored.
+func (rm *RepositoryManager) OpenReport(ctx context.Context, name, file string) (io.ReadSeekCloser, *storage.Object, error) {
+	if rm.reportStorage == nil || !reports.IsValidFileName(file) {
+		return nil, nil, ErrReportNotFound
+	}
+	if _, ok := rm.config.Reports.Schedule(name); !ok {
+		return nil, nil, ErrReportNotFound
+	}
+
+	content, object, err := rm.reportStorage.Get(ctx, reports.Key(name, file))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, fmt.Errorf("%s/%s: %w", name, file, ErrReportNotFound)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, object, nil
+}
+
+// reportURL returns the download path of a report file
+func reportURL(name, file string) string {
+	return fmt.Sprintf("/api/admin/reports/%s/%s", name, file)
+}
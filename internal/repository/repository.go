@@ -2,13 +2,26 @@ package repository
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/mailer"
+	"user_mgmt_go/internal/maintenance"
 	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/notifier"
+	"user_mgmt_go/internal/storage"
 	"user_mgmt_go/internal/utils"
+	"user_mgmt_go/internal/warehouse"
+
+	"github.com/google/uuid"
 )
 
 // RepositoryManager manages all repositories and database connections
@@ -16,10 +29,45 @@ type RepositoryManager struct {
 	Database *Database
 	Repos    *Repository
 	config   *config.Config
+	notifier notifier.Notifier
+
+	// maintenance holds the scheduled maintenance windows
+	maintenance *maintenance.Schedule
+
+	// warehouse receives exported audit logs; nil unless log export is enabled
+	warehouse warehouse.Warehouse
+
+	// mongoProbe caches MongoDB pings for Degradation
+	mongoProbe mongoProbe
+
+	// mailer sends audit subscription digests; nil unless email is enabled
+	mailer mailer.Mailer
+
+	// reportStorage keeps generated reports; nil unless reports are enabled
+	reportStorage storage.Storage
+
+	// setupTokenHash is the SHA-256 of the pending one-time admin setup token
+	setupMu        sync.Mutex
+	setupTokenHash []byte
 }
 
 // NewRepositoryManager creates a new repository manager with all dependencies
 func NewRepositoryManager(cfg *config.Config) (*RepositoryManager, error) {
+	if err := cfg.Residency.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid residency configuration: %w", err)
+	}
+	if err := cfg.Quota.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid quota configuration: %w", err)
+	}
+	schedule, err := maintenance.NewSchedule(cfg.Maintenance)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance configuration: %w", err)
+	}
+	reportStorage, err := newReportStorage(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize database connections
 	database, err := NewDatabase(cfg)
 	if err != nil {
@@ -27,29 +75,115 @@ func NewRepositoryManager(cfg *config.Config) (*RepositoryManager, error) {
 	}
 
 	// Initialize repositories
-	userRepo := NewUserRepository(database.PostgreSQL)
-	logRepo := NewUserLogRepository(database.MongoDB)
+	searchThreshold := cfg.Search.SimilarityThreshold
+	if !database.TrigramEnabled {
+		searchThreshold = 0
+	}
+	userRepo := NewUserRepository(database.PostgreSQL, searchThreshold, cfg.Residency)
+	logRepo := NewUserLogRepository(database.MongoDB, cfg.Logging.SpoolPath, cfg.Logging.SpoolMaxSize*1024*1024, cfg.MongoDB.OperationTimeout)
 
 	repos := &Repository{
-		User: userRepo,
-		Log:  logRepo,
+		User:            userRepo,
+		Log:             logRepo,
+		Job:             NewJobRepository(database.PostgreSQL),
+		Session:         NewSessionRepository(database.PostgreSQL),
+		RoleChange:      NewRoleChangeRepository(database.PostgreSQL),
+		Quota:           NewQuotaRepository(database.PostgreSQL),
+		OIDCCode:        NewOIDCCodeRepository(database.PostgreSQL),
+		LogFilterPreset: NewLogFilterPresetRepository(database.PostgreSQL),
+		SavedUserSearch: NewSavedUserSearchRepository(database.PostgreSQL),
+		Identity:        NewIdentityRepository(database.PostgreSQL),
+		ProfileField:    NewProfileFieldRepository(database.PostgreSQL),
+		APIKey:          NewAPIKeyRepository(database.PostgreSQL),
+		LogExport:       NewLogExportRepository(database.PostgreSQL),
+
+		AuditSubscription: NewAuditSubscriptionRepository(database.PostgreSQL),
+		AdminNotification: NewAdminNotificationRepository(database.PostgreSQL),
 	}
 
 	manager := &RepositoryManager{
-		Database: database,
-		Repos:    repos,
-		config:   cfg,
+		Database:    database,
+		Repos:       repos,
+		config:      cfg,
+		notifier:    notifier.Muted(notifier.New(cfg.Notifier), schedule.InMaintenance),
+		maintenance: schedule,
+		warehouse:   warehouse.New(cfg.LogExport),
+		mailer:      newMailer(cfg.Email),
+
+		reportStorage: reportStorage,
 	}
 
-	// Create default admin user if it doesn't exist
-	if err := manager.createDefaultAdmin(); err != nil {
-		log.Printf("Warning: Failed to create default admin user: %v", err)
+	// Bootstrap the first admin user according to the configured mode
+	if err := manager.bootstrapAdmin(); err != nil {
+		log.Printf("Warning: Failed to bootstrap admin user: %v", err)
 	}
 
 	log.Println("✅ Repository manager initialized successfully")
 	return manager, nil
 }
 
+// NewRepositoryManagerWithRepos creates a repository manager over the given
+// repositories without connecting to any database, for tools and tests that
+// bring their own implementations. Database is nil, so health checks,
+// warmup and index maintenance are unavailable, and no admin is bootstrapped.
+func NewRepositoryManagerWithRepos(cfg *config.Config, repos *Repository) (*RepositoryManager, error) {
+	schedule, err := maintenance.NewSchedule(cfg.Maintenance)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance configuration: %w", err)
+	}
+	reportStorage, err := newReportStorage(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RepositoryManager{
+		Repos:       repos,
+		config:      cfg,
+		notifier:    notifier.Muted(notifier.New(cfg.Notifier), schedule.InMaintenance),
+		maintenance: schedule,
+		warehouse:   warehouse.New(cfg.LogExport),
+		mailer:      newMailer(cfg.Email),
+
+		reportStorage: reportStorage,
+	}, nil
+}
+
+// newMailer returns an SMTP mailer, or nil when email is disabled
+func newMailer(cfg config.EmailConfig) mailer.Mailer {
+	if !cfg.Enabled {
+		return nil
+	}
+	return mailer.NewSMTPMailer(cfg)
+}
+
+// newReportStorage returns the storage for generated reports, the asset
+// storage directory, or nil when reports are disabled
+func newReportStorage(cfg *config.Config) (storage.Storage, error) {
+	if !cfg.Reports.Enabled {
+		return nil, nil
+	}
+	reportStorage, err := storage.NewLocal(cfg.Storage.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize report storage: %w", err)
+	}
+	return reportStorage, nil
+}
+
+// bootstrapAdmin creates or prepares the first admin user based on admin.bootstrap
+func (rm *RepositoryManager) bootstrapAdmin() error {
+	switch rm.config.Admin.Bootstrap {
+	case "disabled":
+		log.Println("Admin bootstrap disabled, skipping admin creation")
+		return nil
+	case "setup_token":
+		return rm.issueSetupToken()
+	case "", "config":
+		return rm.createDefaultAdmin()
+	default:
+		return fmt.Errorf("unknown admin bootstrap mode %q", rm.config.Admin.Bootstrap)
+	}
+}
+
 // createDefaultAdmin creates the default admin user from config
 func (rm *RepositoryManager) createDefaultAdmin() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -66,21 +200,103 @@ func (rm *RepositoryManager) createDefaultAdmin() error {
 		return nil
 	}
 
+	if _, err := rm.createAdminUser(ctx, rm.config.Admin.Password, rm.config.Admin.ForcePasswordChange, "config"); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Default admin user created: %s", rm.config.Admin.Email)
+	return nil
+}
+
+// issueSetupToken generates a one-time setup token and prints it, unless the
+// admin user already exists
+func (rm *RepositoryManager) issueSetupToken() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	exists, err := rm.Repos.User.Exists(ctx, rm.config.Admin.Email)
+	if err != nil {
+		return fmt.Errorf("failed to check admin user existence: %w", err)
+	}
+
+	if exists {
+		log.Printf("Admin user %s already exists, no setup token issued", rm.config.Admin.Email)
+		return nil
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return fmt.Errorf("failed to generate setup token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+	hash := sha256.Sum256([]byte(token))
+
+	rm.setupMu.Lock()
+	rm.setupTokenHash = hash[:]
+	rm.setupMu.Unlock()
+
+	log.Println("🔑 Admin setup required. One-time setup token (valid until used or restart):")
+	log.Printf("🔑 %s", token)
+	log.Println("🔑 POST it with the admin password to /api/setup/admin to create the admin user")
+	return nil
+}
+
+// Maintenance returns the scheduled maintenance windows
+func (rm *RepositoryManager) Maintenance() *maintenance.Schedule {
+	return rm.maintenance
+}
+
+// SetupPending reports whether a one-time admin setup token is waiting to be used
+func (rm *RepositoryManager) SetupPending() bool {
+	rm.setupMu.Lock()
+	defer rm.setupMu.Unlock()
+	return rm.setupTokenHash != nil
+}
+
+// CompleteAdminSetup exchanges the one-time setup token for the admin user
+// with the given password. The token is consumed on success.
+func (rm *RepositoryManager) CompleteAdminSetup(ctx context.Context, token, password string) (*models.User, error) {
+	rm.setupMu.Lock()
+	defer rm.setupMu.Unlock()
+
+	if rm.setupTokenHash == nil {
+		return nil, ErrSetupNotAvailable
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	if subtle.ConstantTimeCompare(hash[:], rm.setupTokenHash) != 1 {
+		return nil, ErrInvalidSetupToken
+	}
+
+	adminUser, err := rm.createAdminUser(ctx, password, false, "setup_token")
+	if err != nil {
+		return nil, err
+	}
+
+	rm.setupTokenHash = nil
+	log.Printf("✅ Admin user created via setup token: %s", adminUser.Email)
+	return adminUser, nil
+}
+
+// createAdminUser creates the configured admin account and logs it
+func (rm *RepositoryManager) createAdminUser(ctx context.Context, password string, mustChangePassword bool, bootstrap string) (*models.User, error) {
 	// Hash the admin password
-	hashedPassword, err := utils.HashPassword(rm.config.Admin.Password)
+	hashedPassword, err := utils.HashPassword(password)
 	if err != nil {
-		return fmt.Errorf("failed to hash admin password: %w", err)
+		return nil, fmt.Errorf("failed to hash admin password: %w", err)
 	}
 
 	// Create admin user
 	adminUser := &models.User{
-		Name:     "Administrator",
-		Email:    rm.config.Admin.Email,
-		Password: hashedPassword,
+		Name:               "Administrator",
+		Email:              rm.config.Admin.Email,
+		Password:           hashedPassword,
+		MustChangePassword: mustChangePassword,
+		Role:               models.RoleAdmin,
 	}
 
 	if err := rm.Repos.User.Create(ctx, adminUser); err != nil {
-		return fmt.Errorf("failed to create admin user: %w", err)
+		return nil, fmt.Errorf("failed to create admin user: %w", err)
 	}
 
 	// Log the admin user creation
@@ -89,9 +305,11 @@ func (rm *RepositoryManager) createDefaultAdmin() error {
 		Event:  models.UserCreated,
 		Action: "CREATE_ADMIN_USER",
 		Details: map[string]interface{}{
-			"email": adminUser.Email,
-			"name":  adminUser.Name,
-			"role":  "admin",
+			"email":                adminUser.Email,
+			"name":                 adminUser.Name,
+			"role":                 "admin",
+			"bootstrap":            bootstrap,
+			"must_change_password": mustChangePassword,
 		},
 	})
 
@@ -99,10 +317,312 @@ func (rm *RepositoryManager) createDefaultAdmin() error {
 		log.Printf("Failed to log admin user creation: %v", err)
 	}
 
-	log.Printf("✅ Default admin user created: %s", rm.config.Admin.Email)
+	return adminUser, nil
+}
+
+// RequestRoleChange records a change of a user's role. Granting admin waits
+// for a second admin's approval and/or the cooldown when configured; other
+// changes are applied immediately.
+func (rm *RepositoryManager) RequestRoleChange(ctx context.Context, userID uuid.UUID, toRole, reason string, requestedBy uuid.UUID) (*models.RoleChange, error) {
+	if userID == requestedBy {
+		return nil, ErrRoleChangeSelfReview
+	}
+
+	user, err := rm.Repos.User.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == toRole {
+		return nil, ErrRoleUnchanged
+	}
+
+	open, err := rm.Repos.RoleChange.GetOpenForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, ErrRoleChangeConflict
+	}
+
+	now := time.Now()
+	change := &models.RoleChange{
+		UserID:      userID,
+		FromRole:    user.Role,
+		ToRole:      toRole,
+		Reason:      reason,
+		RequestedBy: requestedBy,
+		RequestedAt: now,
+	}
+
+	grantsAdmin := toRole == models.RoleAdmin
+	if grantsAdmin && rm.config.Roles.AdminGrantRequiresApproval {
+		change.Status = models.RoleChangePendingApproval
+	} else {
+		rm.scheduleRoleChange(change, now, grantsAdmin)
+	}
+
+	if err := rm.Repos.RoleChange.Create(ctx, change); err != nil {
+		return nil, err
+	}
+
+	if change.Status == models.RoleChangeScheduled && !change.EffectiveAt.After(now) {
+		if err := rm.applyRoleChange(ctx, change, requestedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	return change, nil
+}
+
+// ApproveRoleChange approves a pending role change. The approver must be a
+// different admin from the requester.
+func (rm *RepositoryManager) ApproveRoleChange(ctx context.Context, id uuid.UUID, approverID uuid.UUID) (*models.RoleChange, error) {
+	change, err := rm.Repos.RoleChange.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if change.Status != models.RoleChangePendingApproval {
+		return nil, ErrRoleChangeNotPending
+	}
+	if approverID == change.RequestedBy || approverID == change.UserID {
+		return nil, ErrRoleChangeSelfReview
+	}
+
+	now := time.Now()
+	change.ReviewedBy = &approverID
+	change.ReviewedAt = &now
+	rm.scheduleRoleChange(change, now, change.ToRole == models.RoleAdmin)
+
+	if err := rm.Repos.RoleChange.Update(ctx, change); err != nil {
+		return nil, err
+	}
+
+	if !change.EffectiveAt.After(now) {
+		if err := rm.applyRoleChange(ctx, change, approverID); err != nil {
+			return nil, err
+		}
+	}
+
+	return change, nil
+}
+
+// RejectRoleChange rejects a pending role change or cancels one that is
+// waiting out its cooldown
+func (rm *RepositoryManager) RejectRoleChange(ctx context.Context, id uuid.UUID, reviewerID uuid.UUID) (*models.RoleChange, error) {
+	change, err := rm.Repos.RoleChange.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !change.IsOpen() {
+		return nil, ErrRoleChangeNotPending
+	}
+	if reviewerID == change.UserID {
+		return nil, ErrRoleChangeSelfReview
+	}
+
+	now := time.Now()
+	change.Status = models.RoleChangeRejected
+	change.ReviewedBy = &reviewerID
+	change.ReviewedAt = &now
+
+	if err := rm.Repos.RoleChange.Update(ctx, change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// ApplyDueRoleChanges applies scheduled role changes whose cooldown has ended
+func (rm *RepositoryManager) ApplyDueRoleChanges(ctx context.Context) error {
+	changes, err := rm.Repos.RoleChange.ListDue(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for i := range changes {
+		actor := changes[i].RequestedBy
+		if changes[i].ReviewedBy != nil {
+			actor = *changes[i].ReviewedBy
+		}
+		if err := rm.applyRoleChange(ctx, &changes[i], actor); err != nil {
+			log.Printf("Failed to apply role change %s: %v", changes[i].ID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to apply %d of %d role changes", failed, len(changes))
+	}
 	return nil
 }
 
+// scheduleRoleChange sets the time a change takes effect; only granting admin
+// is subject to the cooldown
+func (rm *RepositoryManager) scheduleRoleChange(change *models.RoleChange, now time.Time, grantsAdmin bool) {
+	effectiveAt := now
+	if grantsAdmin && rm.config.Roles.AdminGrantCooldown > 0 {
+		effectiveAt = now.Add(rm.config.Roles.AdminGrantCooldown)
+	}
+	change.Status = models.RoleChangeScheduled
+	change.EffectiveAt = &effectiveAt
+}
+
+// applyRoleChange updates the user's role, signs them out everywhere so new
+// tokens carry the new role, and logs the change
+func (rm *RepositoryManager) applyRoleChange(ctx context.Context, change *models.RoleChange, actor uuid.UUID) error {
+	if err := rm.Repos.RoleChange.Apply(ctx, change); err != nil {
+		return err
+	}
+
+	revoked, err := rm.Repos.Session.RevokeAllForUser(ctx, change.UserID, actor)
+	if err != nil {
+		log.Printf("Failed to revoke sessions after role change %s: %v", change.ID, err)
+	}
+
+	logEntry := models.NewUserLog(models.UserLogCreateRequest{
+		Event:  models.RoleChangeEvent,
+		Action: "ROLE_CHANGE_APPLIED",
+		Details: map[string]interface{}{
+			"role_change_id":   change.ID,
+			"target_user_id":   change.UserID,
+			"from_role":        change.FromRole,
+			"to_role":          change.ToRole,
+			"requested_by":     change.RequestedBy,
+			"reviewed_by":      change.ReviewedBy,
+			"revoked_sessions": revoked,
+		},
+	})
+	if err := rm.Repos.Log.CreateAsync(logEntry); err != nil {
+		log.Printf("Failed to log role change: %v", err)
+	}
+
+	log.Printf("🔑 Role of user %s changed from %s to %s", change.UserID, change.FromRole, change.ToRole)
+	return nil
+}
+
+// CheckIndexes verifies the database indexes and optionally builds missing ones
+func (rm *RepositoryManager) CheckIndexes(ctx context.Context, build bool) (*IndexReport, error) {
+	return rm.Database.CheckIndexes(ctx, build)
+}
+
+// ConsumeQuota records that principal is creating amount resources. It fails
+// with ErrQuotaExceeded, consuming nothing, when that would exceed the
+// configured daily limit. A limit of 0 disables the quota.
+func (rm *RepositoryManager) ConsumeQuota(ctx context.Context, principal, resource string, amount int) (models.QuotaStatus, error) {
+	now := time.Now()
+	status := models.QuotaStatus{
+		Principal: principal,
+		Resource:  resource,
+		Limit:     rm.quotaLimit(resource),
+		ResetsAt:  quotaWindowStart(now).AddDate(0, 0, 1),
+	}
+	if status.Limit == 0 {
+		return status, nil
+	}
+
+	used, err := rm.Repos.Quota.Consume(ctx, principal, resource, quotaWindowStart(now), amount, status.Limit)
+	if err != nil {
+		if errors.Is(err, ErrQuotaExceeded) {
+			// Report current usage so the caller knows how much is left
+			if current, usageErr := rm.Repos.Quota.GetUsage(ctx, principal, resource, quotaWindowStart(now)); usageErr == nil {
+				status.Used = current
+				status.Remaining = max(status.Limit-current, 0)
+			}
+		}
+		return status, err
+	}
+
+	status.Used = used
+	status.Remaining = max(status.Limit-used, 0)
+	return status, nil
+}
+
+// PreviewQuota reports the quota status after consuming amount, failing with
+// ErrQuotaExceeded when it does not fit, without consuming anything
+func (rm *RepositoryManager) PreviewQuota(ctx context.Context, principal, resource string, amount int) (models.QuotaStatus, error) {
+	now := time.Now()
+	status := models.QuotaStatus{
+		Principal: principal,
+		Resource:  resource,
+		Limit:     rm.quotaLimit(resource),
+		ResetsAt:  quotaWindowStart(now).AddDate(0, 0, 1),
+	}
+	if status.Limit == 0 {
+		return status, nil
+	}
+
+	used, err := rm.Repos.Quota.GetUsage(ctx, principal, resource, quotaWindowStart(now))
+	if err != nil {
+		return status, err
+	}
+	if used+amount > status.Limit {
+		status.Used = used
+		status.Remaining = max(status.Limit-used, 0)
+		return status, fmt.Errorf("%d %s for %s: %w", amount, resource, principal, ErrQuotaExceeded)
+	}
+
+	status.Used = used + amount
+	status.Remaining = status.Limit - status.Used
+	return status, nil
+}
+
+// ReleaseQuota gives back quota consumed for resources that were not created
+func (rm *RepositoryManager) ReleaseQuota(ctx context.Context, principal, resource string, amount int) {
+	if amount <= 0 || rm.quotaLimit(resource) == 0 {
+		return
+	}
+	if err := rm.Repos.Quota.Release(ctx, principal, resource, quotaWindowStart(time.Now()), amount); err != nil {
+		log.Printf("Failed to release %d %s quota for %s: %v", amount, resource, principal, err)
+	}
+}
+
+// QuotaUsage returns the configured limits and every principal's usage in
+// the current window
+func (rm *RepositoryManager) QuotaUsage(ctx context.Context) (*models.QuotaUsageResponse, error) {
+	now := time.Now()
+	usage, err := rm.Repos.Quota.ListUsage(ctx, quotaWindowStart(now))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.QuotaUsageResponse{
+		Limits: map[string]int{
+			models.QuotaResourceUsers: rm.quotaLimit(models.QuotaResourceUsers),
+		},
+		Usage: make([]models.QuotaStatus, 0, len(usage)),
+	}
+	for _, u := range usage {
+		limit := rm.quotaLimit(u.Resource)
+		status := models.QuotaStatus{
+			Principal: u.Principal,
+			Resource:  u.Resource,
+			Limit:     limit,
+			Used:      u.Used,
+			ResetsAt:  u.WindowStart.AddDate(0, 0, 1),
+		}
+		if limit > 0 {
+			status.Remaining = max(limit-u.Used, 0)
+		}
+		response.Usage = append(response.Usage, status)
+	}
+	return response, nil
+}
+
+// quotaLimit returns the configured daily limit for a resource, 0 if unlimited
+func (rm *RepositoryManager) quotaLimit(resource string) int {
+	switch resource {
+	case models.QuotaResourceUsers:
+		return rm.config.Quota.UsersPerDay
+	default:
+		return 0
+	}
+}
+
+// quotaWindowStart returns the start of the UTC day containing t
+func quotaWindowStart(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
 // Close gracefully shuts down all repository connections
 func (rm *RepositoryManager) Close() error {
 	log.Println("🔄 Shutting down repository manager...")
@@ -132,6 +652,11 @@ func (rm *RepositoryManager) HealthCheck() map[string]bool {
 	}
 }
 
+// LogPipelineStatus returns the state of the async log pipeline
+func (rm *RepositoryManager) LogPipelineStatus() LogPipelineStatus {
+	return rm.Repos.Log.PipelineStatus()
+}
+
 // GetStats returns statistics about the repositories
 func (rm *RepositoryManager) GetStats(ctx context.Context) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
@@ -177,13 +702,40 @@ func (rm *RepositoryManager) RunMaintenance(ctx context.Context) error {
 		log.Printf("Deleted %d old log entries", deletedCount)
 	}
 
+	// Delete sessions that expired or were revoked over a day ago
+	deletedSessions, err := rm.Repos.Session.DeleteExpired(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		log.Printf("Failed to delete expired sessions: %v", err)
+	} else {
+		log.Printf("Deleted %d expired sessions", deletedSessions)
+	}
+
+	// Delete quota counters of windows that ended over a week ago
+	deletedQuotaUsage, err := rm.Repos.Quota.DeleteBefore(ctx, quotaWindowStart(time.Now()).AddDate(0, 0, -7))
+	if err != nil {
+		log.Printf("Failed to delete old quota usage: %v", err)
+	} else {
+		log.Printf("Deleted %d old quota counters", deletedQuotaUsage)
+	}
+
+	// Delete authorization codes that were never exchanged
+	deletedCodes, err := rm.Repos.OIDCCode.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		log.Printf("Failed to delete expired authorization codes: %v", err)
+	} else {
+		log.Printf("Deleted %d expired authorization codes", deletedCodes)
+	}
+
 	// Log maintenance completion
 	logEntry := models.NewUserLog(models.UserLogCreateRequest{
 		Event:  models.SystemError, // Using SystemError as maintenance event
 		Action: "SYSTEM_MAINTENANCE",
 		Details: map[string]interface{}{
-			"deleted_logs": deletedCount,
-			"timestamp":    time.Now(),
+			"deleted_logs":        deletedCount,
+			"deleted_sessions":    deletedSessions,
+			"deleted_quota_usage": deletedQuotaUsage,
+			"deleted_oidc_codes":  deletedCodes,
+			"timestamp":           time.Now(),
 		},
 	})
 
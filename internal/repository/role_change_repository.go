@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// roleChangeRepository implements RoleChangeRepository interface
+type roleChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleChangeRepository creates a new role change repository
+func NewRoleChangeRepository(db *gorm.DB) RoleChangeRepository {
+	return &roleChangeRepository{db: db}
+}
+
+// Create stores a new role change
+func (r *roleChangeRepository) Create(ctx context.Context, change *models.RoleChange) error {
+	if err := r.db.WithContext(ctx).Create(change).Error; err != nil {
+		return fmt.Errorf("failed to create role change: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a role change by ID
+func (r *roleChangeRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.RoleChange, error) {
+	var change models.RoleChange
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&change).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("role change with ID %s: %w", id, ErrRoleChangeNotFound)
+		}
+		return nil, fmt.Errorf("failed to get role change: %w", err)
+	}
+	return &change, nil
+}
+
+// GetOpenForUser returns the user's pending or scheduled role change, if any
+func (r *roleChangeRepository) GetOpenForUser(ctx context.Context, userID uuid.UUID) (*models.RoleChange, error) {
+	var change models.RoleChange
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status IN ?", userID, []models.RoleChangeStatus{models.RoleChangePendingApproval, models.RoleChangeScheduled}).
+		First(&change).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get open role change: %w", err)
+	}
+	return &change, nil
+}
+
+// List returns role changes, newest first, optionally filtered by status
+func (r *roleChangeRepository) List(ctx context.Context, status models.RoleChangeStatus) ([]models.RoleChange, error) {
+	query := r.db.WithContext(ctx).Order("requested_at DESC").Limit(100)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var changes []models.RoleChange
+	if err := query.Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list role changes: %w", err)
+	}
+	return changes, nil
+}
+
+// Update saves a role change
+func (r *roleChangeRepository) Update(ctx context.Context, change *models.RoleChange) error {
+	if err := r.db.WithContext(ctx).Save(change).Error; err != nil {
+		return fmt.Errorf("failed to update role change: %w", err)
+	}
+	return nil
+}
+
+// ListDue returns scheduled role changes whose cooldown ended before the given time
+func (r *roleChangeRepository) ListDue(ctx context.Context, before time.Time) ([]models.RoleChange, error) {
+	var changes []models.RoleChange
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND effective_at <= ?", models.RoleChangeScheduled, before).
+		Order("effective_at").
+		Find(&changes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due role changes: %w", err)
+	}
+	return changes, nil
+}
+
+// Apply sets the user's role and marks the change applied in one transaction
+func (r *roleChangeRepository) Apply(ctx context.Context, change *models.RoleChange) error {
+	now := time.Now()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).Where("id = ?", change.UserID).Update("role", change.ToRole)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("user with ID %s: %w", change.UserID, ErrUserNotFound)
+		}
+
+		change.Status = models.RoleChangeApplied
+		change.AppliedAt = &now
+		return tx.Save(change).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply role change: %w", err)
+	}
+	return nil
+}
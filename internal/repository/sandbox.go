@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// sandboxPurgeBatchSize is how many sandbox users are purged at a time
+const sandboxPurgeBatchSize = 500
+
+// PurgeSandbox permanently deletes the sandbox users created before the
+// latest nightly purge time, together with the audit logs they acted in or
+// were the target of. Each user gets a PERMANENT_DELETE_USER entry, so the
+// user changes feed reports them as purged, linked to a PURGE_SANDBOX_USERS
+// summary. It runs on the scheduler every hour; users created since the
+// purge time are kept until the next night, so a purge that was missed is
+// caught up on the next run.
+func (rm *RepositoryManager) PurgeSandbox(ctx context.Context) error {
+	sandbox := rm.config.Sandbox
+	if !sandbox.Enabled {
+		return nil
+	}
+	cutoff := sandbox.LastPurge(time.Now())
+	batchID := uuid.New().String()
+
+	var purgedUsers, purgedLogs int64
+	var failed int
+	for {
+		ids, err := rm.Repos.User.ListIDsByEmailDomain(ctx, sandbox.EmailDomain, cutoff, sandboxPurgeBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		// Logs first, so a failure leaves the users to be found again
+		deleted, err := rm.Repos.Log.DeleteByUserIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		purgedLogs += deleted
+
+		var batchFailed int
+		var entries []*models.UserLog
+		for _, id := range ids {
+			if err := rm.Repos.User.PermanentDelete(ctx, id); err != nil {
+				log.Printf("Failed to purge sandbox user %s: %v", id, err)
+				batchFailed++
+				continue
+			}
+			purgedUsers++
+			entries = append(entries, models.NewUserLog(models.UserLogCreateRequest{
+				Event:  models.UserDeleted,
+				Action: "PERMANENT_DELETE_USER",
+				Details: map[string]interface{}{
+					"permanently_deleted_user_id": id,
+					"reason":                      "sandbox_purge",
+				},
+				BatchID: batchID,
+			}))
+		}
+		rm.logSandboxPurges(ctx, entries)
+		failed += batchFailed
+		if batchFailed == len(ids) || len(ids) < sandboxPurgeBatchSize {
+			// Stop rather than listing the same failing users again
+			break
+		}
+	}
+
+	if purgedUsers > 0 || purgedLogs > 0 {
+		log.Printf("🧹 Purged %d sandbox users and %d of their log entries", purgedUsers, purgedLogs)
+
+		logEntry := models.NewUserLog(models.UserLogCreateRequest{
+			Event:  models.UserDeleted,
+			Action: "PURGE_SANDBOX_USERS",
+			Details: map[string]interface{}{
+				"email_domain":   sandbox.EmailDomain,
+				"created_before": cutoff,
+				"users":          purgedUsers,
+				"log_entries":    purgedLogs,
+			},
+			BatchID:      batchID,
+			BatchSummary: true,
+		})
+		if err := rm.Repos.Log.CreateAsync(logEntry); err != nil {
+			log.Printf("Failed to log sandbox purge: %v", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to purge %d sandbox users", failed)
+	}
+	return nil
+}
+
+// logSandboxPurges writes the per-user purge entries before the next batch
+// is purged: the user changes feed reads them, and an entry still queued
+// when a client reads past it would never reach that client
+func (rm *RepositoryManager) logSandboxPurges(ctx context.Context, entries []*models.UserLog) {
+	if err := rm.Repos.Log.BulkCreate(ctx, entries); err != nil {
+		log.Printf("Failed to log sandbox purges, queueing them: %v", err)
+		for _, entry := range entries {
+			rm.Repos.Log.CreateAsync(entry)
+		}
+	}
+}
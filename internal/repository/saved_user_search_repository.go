@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// savedUserSearchRepository implements SavedUserSearchRepository interface
+type savedUserSearchRepository struct {
+	db *gorm.DB
+}
+
+// NewSavedUserSearchRepository creates a new saved user search repository
+func NewSavedUserSearchRepository(db *gorm.DB) SavedUserSearchRepository {
+	return &savedUserSearchRepository{db: db}
+}
+
+// ListForAdmin returns an admin's saved searches ordered by name
+func (r *savedUserSearchRepository) ListForAdmin(ctx context.Context, adminID uuid.UUID) ([]models.SavedUserSearch, error) {
+	var searches []models.SavedUserSearch
+	err := r.db.WithContext(ctx).
+		Where("admin_id = ?", adminID).
+		Order("name").
+		Find(&searches).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved user searches: %w", err)
+	}
+	return searches, nil
+}
+
+// GetForAdmin returns one of an admin's saved searches
+func (r *savedUserSearchRepository) GetForAdmin(ctx context.Context, adminID, id uuid.UUID) (*models.SavedUserSearch, error) {
+	var search models.SavedUserSearch
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND admin_id = ?", id, adminID).
+		First(&search).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("saved user search %s: %w", id, ErrSavedUserSearchNotFound)
+		}
+		return nil, fmt.Errorf("failed to get saved user search: %w", err)
+	}
+	return &search, nil
+}
+
+// Save stores a search, replacing the admin's search with the same name
+func (r *savedUserSearchRepository) Save(ctx context.Context, search *models.SavedUserSearch) error {
+	err := r.db.WithContext(ctx).
+		Clauses(
+			clause.OnConflict{
+				Columns: []clause.Column{{Name: "admin_id"}, {Name: "name"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"search", "role", "region", "created_within_days", "sort_by", "sort_dir", "updated_at",
+				}),
+			},
+			clause.Returning{},
+		).
+		Create(search).Error
+	if err != nil {
+		return fmt.Errorf("failed to save user search: %w", err)
+	}
+	return nil
+}
+
+// Delete removes one of an admin's saved searches
+func (r *savedUserSearchRepository) Delete(ctx context.Context, adminID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND admin_id = ?", id, adminID).
+		Delete(&models.SavedUserSearch{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete saved user search: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("saved user search %s: %w", id, ErrSavedUserSearchNotFound)
+	}
+	return nil
+}
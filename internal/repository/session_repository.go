@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sessionRepository implements SessionRepository interface
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// Create stores a new session
+func (r *sessionRepository) Create(ctx context.Context, session *models.Session) error {
+	if err := r.db.WithContext(ctx).Create(session).Error; err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a session by ID, including revoked and expired ones
+func (r *sessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("session with ID %s: %w", id, ErrSessionNotFound)
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return &session, nil
+}
+
+// Touch records activity on a session
+func (r *sessionRepository) Touch(ctx context.Context, id uuid.UUID, ipAddress string) error {
+	err := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_activity_at": time.Now(),
+			"ip_address":       ipAddress,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update session activity: %w", err)
+	}
+	return nil
+}
+
+// ListActive returns unrevoked, unexpired sessions for a role, most recently
+// active first
+func (r *sessionRepository) ListActive(ctx context.Context, role string) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("role = ? AND revoked_at IS NULL AND expires_at > ?", role, time.Now()).
+		Order("last_activity_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// Revoke marks a session as revoked
+func (r *sessionRepository) Revoke(ctx context.Context, id uuid.UUID, revokedBy uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"revoked_by": revokedBy,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("session with ID %s: %w", id, ErrSessionNotFound)
+	}
+	return nil
+}
+
+// RevokeAllForUser revokes every active session of a user
+func (r *sessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID, revokedBy uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"revoked_by": revokedBy,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke user sessions: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteExpired removes sessions that expired or were revoked before the cutoff
+func (r *sessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ? OR revoked_at < ?", before, before).
+		Delete(&models.Session{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// maxStatsWidgetDays is the longest period a stats widget may cover
+const maxStatsWidgetDays = 90
+
+// statsWidget describes a stats widget and builds its datasets
+type statsWidget struct {
+	info  models.StatsWidgetInfo
+	build func(rm *RepositoryManager, ctx context.Context, widget *models.StatsWidget) error
+}
+
+// statsWidgets are the widgets served by the widgets API, in display order
+var statsWidgets = []statsWidget{
+	{
+		info: models.StatsWidgetInfo{
+			Name:           "summary",
+			Title:          "Quick Stats",
+			Description:    "Active and deleted users, active sessions and recent audit log volume",
+			Type:           models.StatsWidgetCounter,
+			RefreshSeconds: 60,
+		},
+		build: (*RepositoryManager).buildSummaryWidget,
+	},
+	{
+		info: models.StatsWidgetInfo{
+			Name:           "health",
+			Title:          "Service Health",
+			Description:    "Database connection checks, 1 when healthy",
+			Type:           models.StatsWidgetStatus,
+			RefreshSeconds: 15,
+		},
+		build: (*RepositoryManager).buildHealthWidget,
+	},
+	{
+		info: models.StatsWidgetInfo{
+			Name:           "log_pipeline",
+			Title:          "Log Pipeline",
+			Description:    "Async audit log queue, spool and drops; degraded is 1 while MongoDB is unavailable",
+			Type:           models.StatsWidgetStatus,
+			RefreshSeconds: 15,
+		},
+		build: (*RepositoryManager).buildLogPipelineWidget,
+	},
+	{
+		info: models.StatsWidgetInfo{
+			Name:           "event_distribution",
+			Title:          "Event Distribution",
+			Description:    "Audit log events by type over the period",
+			Type:           models.StatsWidgetDoughnut,
+			DefaultDays:    30,
+			RefreshSeconds: 300,
+		},
+		build: (*RepositoryManager).buildEventDistributionWidget,
+	},
+	{
+		info: models.StatsWidgetInfo{
+			Name:           "activity_timeline",
+			Title:          "User Activity Timeline",
+			Description:    "Audit log events, successful logins and failed logins per day (UTC)",
+			Type:           models.StatsWidgetLine,
+			DefaultDays:    7,
+			RefreshSeconds: 300,
+		},
+		build: (*RepositoryManager).buildActivityTimelineWidget,
+	},
+	{
+		info: models.StatsWidgetInfo{
+			Name:           "user_growth",
+			Title:          "User Growth",
+			Description:    "Users created and deleted per day (UTC), and the net change",
+			Type:           models.StatsWidgetLine,
+			DefaultDays:    7,
+			RefreshSeconds: 300,
+		},
+		build: (*RepositoryManager).buildUserGrowthWidget,
+	},
+	{
+		info: models.StatsWidgetInfo{
+			Name:           "admin_activity",
+			Title:          "Admin Activity",
+			Description:    "Audit log events per admin over the period, most active first",
+			Type:           models.StatsWidgetCounter,
+			DefaultDays:    30,
+			RefreshSeconds: 300,
+		},
+		build: (*RepositoryManager).buildAdminActivityWidget,
+	},
+}
+
+// maxAdminActivityAdmins caps the admins listed by the admin activity widget
+const maxAdminActivityAdmins = 100
+
+// StatsWidgets lists the stats widgets available from StatsWidget
+func (rm *RepositoryManager) StatsWidgets() []models.StatsWidgetInfo {
+	infos := make([]models.StatsWidgetInfo, len(statsWidgets))
+	for i, widget := range statsWidgets {
+		infos[i] = widget.info
+	}
+	return infos
+}
+
+// StatsWidget builds the named stats widget. days sets the period of widgets
+// covering one, 0 using the widget's default; it fails with
+// ErrInvalidStatsPeriod outside 1 to 90 days and ErrStatsWidgetNotFound for
+// an unknown name.
+func (rm *RepositoryManager) StatsWidget(ctx context.Context, name string, days int) (*models.StatsWidget, error) {
+	for _, spec := range statsWidgets {
+		if spec.info.Name != name {
+			continue
+		}
+
+		widget := &models.StatsWidget{StatsWidgetInfo: spec.info}
+		if spec.info.DefaultDays > 0 {
+			if days == 0 {
+				days = spec.info.DefaultDays
+			}
+			if days < 1 || days > maxStatsWidgetDays {
+				return nil, fmt.Errorf("%d days: %w", days, ErrInvalidStatsPeriod)
+			}
+			widget.Days = days
+		}
+
+		if err := spec.build(rm, ctx, widget); err != nil {
+			return nil, fmt.Errorf("failed to build %s widget: %w", name, err)
+		}
+		widget.GeneratedAt = time.Now()
+		return widget, nil
+	}
+	return nil, fmt.Errorf("widget %q: %w", name, ErrStatsWidgetNotFound)
+}
+
+// buildSummaryWidget counts users, sessions and recent log entries
+func (rm *RepositoryManager) buildSummaryWidget(ctx context.Context, widget *models.StatsWidget) error {
+	users, err := rm.Repos.User.Count(ctx, UserFilter{})
+	if err != nil {
+		return err
+	}
+	deleted, err := rm.Repos.User.GetAllDeleted(ctx, ListParams{PageSize: 1}, DeletedUserFilter{})
+	if err != nil {
+		return err
+	}
+	sessions, err := rm.Repos.Session.ListActive(ctx, "")
+	if err != nil {
+		return err
+	}
+	since := time.Now().AddDate(0, 0, -30)
+	logs, err := rm.Repos.Log.Count(ctx, models.LogFilterRequest{StartDate: &since})
+	if err != nil {
+		return err
+	}
+
+	widget.Labels = []string{"active_users", "deleted_users", "active_sessions", "logs_last_30_days"}
+	widget.Datasets = []models.StatsWidgetDataset{{
+		Label: "Count",
+		Data:  []float64{float64(users), float64(deleted.Total), float64(len(sessions)), float64(logs)},
+	}}
+	return nil
+}
+
+// buildHealthWidget reports each database connection check
+func (rm *RepositoryManager) buildHealthWidget(ctx context.Context, widget *models.StatsWidget) error {
+	health := rm.HealthCheck()
+	widget.Labels = make([]string, 0, len(health))
+	for name := range health {
+		widget.Labels = append(widget.Labels, name)
+	}
+	sort.Strings(widget.Labels)
+
+	data := make([]float64, len(widget.Labels))
+	for i, name := range widget.Labels {
+		if health[name] {
+			data[i] = 1
+		}
+	}
+	widget.Datasets = []models.StatsWidgetDataset{{Label: "Healthy", Data: data}}
+	return nil
+}
+
+// buildLogPipelineWidget reports the async log pipeline state
+func (rm *RepositoryManager) buildLogPipelineWidget(ctx context.Context, widget *models.StatsWidget) error {
+	status := rm.LogPipelineStatus()
+	degraded := 0.0
+	if status.Degraded {
+		degraded = 1
+	}
+
+	widget.Labels = []string{"degraded", "queued_entries", "spooled_bytes", "dropped_entries"}
+	widget.Datasets = []models.StatsWidgetDataset{{
+		Label: "Value",
+		Data:  []float64{degraded, float64(status.QueuedEntries), float64(status.SpooledBytes), float64(status.DroppedEntries)},
+	}}
+	return nil
+}
+
+// buildEventDistributionWidget counts log events by type, largest first
+func (rm *RepositoryManager) buildEventDistributionWidget(ctx context.Context, widget *models.StatsWidget) error {
+	counts, err := rm.Repos.Log.GetEventStats(ctx, nil, widget.Days)
+	if err != nil {
+		return err
+	}
+
+	events := make([]models.LogEventType, 0, len(counts))
+	for event := range counts {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if counts[events[i]] != counts[events[j]] {
+			return counts[events[i]] > counts[events[j]]
+		}
+		return events[i] < events[j]
+	})
+
+	widget.Labels = make([]string, len(events))
+	data := make([]float64, len(events))
+	for i, event := range events {
+		widget.Labels[i] = string(event)
+		data[i] = float64(counts[event])
+	}
+	widget.Datasets = []models.StatsWidgetDataset{{Label: "Events", Data: data}}
+	return nil
+}
+
+// buildActivityTimelineWidget counts log events per UTC day, ending today
+func (rm *RepositoryManager) buildActivityTimelineWidget(ctx context.Context, widget *models.StatsWidget) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -(widget.Days - 1))
+	counts, err := rm.Repos.Log.GetEventCountsByWindow(ctx, from, 24*time.Hour, widget.Days)
+	if err != nil {
+		return err
+	}
+
+	widget.Labels = make([]string, widget.Days)
+	total := make([]float64, widget.Days)
+	logins := make([]float64, widget.Days)
+	failed := make([]float64, widget.Days)
+	for day := 0; day < widget.Days; day++ {
+		widget.Labels[day] = from.AddDate(0, 0, day).Format("2006-01-02")
+		for event, perDay := range counts {
+			if day >= len(perDay) {
+				continue
+			}
+			total[day] += float64(perDay[day])
+			switch event {
+			case models.LoginSuccess:
+				logins[day] += float64(perDay[day])
+			case models.LoginFailed:
+				failed[day] += float64(perDay[day])
+			}
+		}
+	}
+
+	widget.Datasets = []models.StatsWidgetDataset{
+		{Label: "Events", Data: total},
+		{Label: "Successful logins", Data: logins},
+		{Label: "Failed logins", Data: failed},
+	}
+	return nil
+}
+
+// buildUserGrowthWidget counts users created and deleted per UTC day, ending today
+func (rm *RepositoryManager) buildUserGrowthWidget(ctx context.Context, widget *models.StatsWidget) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -(widget.Days - 1))
+	created, deleted, err := rm.Repos.User.CountCreatedAndDeletedByDay(ctx, from, widget.Days)
+	if err != nil {
+		return err
+	}
+
+	widget.Labels = make([]string, widget.Days)
+	createdData := make([]float64, widget.Days)
+	deletedData := make([]float64, widget.Days)
+	net := make([]float64, widget.Days)
+	for day := 0; day < widget.Days; day++ {
+		widget.Labels[day] = from.AddDate(0, 0, day).Format("2006-01-02")
+		createdData[day] = float64(created[day])
+		deletedData[day] = float64(deleted[day])
+		net[day] = createdData[day] - deletedData[day]
+	}
+
+	widget.Datasets = []models.StatsWidgetDataset{
+		{Label: "Created", Data: createdData},
+		{Label: "Deleted", Data: deletedData},
+		{Label: "Net change", Data: net},
+	}
+	return nil
+}
+
+// buildAdminActivityWidget counts the audit log events of each admin,
+// labelled by email
+func (rm *RepositoryManager) buildAdminActivityWidget(ctx context.Context, widget *models.StatsWidget) error {
+	admins, err := rm.Repos.User.ListFiltered(ctx, "", UserFilter{Role: models.RoleAdmin}, ListParams{Page: 1, PageSize: maxAdminActivityAdmins})
+	if err != nil {
+		return err
+	}
+	ids := make([]uuid.UUID, len(admins.Users))
+	for i, admin := range admins.Users {
+		ids[i] = admin.ID
+	}
+	stats, err := rm.Repos.Log.GetActorEventStats(ctx, ids, time.Now().AddDate(0, 0, -widget.Days))
+	if err != nil {
+		return err
+	}
+
+	type adminTotals struct {
+		email                       string
+		total, userChanges, signIns float64
+	}
+	totals := make([]adminTotals, len(admins.Users))
+	for i, admin := range admins.Users {
+		totals[i].email = admin.Email
+		for event, count := range stats[admin.ID] {
+			totals[i].total += float64(count)
+			switch event {
+			case models.UserCreated, models.UserUpdated, models.UserDeleted, models.RoleChangeEvent:
+				totals[i].userChanges += float64(count)
+			case models.AdminLogin, models.LoginSuccess:
+				totals[i].signIns += float64(count)
+			}
+		}
+	}
+	sort.SliceStable(totals, func(i, j int) bool {
+		if totals[i].total != totals[j].total {
+			return totals[i].total > totals[j].total
+		}
+		return totals[i].email < totals[j].email
+	})
+
+	widget.Labels = make([]string, len(totals))
+	total := make([]float64, len(totals))
+	userChanges := make([]float64, len(totals))
+	signIns := make([]float64, len(totals))
+	for i, admin := range totals {
+		widget.Labels[i] = admin.email
+		total[i] = admin.total
+		userChanges[i] = admin.userChanges
+		signIns[i] = admin.signIns
+	}
+	widget.Datasets = []models.StatsWidgetDataset{
+		{Label: "Events", Data: total},
+		{Label: "User and role changes", Data: userChanges},
+		{Label: "Sign-ins", Data: signIns},
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/notifier"
+)
+
+// CheckUserLimit checks that creating `adding` more users keeps the active
+// users within the configured hard limit, failing with ErrUserLimitReached
+// otherwise. The returned status reports whether the creation takes them
+// past the soft limit; operators are alerted when it first does. The check
+// is not atomic with the creation, so concurrent creations may overshoot
+// the hard limit slightly.
+func (rm *RepositoryManager) CheckUserLimit(ctx context.Context, adding int) (models.UsageLimitStatus, error) {
+	status, err := rm.PreviewUserLimit(ctx, adding)
+	if err == nil && status.OverSoftLimit && status.Used <= status.SoftLimit {
+		rm.alertUserSoftLimit(ctx, status, status.Used+int64(adding))
+	}
+	return status, err
+}
+
+// PreviewUserLimit checks the active user limits like CheckUserLimit, but
+// without alerting operators, for creations that are only being validated
+func (rm *RepositoryManager) PreviewUserLimit(ctx context.Context, adding int) (models.UsageLimitStatus, error) {
+	status := models.UsageLimitStatus{
+		Resource:  models.QuotaResourceUsers,
+		SoftLimit: int64(rm.config.Quota.UsersSoftLimit),
+		HardLimit: int64(rm.config.Quota.UsersHardLimit),
+	}
+	if status.SoftLimit == 0 && status.HardLimit == 0 {
+		return status, nil
+	}
+
+	used, err := rm.Repos.User.Count(ctx, UserFilter{})
+	if err != nil {
+		return status, err
+	}
+	status.Used = used
+
+	after := used + int64(adding)
+	if status.HardLimit > 0 && after > status.HardLimit {
+		return status, fmt.Errorf("%d active users, creating %d more: %w", used, adding, ErrUserLimitReached)
+	}
+
+	status.OverSoftLimit = status.SoftLimit > 0 && after > status.SoftLimit
+	return status, nil
+}
+
+// alertUserSoftLimit tells operators the active users crossed the soft limit
+func (rm *RepositoryManager) alertUserSoftLimit(ctx context.Context, status models.UsageLimitStatus, after int64) {
+	alert := notifier.Alert{
+		Kind:     "user_soft_limit",
+		Severity: notifier.SeverityWarning,
+		Title:    "Active users passed the soft limit",
+		Message:  fmt.Sprintf("Active users reached %d, past the soft limit of %d", after, status.SoftLimit),
+		Details: map[string]interface{}{
+			"active_users": after,
+			"soft_limit":   status.SoftLimit,
+			"hard_limit":   status.HardLimit,
+		},
+		Time: time.Now(),
+	}
+	if err := rm.notifier.Notify(ctx, alert); err != nil && !errors.Is(err, notifier.ErrMuted) {
+		log.Printf("Failed to send user soft limit alert: %v", err)
+	}
+}
+
+// Usage reports user counts against their limits, audit log volume and
+// creation quota usage
+func (rm *RepositoryManager) Usage(ctx context.Context) (*models.UsageReport, error) {
+	now := time.Now()
+	report := &models.UsageReport{
+		Region: rm.config.Residency.Region,
+		Users: models.UsageLimitStatus{
+			Resource:  models.QuotaResourceUsers,
+			SoftLimit: int64(rm.config.Quota.UsersSoftLimit),
+			HardLimit: int64(rm.config.Quota.UsersHardLimit),
+		},
+		GeneratedAt: now.UTC(),
+	}
+
+	// Count users
+	used, err := rm.Repos.User.Count(ctx, UserFilter{})
+	if err != nil {
+		return nil, err
+	}
+	report.Users.Used = used
+	report.Users.OverSoftLimit = report.Users.SoftLimit > 0 && used > report.Users.SoftLimit
+
+	deleted, err := rm.Repos.User.GetAllDeleted(ctx, ListParams{PageSize: 1}, DeletedUserFilter{})
+	if err != nil {
+		return nil, err
+	}
+	report.DeletedUsers = deleted.Total
+
+	// Count logs
+	if report.Logs.Total, err = rm.Repos.Log.Count(ctx, models.LogFilterRequest{}); err != nil {
+		return nil, err
+	}
+	dayAgo := now.Add(-24 * time.Hour)
+	if report.Logs.Last24Hours, err = rm.Repos.Log.Count(ctx, models.LogFilterRequest{StartDate: &dayAgo}); err != nil {
+		return nil, err
+	}
+	monthAgo := now.AddDate(0, 0, -30)
+	if report.Logs.Last30Days, err = rm.Repos.Log.Count(ctx, models.LogFilterRequest{StartDate: &monthAgo}); err != nil {
+		return nil, err
+	}
+
+	// Creation quotas
+	if report.Quotas, err = rm.QuotaUsage(ctx); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// User changes feed page sizes
+const (
+	defaultUserChangesLimit = 100
+	maxUserChangesLimit     = 1000
+)
+
+// EncodeUserChangesCursor returns the opaque cursor resuming the user changes
+// feed after the change of userID at changedAt
+func EncodeUserChangesCursor(changedAt time.Time, userID uuid.UUID) string {
+	raw := changedAt.UTC().Format(time.RFC3339Nano) + "|" + userID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeUserChangesCursor parses a cursor from EncodeUserChangesCursor. An
+// empty cursor starts the feed from the beginning.
+func DecodeUserChangesCursor(cursor string) (time.Time, uuid.UUID, error) {
+	if cursor == "" {
+		return time.Time{}, uuid.Nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, fmt.Errorf("%w: %v", ErrInvalidChangesCursor, err)
+	}
+	changedAtStr, userIDStr, found := strings.Cut(string(raw), "|")
+	if !found {
+		return time.Time{}, uuid.Nil, ErrInvalidChangesCursor
+	}
+	changedAt, err := time.Parse(time.RFC3339Nano, changedAtStr)
+	if err != nil {
+		return time.Time{}, uuid.Nil, fmt.Errorf("%w: %v", ErrInvalidChangesCursor, err)
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return time.Time{}, uuid.Nil, fmt.Errorf("%w: %v", ErrInvalidChangesCursor, err)
+	}
+	return changedAt, userID, nil
+}
+
+// UserChanges returns the users created, updated, deleted or permanently
+// deleted after the cursor, so downstream systems can sync incrementally.
+// Row changes come from updated_at and deleted_at, permanent deletions from
+// the audit trail. limit is capped at 1000, 0 meaning 100; a malformed
+// cursor fails with ErrInvalidChangesCursor.
+func (rm *RepositoryManager) UserChanges(ctx context.Context, cursor string, limit int) (*models.UserChangesResponse, error) {
+	since, afterID, err := DecodeUserChangesCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultUserChangesLimit
+	}
+	if limit > maxUserChangesLimit {
+		limit = maxUserChangesLimit
+	}
+
+	// Fetch one more than the page from each source to tell if more remain
+	changes, err := rm.Repos.User.ListChangedSince(ctx, since, afterID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	purges, err := rm.Repos.Log.ListPermanentDeletionsSince(ctx, since, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	for _, purge := range purges {
+		if userChangeAfter(purge, since, afterID) {
+			changes = append(changes, purge)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return userChangeAfter(changes[j], changes[i].ChangedAt, changes[i].UserID)
+	})
+
+	response := &models.UserChangesResponse{Changes: changes}
+	if len(changes) > limit {
+		response.Changes = changes[:limit]
+		response.HasMore = true
+	}
+	if len(response.Changes) == 0 {
+		response.NextCursor = EncodeUserChangesCursor(since, afterID)
+	} else {
+		last := response.Changes[len(response.Changes)-1]
+		response.NextCursor = EncodeUserChangesCursor(last.ChangedAt, last.UserID)
+	}
+	return response, nil
+}
+
+// userChangeAfter reports whether change sorts after the (changedAt, userID)
+// position, in the feed's order of change time then user ID
+func userChangeAfter(change models.UserChange, changedAt time.Time, userID uuid.UUID) bool {
+	if !change.ChangedAt.Equal(changedAt) {
+		return change.ChangedAt.After(changedAt)
+	}
+	return change.UserID.String() > userID.String()
+}
@@ -2,11 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"user_mgmt_go/internal/buildinfo"
 	"user_mgmt_go/internal/models"
 
 	"github.com/google/uuid"
@@ -23,18 +25,52 @@ type userLogRepository struct {
 	logChannel chan *models.UserLog
 	wg         *sync.WaitGroup
 	stopChan   chan struct{}
+	opTimeout  time.Duration // Per-operation budget within a request
+
+	// Degrade mode: when MongoDB is unavailable or the queue is full, entries
+	// go to the disk spool so request handlers never block on logging
+	spool         *logSpool
+	stateMu       sync.Mutex
+	degraded      bool
+	degradedSince time.Time
+	degradeReason string
+	dropped       int64 // Entries lost because no spool was available
+
+	// batchMu serializes CreateAsyncBatch so concurrent batches cannot
+	// overcommit the queue
+	batchMu sync.Mutex
 }
 
-// NewUserLogRepository creates a new user log repository with async logging capability
-func NewUserLogRepository(db *mongo.Database) UserLogRepository {
+// batchQueueReserve is the share of the async queue kept free of submitted
+// batches, so this service's own entries still fit when callers push hard
+const batchQueueReserve = 4 // 1/4 of the queue
+
+// NewUserLogRepository creates a new user log repository with async logging
+// capability. Entries that cannot reach MongoDB are buffered in the spool file
+// at spoolPath (up to spoolMaxBytes) and replayed once it recovers.
+// Each call made while handling a request is limited to opTimeout.
+func NewUserLogRepository(db *mongo.Database, spoolPath string, spoolMaxBytes int64, opTimeout time.Duration) UserLogRepository {
 	repo := &userLogRepository{
 		db:         db,
+		opTimeout:  opTimeout,
 		collection: db.Collection(models.UserLog{}.CollectionName()),
 		logChannel: make(chan *models.UserLog, 1000), // Buffer size of 1000
 		wg:         &sync.WaitGroup{},
 		stopChan:   make(chan struct{}),
 	}
 
+	if spoolPath != "" {
+		spool, err := newLogSpool(spoolPath, spoolMaxBytes)
+		if err != nil {
+			log.Printf("⚠️  Log spool disabled, entries will be dropped while MongoDB is unavailable: %v", err)
+		} else {
+			repo.spool = spool
+			if spool.Size() > 0 {
+				log.Printf("📼 Found %d bytes of spooled logs, will replay once MongoDB is reachable", spool.Size())
+			}
+		}
+	}
+
 	// Start async log processor
 	repo.startAsyncProcessor()
 
@@ -70,6 +106,9 @@ func (r *userLogRepository) startAsyncProcessor() {
 					batch = batch[:0]
 				}
 
+				// Replay spooled logs once MongoDB is reachable again
+				r.tryRecover()
+
 			case <-r.stopChan:
 				// Process remaining logs before shutting down
 				if len(batch) > 0 {
@@ -83,48 +122,223 @@ func (r *userLogRepository) startAsyncProcessor() {
 
 // processBatch processes a batch of logs
 func (r *userLogRepository) processBatch(logs []*models.UserLog) {
+	// Don't wait on MongoDB timeouts while degraded; tryRecover probes it
+	if r.isDegraded() {
+		r.spoolLogs(logs)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	if err := r.BulkCreate(ctx, logs); err != nil {
 		log.Printf("Failed to process log batch: %v", err)
-		// In production, you might want to implement retry logic or dead letter queue
+		r.markDegraded(fmt.Sprintf("mongodb write failed: %v", err))
+		r.spoolLogs(logs)
 	}
 }
 
-// Create creates a new log entry synchronously
-func (r *userLogRepository) Create(ctx context.Context, logEntry *models.UserLog) error {
+// spoolLogs buffers entries on disk until MongoDB is available
+func (r *userLogRepository) spoolLogs(logs []*models.UserLog) {
+	// Assign IDs up front so a replay that partially succeeded can be retried
+	// without duplicating entries
+	for _, logEntry := range logs {
+		if logEntry.ID.IsZero() {
+			logEntry.ID = primitive.NewObjectID()
+		}
+	}
+
+	if r.spool != nil {
+		err := r.spool.Append(logs)
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to spool %d log entries: %v", len(logs), err)
+	}
+
+	r.stateMu.Lock()
+	r.dropped += int64(len(logs))
+	r.stateMu.Unlock()
+}
+
+// tryRecover replays the spool and leaves degrade mode once MongoDB accepts writes
+func (r *userLogRepository) tryRecover() {
+	hasSpool := r.spool != nil && r.spool.Size() > 0
+	if !r.isDegraded() && !hasSpool {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	err := r.db.Client().Ping(ctx, nil)
+	cancel()
+	if err != nil {
+		return
+	}
+
+	if hasSpool {
+		err := r.spool.Replay(func(logs []*models.UserLog) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			return r.replayBatch(ctx, logs)
+		})
+		if err != nil {
+			log.Printf("Failed to replay spooled logs: %v", err)
+			return
+		}
+	}
+
+	r.stateMu.Lock()
+	wasDegraded := r.degraded
+	r.degraded = false
+	r.degradeReason = ""
+	r.stateMu.Unlock()
+
+	if wasDegraded {
+		log.Println("✅ Log pipeline recovered, spooled entries replayed to MongoDB")
+	}
+}
+
+// replayBatch inserts spooled entries, ignoring ones already written by an
+// earlier, interrupted replay
+func (r *userLogRepository) replayBatch(ctx context.Context, logs []*models.UserLog) error {
+	documents := make([]interface{}, len(logs))
+	for i, logEntry := range logs {
+		documents[i] = logEntry
+	}
+
+	_, err := r.collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if err != nil && !isOnlyDuplicateKeyErrors(err) {
+		return fmt.Errorf("failed to replay logs: %w", err)
+	}
+	return nil
+}
+
+// isOnlyDuplicateKeyErrors reports whether every failed write was a duplicate _id
+func isOnlyDuplicateKeyErrors(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != 11000 {
+			return false
+		}
+	}
+	return true
+}
+
+// markDegraded switches the pipeline to degrade mode
+func (r *userLogRepository) markDegraded(reason string) {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+
+	if r.degraded {
+		return
+	}
+	r.degraded = true
+	r.degradedSince = time.Now()
+	r.degradeReason = reason
+	log.Printf("⚠️  Log pipeline degraded (%s), buffering entries to disk", reason)
+}
+
+// isDegraded reports whether the pipeline is in degrade mode
+func (r *userLogRepository) isDegraded() bool {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	return r.degraded
+}
+
+// PipelineStatus returns the current state of the async log pipeline
+func (r *userLogRepository) PipelineStatus() LogPipelineStatus {
+	r.stateMu.Lock()
+	status := LogPipelineStatus{
+		Degraded:       r.degraded,
+		Reason:         r.degradeReason,
+		QueuedEntries:  len(r.logChannel),
+		DroppedEntries: r.dropped,
+	}
+	if r.degraded {
+		since := r.degradedSince
+		status.DegradedSince = &since
+	}
+	r.stateMu.Unlock()
+
+	if r.spool != nil {
+		status.SpooledBytes = r.spool.Size()
+		status.DroppedEntries += r.spool.Dropped()
+	}
+	return status
+}
+
+// stampLog fills in the timestamp and the build of this instance and
+// normalizes the user ID
+func stampLog(logEntry *models.UserLog) {
+	normalizeLogUserID(logEntry)
 	if logEntry.Timestamp.IsZero() {
 		logEntry.Timestamp = time.Now()
 	}
+	if logEntry.AppVersion == "" {
+		logEntry.AppVersion = buildinfo.Version
+		logEntry.GitCommit = buildinfo.GitCommit
+	}
+}
+
+// Create creates a new log entry synchronously
+func (r *userLogRepository) Create(ctx context.Context, logEntry *models.UserLog) error {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
+	stampLog(logEntry)
 
 	_, err := r.collection.InsertOne(ctx, logEntry)
 	if err != nil {
-		return fmt.Errorf("failed to create log entry: %w", err)
+		return fmt.Errorf("failed to create log entry: %w", translateError(err))
 	}
 	return nil
 }
 
 // CreateAsync creates a new log entry asynchronously
 func (r *userLogRepository) CreateAsync(logEntry *models.UserLog) error {
-	if logEntry.Timestamp.IsZero() {
-		logEntry.Timestamp = time.Now()
-	}
+	stampLog(logEntry)
 
 	select {
 	case r.logChannel <- logEntry:
 		return nil
 	default:
-		// Channel is full, log synchronously as fallback
-		log.Printf("Async log channel full, falling back to sync logging")
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		return r.Create(ctx, logEntry)
+		// Channel is full; spool instead of blocking the caller on MongoDB
+		r.markDegraded("async log queue full")
+		r.spoolLogs([]*models.UserLog{logEntry})
+		return nil
 	}
 }
 
+// CreateAsyncBatch queues entries for asynchronous logging when the pipeline
+// can take all of them. Unlike CreateAsync it never falls back to the spool:
+// while the pipeline is degraded or the queue is nearly full it returns
+// ErrLogPipelineBusy, so the submitter backs off instead.
+func (r *userLogRepository) CreateAsyncBatch(logs []*models.UserLog) error {
+	if r.isDegraded() {
+		return ErrLogPipelineBusy
+	}
+
+	r.batchMu.Lock()
+	defer r.batchMu.Unlock()
+
+	free := cap(r.logChannel) - len(r.logChannel) - cap(r.logChannel)/batchQueueReserve
+	if len(logs) > free {
+		return ErrLogPipelineBusy
+	}
+	for _, logEntry := range logs {
+		r.CreateAsync(logEntry)
+	}
+	return nil
+}
+
 // GetByID retrieves a log entry by ID
 func (r *userLogRepository) GetByID(ctx context.Context, id string) (*models.UserLog, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, fmt.Errorf("invalid log ID format: %w", err)
@@ -134,15 +348,18 @@ func (r *userLogRepository) GetByID(ctx context.Context, id string) (*models.Use
 	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&logEntry)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("log entry with ID %s not found", id)
+			return nil, fmt.Errorf("log entry with ID %s: %w", id, ErrLogNotFound)
 		}
-		return nil, fmt.Errorf("failed to get log entry: %w", err)
+		return nil, fmt.Errorf("failed to get log entry: %w", translateError(err))
 	}
 	return &logEntry, nil
 }
 
 // List retrieves logs with advanced filtering
 func (r *userLogRepository) List(ctx context.Context, filter models.LogFilterRequest) (*models.UserLogsListResponse, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
 	// Set defaults
 	if filter.Page <= 0 {
 		filter.Page = 1
@@ -194,11 +411,39 @@ func (r *userLogRepository) List(ctx context.Context, filter models.LogFilterReq
 	}, nil
 }
 
+// Export retrieves up to limit logs matching the filter, latest first,
+// ignoring the filter's pagination
+func (r *userLogRepository) Export(ctx context.Context, filter models.LogFilterRequest, limit int) ([]models.UserLogResponse, error) {
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "timestamp", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, r.buildLogFilter(filter), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find logs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var logs []models.UserLog
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, fmt.Errorf("failed to decode logs: %w", err)
+	}
+
+	logResponses := make([]models.UserLogResponse, len(logs))
+	for i, logEntry := range logs {
+		logResponses[i] = logEntry.ToResponse()
+	}
+	return logResponses, nil
+}
+
 // GetByUserID retrieves logs for a specific user
 func (r *userLogRepository) GetByUserID(ctx context.Context, userID uuid.UUID, params ListParams) (*models.UserLogsListResponse, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
 	params.SetDefaults()
 
-	filter := bson.M{"user_id": userID.String()}
+	filter := bson.M{"user_id": logUserIDValue(userID)}
 	
 	// Count total documents
 	total, err := r.collection.CountDocuments(ctx, filter)
@@ -242,6 +487,9 @@ func (r *userLogRepository) GetByUserID(ctx context.Context, userID uuid.UUID, p
 
 // GetByEvent retrieves logs by event type
 func (r *userLogRepository) GetByEvent(ctx context.Context, event models.LogEventType, params ListParams) (*models.UserLogsListResponse, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
 	params.SetDefaults()
 
 	filter := bson.M{"event": event}
@@ -288,12 +536,18 @@ func (r *userLogRepository) GetByEvent(ctx context.Context, event models.LogEven
 
 // Count returns the total number of logs matching the filter
 func (r *userLogRepository) Count(ctx context.Context, filter models.LogFilterRequest) (int64, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
 	mongoFilter := r.buildLogFilter(filter)
 	return r.collection.CountDocuments(ctx, mongoFilter)
 }
 
 // GetEventStats returns statistics about events for a user within a time period
 func (r *userLogRepository) GetEventStats(ctx context.Context, userID *uuid.UUID, days int) (map[models.LogEventType]int64, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
 	matchStage := bson.M{
 		"timestamp": bson.M{
 			"$gte": time.Now().AddDate(0, 0, -days),
@@ -301,7 +555,7 @@ func (r *userLogRepository) GetEventStats(ctx context.Context, userID *uuid.UUID
 	}
 	
 	if userID != nil {
-		matchStage["user_id"] = userID.String()
+		matchStage["user_id"] = logUserIDValue(*userID)
 	}
 
 	pipeline := []bson.M{
@@ -335,10 +589,129 @@ func (r *userLogRepository) GetEventStats(ctx context.Context, userID *uuid.UUID
 	return stats, nil
 }
 
+// GetActorEventStats counts the events of each of the given users since
+// since, by user and event type. Per-request entries are left out.
+func (r *userLogRepository) GetActorEventStats(ctx context.Context, userIDs []uuid.UUID, since time.Time) (map[uuid.UUID]map[models.LogEventType]int64, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
+	stats := make(map[uuid.UUID]map[models.LogEventType]int64)
+	if len(userIDs) == 0 {
+		return stats, nil
+	}
+	values := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		values[i] = logUserIDValue(id)
+	}
+
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"user_id":     bson.M{"$in": values},
+			"timestamp":   bson.M{"$gte": since},
+			"data.action": bson.M{"$ne": "HTTP_REQUEST"},
+		}},
+		{"$group": bson.M{
+			"_id":   bson.M{"user_id": "$user_id", "event": "$event"},
+			"count": bson.M{"$sum": 1},
+		}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get actor event stats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var result struct {
+			ID struct {
+				UserID string              `bson:"user_id"`
+				Event  models.LogEventType `bson:"event"`
+			} `bson:"_id"`
+			Count int64 `bson:"count"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			continue
+		}
+		userID, err := uuid.Parse(result.ID.UserID)
+		if err != nil {
+			continue
+		}
+		if stats[userID] == nil {
+			stats[userID] = make(map[models.LogEventType]int64)
+		}
+		stats[userID][result.ID.Event] = result.Count
+	}
+	return stats, cursor.Err()
+}
+
+// GetEventCountsByWindow counts events per type in consecutive windows
+// starting at from. Each slice has one count per window, oldest first.
+func (r *userLogRepository) GetEventCountsByWindow(ctx context.Context, from time.Time, window time.Duration, windows int) (map[models.LogEventType][]int64, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
+	if window <= 0 || windows <= 0 {
+		return nil, fmt.Errorf("window and windows must be positive")
+	}
+	to := from.Add(window * time.Duration(windows))
+
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"timestamp": bson.M{"$gte": from, "$lt": to},
+		}},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"event": "$event",
+					"window": bson.M{"$floor": bson.M{"$divide": bson.A{
+						bson.M{"$subtract": bson.A{"$timestamp", from}},
+						window.Milliseconds(),
+					}}},
+				},
+				"count": bson.M{"$sum": 1},
+			},
+		},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event counts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	counts := make(map[models.LogEventType][]int64)
+	for cursor.Next(ctx) {
+		var result struct {
+			ID struct {
+				Event  models.LogEventType `bson:"event"`
+				Window float64             `bson:"window"`
+			} `bson:"_id"`
+			Count int64 `bson:"count"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			continue
+		}
+		index := int(result.ID.Window)
+		if index < 0 || index >= windows {
+			continue
+		}
+		if counts[result.ID.Event] == nil {
+			counts[result.ID.Event] = make([]int64, windows)
+		}
+		counts[result.ID.Event][index] = result.Count
+	}
+
+	return counts, nil
+}
+
 // GetUserActivity returns recent activity for a user
 func (r *userLogRepository) GetUserActivity(ctx context.Context, userID uuid.UUID, days int) ([]models.UserLogResponse, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
 	filter := bson.M{
-		"user_id": userID.String(),
+		"user_id": logUserIDValue(userID),
 		"timestamp": bson.M{
 			"$gte": time.Now().AddDate(0, 0, -days),
 		},
@@ -368,6 +741,177 @@ func (r *userLogRepository) GetUserActivity(ctx context.Context, userID uuid.UUI
 	return activities, nil
 }
 
+// GetLatestDeletion returns the most recent USER_DELETED entry targeting the
+// given user, or nil if there is none
+func (r *userLogRepository) GetLatestDeletion(ctx context.Context, userID uuid.UUID) (*models.UserLog, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
+	// Target IDs have been logged both as UUID values and as strings
+	filter := bson.M{
+		"event": models.UserDeleted,
+		"data.details.deleted_user_id": bson.M{
+			"$in": bson.A{userID, userID.String()},
+		},
+	}
+
+	opts := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+
+	var logEntry models.UserLog
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&logEntry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get deletion log entry: %w", err)
+	}
+	return &logEntry, nil
+}
+
+// GetLatestLifecycleEvent returns the most recent SYSTEM_STARTUP or
+// SYSTEM_SHUTDOWN entry written by the given instance, or nil if there is none
+func (r *userLogRepository) GetLatestLifecycleEvent(ctx context.Context, instance string) (*models.UserLog, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
+	filter := bson.M{
+		"event": bson.M{
+			"$in": bson.A{models.SystemStartup, models.SystemShutdown},
+		},
+		"data.details.instance": instance,
+	}
+
+	opts := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+
+	var logEntry models.UserLog
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&logEntry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get lifecycle log entry: %w", err)
+	}
+	return &logEntry, nil
+}
+
+// ListPermanentDeletionsSince lists users permanently deleted at or after
+// since, oldest first, from the PERMANENT_DELETE_USER audit entries. Entries
+// without a recognizable user ID are skipped.
+func (r *userLogRepository) ListPermanentDeletionsSince(ctx context.Context, since time.Time, limit int) ([]models.UserChange, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
+	filter := bson.M{
+		"event":       models.UserDeleted,
+		"data.action": "PERMANENT_DELETE_USER",
+		"timestamp":   bson.M{"$gte": since},
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find permanent deletions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var logs []models.UserLog
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, fmt.Errorf("failed to decode permanent deletions: %w", err)
+	}
+
+	changes := make([]models.UserChange, 0, len(logs))
+	for _, logEntry := range logs {
+		id, ok := parseStoredLogUserID(logEntry.Data.Details["permanently_deleted_user_id"])
+		if !ok {
+			continue
+		}
+		changes = append(changes, models.UserChange{
+			UserID:    id,
+			Change:    models.UserChangePurged,
+			ChangedAt: logEntry.Timestamp,
+		})
+	}
+	return changes, nil
+}
+
+// ListForExport lists logs after the (afterTimestamp, afterID) position and
+// before the given time, in (timestamp, ID) order. An empty afterID starts
+// at afterTimestamp itself.
+func (r *userLogRepository) ListForExport(ctx context.Context, afterTimestamp time.Time, afterID string, before time.Time, limit int) ([]models.UserLog, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
+	after := bson.M{"timestamp": bson.M{"$gte": afterTimestamp}}
+	if afterID != "" {
+		objectID, err := primitive.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid export position %q: %w", afterID, err)
+		}
+		after = bson.M{"$or": bson.A{
+			bson.M{"timestamp": bson.M{"$gt": afterTimestamp}},
+			bson.M{"timestamp": afterTimestamp, "_id": bson.M{"$gt": objectID}},
+		}}
+	}
+	filter := bson.M{
+		"$and": bson.A{after, bson.M{"timestamp": bson.M{"$lt": before}}},
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find logs for export: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var logs []models.UserLog
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, fmt.Errorf("failed to decode logs for export: %w", err)
+	}
+	return logs, nil
+}
+
+// ListRequestSamples lists the latest request logs of requests that failed
+// with a 5xx status or took at least slow to answer, only those made by
+// userID unless it is empty
+func (r *userLogRepository) ListRequestSamples(ctx context.Context, slow time.Duration, userID string, limit int) ([]models.UserLog, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
+	filter := bson.M{
+		"event":       models.SystemError,
+		"data.action": "HTTP_REQUEST",
+		"$or": bson.A{
+			bson.M{"data.status_code": bson.M{"$gte": 500}},
+			bson.M{"data.duration": bson.M{"$gte": slow.Milliseconds()}},
+		},
+	}
+	if userID != "" {
+		filter["data.details.user_id"] = userID
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find request samples: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var logs []models.UserLog
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, fmt.Errorf("failed to decode request samples: %w", err)
+	}
+	return logs, nil
+}
+
 // DeleteOldLogs deletes logs older than specified days
 func (r *userLogRepository) DeleteOldLogs(ctx context.Context, olderThanDays int) (int64, error) {
 	cutoffDate := time.Now().AddDate(0, 0, -olderThanDays)
@@ -385,6 +929,31 @@ func (r *userLogRepository) DeleteOldLogs(ctx context.Context, olderThanDays int
 	return result.DeletedCount, nil
 }
 
+// DeleteByUserIDs deletes the logs the given users acted in or were the
+// target of
+func (r *userLogRepository) DeleteByUserIDs(ctx context.Context, userIDs []uuid.UUID) (int64, error) {
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+	values := make([]string, len(userIDs))
+	targets := make(bson.A, 0, 2*len(userIDs))
+	for i, id := range userIDs {
+		values[i] = logUserIDValue(id)
+		// Target IDs have been logged both as UUID values and as strings
+		targets = append(targets, id, id.String())
+	}
+
+	conditions := bson.A{bson.M{"user_id": bson.M{"$in": values}}}
+	for _, key := range models.TargetUserDetailKeys {
+		conditions = append(conditions, bson.M{"data.details." + key: bson.M{"$in": targets}})
+	}
+	result, err := r.collection.DeleteMany(ctx, bson.M{"$or": conditions})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete user logs: %w", err)
+	}
+	return result.DeletedCount, nil
+}
+
 // BulkCreate creates multiple log entries in a single operation
 func (r *userLogRepository) BulkCreate(ctx context.Context, logs []*models.UserLog) error {
 	if len(logs) == 0 {
@@ -394,15 +963,13 @@ func (r *userLogRepository) BulkCreate(ctx context.Context, logs []*models.UserL
 	// Convert to interface slice for MongoDB
 	documents := make([]interface{}, len(logs))
 	for i, logEntry := range logs {
-		if logEntry.Timestamp.IsZero() {
-			logEntry.Timestamp = time.Now()
-		}
+		stampLog(logEntry)
 		documents[i] = logEntry
 	}
 
 	_, err := r.collection.InsertMany(ctx, documents)
 	if err != nil {
-		return fmt.Errorf("failed to bulk create logs: %w", err)
+		return fmt.Errorf("failed to bulk create logs: %w", translateError(err))
 	}
 
 	return nil
@@ -410,6 +977,9 @@ func (r *userLogRepository) BulkCreate(ctx context.Context, logs []*models.UserL
 
 // SearchLogs searches logs based on a search term
 func (r *userLogRepository) SearchLogs(ctx context.Context, searchTerm string, filter models.LogFilterRequest) (*models.UserLogsListResponse, error) {
+	ctx, cancel := callContext(ctx, r.opTimeout)
+	defer cancel()
+
 	// Set defaults
 	if filter.Page <= 0 {
 		filter.Page = 1
@@ -479,7 +1049,7 @@ func (r *userLogRepository) buildLogFilter(filter models.LogFilterRequest) bson.
 	mongoFilter := bson.M{}
 
 	if filter.UserID != nil {
-		mongoFilter["user_id"] = filter.UserID.String()
+		mongoFilter["user_id"] = logUserIDValue(*filter.UserID)
 	}
 
 	if filter.Event != nil {
@@ -494,6 +1064,14 @@ func (r *userLogRepository) buildLogFilter(filter models.LogFilterRequest) bson.
 		mongoFilter["data.action"] = bson.M{"$regex": filter.Action, "$options": "i"}
 	}
 
+	if filter.BatchID != "" {
+		mongoFilter["batch_id"] = filter.BatchID
+	}
+
+	if filter.BatchSummaries {
+		mongoFilter["batch_summary"] = true
+	}
+
 	// Date range filter
 	if filter.StartDate != nil || filter.EndDate != nil {
 		timeFilter := bson.M{}
@@ -2,33 +2,91 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"user_mgmt_go/internal/config"
 	"user_mgmt_go/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // userRepository implements the UserRepository interface
 type userRepository struct {
-	db *gorm.DB
+	db              *gorm.DB
+	searchThreshold float64 // pg_trgm similarity threshold, 0 disables fuzzy search
+	residency       config.ResidencyConfig
 }
 
-// NewUserRepository creates a new user repository instance
-func NewUserRepository(db *gorm.DB) UserRepository {
+// NewUserRepository creates a new user repository instance.
+// searchThreshold enables typo-tolerant search when greater than zero and
+// requires the pg_trgm extension. When residency.Region is set, the
+// repository only reads and writes users of that region.
+func NewUserRepository(db *gorm.DB, searchThreshold float64, residency config.ResidencyConfig) UserRepository {
 	return &userRepository{
-		db: db,
+		db:              db,
+		searchThreshold: searchThreshold,
+		residency:       residency,
 	}
 }
 
+// scoped returns a query limited to the users this instance may access. All
+// reads and writes except email existence checks go through it.
+func (r *userRepository) scoped(ctx context.Context) *gorm.DB {
+	return r.inRegion(r.db.WithContext(ctx))
+}
+
+// inRegion limits query to this instance's region, like scoped
+func (r *userRepository) inRegion(query *gorm.DB) *gorm.DB {
+	if r.residency.Region != "" {
+		query = query.Where("region = ?", r.residency.Region)
+	}
+	return query
+}
+
+// assignRegion defaults the user's region and rejects regions this instance
+// may not store
+func (r *userRepository) assignRegion(user *models.User) error {
+	region, err := resolveRegion(r.residency, user.Region)
+	if err != nil {
+		return err
+	}
+	user.Region = region
+	return nil
+}
+
+// resolveRegion returns the region a user requesting region is stored in,
+// or ErrRegionNotAllowed when this instance may not store it
+func resolveRegion(residency config.ResidencyConfig, region string) (string, error) {
+	if region == "" {
+		region = residency.DefaultRegion
+		if residency.Region != "" {
+			region = residency.Region
+		}
+	}
+	if residency.Region != "" && region != residency.Region {
+		return region, fmt.Errorf("user region %q on %q instance: %w", region, residency.Region, ErrRegionNotAllowed)
+	}
+	if !residency.IsAllowedRegion(region) {
+		return region, fmt.Errorf("user region %q: %w", region, ErrRegionNotAllowed)
+	}
+	return region, nil
+}
+
 // Create creates a new user in the database
 func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	if err := r.assignRegion(user); err != nil {
+		return err
+	}
+
 	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
-		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
-			return fmt.Errorf("user with email %s already exists", user.Email)
+		if err = translateError(err); errors.Is(err, ErrDuplicate) {
+			return fmt.Errorf("user with email %s: %w", user.Email, ErrEmailTaken)
 		}
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -38,21 +96,39 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 // GetByID retrieves a user by ID
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var user models.User
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
+	if err := r.scoped(ctx).Where("id = ?", id).First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user with ID %s not found", id)
+			return nil, fmt.Errorf("user with ID %s: %w", id, ErrUserNotFound)
 		}
 		return nil, fmt.Errorf("failed to get user by ID: %w", err)
 	}
 	return &user, nil
 }
 
+// GetByIDs retrieves users by ID in one query, keyed by ID. IDs without a
+// user are absent from the result.
+func (r *userRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.User, error) {
+	users := make(map[uuid.UUID]*models.User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	var found []models.User
+	if err := r.scoped(ctx).Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to get users by ID: %w", err)
+	}
+	for i := range found {
+		users[found[i].ID] = &found[i]
+	}
+	return users, nil
+}
+
 // GetByEmail retrieves a user by email
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.scoped(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user with email %s not found", email)
+			return nil, fmt.Errorf("user with email %s: %w", email, ErrUserNotFound)
 		}
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
@@ -61,29 +137,43 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 
 // Update updates a user's fields
 func (r *userRepository) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
-	result := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(updates)
+	result := r.scoped(ctx).Model(&models.User{}).Where("id = ?", id).Updates(updates)
 	if result.Error != nil {
-		if strings.Contains(result.Error.Error(), "duplicate key") || strings.Contains(result.Error.Error(), "unique constraint") {
-			return fmt.Errorf("email already exists")
+		err := translateError(result.Error)
+		if errors.Is(err, ErrDuplicate) {
+			return ErrEmailTaken
 		}
-		return fmt.Errorf("failed to update user: %w", result.Error)
+		return fmt.Errorf("failed to update user: %w", err)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user with ID %s not found", id)
+		return fmt.Errorf("user with ID %s: %w", id, ErrUserNotFound)
 	}
 	return nil
 }
 
-// Delete soft deletes a user
+// Delete soft deletes a user.
+// Returns ErrUserAlreadyDeleted if the user is already soft-deleted and
+// ErrUserNotFound if no such user exists, so retries can be treated as idempotent.
 func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).Delete(&models.User{}, id)
+	result := r.scoped(ctx).Delete(&models.User{}, id)
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete user: %w", result.Error)
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("user with ID %s not found", id)
+	if result.RowsAffected > 0 {
+		return nil
 	}
-	return nil
+
+	// Nothing was deleted - check whether the user was already soft-deleted
+	var count int64
+	if err := r.scoped(ctx).Unscoped().Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check deleted user: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("user with ID %s: %w", id, ErrUserAlreadyDeleted)
+	}
+	return fmt.Errorf("user with ID %s: %w", id, ErrUserNotFound)
 }
 
 // List retrieves users with pagination and filtering
@@ -98,7 +188,7 @@ func (r *userRepository) List(ctx context.Context, params ListParams) (*models.U
 	var total int64
 
 	// Base query
-	query := r.db.WithContext(ctx).Model(&models.User{})
+	query := r.scoped(ctx).Model(&models.User{})
 
 	// Count total records
 	if err := query.Count(&total).Error; err != nil {
@@ -132,7 +222,7 @@ func (r *userRepository) List(ctx context.Context, params ListParams) (*models.U
 // Count returns the total number of users matching the filter
 func (r *userRepository) Count(ctx context.Context, filter UserFilter) (int64, error) {
 	var count int64
-	query := r.db.WithContext(ctx).Model(&models.User{})
+	query := r.scoped(ctx).Model(&models.User{})
 	
 	// Apply filters
 	query = r.applyUserFilters(query, filter)
@@ -150,6 +240,12 @@ func (r *userRepository) CreateBatch(ctx context.Context, users []*models.User)
 		return nil
 	}
 
+	for _, user := range users {
+		if err := r.assignRegion(user); err != nil {
+			return err
+		}
+	}
+
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.CreateInBatches(users, 100).Error; err != nil {
 			return fmt.Errorf("failed to create users in batch: %w", err)
@@ -164,7 +260,7 @@ func (r *userRepository) DeleteBatch(ctx context.Context, ids []uuid.UUID) error
 		return nil
 	}
 
-	result := r.db.WithContext(ctx).Delete(&models.User{}, ids)
+	result := r.scoped(ctx).Delete(&models.User{}, ids)
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete users in batch: %w", result.Error)
 	}
@@ -172,8 +268,28 @@ func (r *userRepository) DeleteBatch(ctx context.Context, ids []uuid.UUID) error
 	return nil
 }
 
-// Search searches users by name or email
+// Search searches users by name or email.
+// Substring matches are always included; when fuzzy search is enabled,
+// near matches (e.g. "jhon.doe" for "john.doe") are included and ranked
+// by similarity ahead of the requested sort order.
 func (r *userRepository) Search(ctx context.Context, query string, params ListParams) (*models.UsersListResponse, error) {
+	return r.search(ctx, query, UserFilter{}, params, true)
+}
+
+// SearchByName searches users by name only, like Search, for callers that
+// must not reveal which emails exist
+func (r *userRepository) SearchByName(ctx context.Context, query string, params ListParams) (*models.UsersListResponse, error) {
+	return r.search(ctx, query, UserFilter{}, params, false)
+}
+
+// ListFiltered lists users matching filter and, when query is not empty,
+// the search query as in Search
+func (r *userRepository) ListFiltered(ctx context.Context, query string, filter UserFilter, params ListParams) (*models.UsersListResponse, error) {
+	return r.search(ctx, query, filter, params, true)
+}
+
+// search implements Search, SearchByName and ListFiltered
+func (r *userRepository) search(ctx context.Context, query string, filter UserFilter, params ListParams, matchEmail bool) (*models.UsersListResponse, error) {
 	params.SetDefaults()
 	
 	if !IsValidUserSortField(params.SortBy) {
@@ -183,26 +299,77 @@ func (r *userRepository) Search(ctx context.Context, query string, params ListPa
 	var users []models.User
 	var total int64
 
-	searchTerm := "%" + strings.ToLower(query) + "%"
-	
-	// Build search query
-	dbQuery := r.db.WithContext(ctx).Model(&models.User{}).Where(
-		"LOWER(name) LIKE ? OR LOWER(email) LIKE ?", 
-		searchTerm, searchTerm,
-	)
+	lowerQuery := strings.ToLower(query)
+	searchTerm := "%" + lowerQuery + "%"
+	fuzzy := query != "" && r.searchThreshold > 0
+
+	find := func(db *gorm.DB) error {
+		// Build search query. Near matches use the <% operator, which the
+		// trigram indexes serve, with the threshold set on the transaction.
+		dbQuery := r.applyUserFilters(r.inRegion(db).Model(&models.User{}), filter)
+		switch {
+		case query == "":
+			// Filters only
+		case !matchEmail && fuzzy:
+			dbQuery = dbQuery.Where("LOWER(name) LIKE ? OR ? <% LOWER(name)", searchTerm, lowerQuery)
+		case !matchEmail:
+			dbQuery = dbQuery.Where("LOWER(name) LIKE ?", searchTerm)
+		case fuzzy:
+			dbQuery = dbQuery.Where(
+				"LOWER(name) LIKE ? OR LOWER(email) LIKE ? OR ? <% LOWER(name) OR ? <% LOWER(email)",
+				searchTerm, searchTerm, lowerQuery, lowerQuery,
+			)
+		default:
+			dbQuery = dbQuery.Where(
+				"LOWER(name) LIKE ? OR LOWER(email) LIKE ?", 
+				searchTerm, searchTerm,
+			)
+		}
+
+		// Count total matching records
+		if err := dbQuery.Count(&total).Error; err != nil {
+			return fmt.Errorf("failed to count search results: %w", err)
+		}
 
-	// Count total matching records
-	if err := dbQuery.Count(&total).Error; err != nil {
-		return nil, fmt.Errorf("failed to count search results: %w", err)
+		// Apply pagination and sorting
+		orderClause := fmt.Sprintf("%s %s", params.SortBy, strings.ToUpper(params.SortDir))
+		var order interface{} = orderClause
+		if fuzzy && matchEmail {
+			// Best matches first when fuzzy search is enabled, then the requested order
+			order = clause.OrderBy{Expression: clause.Expr{
+				SQL:  "GREATEST(word_similarity(?, LOWER(name)), word_similarity(?, LOWER(email))) DESC, " + orderClause,
+				Vars: []interface{}{lowerQuery, lowerQuery},
+			}}
+		} else if fuzzy {
+			order = clause.OrderBy{Expression: clause.Expr{
+				SQL:  "word_similarity(?, LOWER(name)) DESC, " + orderClause,
+				Vars: []interface{}{lowerQuery},
+			}}
+		}
+
+		if err := dbQuery.Order(order).
+			Offset(params.GetOffset()).
+			Limit(params.GetLimit()).
+			Find(&users).Error; err != nil {
+			return fmt.Errorf("failed to search users: %w", err)
+		}
+		return nil
 	}
 
-	// Apply pagination and sorting
-	orderClause := fmt.Sprintf("%s %s", params.SortBy, strings.ToUpper(params.SortDir))
-	if err := dbQuery.Order(orderClause).
-		Offset(params.GetOffset()).
-		Limit(params.GetLimit()).
-		Find(&users).Error; err != nil {
-		return nil, fmt.Errorf("failed to search users: %w", err)
+	var err error
+	if fuzzy {
+		err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			threshold := strconv.FormatFloat(r.searchThreshold, 'f', -1, 64)
+			if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", threshold).Error; err != nil {
+				return fmt.Errorf("failed to set search similarity threshold: %w", err)
+			}
+			return find(tx)
+		})
+	} else {
+		err = find(r.db.WithContext(ctx))
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	// Convert to response format
@@ -220,7 +387,8 @@ func (r *userRepository) Search(ctx context.Context, query string, params ListPa
 	}, nil
 }
 
-// Exists checks if a user with the given email exists
+// Exists checks if a user with the given email exists in any region, since
+// emails are unique across regions
 func (r *userRepository) Exists(ctx context.Context, email string) (bool, error) {
 	var count int64
 	if err := r.db.WithContext(ctx).Model(&models.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
@@ -229,8 +397,9 @@ func (r *userRepository) Exists(ctx context.Context, email string) (bool, error)
 	return count > 0, nil
 }
 
-// GetAllDeleted retrieves all soft-deleted users
-func (r *userRepository) GetAllDeleted(ctx context.Context, params ListParams) (*models.UsersListResponse, error) {
+// GetAllDeleted retrieves soft-deleted users, optionally filtered by name/email
+// and deletion date range
+func (r *userRepository) GetAllDeleted(ctx context.Context, params ListParams, filter DeletedUserFilter) (*models.UsersListResponse, error) {
 	params.SetDefaults()
 	
 	if !IsValidUserSortField(params.SortBy) {
@@ -241,7 +410,19 @@ func (r *userRepository) GetAllDeleted(ctx context.Context, params ListParams) (
 	var total int64
 
 	// Query only soft-deleted records
-	query := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).Where("deleted_at IS NOT NULL")
+	query := r.scoped(ctx).Unscoped().Model(&models.User{}).Where("deleted_at IS NOT NULL")
+
+	// Apply filters
+	if filter.Search != "" {
+		searchTerm := "%" + strings.ToLower(filter.Search) + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(email) LIKE ?", searchTerm, searchTerm)
+	}
+	if filter.DeletedAfter != nil {
+		query = query.Where("deleted_at >= ?", *filter.DeletedAfter)
+	}
+	if filter.DeletedBefore != nil {
+		query = query.Where("deleted_at <= ?", *filter.DeletedBefore)
+	}
 
 	// Count total deleted records
 	if err := query.Count(&total).Error; err != nil {
@@ -272,30 +453,133 @@ func (r *userRepository) GetAllDeleted(ctx context.Context, params ListParams) (
 	}, nil
 }
 
+// GetDeletedByID retrieves a soft-deleted user by ID, including deleted_at.
+// Returns ErrUserNotFound if no soft-deleted user has the given ID.
+func (r *userRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := r.scoped(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("deleted user with ID %s: %w", id, ErrUserNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deleted user by ID: %w", err)
+	}
+	return &user, nil
+}
+
 // RestoreDeleted restores a soft-deleted user
 func (r *userRepository) RestoreDeleted(ctx context.Context, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	result := r.scoped(ctx).Unscoped().Model(&models.User{}).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
 	if result.Error != nil {
 		return fmt.Errorf("failed to restore user: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("deleted user with ID %s not found", id)
+		return fmt.Errorf("deleted user with ID %s: %w", id, ErrUserNotFound)
 	}
 	return nil
 }
 
 // PermanentDelete permanently deletes a user from the database
 func (r *userRepository) PermanentDelete(ctx context.Context, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).Unscoped().Delete(&models.User{}, id)
+	result := r.scoped(ctx).Unscoped().Delete(&models.User{}, id)
 	if result.Error != nil {
 		return fmt.Errorf("failed to permanently delete user: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user with ID %s not found", id)
+		return fmt.Errorf("user with ID %s: %w", id, ErrUserNotFound)
 	}
 	return nil
 }
 
+// ListIDsByEmailDomain lists the IDs of users, soft-deleted ones included,
+// with an email at domain or one of its subdomains, created before
+// createdBefore
+func (r *userRepository) ListIDsByEmailDomain(ctx context.Context, domain string, createdBefore time.Time, limit int) ([]uuid.UUID, error) {
+	domain = strings.ToLower(domain)
+	var ids []uuid.UUID
+	if err := r.scoped(ctx).Unscoped().Model(&models.User{}).
+		Where("(LOWER(email) LIKE ? OR LOWER(email) LIKE ?) AND created_at < ?", "%@"+domain, "%@%."+domain, createdBefore).
+		Order("created_at, id").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to list users by email domain: %w", err)
+	}
+	return ids, nil
+}
+
+// userChangedAt is when a user row last changed: its latest write or its
+// soft deletion, which does not touch updated_at. It matches the expression
+// of idx_users_changed_at.
+const userChangedAt = "GREATEST(created_at, updated_at, deleted_at)"
+
+// ListChangedSince lists users, soft-deleted ones included, that changed
+// after the (since, afterID) position, oldest change first and ties broken
+// by ID. Users created after since are reported as created.
+func (r *userRepository) ListChangedSince(ctx context.Context, since time.Time, afterID uuid.UUID, limit int) ([]models.UserChange, error) {
+	var rows []struct {
+		ID        uuid.UUID
+		CreatedAt time.Time
+		DeletedAt *time.Time
+		ChangedAt time.Time
+	}
+	if err := r.scoped(ctx).Unscoped().Model(&models.User{}).
+		Select("id, created_at, deleted_at, "+userChangedAt+" AS changed_at").
+		Where("("+userChangedAt+", id) > (?, ?)", since, afterID).
+		Order(userChangedAt + ", id").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list changed users: %w", err)
+	}
+
+	changes := make([]models.UserChange, len(rows))
+	for i, row := range rows {
+		change := models.UserChangeUpdated
+		switch {
+		case row.DeletedAt != nil:
+			change = models.UserChangeDeleted
+		case row.CreatedAt.After(since):
+			change = models.UserChangeCreated
+		}
+		changes[i] = models.UserChange{UserID: row.ID, Change: change, ChangedAt: row.ChangedAt}
+	}
+	return changes, nil
+}
+
+// CountCreatedAndDeletedByDay counts the users created and the users
+// soft-deleted on each of days consecutive days starting at from, oldest
+// first. Users deleted since are still counted as created.
+func (r *userRepository) CountCreatedAndDeletedByDay(ctx context.Context, from time.Time, days int) (created, deleted []int64, err error) {
+	if created, err = r.countByDay(ctx, "created_at", from, days); err != nil {
+		return nil, nil, err
+	}
+	if deleted, err = r.countByDay(ctx, "deleted_at", from, days); err != nil {
+		return nil, nil, err
+	}
+	return created, deleted, nil
+}
+
+// countByDay counts the users whose timestamp column falls on each day
+func (r *userRepository) countByDay(ctx context.Context, column string, from time.Time, days int) ([]int64, error) {
+	var rows []struct {
+		Day   int
+		Count int64
+	}
+	if err := r.scoped(ctx).Unscoped().Model(&models.User{}).
+		Select("FLOOR(EXTRACT(EPOCH FROM "+column+" - ?) / 86400)::int AS day, COUNT(*) AS count", from).
+		Where(column+" >= ? AND "+column+" < ?", from, from.AddDate(0, 0, days)).
+		Group("day").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count users by %s: %w", column, err)
+	}
+
+	counts := make([]int64, days)
+	for _, row := range rows {
+		if row.Day >= 0 && row.Day < days {
+			counts[row.Day] = row.Count
+		}
+	}
+	return counts, nil
+}
+
 // applyUserFilters applies filters to the query
 func (r *userRepository) applyUserFilters(query *gorm.DB, filter UserFilter) *gorm.DB {
 	if filter.Email != "" {
@@ -305,6 +589,14 @@ func (r *userRepository) applyUserFilters(query *gorm.DB, filter UserFilter) *go
 	if filter.Name != "" {
 		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(filter.Name)+"%")
 	}
+
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+
+	if filter.Region != "" {
+		query = query.Where("region = ?", filter.Region)
+	}
 	
 	if filter.CreatedAt != nil {
 		if filter.CreatedAt.From != nil {
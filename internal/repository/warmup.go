@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"gorm.io/gorm"
+)
+
+// Warmup opens up to connections PostgreSQL pool connections at once and
+// runs the hot-path lookups on each, so connection setup and the driver's
+// per-connection statement cache are paid for before the first request.
+// MongoDB is pinged and the audit log queried once to settle its pool.
+func (rm *RepositoryManager) Warmup(ctx context.Context, connections int) error {
+	// Connections beyond the idle limit would be closed again once released
+	if connections > postgresMaxIdleConns {
+		connections = postgresMaxIdleConns
+	}
+
+	// Hold every connection until all are open so each one is distinct
+	var opened, done sync.WaitGroup
+	opened.Add(connections)
+	done.Add(connections)
+	errs := make([]error, connections)
+	for i := 0; i < connections; i++ {
+		go func(i int) {
+			defer done.Done()
+			entered := false
+			errs[i] = rm.Database.PostgreSQL.WithContext(ctx).Connection(func(tx *gorm.DB) error {
+				entered = true
+				opened.Done()
+				err := rm.primeStatements(ctx, tx)
+				waitOrCancel(ctx, &opened)
+				return err
+			})
+			if !entered {
+				opened.Done()
+			}
+		}(i)
+	}
+	done.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to warm PostgreSQL pool: %w", err)
+	}
+
+	// Settle the MongoDB pool
+	collection := rm.Database.MongoDB.Collection(models.UserLog{}.CollectionName())
+	if _, err := collection.CountDocuments(ctx, bson.M{"user_id": uuid.Nil.String()}); err != nil {
+		return fmt.Errorf("failed to warm MongoDB: %w", err)
+	}
+
+	return nil
+}
+
+// primeStatements runs the lookups made by logins and authenticated
+// requests through repositories bound to a single pooled connection
+func (rm *RepositoryManager) primeStatements(ctx context.Context, tx *gorm.DB) error {
+	users := NewUserRepository(tx, 0, rm.config.Residency)
+	if _, err := users.GetByID(ctx, uuid.Nil); err != nil && !errors.Is(err, ErrUserNotFound) {
+		return err
+	}
+	if _, err := users.GetByEmail(ctx, ""); err != nil && !errors.Is(err, ErrUserNotFound) {
+		return err
+	}
+
+	sessions := NewSessionRepository(tx)
+	if _, err := sessions.GetByID(ctx, uuid.Nil); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+
+	_, err := NewProfileFieldRepository(tx).ListRequired(ctx)
+	return err
+}
+
+// waitOrCancel waits for wg unless ctx is done first
+func waitOrCancel(ctx context.Context, wg *sync.WaitGroup) {
+	ready := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(ready)
+	}()
+	select {
+	case <-ready:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,209 @@
+// Package scanner checks uploaded files for malware before they are stored
+// or processed. Scanning is delegated to clamd or an external HTTP API;
+// without a provider every upload is accepted.
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"user_mgmt_go/internal/config"
+)
+
+// Verdict is the outcome of scanning one upload
+type Verdict struct {
+	Infected  bool
+	Threat    string // Signature or reason reported by the scanner
+	Scanner   string // Provider that produced the verdict
+	Unscanned bool   // The scanner failed and the upload was let through
+}
+
+// Scanner scans uploads. An error means the upload could not be scanned,
+// not that it is infected.
+type Scanner interface {
+	Scan(ctx context.Context, name string, r io.Reader) (Verdict, error)
+}
+
+// New creates the scanner described by the configuration
+func New(cfg config.ScannerConfig) Scanner {
+	var s Scanner
+	switch cfg.Provider {
+	case config.ScannerClamAV:
+		s = NewClamAV(cfg.ClamAVAddress, cfg.Timeout)
+	case config.ScannerHTTP:
+		s = NewHTTP(cfg.APIURL, cfg.APIToken, cfg.Timeout)
+	default:
+		return Nop{}
+	}
+	if cfg.FailOpen {
+		return FailOpen(s)
+	}
+	return s
+}
+
+// FailOpen wraps a scanner so uploads it cannot scan are let through,
+// flagged as unscanned, instead of failing
+func FailOpen(next Scanner) Scanner {
+	return failOpenScanner{next: next}
+}
+
+// failOpenScanner lets uploads through when scanning fails
+type failOpenScanner struct {
+	next Scanner
+}
+
+// Scan scans the upload, reporting it unscanned if the scanner fails
+func (f failOpenScanner) Scan(ctx context.Context, name string, r io.Reader) (Verdict, error) {
+	verdict, err := f.next.Scan(ctx, name, r)
+	if err != nil {
+		log.Printf("⚠️  Upload %q accepted without scanning: %v", name, err)
+		return Verdict{Scanner: verdict.Scanner, Unscanned: true}, nil
+	}
+	return verdict, nil
+}
+
+// Nop accepts every upload without scanning it
+type Nop struct{}
+
+// Scan reports the upload clean
+func (Nop) Scan(ctx context.Context, name string, r io.Reader) (Verdict, error) {
+	return Verdict{Scanner: config.ScannerNone}, nil
+}
+
+// clamAVChunkSize is the largest chunk streamed to clamd at once
+const clamAVChunkSize = 64 * 1024
+
+// ClamAV scans uploads with clamd's INSTREAM command over TCP
+type ClamAV struct {
+	address string
+	timeout time.Duration
+}
+
+// NewClamAV creates a scanner talking to clamd at address
+func NewClamAV(address string, timeout time.Duration) *ClamAV {
+	return &ClamAV{address: address, timeout: timeout}
+}
+
+// Scan streams the upload to clamd and parses its reply, which is
+// "stream: OK", "stream: <signature> FOUND" or "<reason> ERROR"
+func (s *ClamAV) Scan(ctx context.Context, name string, r io.Reader) (Verdict, error) {
+	verdict := Verdict{Scanner: config.ScannerClamAV}
+
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return verdict, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if s.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(s.timeout))
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return verdict, fmt.Errorf("failed to start clamd scan: %w", err)
+	}
+
+	// Each chunk is prefixed with its length; a zero length ends the stream
+	buf := make([]byte, clamAVChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return verdict, fmt.Errorf("failed to stream upload to clamd: %w", err)
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return verdict, fmt.Errorf("failed to stream upload to clamd: %w", err)
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return verdict, fmt.Errorf("failed to read upload: %w", readErr)
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return verdict, fmt.Errorf("failed to finish clamd scan: %w", err)
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return verdict, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	result := strings.TrimSpace(strings.TrimRight(string(reply), "\x00"))
+	result = strings.TrimPrefix(result, "stream: ")
+
+	switch {
+	case result == "OK":
+		return verdict, nil
+	case strings.HasSuffix(result, " FOUND"):
+		verdict.Infected = true
+		verdict.Threat = strings.TrimSuffix(result, " FOUND")
+		return verdict, nil
+	default:
+		return verdict, fmt.Errorf("clamd scan failed: %s", result)
+	}
+}
+
+// HTTP scans uploads with an external API. The upload is POSTed as the
+// request body, with its file name in the X-File-Name header, and the API
+// answers 200 with {"infected": bool, "threat": string}.
+type HTTP struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewHTTP creates a scanner POSTing uploads to url
+func NewHTTP(url, token string, timeout time.Duration) *HTTP {
+	return &HTTP{url: url, token: token, client: &http.Client{Timeout: timeout}}
+}
+
+// Scan submits the upload and decodes the verdict
+func (s *HTTP) Scan(ctx context.Context, name string, r io.Reader) (Verdict, error) {
+	verdict := Verdict{Scanner: config.ScannerHTTP}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, r)
+	if err != nil {
+		return verdict, fmt.Errorf("failed to create scan request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-File-Name", name)
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return verdict, fmt.Errorf("failed to reach scanner: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return verdict, fmt.Errorf("scanner responded %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	var result struct {
+		Infected bool   `json:"infected"`
+		Threat   string `json:"threat"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return verdict, fmt.Errorf("failed to decode scanner verdict: %w", err)
+	}
+	verdict.Infected = result.Infected
+	verdict.Threat = result.Threat
+	return verdict, nil
+}
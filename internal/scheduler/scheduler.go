@@ -0,0 +1,150 @@
+// Package scheduler runs periodic background jobs. When several instances
+// run, a shared LockProvider and StateStore ensure each job executes once
+// per interval across the fleet.
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// LockProvider provides cluster-wide mutual exclusion for jobs
+type LockProvider interface {
+	// TryLock attempts to acquire the named lock without blocking. When
+	// acquired, release must be called once the job finishes. Locks held by
+	// a crashed instance must be released automatically so another
+	// instance can take over.
+	TryLock(ctx context.Context, name string) (release func(), acquired bool, err error)
+}
+
+// StateStore records when jobs last ran so an interval is honoured across instances
+type StateStore interface {
+	LastRun(ctx context.Context, name string) (time.Time, error)
+	RecordRun(ctx context.Context, name string, startedAt time.Time, duration time.Duration, runErr error) error
+}
+
+// Job is a periodic unit of work
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Timeout  time.Duration // Defaults to Interval
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs registered jobs on their intervals
+type Scheduler struct {
+	locks LockProvider
+	state StateStore
+	jobs  []Job
+
+	// ctx is the parent of every job run; Stop cancels it so running jobs
+	// return instead of holding up shutdown until their timeout
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// New creates a scheduler using the given lock provider and state store
+func New(locks LockProvider, state StateStore) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		locks:  locks,
+		state:  state,
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Register adds a job; it must be called before Start
+func (s *Scheduler) Register(job Job) {
+	if job.Timeout <= 0 {
+		job.Timeout = job.Interval
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start launches a goroutine per registered job
+func (s *Scheduler) Start() {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(job)
+		log.Printf("⏰ Scheduled job %s every %s", job.Name, job.Interval)
+	}
+}
+
+// Stop signals all jobs to stop, cancels the context of running jobs and
+// waits for them to return
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.cancel()
+	s.wg.Wait()
+}
+
+// loop checks whether the job is due every poll interval
+func (s *Scheduler) loop(job Job) {
+	defer s.wg.Done()
+
+	// Poll more often than the interval so a crashed instance's job is
+	// picked up by another instance soon after it becomes due
+	poll := job.Interval
+	if poll > time.Minute {
+		poll = time.Minute
+	}
+
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	s.runIfDue(job)
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.runIfDue(job)
+		}
+	}
+}
+
+// runIfDue runs the job if this instance holds its lock and the interval has elapsed
+func (s *Scheduler) runIfDue(job Job) {
+	ctx, cancel := context.WithTimeout(s.ctx, job.Timeout)
+	defer cancel()
+
+	release, acquired, err := s.locks.TryLock(ctx, job.Name)
+	if err != nil {
+		log.Printf("⚠️  Job %s: failed to acquire lock: %v", job.Name, err)
+		return
+	}
+	if !acquired {
+		// Another instance is running it
+		return
+	}
+	defer release()
+
+	lastRun, err := s.state.LastRun(ctx, job.Name)
+	if err != nil {
+		log.Printf("⚠️  Job %s: failed to read last run: %v", job.Name, err)
+		return
+	}
+	if time.Since(lastRun) < job.Interval {
+		return
+	}
+
+	startedAt := time.Now()
+	runErr := job.Run(ctx)
+	duration := time.Since(startedAt)
+
+	if runErr != nil {
+		log.Printf("❌ Job %s failed after %s: %v", job.Name, duration, runErr)
+	} else {
+		log.Printf("✅ Job %s completed in %s", job.Name, duration)
+	}
+
+	if err := s.state.RecordRun(context.Background(), job.Name, startedAt, duration, runErr); err != nil {
+		log.Printf("⚠️  Job %s: failed to record run: %v", job.Name, err)
+	}
+}
@@ -0,0 +1,142 @@
+// Package storage stores user-uploaded assets such as avatars behind a small
+// interface so the backing store can change without touching the handlers.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when no object is stored under the given key
+var ErrNotFound = errors.New("object not found")
+
+// Object describes a stored object
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ETag        string // Quoted strong validator for HTTP caching
+	ModTime     time.Time
+}
+
+// Storage stores objects under slash-separated keys such as "avatars/abc.png"
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) (*Object, error)
+	Get(ctx context.Context, key string) (io.ReadSeekCloser, *Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// localStorage implements Storage on the local filesystem
+type localStorage struct {
+	root string
+}
+
+// NewLocal creates a filesystem storage rooted at dir, creating it if needed
+func NewLocal(dir string) (Storage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &localStorage{root: dir}, nil
+}
+
+// Put writes the object atomically so readers never see a partial file
+func (s *localStorage) Put(ctx context.Context, key string, r io.Reader) (*Object, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return nil, fmt.Errorf("failed to store object: %w", err)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+	return newObject(key, info), nil
+}
+
+// Get opens an object for reading; the caller must close it
+func (s *localStorage) Get(ctx context.Context, key string) (io.ReadSeekCloser, *Object, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+		}
+		return nil, nil, fmt.Errorf("failed to open object: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+	return file, newObject(key, info), nil
+}
+
+// Delete removes an object; deleting a missing object is not an error
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// path maps a key to a file under the root, rejecting keys that escape it
+func (s *localStorage) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
+
+// newObject describes a stored file. Objects are written once under a new
+// key, so size and modification time identify the content.
+func newObject(key string, info os.FileInfo) *Object {
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &Object{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: contentType,
+		ETag:        fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()),
+		ModTime:     info.ModTime(),
+	}
+}
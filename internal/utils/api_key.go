@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKeyHeader is the request header carrying an API key
+const APIKeyHeader = "X-API-Key"
+
+// API key layout: a recognizable prefix followed by 32 random bytes
+const (
+	apiKeyPrefix       = "umk_"
+	apiKeyRandomBytes  = 32
+	apiKeyDisplayChars = 12 // Leading characters kept to identify a key
+)
+
+// GenerateAPIKey returns a new API key, the leading characters shown to
+// identify it and the hash stored in its place
+func GenerateAPIKey() (key, displayPrefix, hash string, err error) {
+	bytes := make([]byte, apiKeyRandomBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(bytes)
+	return key, key[:apiKeyDisplayChars], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hex SHA-256 of an API key. Keys carry enough
+// entropy that a fast hash is sufficient.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// LooksLikeAPIKey reports whether a value has the API key layout
+func LooksLikeAPIKey(value string) bool {
+	return strings.HasPrefix(value, apiKeyPrefix) && len(value) > apiKeyDisplayChars
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 
 	"golang.org/x/crypto/bcrypt"
@@ -39,4 +41,18 @@ func IsValidPassword(password string) bool {
 	// - Contains special character
 	
 	return true
-} 
\ No newline at end of file
+} 
+
+// GenerateRandomPassword returns a random URL-safe password of the given length
+func GenerateRandomPassword(length int) (string, error) {
+	if length < 6 {
+		return "", fmt.Errorf("password length must be at least 6")
+	}
+
+	bytes := make([]byte, length)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes)[:length], nil
+}
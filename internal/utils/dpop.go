@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DPoPHeader is the request header carrying a proof-of-possession JWT
+const DPoPHeader = "DPoP"
+
+// dpopClockSkew tolerates proofs created slightly in the future
+const dpopClockSkew = 5 * time.Second
+
+// DPoPVerifier verifies DPoP proofs (RFC 9449). A proof is a JWT signed by a
+// key the client holds, carrying the public key in its header; tokens bound
+// to the key's thumbprint are only accepted with a fresh proof signed by it.
+// Proof IDs are remembered in memory to reject replays on this instance.
+type DPoPVerifier struct {
+	maxAge time.Duration
+	seen   map[string]time.Time // Proof jti -> when it can be forgotten
+	mutex  sync.Mutex
+}
+
+// dpopClaims are the claims of a DPoP proof
+type dpopClaims struct {
+	Method          string `json:"htm"`
+	URI             string `json:"htu"`
+	AccessTokenHash string `json:"ath,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// NewDPoPVerifier creates a verifier accepting proofs up to maxAge old
+func NewDPoPVerifier(maxAge time.Duration) *DPoPVerifier {
+	return &DPoPVerifier{
+		maxAge: maxAge,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// Verify checks a proof for a request and returns the thumbprint of the key
+// that signed it. When accessToken is set, the proof must also carry its hash.
+func (v *DPoPVerifier) Verify(proof, method, uri, accessToken string) (string, error) {
+	if proof == "" {
+		return "", errors.New("DPoP proof is required")
+	}
+
+	var thumbprint string
+	claims := &dpopClaims{}
+	token, err := jwt.ParseWithClaims(proof, claims, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != "dpop+jwt" {
+			return nil, errors.New("proof typ must be dpop+jwt")
+		}
+		jwk, ok := token.Header["jwk"].(map[string]interface{})
+		if !ok {
+			return nil, errors.New("proof has no jwk header")
+		}
+		key, err := publicKeyFromJWK(jwk)
+		if err != nil {
+			return nil, err
+		}
+		thumbprint, err = JWKThumbprint(jwk)
+		return key, err
+	}, jwt.WithValidMethods([]string{"ES256", "RS256"}), jwt.WithIssuedAt())
+	if err != nil || !token.Valid {
+		return "", fmt.Errorf("invalid DPoP proof: %w", err)
+	}
+
+	if !strings.EqualFold(claims.Method, method) {
+		return "", errors.New("DPoP proof htm does not match the request method")
+	}
+	if stripQuery(claims.URI) != stripQuery(uri) {
+		return "", errors.New("DPoP proof htu does not match the request URL")
+	}
+	if claims.IssuedAt == nil {
+		return "", errors.New("DPoP proof has no iat")
+	}
+	issuedAt := claims.IssuedAt.Time
+	if time.Since(issuedAt) > v.maxAge || time.Until(issuedAt) > dpopClockSkew {
+		return "", errors.New("DPoP proof is too old or from the future")
+	}
+	if accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		if claims.AccessTokenHash != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			return "", errors.New("DPoP proof ath does not match the access token")
+		}
+	}
+	if claims.ID == "" {
+		return "", errors.New("DPoP proof has no jti")
+	}
+	if !v.remember(thumbprint+":"+claims.ID, issuedAt.Add(v.maxAge+dpopClockSkew)) {
+		return "", errors.New("DPoP proof has already been used")
+	}
+
+	return thumbprint, nil
+}
+
+// remember records a proof ID, returning false if it was already seen
+func (v *DPoPVerifier) remember(id string, until time.Time) bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	now := time.Now()
+	for seenID, expiry := range v.seen {
+		if now.After(expiry) {
+			delete(v.seen, seenID)
+		}
+	}
+
+	if _, exists := v.seen[id]; exists {
+		return false
+	}
+	v.seen[id] = until
+	return true
+}
+
+// DPoPTargetURI returns the URL a proof for r must name in htu
+func DPoPTargetURI(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+// stripQuery removes the query and fragment, which htu comparison ignores
+func stripQuery(uri string) string {
+	if i := strings.IndexAny(uri, "?#"); i >= 0 {
+		return uri[:i]
+	}
+	return uri
+}
+
+// JWKThumbprint computes the RFC 7638 SHA-256 thumbprint of a public JWK
+func JWKThumbprint(jwk map[string]interface{}) (string, error) {
+	var members []string
+	switch jwk["kty"] {
+	case "EC":
+		members = []string{"crv", "kty", "x", "y"}
+	case "RSA":
+		members = []string{"e", "kty", "n"}
+	default:
+		return "", fmt.Errorf("unsupported jwk kty %v", jwk["kty"])
+	}
+
+	// Required members only, in lexicographic order, without whitespace
+	parts := make([]string, len(members))
+	for i, member := range members {
+		value, ok := jwk[member].(string)
+		if !ok {
+			return "", fmt.Errorf("jwk is missing %s", member)
+		}
+		encoded, _ := json.Marshal(value)
+		parts[i] = fmt.Sprintf("%q:%s", member, encoded)
+	}
+	sum := sha256.Sum256([]byte("{" + strings.Join(parts, ",") + "}"))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// publicKeyFromJWK builds an ECDSA P-256 or RSA public key from a JWK
+func publicKeyFromJWK(jwk map[string]interface{}) (interface{}, error) {
+	if _, hasPrivate := jwk["d"]; hasPrivate {
+		return nil, errors.New("jwk must not contain a private key")
+	}
+
+	decode := func(member string) (*big.Int, error) {
+		value, _ := jwk[member].(string)
+		data, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(data) == 0 {
+			return nil, fmt.Errorf("jwk %s is invalid", member)
+		}
+		return new(big.Int).SetBytes(data), nil
+	}
+
+	switch jwk["kty"] {
+	case "EC":
+		if jwk["crv"] != "P-256" {
+			return nil, fmt.Errorf("unsupported jwk curve %v", jwk["crv"])
+		}
+		x, err := decode("x")
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode("y")
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("jwk point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	case "RSA":
+		n, err := decode("n")
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode("e")
+		if err != nil {
+			return nil, err
+		}
+		if n.BitLen() < 2048 {
+			return nil, errors.New("jwk RSA key must be at least 2048 bits")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	default:
+		return nil, fmt.Errorf("unsupported jwk kty %v", jwk["kty"])
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"user_mgmt_go/internal/models"
@@ -16,27 +17,38 @@ type JWTManager struct {
 	secretKey    string
 	tokenExpiry  time.Duration
 	refreshExpiry time.Duration
+	leeway        time.Duration // Clock skew tolerated for exp and nbf
 }
 
-// NewJWTManager creates a new JWT manager instance
-func NewJWTManager(secretKey string, tokenExpiry time.Duration) *JWTManager {
+// NewJWTManager creates a new JWT manager instance. Tokens are accepted up
+// to leeway past their expiry or before their not-before time, so small
+// clock drift between services does not reject them.
+func NewJWTManager(secretKey string, tokenExpiry, leeway time.Duration) *JWTManager {
 	return &JWTManager{
 		secretKey:     secretKey,
 		tokenExpiry:   tokenExpiry,
 		refreshExpiry: tokenExpiry * 7, // Refresh token lasts 7x longer than access token
+		leeway:        max(leeway, 0),
 	}
 }
 
-// GenerateTokenPair generates both access and refresh tokens for a user
-func (j *JWTManager) GenerateTokenPair(user *models.User, role string) (*models.TokenPair, error) {
+// GenerateTokenPair generates both access and refresh tokens for a user.
+// Both tokens carry sessionID so revoking the session invalidates them.
+func (j *JWTManager) GenerateTokenPair(user *models.User, role string, sessionID string) (*models.TokenPair, error) {
+	return j.GenerateBoundTokenPair(user, role, sessionID, "")
+}
+
+// GenerateBoundTokenPair generates a token pair bound to the DPoP key with
+// thumbprint jkt; an empty jkt generates plain bearer tokens
+func (j *JWTManager) GenerateBoundTokenPair(user *models.User, role, sessionID, jkt string) (*models.TokenPair, error) {
 	// Generate access token
-	accessToken, expiresAt, err := j.generateToken(user, role, j.tokenExpiry, models.AccessToken)
+	accessToken, expiresAt, err := j.generateToken(user, role, sessionID, jkt, j.tokenExpiry, models.AccessToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	// Generate refresh token
-	refreshToken, _, err := j.generateToken(user, role, j.refreshExpiry, models.RefreshToken)
+	refreshToken, _, err := j.generateToken(user, role, sessionID, jkt, j.refreshExpiry, models.RefreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -49,19 +61,22 @@ func (j *JWTManager) GenerateTokenPair(user *models.User, role string) (*models.
 }
 
 // generateToken generates a JWT token with specified duration and type
-func (j *JWTManager) generateToken(user *models.User, role string, duration time.Duration, tokenType models.TokenType) (string, time.Time, error) {
+func (j *JWTManager) generateToken(user *models.User, role string, sessionID string, jkt string, duration time.Duration, tokenType models.TokenType) (string, time.Time, error) {
 	now := time.Now()
 	expiresAt := now.Add(duration)
 
 	// Create JWT claims
 	claims := &models.JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Name:   user.Name,
-		Role:   role,
+		UserID:                    user.ID,
+		Email:                     user.Email,
+		Name:                      user.Name,
+		Role:                      role,
+		PasswordChangeRequired:    user.MustChangePassword,
+		ProfileCompletionRequired: user.ProfileIncomplete,
+		SessionID:                 sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ID:        uuid.New().String(),          // Unique token ID for revocation
-			Subject:   user.ID.String(),             // User ID
+			ID:        uuid.New().String(),                 // Unique token ID for revocation
+			Subject:   user.ID.String(),                    // User ID
 			Audience:  jwt.ClaimStrings{string(tokenType)}, // Token type
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			NotBefore: jwt.NewNumericDate(now),
@@ -69,6 +84,9 @@ func (j *JWTManager) generateToken(user *models.User, role string, duration time
 			Issuer:    "user_mgmt_go",
 		},
 	}
+	if jkt != "" {
+		claims.Confirmation = &models.TokenConfirmation{JWKThumbprint: jkt}
+	}
 
 	// Create token with claims
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -91,7 +109,7 @@ func (j *JWTManager) ValidateToken(tokenString string) (*models.JWTClaims, error
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(j.secretKey), nil
-	})
+	}, jwt.WithLeeway(j.leeway))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenMalformed) {
@@ -111,15 +129,17 @@ func (j *JWTManager) ValidateToken(tokenString string) (*models.JWTClaims, error
 	}
 
 	// Additional validation
-	if !claims.IsValid() {
+	if !claims.IsValid(j.leeway) {
 		return nil, fmt.Errorf("token claims validation failed")
 	}
 
 	return claims, nil
 }
 
-// RefreshAccessToken generates a new access token using a valid refresh token
-func (j *JWTManager) RefreshAccessToken(refreshTokenString string) (*models.RefreshTokenResponse, error) {
+// RefreshAccessToken generates a new access token using a valid refresh token.
+// A refresh token bound to a DPoP key needs proofJKT, the thumbprint of the
+// key that signed the request's proof, to match; the new token stays bound.
+func (j *JWTManager) RefreshAccessToken(refreshTokenString string, proofJKT string) (*models.RefreshTokenResponse, error) {
 	// Validate refresh token
 	claims, err := j.ValidateToken(refreshTokenString)
 	if err != nil {
@@ -131,15 +151,22 @@ func (j *JWTManager) RefreshAccessToken(refreshTokenString string) (*models.Refr
 		return nil, fmt.Errorf("provided token is not a refresh token")
 	}
 
+	// Check proof of possession for bound tokens
+	if jkt := claims.BoundKey(); jkt != "" && jkt != proofJKT {
+		return nil, fmt.Errorf("refresh token is bound to a DPoP key; a proof signed by that key is required")
+	}
+
 	// Create user object from claims
 	user := &models.User{
-		ID:    claims.UserID,
-		Email: claims.Email,
-		Name:  claims.Name,
+		ID:                 claims.UserID,
+		Email:              claims.Email,
+		Name:               claims.Name,
+		MustChangePassword: claims.PasswordChangeRequired,
+		ProfileIncomplete:  claims.ProfileCompletionRequired,
 	}
 
 	// Generate new access token
-	accessToken, expiresAt, err := j.generateToken(user, claims.Role, j.tokenExpiry, models.AccessToken)
+	accessToken, expiresAt, err := j.generateToken(user, claims.Role, claims.SessionID, claims.BoundKey(), j.tokenExpiry, models.AccessToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate new access token: %w", err)
 	}
@@ -156,13 +183,19 @@ func ExtractTokenFromHeader(authHeader string) (string, error) {
 		return "", fmt.Errorf("authorization header is required")
 	}
 
-	// Check for Bearer prefix
+	// Check for Bearer prefix; DPoP-bound tokens use the DPoP scheme
+	var token string
 	const bearerPrefix = "Bearer "
-	if len(authHeader) < len(bearerPrefix) || authHeader[:len(bearerPrefix)] != bearerPrefix {
-		return "", fmt.Errorf("authorization header must start with 'Bearer '")
+	const dpopPrefix = "DPoP "
+	switch {
+	case strings.HasPrefix(authHeader, bearerPrefix):
+		token = authHeader[len(bearerPrefix):]
+	case strings.HasPrefix(authHeader, dpopPrefix):
+		token = authHeader[len(dpopPrefix):]
+	default:
+		return "", fmt.Errorf("authorization header must start with 'Bearer ' or 'DPoP '")
 	}
 
-	token := authHeader[len(bearerPrefix):]
 	if token == "" {
 		return "", fmt.Errorf("token is required")
 	}
@@ -188,7 +221,7 @@ func (j *JWTManager) GenerateTokenForUser(userID uuid.UUID, email, name, role st
 		Name:  name,
 	}
 	
-	return j.GenerateTokenPair(user, role)
+	return j.GenerateTokenPair(user, role, "")
 }
 
 // IsTokenExpired checks if a token is expired without full validation
@@ -0,0 +1,286 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"user_mgmt_go/internal/config"
+)
+
+// metadataTokenURL serves access tokens of the default service account on GCE
+const metadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// BigQuery writes rows with MERGE query jobs through the BigQuery REST API,
+// keyed on the log ID so rows sent again are never stored twice
+type BigQuery struct {
+	endpoint string
+	project  string
+	dataset  string
+	timeout  time.Duration
+	client   *http.Client
+
+	// tokenFile holds an access token refreshed by another process; without
+	// it tokens are fetched from the metadata server and cached until expiry
+	tokenFile    string
+	tokenMu      sync.Mutex
+	token        string
+	tokenExpires time.Time
+}
+
+// NewBigQuery creates a warehouse writing to a dataset through the BigQuery
+// REST API at endpoint
+func NewBigQuery(endpoint, project, dataset, tokenFile string, timeout time.Duration) *BigQuery {
+	return &BigQuery{
+		endpoint:  strings.TrimRight(endpoint, "/"),
+		project:   project,
+		dataset:   dataset,
+		tokenFile: tokenFile,
+		timeout:   timeout,
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+// Target identifies the table as "bigquery:<project>.<dataset>.<table>"
+func (b *BigQuery) Target(table string) string {
+	return fmt.Sprintf("%s:%s.%s.%s", config.WarehouseBigQuery, b.project, b.dataset, table)
+}
+
+// EnsureTable creates the table partitioned by day on its timestamp column.
+// A table that already exists is left as it is.
+func (b *BigQuery) EnsureTable(ctx context.Context, table string) error {
+	type field struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+		Mode string `json:"mode"`
+	}
+	fields := make([]field, len(columns))
+	for i, col := range columns {
+		mode := "REQUIRED"
+		if col.Nullable {
+			mode = "NULLABLE"
+		}
+		fields[i] = field{Name: col.Name, Type: col.BigQuery, Mode: mode}
+	}
+
+	definition := map[string]interface{}{
+		"tableReference": map[string]string{
+			"projectId": b.project,
+			"datasetId": b.dataset,
+			"tableId":   table,
+		},
+		"schema":           map[string]interface{}{"fields": fields},
+		"timePartitioning": map[string]string{"type": "DAY", "field": "timestamp"},
+	}
+
+	path := fmt.Sprintf("/bigquery/v2/projects/%s/datasets/%s/tables", b.project, b.dataset)
+	status, err := b.post(ctx, path, definition, nil)
+	if status == http.StatusConflict {
+		return nil
+	}
+	return err
+}
+
+// Insert merges the rows into the table in one query job, adding only the
+// IDs the table does not hold yet, so a batch sent again after a crash or a
+// timed-out job is not duplicated. The rows are passed as a query parameter;
+// the ON clause is bounded to the batch's time range so the merge reads only
+// the partitions the batch falls in.
+func (b *BigQuery) Insert(ctx context.Context, table, token string, rows []Row) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	structTypes := make([]map[string]interface{}, len(columns))
+	for i, col := range columns {
+		structTypes[i] = map[string]interface{}{"name": col.Name, "type": map[string]string{"type": col.BigQuery}}
+	}
+	values := make([]map[string]interface{}, len(rows))
+	from, to := rows[0].Timestamp, rows[0].Timestamp
+	for i, row := range rows {
+		fields, err := parameterFields(row)
+		if err != nil {
+			return err
+		}
+		values[i] = map[string]interface{}{"structValues": fields}
+		if row.Timestamp.Before(from) {
+			from = row.Timestamp
+		}
+		if row.Timestamp.After(to) {
+			to = row.Timestamp
+		}
+	}
+
+	// Leave the HTTP client time to receive the reply once the job times out
+	jobTimeout := b.timeout * 3 / 4
+
+	query := fmt.Sprintf("MERGE `%s.%s.%s` AS target "+
+		"USING (SELECT * FROM UNNEST(@rows)) AS source "+
+		"ON target.id = source.id AND target.timestamp BETWEEN @from AND @to "+
+		"WHEN NOT MATCHED THEN INSERT ROW", b.project, b.dataset, table)
+	request := map[string]interface{}{
+		"query":         query,
+		"useLegacySql":  false,
+		"parameterMode": "NAMED",
+		"timeoutMs":     jobTimeout.Milliseconds(),
+		"queryParameters": []map[string]interface{}{
+			{
+				"name": "rows",
+				"parameterType": map[string]interface{}{
+					"type":      "ARRAY",
+					"arrayType": map[string]interface{}{"type": "STRUCT", "structTypes": structTypes},
+				},
+				"parameterValue": map[string]interface{}{"arrayValues": values},
+			},
+			timestampParameter("from", from),
+			timestampParameter("to", to),
+		},
+	}
+
+	var response struct {
+		JobComplete bool `json:"jobComplete"`
+		Errors      []struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	path := fmt.Sprintf("/bigquery/v2/projects/%s/queries", b.project)
+	if _, err := b.post(ctx, path, request, &response); err != nil {
+		return err
+	}
+
+	if len(response.Errors) > 0 {
+		return fmt.Errorf("BigQuery failed to merge %d rows (%s: %s)",
+			len(rows), response.Errors[0].Reason, response.Errors[0].Message)
+	}
+	// The job may still finish; sending the batch again is safe either way
+	if !response.JobComplete {
+		return fmt.Errorf("BigQuery did not merge %d rows within %s", len(rows), jobTimeout)
+	}
+	return nil
+}
+
+// parameterFields encodes a row as the struct fields of a query parameter.
+// BigQuery takes every value as a string; a missing value is NULL.
+func parameterFields(row Row) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode row %s: %w", row.ID, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var decoded map[string]interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("failed to encode row %s: %w", row.ID, err)
+	}
+
+	fields := make(map[string]interface{}, len(decoded))
+	for name, value := range decoded {
+		if value == nil {
+			fields[name] = map[string]interface{}{}
+			continue
+		}
+		fields[name] = map[string]string{"value": fmt.Sprint(value)}
+	}
+	return fields, nil
+}
+
+// timestampParameter returns a named TIMESTAMP query parameter
+func timestampParameter(name string, value time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"name":           name,
+		"parameterType":  map[string]string{"type": "TIMESTAMP"},
+		"parameterValue": map[string]string{"value": value.UTC().Format(time.RFC3339Nano)},
+	}
+}
+
+// post sends a JSON request to the API and decodes the reply into result
+// when given. The HTTP status is returned alongside any error.
+func (b *BigQuery) post(ctx context.Context, path string, body, result interface{}) (int, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode BigQuery request: %w", err)
+	}
+	accessToken, err := b.accessToken(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create BigQuery request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reach BigQuery: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("BigQuery responded %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode BigQuery response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// accessToken returns a token for the API, reading the token file on every
+// call so refreshes by another process are picked up
+func (b *BigQuery) accessToken(ctx context.Context) (string, error) {
+	if b.tokenFile != "" {
+		data, err := os.ReadFile(b.tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read BigQuery token file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	b.tokenMu.Lock()
+	defer b.tokenMu.Unlock()
+
+	// Refresh a minute early so a token does not expire mid-request
+	if b.token != "" && time.Now().Add(time.Minute).Before(b.tokenExpires) {
+		return b.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataTokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create metadata token request: %w", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get access token from the metadata server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server responded %d to the token request", resp.StatusCode)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode metadata server token: %w", err)
+	}
+
+	b.token = token.AccessToken
+	b.tokenExpires = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return b.token, nil
+}
@@ -0,0 +1,104 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"user_mgmt_go/internal/config"
+)
+
+// ClickHouse writes rows through the ClickHouse HTTP interface. The table is
+// a ReplacingMergeTree keyed on (timestamp, id), so rows sent twice collapse
+// on merge, and inserts carry a deduplication token so a repeated batch is
+// dropped at once.
+type ClickHouse struct {
+	url      string
+	database string
+	user     string
+	password string
+	client   *http.Client
+}
+
+// NewClickHouse creates a warehouse writing to database through the HTTP
+// interface at baseURL
+func NewClickHouse(baseURL, database, user, password string, timeout time.Duration) *ClickHouse {
+	return &ClickHouse{
+		url:      strings.TrimRight(baseURL, "/"),
+		database: database,
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Target identifies the table as "clickhouse:<database>.<table>"
+func (c *ClickHouse) Target(table string) string {
+	return fmt.Sprintf("%s:%s.%s", config.WarehouseClickHouse, c.database, table)
+}
+
+// EnsureTable creates the table partitioned by day. Deduplication of
+// repeated inserts is enabled with non_replicated_deduplication_window, which
+// replicated tables have by default.
+func (c *ClickHouse) EnsureTable(ctx context.Context, table string) error {
+	definitions := make([]string, len(columns))
+	for i, col := range columns {
+		definitions[i] = fmt.Sprintf("`%s` %s", col.Name, col.ClickHouse)
+	}
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s`.`%s` (%s) "+
+		"ENGINE = ReplacingMergeTree "+
+		"PARTITION BY toYYYYMMDD(timestamp) "+
+		"ORDER BY (timestamp, id) "+
+		"SETTINGS non_replicated_deduplication_window = 1000",
+		c.database, table, strings.Join(definitions, ", "))
+
+	return c.exec(ctx, url.Values{}, strings.NewReader(query))
+}
+
+// Insert sends the rows as JSONEachRow
+func (c *ClickHouse) Insert(ctx context.Context, table, token string, rows []Row) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return fmt.Errorf("failed to encode log %s: %w", row.ID, err)
+		}
+	}
+
+	params := url.Values{}
+	params.Set("query", fmt.Sprintf("INSERT INTO `%s`.`%s` FORMAT JSONEachRow", c.database, table))
+	params.Set("insert_deduplication_token", token)
+	params.Set("date_time_input_format", "best_effort")
+	return c.exec(ctx, params, &body)
+}
+
+// exec POSTs a query, or the data of the query in params, and checks the reply
+func (c *ClickHouse) exec(ctx context.Context, params url.Values, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/?"+params.Encode(), body)
+	if err != nil {
+		return fmt.Errorf("failed to create ClickHouse request: %w", err)
+	}
+	if c.user != "" {
+		req.Header.Set("X-ClickHouse-User", c.user)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach ClickHouse: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("ClickHouse responded %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,130 @@
+// Package warehouse ships audit logs to an analytics warehouse for
+// long-range analysis beyond the MongoDB retention. Logs are written to a
+// table partitioned by day on their timestamp, which the warehouse creates
+// on first use.
+package warehouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/models"
+)
+
+// Row is one audit log as stored in the warehouse. Free-form values are
+// JSON-encoded strings, so the schema stays fixed as log details evolve.
+type Row struct {
+	ID           string    `json:"id"` // Hex ObjectID of the log
+	Timestamp    time.Time `json:"timestamp"`
+	UserID       *string   `json:"user_id"`
+	Event        string    `json:"event"`
+	Action       string    `json:"action"`
+	Details      string    `json:"details"`
+	OldValues    string    `json:"old_values"`
+	NewValues    string    `json:"new_values"`
+	Error        string    `json:"error"`
+	StatusCode   int       `json:"status_code"`
+	DurationMS   int64     `json:"duration_ms"`
+	IPAddress    string    `json:"ip_address"`
+	UserAgent    string    `json:"user_agent"`
+	BatchID      string    `json:"batch_id"`
+	BatchSummary bool      `json:"batch_summary"`
+	AppVersion   string    `json:"app_version"`
+	GitCommit    string    `json:"git_commit"`
+}
+
+// column is a Row field with its type in each warehouse
+type column struct {
+	Name       string
+	BigQuery   string
+	Nullable   bool
+	ClickHouse string
+}
+
+// columns lists the table schema in Row field order. Keep it in sync with Row.
+var columns = []column{
+	{Name: "id", BigQuery: "STRING", ClickHouse: "String"},
+	{Name: "timestamp", BigQuery: "TIMESTAMP", ClickHouse: "DateTime64(3, 'UTC')"},
+	{Name: "user_id", BigQuery: "STRING", Nullable: true, ClickHouse: "Nullable(String)"},
+	{Name: "event", BigQuery: "STRING", ClickHouse: "LowCardinality(String)"},
+	{Name: "action", BigQuery: "STRING", ClickHouse: "LowCardinality(String)"},
+	{Name: "details", BigQuery: "STRING", ClickHouse: "String"},
+	{Name: "old_values", BigQuery: "STRING", ClickHouse: "String"},
+	{Name: "new_values", BigQuery: "STRING", ClickHouse: "String"},
+	{Name: "error", BigQuery: "STRING", ClickHouse: "String"},
+	{Name: "status_code", BigQuery: "INT64", ClickHouse: "Int32"},
+	{Name: "duration_ms", BigQuery: "INT64", ClickHouse: "Int64"},
+	{Name: "ip_address", BigQuery: "STRING", ClickHouse: "String"},
+	{Name: "user_agent", BigQuery: "STRING", ClickHouse: "String"},
+	{Name: "batch_id", BigQuery: "STRING", ClickHouse: "String"},
+	{Name: "batch_summary", BigQuery: "BOOL", ClickHouse: "Bool"},
+	{Name: "app_version", BigQuery: "STRING", ClickHouse: "LowCardinality(String)"},
+	{Name: "git_commit", BigQuery: "STRING", ClickHouse: "LowCardinality(String)"},
+}
+
+// NewRow converts a stored audit log to a warehouse row
+func NewRow(entry models.UserLog) Row {
+	return Row{
+		ID:           entry.ID.Hex(),
+		Timestamp:    entry.Timestamp.UTC(),
+		UserID:       entry.UserID,
+		Event:        string(entry.Event),
+		Action:       entry.Data.Action,
+		Details:      encodeJSON(entry.Data.Details),
+		OldValues:    encodeJSON(entry.Data.OldValues),
+		NewValues:    encodeJSON(entry.Data.NewValues),
+		Error:        entry.Data.Error,
+		StatusCode:   entry.Data.StatusCode,
+		DurationMS:   entry.Data.Duration,
+		IPAddress:    entry.IPAddress,
+		UserAgent:    entry.UserAgent,
+		BatchID:      entry.BatchID,
+		BatchSummary: entry.BatchSummary,
+		AppVersion:   entry.AppVersion,
+		GitCommit:    entry.GitCommit,
+	}
+}
+
+// encodeJSON encodes a free-form log value, or returns "" when it is empty.
+// Values that cannot be encoded are kept as their printed form so one odd
+// log does not hold up the export.
+func encodeJSON(values map[string]interface{}) string {
+	if len(values) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("%v", values))
+	}
+	return string(data)
+}
+
+// Warehouse stores audit log rows
+type Warehouse interface {
+	// Target identifies the destination table, e.g. for checkpoints
+	Target(table string) string
+	// EnsureTable creates the day-partitioned table unless it exists
+	EnsureTable(ctx context.Context, table string) error
+	// Insert writes rows. Inserting a batch again with the same token, as
+	// after a crash before its checkpoint was saved, must not duplicate it.
+	Insert(ctx context.Context, table, token string, rows []Row) error
+}
+
+// New creates the warehouse described by the configuration, or nil when
+// export is disabled
+func New(cfg config.LogExportConfig) Warehouse {
+	if !cfg.Enabled {
+		return nil
+	}
+	switch cfg.Provider {
+	case config.WarehouseClickHouse:
+		return NewClickHouse(cfg.ClickHouseURL, cfg.ClickHouseDatabase, cfg.ClickHouseUser, cfg.ClickHousePassword, cfg.Timeout)
+	case config.WarehouseBigQuery:
+		return NewBigQuery(cfg.BigQueryEndpoint, cfg.BigQueryProject, cfg.BigQueryDataset, cfg.BigQueryTokenFile, cfg.Timeout)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,14 @@
+-- Baseline users table, matching the schema previously created by GORM AutoMigrate
+CREATE TABLE IF NOT EXISTS users (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       varchar(255) NOT NULL,
+    email      varchar(255) NOT NULL,
+    password   varchar(255) NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at);
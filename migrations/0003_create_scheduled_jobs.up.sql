@@ -0,0 +1,8 @@
+-- Run bookkeeping for scheduled jobs, shared by all instances
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    name             varchar(100) PRIMARY KEY,
+    last_run_at      timestamptz NOT NULL,
+    last_duration_ms bigint NOT NULL DEFAULT 0,
+    last_error       text,
+    updated_at       timestamptz NOT NULL DEFAULT now()
+);
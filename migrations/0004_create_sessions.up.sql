@@ -0,0 +1,16 @@
+-- Login sessions; every token pair carries its session ID so a session can be revoked
+CREATE TABLE IF NOT EXISTS sessions (
+    id               uuid PRIMARY KEY,
+    user_id          uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role             varchar(20) NOT NULL,
+    ip_address       varchar(45),
+    user_agent       text,
+    created_at       timestamptz NOT NULL,
+    last_activity_at timestamptz NOT NULL,
+    expires_at       timestamptz NOT NULL,
+    revoked_at       timestamptz,
+    revoked_by       uuid
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_active ON sessions (role, last_activity_at) WHERE revoked_at IS NULL;
@@ -0,0 +1,25 @@
+-- Roles move from a hard-coded admin email to a column on users
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role varchar(20) NOT NULL DEFAULT 'user';
+
+-- Preserve access for the account that was previously treated as admin
+UPDATE users SET role = 'admin' WHERE email = 'admin@example.com';
+
+-- Requested role changes; granting admin may need a second admin's approval
+-- and a cooldown before it is applied
+CREATE TABLE IF NOT EXISTS role_changes (
+    id           uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    from_role    varchar(20) NOT NULL,
+    to_role      varchar(20) NOT NULL,
+    reason       text,
+    status       varchar(20) NOT NULL,
+    requested_by uuid NOT NULL,
+    requested_at timestamptz NOT NULL,
+    reviewed_by  uuid,
+    reviewed_at  timestamptz,
+    effective_at timestamptz,
+    applied_at   timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_changes_user_id ON role_changes (user_id);
+CREATE INDEX IF NOT EXISTS idx_role_changes_status ON role_changes (status, effective_at);
@@ -0,0 +1,11 @@
+-- Creation quota counters; one row per principal, resource and UTC day
+CREATE TABLE IF NOT EXISTS quota_usage (
+    principal    varchar(100) NOT NULL,
+    resource     varchar(50) NOT NULL,
+    window_start timestamptz NOT NULL,
+    used         integer NOT NULL DEFAULT 0,
+    updated_at   timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (principal, resource, window_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quota_usage_window_start ON quota_usage (window_start);
@@ -0,0 +1,2 @@
+-- Storage key of the user's avatar; a new key is used for every upload
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key varchar(255);
@@ -0,0 +1,4 @@
+-- Data residency region of the user; empty for users created before tagging
+ALTER TABLE users ADD COLUMN IF NOT EXISTS region varchar(16) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_users_region ON users (region);
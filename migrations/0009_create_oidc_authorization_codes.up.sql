@@ -0,0 +1,17 @@
+-- Pending authorization codes of the OpenID Connect provider; rows are
+-- deleted when the code is exchanged
+CREATE TABLE IF NOT EXISTS oidc_authorization_codes (
+    code_hash             varchar(64) PRIMARY KEY,
+    client_id             varchar(100) NOT NULL,
+    user_id               uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    redirect_uri          text NOT NULL,
+    scope                 text NOT NULL,
+    nonce                 text NOT NULL DEFAULT '',
+    code_challenge        varchar(128) NOT NULL DEFAULT '',
+    code_challenge_method varchar(10) NOT NULL DEFAULT '',
+    auth_time             timestamptz NOT NULL,
+    expires_at            timestamptz NOT NULL,
+    created_at            timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_oidc_authorization_codes_expires_at ON oidc_authorization_codes (expires_at);
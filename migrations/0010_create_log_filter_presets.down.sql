@@ -0,0 +1 @@
+DROP TABLE IF EXISTS log_filter_presets;
@@ -0,0 +1,14 @@
+-- Named log filters saved by admins for the admin panel Logs page
+CREATE TABLE IF NOT EXISTS log_filter_presets (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id   uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       varchar(100) NOT NULL,
+    event      varchar(50),
+    user_id    uuid,
+    start_date timestamptz,
+    end_date   timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_log_filter_presets_admin_name ON log_filter_presets (admin_id, name);
@@ -0,0 +1,12 @@
+-- External (social) identities linked to local accounts; one per provider per user
+CREATE TABLE IF NOT EXISTS user_identities (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider   varchar(50) NOT NULL,
+    subject    varchar(255) NOT NULL,
+    email      varchar(255),
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_identities_provider_subject ON user_identities (provider, subject);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_identities_user_provider ON user_identities (user_id, provider);
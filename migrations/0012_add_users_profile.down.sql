@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS required_profile_fields;
+ALTER TABLE users DROP COLUMN IF EXISTS profile;
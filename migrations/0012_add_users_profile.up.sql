@@ -0,0 +1,9 @@
+-- Free-form profile fields such as phone or department
+ALTER TABLE users ADD COLUMN IF NOT EXISTS profile jsonb NOT NULL DEFAULT '{}';
+
+-- Profile fields admins require every user to fill in; checked at login
+CREATE TABLE IF NOT EXISTS required_profile_fields (
+    field      varchar(50) PRIMARY KEY,
+    created_by uuid,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
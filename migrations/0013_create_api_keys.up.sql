@@ -0,0 +1,19 @@
+-- API keys for machine clients; only the SHA-256 of each key is stored
+CREATE TABLE IF NOT EXISTS api_keys (
+    id                   uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                 varchar(100) NOT NULL,
+    prefix               varchar(16) NOT NULL,
+    key_hash             char(64) NOT NULL,
+    owner_id             uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at           timestamptz,
+    last_used_at         timestamptz,
+    last_used_ip         varchar(45),
+    rotation_due_at      timestamptz,
+    rotation_reminded_at timestamptz,
+    rotated_to           uuid REFERENCES api_keys (id) ON DELETE SET NULL,
+    revoked_at           timestamptz,
+    created_at           timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_rotation_due_at ON api_keys (rotation_due_at) WHERE revoked_at IS NULL AND rotated_to IS NULL;
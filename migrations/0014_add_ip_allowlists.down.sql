@@ -0,0 +1,2 @@
+ALTER TABLE api_keys DROP COLUMN IF EXISTS allowed_ips;
+ALTER TABLE users DROP COLUMN IF EXISTS allowed_ips;
@@ -0,0 +1,3 @@
+-- Networks an account or API key may be used from; empty allows any address
+ALTER TABLE users ADD COLUMN IF NOT EXISTS allowed_ips jsonb NOT NULL DEFAULT '[]';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_ips jsonb NOT NULL DEFAULT '[]';
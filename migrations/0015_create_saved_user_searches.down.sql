@@ -0,0 +1 @@
+DROP TABLE IF EXISTS saved_user_searches;
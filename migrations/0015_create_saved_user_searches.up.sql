@@ -0,0 +1,16 @@
+-- Named users list filters saved by admins for the API and admin panel Users page
+CREATE TABLE IF NOT EXISTS saved_user_searches (
+    id                  uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id            uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name                varchar(100) NOT NULL,
+    search              varchar(255) NOT NULL DEFAULT '',
+    role                varchar(20) NOT NULL DEFAULT '',
+    region              varchar(16) NOT NULL DEFAULT '',
+    created_within_days integer,
+    sort_by             varchar(20) NOT NULL DEFAULT 'created_at',
+    sort_dir            varchar(4) NOT NULL DEFAULT 'desc',
+    created_at          timestamptz NOT NULL DEFAULT now(),
+    updated_at          timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_user_searches_admin_name ON saved_user_searches (admin_id, name);
@@ -0,0 +1,14 @@
+-- Single-use nonces of emailed account tokens such as password reset and
+-- email verification links; consumed rows are kept until they expire so
+-- reuse can be told apart from an unknown token
+CREATE TABLE IF NOT EXISTS account_tokens (
+    nonce_hash  varchar(64) PRIMARY KEY,
+    user_id     uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose     varchar(30) NOT NULL,
+    expires_at  timestamptz NOT NULL,
+    consumed_at timestamptz,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_tokens_user_id ON account_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_account_tokens_expires_at ON account_tokens (expires_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_users_changed_at;
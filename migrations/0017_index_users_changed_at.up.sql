@@ -0,0 +1,2 @@
+-- Serves the user changes feed, which pages users by when they last changed
+CREATE INDEX IF NOT EXISTS idx_users_changed_at ON users ((GREATEST(created_at, updated_at, deleted_at)), id);
@@ -0,0 +1,9 @@
+-- How far audit logs have been exported to each warehouse table; logs are
+-- exported in (timestamp, id) order and resume after the last exported pair
+CREATE TABLE IF NOT EXISTS log_export_checkpoints (
+    target         varchar(200) PRIMARY KEY,
+    last_timestamp timestamptz NOT NULL,
+    last_log_id    varchar(24) NOT NULL,
+    exported_rows  bigint NOT NULL DEFAULT 0,
+    updated_at     timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS admin_notifications;
+DROP TABLE IF EXISTS audit_subscriptions;
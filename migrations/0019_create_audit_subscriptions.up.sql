@@ -0,0 +1,28 @@
+-- Audit events each admin subscribed to, matched by event type or action
+-- name, or by minimum severity
+CREATE TABLE IF NOT EXISTS audit_subscriptions (
+    admin_id     uuid PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    events       jsonb NOT NULL DEFAULT '[]',
+    min_severity varchar(20),
+    email        boolean NOT NULL DEFAULT false,
+    panel        boolean NOT NULL DEFAULT true,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    updated_at   timestamptz NOT NULL DEFAULT now()
+);
+
+-- Matching audit entries delivered to an admin's panel inbox, once per entry
+CREATE TABLE IF NOT EXISTS admin_notifications (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id   uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    log_id     varchar(24) NOT NULL,
+    event      varchar(50) NOT NULL,
+    action     varchar(100),
+    severity   varchar(20) NOT NULL,
+    user_id    varchar(36),
+    logged_at  timestamptz NOT NULL,
+    read_at    timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_notifications_admin_log ON admin_notifications (admin_id, log_id);
+CREATE INDEX IF NOT EXISTS idx_admin_notifications_admin_logged_at ON admin_notifications (admin_id, logged_at DESC);
@@ -0,0 +1,3 @@
+-- No emailed token flow issues account tokens yet; drop the unused table
+-- until password reset or email verification is built
+DROP TABLE IF EXISTS account_tokens;
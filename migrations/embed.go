@@ -0,0 +1,13 @@
+// Package migrations holds the versioned PostgreSQL schema migrations.
+//
+// Each migration is a pair of files named NNNN_description.up.sql and
+// NNNN_description.down.sql. Files are embedded into the binaries so the
+// server and the usermgmt CLI always agree on the expected schema version.
+package migrations
+
+import "embed"
+
+// FS contains all migration files
+//
+//go:embed *.sql
+var FS embed.FS
@@ -26,8 +26,11 @@ db.createCollection('user_logs', {
             'LOGIN_SUCCESS',
             'LOGIN_FAILED',
             'TOKEN_REFRESH',
+            'UPLOAD_REJECTED',
             'SYSTEM_ERROR',
-            'VALIDATION_ERROR'
+            'VALIDATION_ERROR',
+            'SYSTEM_STARTUP',
+            'SYSTEM_SHUTDOWN'
           ],
           description: 'Event type - must be one of the predefined values'
         },
@@ -116,7 +116,8 @@
             fetch('/api/auth/logout', {
                 method: 'POST',
                 headers: {
-                    'Authorization': 'Bearer ' + token
+                    'Authorization': 'Bearer ' + token,
+                    'X-Admin-Panel-Page': window.location.pathname
                 }
             })
             .finally(() => {
@@ -361,7 +362,8 @@
                 method: 'POST',
                 credentials: 'include', // Include cookies
                 headers: {
-                    'Content-Type': 'application/json'
+                    'Content-Type': 'application/json',
+                    'X-Admin-Panel-Page': window.location.pathname
                 }
             })
             .then(() => {
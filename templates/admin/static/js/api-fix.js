@@ -26,7 +26,8 @@ document.addEventListener('DOMContentLoaded', function() {
                     method: 'POST',
                     credentials: 'include',
                     headers: {
-                        'Content-Type': 'application/json'
+                        'Content-Type': 'application/json',
+                        'X-Admin-Panel-Page': window.location.pathname
                     }
                 })
                 .then(() => {
@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteActivityCSV tests the CSV layout of user activity exports
+func TestWriteActivityCSV(t *testing.T) {
+	logs := []models.UserLogResponse{
+		{
+			Event:     models.UserUpdated,
+			Timestamp: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+			IPAddress: "10.0.0.1",
+			UserAgent: "curl/8.0, \"quoted\"",
+			Data: models.LogData{
+				Action:    "UPDATE_USER",
+				Details:   map[string]interface{}{"field": "name"},
+				OldValues: map[string]interface{}{"name": "Old"},
+				NewValues: map[string]interface{}{"name": "New"},
+			},
+		},
+		{
+			Event:     models.LoginFailed,
+			Timestamp: time.Date(2024, 2, 1, 8, 30, 0, 0, time.UTC),
+			Data:      models.LogData{Action: "LOGIN", StatusCode: 401, Error: "invalid credentials"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, models.WriteActivityCSV(&buf, logs))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	assert.Equal(t, []string{"timestamp", "event", "action", "ip_address", "user_agent", "status_code", "error", "details", "old_values", "new_values"}, records[0])
+	assert.Equal(t, []string{"2024-03-01T12:00:00Z", "USER_UPDATED", "UPDATE_USER", "10.0.0.1", "curl/8.0, \"quoted\"", "", "", `{"field":"name"}`, `{"name":"Old"}`, `{"name":"New"}`}, records[1])
+	assert.Equal(t, []string{"2024-02-01T08:30:00Z", "LOGIN_FAILED", "LOGIN", "", "", "401", "invalid credentials", "", "", ""}, records[2])
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+)
+
+// TestFindLogAnomalies tests detection of bursts against the baseline
+func TestFindLogAnomalies(t *testing.T) {
+	cfg := config.AnomalyConfig{Enabled: true, Factor: 3, MinEvents: 20}
+
+	counts := map[models.LogEventType][]int64{
+		models.UserDeleted: {2, 1, 3, 2, 40},         // Burst
+		models.UserLogin:   {100, 90, 110, 100, 120}, // Normal variation
+		models.UserUpdated: {0, 0, 0, 0, 10},         // Below MinEvents
+		models.LoginFailed: {0, 0, 0, 0, 25},         // New activity
+	}
+
+	anomalies := repository.FindLogAnomalies(counts, cfg)
+	require.Len(t, anomalies, 2)
+
+	assert.Equal(t, models.LoginFailed, anomalies[0].Event)
+	assert.Equal(t, float64(0), anomalies[0].Baseline)
+
+	assert.Equal(t, models.UserDeleted, anomalies[1].Event)
+	assert.Equal(t, int64(40), anomalies[1].Count)
+	assert.Equal(t, 2.0, anomalies[1].Baseline)
+	assert.Equal(t, 20.0, anomalies[1].Factor)
+}
+
+// TestAnomalyConfigValidate tests anomaly configuration checks
+func TestAnomalyConfigValidate(t *testing.T) {
+	assert.NoError(t, config.AnomalyConfig{}.Validate())
+	assert.Error(t, config.AnomalyConfig{Enabled: true, BaselineWindows: 12, Factor: 3}.Validate())
+	assert.Error(t, config.AnomalyConfig{Enabled: true, Window: 1, BaselineWindows: 12, Factor: 1}.Validate())
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := utils.GenerateAPIKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(key, "umk_"))
+	assert.True(t, strings.HasPrefix(key, prefix))
+	assert.Len(t, prefix, 12)
+	assert.Equal(t, utils.HashAPIKey(key), hash)
+	assert.NotContains(t, hash, key)
+	assert.True(t, utils.LooksLikeAPIKey(key))
+
+	other, _, otherHash, err := utils.GenerateAPIKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+	assert.NotEqual(t, hash, otherHash)
+}
+
+func TestLooksLikeAPIKey(t *testing.T) {
+	assert.False(t, utils.LooksLikeAPIKey(""))
+	assert.False(t, utils.LooksLikeAPIKey("umk_short"))
+	assert.False(t, utils.LooksLikeAPIKey("Bearer eyJhbGciOiJIUzI1NiJ9"))
+}
+
+func TestAPIKeyIsActive(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	assert.True(t, (&models.APIKey{}).IsActive(now))
+	assert.True(t, (&models.APIKey{ExpiresAt: &future}).IsActive(now))
+	assert.False(t, (&models.APIKey{ExpiresAt: &past}).IsActive(now))
+	assert.False(t, (&models.APIKey{RevokedAt: &past}).IsActive(now))
+
+	// A rotated key stays active until its overlap window closes
+	replacement := uuid.New()
+	assert.True(t, (&models.APIKey{ExpiresAt: &future, RotatedTo: &replacement}).IsActive(now))
+}
+
+func TestAPIKeysConfigValidate(t *testing.T) {
+	valid := config.APIKeysConfig{
+		RotationPeriod:  90 * 24 * time.Hour,
+		ReminderBefore:  14 * 24 * time.Hour,
+		RotationOverlap: 24 * time.Hour,
+	}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, config.APIKeysConfig{}.Validate())
+
+	tooLate := valid
+	tooLate.ReminderBefore = tooLate.RotationPeriod
+	assert.Error(t, tooLate.Validate())
+
+	negative := valid
+	negative.RotationOverlap = -time.Hour
+	assert.Error(t, negative.Validate())
+}
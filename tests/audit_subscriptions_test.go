@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/mailer"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+)
+
+// memoryAuditSubscriptions is an in-memory AuditSubscriptionRepository
+type memoryAuditSubscriptions struct {
+	repository.AuditSubscriptionRepository
+	subscriptions []models.AuditSubscription
+}
+
+func (r *memoryAuditSubscriptions) List(ctx context.Context) ([]models.AuditSubscription, error) {
+	return append([]models.AuditSubscription(nil), r.subscriptions...), nil
+}
+
+// memoryAdminNotifications is an AdminNotificationRepository recording
+// created notifications
+type memoryAdminNotifications struct {
+	repository.AdminNotificationRepository
+	created []models.AdminNotification
+}
+
+func (r *memoryAdminNotifications) CreateBatch(ctx context.Context, notifications []models.AdminNotification) error {
+	r.created = append(r.created, notifications...)
+	return nil
+}
+
+// TestAuditSubscriptionMatches tests matching by event, action and severity
+func TestAuditSubscriptionMatches(t *testing.T) {
+	loginFailed := models.UserLog{Event: models.LoginFailed}
+	purge := models.UserLog{Event: models.UserDeleted, Data: models.LogData{Action: "PERMANENT_DELETE_USER"}}
+	login := models.UserLog{Event: models.LoginSuccess}
+
+	assert.Equal(t, models.AuditSeverityWarning, models.AuditSeverity(loginFailed))
+	assert.Equal(t, models.AuditSeverityCritical, models.AuditSeverity(purge))
+	assert.Equal(t, models.AuditSeverityInfo, models.AuditSeverity(login))
+
+	byName := models.AuditSubscription{Events: models.StringList{"LOGIN_FAILED", "PERMANENT_DELETE_USER"}}
+	assert.True(t, byName.Matches(loginFailed, models.AuditSeverity(loginFailed)))
+	assert.True(t, byName.Matches(purge, models.AuditSeverity(purge)))
+	assert.False(t, byName.Matches(login, models.AuditSeverity(login)))
+
+	bySeverity := models.AuditSubscription{MinSeverity: models.AuditSeverityWarning}
+	assert.True(t, bySeverity.Matches(loginFailed, models.AuditSeverity(loginFailed)))
+	assert.True(t, bySeverity.Matches(purge, models.AuditSeverity(purge)))
+	assert.False(t, bySeverity.Matches(login, models.AuditSeverity(login)))
+
+	assert.NoError(t, models.AuditSubscriptionRequest{Events: []string{"LOGIN_FAILED"}, Panel: true}.Validate())
+	assert.Error(t, models.AuditSubscriptionRequest{Panel: true}.Validate())
+	assert.Error(t, models.AuditSubscriptionRequest{Events: []string{"login failed"}, Panel: true}.Validate())
+	// Names are checked against the event types and actions actually logged
+	assert.NoError(t, models.AuditSubscriptionRequest{Events: []string{"PERMANENT_DELETE_USER", "ROLE_CHANGE"}, Panel: true}.Validate())
+	assert.Error(t, models.AuditSubscriptionRequest{Events: []string{"PERMANENT_DELETE_USERS"}, Panel: true}.Validate())
+	assert.Error(t, models.AuditSubscriptionRequest{MinSeverity: "urgent", Panel: true}.Validate())
+	assert.Error(t, models.AuditSubscriptionRequest{MinSeverity: models.AuditSeverityInfo}.Validate())
+}
+
+// TestNotifyAuditSubscribers tests that matching audit entries reach the
+// inboxes of subscribed admins only, starting from the first run
+func TestNotifyAuditSubscribers(t *testing.T) {
+	users := newMemoryUsers()
+	admin := &models.User{ID: uuid.New(), Email: "admin@example.com", Name: "Admin", Role: models.RoleAdmin}
+	demoted := &models.User{ID: uuid.New(), Email: "former@example.com", Name: "Former", Role: models.RoleUser}
+	require.NoError(t, users.Create(context.Background(), admin))
+	require.NoError(t, users.Create(context.Background(), demoted))
+
+	subscriptions := &memoryAuditSubscriptions{subscriptions: []models.AuditSubscription{
+		{AdminID: admin.ID, Events: models.StringList{"LOGIN_FAILED"}, Panel: true},
+		{AdminID: demoted.ID, MinSeverity: models.AuditSeverityInfo, Panel: true},
+	}}
+	notifications := &memoryAdminNotifications{}
+	checkpoints := &memoryCheckpoints{checkpoints: make(map[string]models.LogExportCheckpoint)}
+	logs := &exportLogs{}
+
+	cfg := &config.Config{AuditSubscriptions: config.AuditSubscriptionsConfig{Enabled: true, Interval: time.Minute, BatchSize: 2, MaxBatches: 10}}
+	repoManager, err := repository.NewRepositoryManagerWithRepos(cfg, &repository.Repository{
+		User:              users,
+		Log:               logs,
+		LogExport:         checkpoints,
+		AuditSubscription: subscriptions,
+		AdminNotification: notifications,
+	})
+	require.NoError(t, err)
+
+	// The first run only starts the checkpoint, so older entries are never delivered
+	start := time.Now().Add(-time.Hour)
+	logs.logs = []models.UserLog{{ID: primitive.NewObjectID(), Event: models.LoginFailed, Timestamp: start}}
+	require.NoError(t, repoManager.NotifyAuditSubscribers(context.Background()))
+	assert.Empty(t, notifications.created)
+
+	// Rewind to just after the old entry so the entries below are in range
+	checkpoint := checkpoints.checkpoints["audit_subscriptions"]
+	checkpoint.LastTimestamp = start
+	checkpoint.LastLogID = logs.logs[0].ID.Hex()
+	checkpoints.checkpoints["audit_subscriptions"] = checkpoint
+
+	failed := models.UserLog{ID: primitive.NewObjectID(), Event: models.LoginFailed, Timestamp: start.Add(time.Minute)}
+	logs.logs = append(logs.logs,
+		models.UserLog{ID: primitive.NewObjectID(), Event: models.LoginFailed, Timestamp: start.Add(time.Second), Data: models.LogData{Action: "HTTP_REQUEST"}},
+		models.UserLog{ID: primitive.NewObjectID(), Event: models.LoginSuccess, Timestamp: start.Add(2 * time.Second)},
+		failed,
+	)
+	require.NoError(t, repoManager.NotifyAuditSubscribers(context.Background()))
+
+	require.Len(t, notifications.created, 1)
+	assert.Equal(t, admin.ID, notifications.created[0].AdminID)
+	assert.Equal(t, failed.ID.Hex(), notifications.created[0].LogID)
+	assert.Equal(t, models.AuditSeverityWarning, notifications.created[0].Severity)
+	assert.Equal(t, failed.Timestamp, checkpoints.checkpoints["audit_subscriptions"].LastTimestamp)
+}
+
+// TestAuditDigest tests the subscription digest email
+func TestAuditDigest(t *testing.T) {
+	userID := uuid.New().String()
+	admin := &models.User{Name: "Jane", Email: "jane@example.com"}
+	msg := mailer.AuditDigest(admin, []models.UserLog{
+		{Event: models.UserDeleted, Data: models.LogData{Action: "PERMANENT_DELETE_USER"}, UserID: &userID, Timestamp: time.Now()},
+	})
+
+	assert.Equal(t, "jane@example.com", msg.To)
+	assert.Equal(t, "1 audit event matched your subscription", msg.Subject)
+	assert.Contains(t, msg.Body, "[critical] USER_DELETED / PERMANENT_DELETE_USER (user "+userID+")")
+}
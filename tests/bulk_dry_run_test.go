@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/handlers"
+	"user_mgmt_go/internal/models"
+)
+
+// TestBulkCreateDryRun tests that a dry run reports every problem a real
+// run would hit, per user, without creating or logging anything
+func TestBulkCreateDryRun(t *testing.T) {
+	stack := newHandlerStack(t, &config.Config{
+		Registration: config.RegistrationConfig{DeniedEmailDomains: []string{"mailinator.com"}},
+	})
+	admin := stack.addUser(t, models.RoleAdmin)
+	adminToken := stack.token(t, admin)
+
+	users := []models.UserCreateRequest{
+		{Name: "Jane", Email: "jane@example.com", Password: "jane-password-123"},
+		{Name: "Short", Email: "short@example.com", Password: "abc"},
+		{Name: "Jane Again", Email: "JANE@example.com", Password: "jane-password-123"},
+		{Name: "Existing", Email: admin.Email, Password: "existing-password-123"},
+		{Name: "Throwaway", Email: "someone@mailinator.com", Password: "throwaway-password-123"},
+		{Name: "Bad Email", Email: "not-an-email", Password: "bad-email-password-123"},
+	}
+	w := stack.do(t, http.MethodPost, "/api/admin/users/bulk-create?dry_run=true", adminToken, handlers.BulkCreateUsersRequest{Users: users})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var report handlers.BulkCreateUsersResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.SuccessCount)
+	assert.Equal(t, 5, report.ErrorCount)
+
+	errs := make([]string, len(report.Results))
+	for i, result := range report.Results {
+		errs[i] = result.Error
+		assert.Nil(t, result.UserID)
+	}
+	assert.Equal(t, []string{
+		"",
+		"Invalid user data: password must be at least 6 characters",
+		"Duplicate email in batch",
+		"User already exists",
+		"Email domain not allowed",
+		"Invalid user data: email is not a valid email address",
+	}, errs)
+
+	// Nothing was created or logged
+	_, err := stack.users.GetByEmail(t.Context(), "jane@example.com")
+	assert.Error(t, err)
+	assert.Empty(t, stack.logs.events())
+}
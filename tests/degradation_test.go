@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+)
+
+// TestDegradationHeaders tests that responses and the readiness check report
+// degraded subsystems
+func TestDegradationHeaders(t *testing.T) {
+	stack := newHandlerStack(t, &config.Config{})
+
+	w := stack.do(t, http.MethodGet, "/api/ping", "", nil)
+	assert.Equal(t, "ok", w.Header().Get(middleware.ServiceStatusHeader))
+	assert.Empty(t, w.Header().Get(middleware.DegradedSubsystemsHeader))
+
+	since := time.Now().Add(-time.Minute)
+	stack.logs.mutex.Lock()
+	stack.logs.pipeline.Degraded = true
+	stack.logs.pipeline.DegradedSince = &since
+	stack.logs.mutex.Unlock()
+
+	w = stack.do(t, http.MethodGet, "/api/ping", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "degraded", w.Header().Get(middleware.ServiceStatusHeader))
+	assert.Equal(t, models.SubsystemLogPipeline, w.Header().Get(middleware.DegradedSubsystemsHeader))
+
+	// Without PostgreSQL the instance is not ready, and says what else is degraded
+	w = stack.do(t, http.MethodGet, "/health/ready", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var readiness models.ReadinessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &readiness))
+	assert.Equal(t, "not_ready", readiness.Status)
+	assert.True(t, readiness.Degradation.Degraded)
+	assert.Equal(t, []string{models.SubsystemLogPipeline}, readiness.Degradation.Subsystems)
+	require.NotNil(t, readiness.Degradation.Since)
+	assert.WithinDuration(t, since, *readiness.Degradation.Since, time.Second)
+}
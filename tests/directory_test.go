@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"user_mgmt_go/internal/models"
+)
+
+// TestDirectoryEntry tests that directory entries only expose allowed fields
+func TestDirectoryEntry(t *testing.T) {
+	user := models.UserResponse{
+		ID:        uuid.New(),
+		Name:      "John Doe",
+		Email:     "john.doe@example.com",
+		Role:      models.RoleAdmin,
+		Region:    "eu",
+		AvatarURL: "/assets/avatars/john.png",
+	}
+
+	entry := user.ToDirectoryEntry(false)
+	assert.Equal(t, user.ID, entry.ID)
+	assert.Equal(t, "John Doe", entry.Name)
+	assert.Equal(t, "/assets/avatars/john.png", entry.AvatarURL)
+	assert.Empty(t, entry.Email)
+
+	assert.Equal(t, "john.doe@example.com", user.ToDirectoryEntry(true).Email)
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/utils"
+)
+
+// signDPoPProof creates a DPoP proof signed by key
+func signDPoPProof(t *testing.T, key *ecdsa.PrivateKey, method, uri, accessToken string) string {
+	claims := jwt.MapClaims{
+		"htm": method,
+		"htu": uri,
+		"iat": time.Now().Unix(),
+		"jti": uuid.NewString(),
+	}
+	if accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		claims["ath"] = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = ecJWK(key)
+	proof, err := token.SignedString(key)
+	require.NoError(t, err)
+	return proof
+}
+
+func ecJWK(key *ecdsa.PrivateKey) map[string]interface{} {
+	return map[string]interface{}{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// TestDPoPVerifier tests proof verification and replay protection
+func TestDPoPVerifier(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	verifier := utils.NewDPoPVerifier(time.Minute)
+	uri := "https://users.example.com/api/auth/login"
+
+	expected, err := utils.JWKThumbprint(ecJWK(key))
+	require.NoError(t, err)
+
+	proof := signDPoPProof(t, key, "POST", uri, "")
+	jkt, err := verifier.Verify(proof, "POST", uri+"?ignored=1", "")
+	require.NoError(t, err)
+	assert.Equal(t, expected, jkt)
+
+	// A proof can only be used once
+	_, err = verifier.Verify(proof, "POST", uri, "")
+	assert.Error(t, err)
+
+	// Proofs are bound to the request method, URL and access token
+	_, err = verifier.Verify(signDPoPProof(t, key, "GET", uri, ""), "POST", uri, "")
+	assert.Error(t, err)
+	_, err = verifier.Verify(signDPoPProof(t, key, "POST", "https://other.example.com/", ""), "POST", uri, "")
+	assert.Error(t, err)
+	_, err = verifier.Verify(signDPoPProof(t, key, "GET", uri, "token-a"), "GET", uri, "token-b")
+	assert.Error(t, err)
+	_, err = verifier.Verify(signDPoPProof(t, key, "GET", uri, "token-a"), "GET", uri, "token-a")
+	assert.NoError(t, err)
+
+	_, err = verifier.Verify("", "POST", uri, "")
+	assert.Error(t, err)
+}
+
+// TestBoundTokenRefresh tests that bound refresh tokens need the bound key
+func TestBoundTokenRefresh(t *testing.T) {
+	jwtManager := utils.NewJWTManager("test-secret", time.Hour, 0)
+	user := &models.User{ID: uuid.New(), Email: "jane@example.com", Name: "Jane Doe"}
+
+	pair, err := jwtManager.GenerateBoundTokenPair(user, models.RoleUser, "", "thumbprint")
+	require.NoError(t, err)
+
+	claims, err := jwtManager.ValidateToken(pair.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "thumbprint", claims.BoundKey())
+
+	_, err = jwtManager.RefreshAccessToken(pair.RefreshToken, "")
+	assert.Error(t, err)
+	_, err = jwtManager.RefreshAccessToken(pair.RefreshToken, "other")
+	assert.Error(t, err)
+
+	refreshed, err := jwtManager.RefreshAccessToken(pair.RefreshToken, "thumbprint")
+	require.NoError(t, err)
+	claims, err = jwtManager.ValidateToken(refreshed.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "thumbprint", claims.BoundKey(), "refreshed tokens stay bound")
+}
@@ -0,0 +1,326 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/handlers"
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/oidc"
+	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/internal/utils"
+)
+
+// stackPassword is the password of every user added to a handler stack
+const stackPassword = "stack-password-123"
+
+// handlerStack is the API router wired to in-memory repositories and a real
+// JWT manager, for testing routes end to end without any database
+type handlerStack struct {
+	router   *gin.Engine
+	jwt      *utils.JWTManager
+	users    *memoryUsers
+	logs     *memoryLogs
+	sessions *memorySessions
+	manifest []handlers.RouteManifestEntry
+
+	requests atomic.Uint32
+}
+
+// newHandlerStack builds the routes of the given configuration over empty
+// in-memory repositories
+func newHandlerStack(t *testing.T, cfg *config.Config) *handlerStack {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	stack := &handlerStack{
+		jwt:      utils.NewJWTManager("handler-stack-test-secret-0123456789", time.Hour, 0),
+		users:    newMemoryUsers(),
+		logs:     &memoryLogs{},
+		sessions: newMemorySessions(),
+	}
+	repoManager, err := repository.NewRepositoryManagerWithRepos(cfg, &repository.Repository{
+		User:         stack.users,
+		Log:          stack.logs,
+		Session:      stack.sessions,
+		Identity:     memoryIdentities{},
+		ProfileField: memoryProfileFields{},
+	})
+	require.NoError(t, err)
+
+	provider, err := oidc.NewProvider(config.OIDCConfig{
+		Issuer:      "https://users.example.com/",
+		CodeExpiry:  time.Minute,
+		TokenExpiry: time.Hour,
+	})
+	require.NoError(t, err)
+
+	middlewareManager := middleware.NewMiddlewareManager(cfg, stack.jwt, repoManager)
+	handlerManager := handlers.NewHandlerManager(
+		stack.jwt, repoManager, middlewareManager, models.LogRedactionPolicy{}, nil, nil, nil,
+		0, 0, provider, "", cfg.Directory, nil, nil, cfg.APIKeys, cfg,
+	)
+
+	stack.router = gin.New()
+	handlerManager.SetupRoutes(stack.router)
+	require.NoError(t, handlerManager.BuildRouteManifest(stack.router))
+	stack.manifest = handlerManager.RouteManifest()
+	return stack
+}
+
+// addUser stores a user with the given role and stackPassword
+func (s *handlerStack) addUser(t *testing.T, role string) *models.User {
+	t.Helper()
+	hashed, err := utils.HashPassword(stackPassword)
+	require.NoError(t, err)
+
+	id := uuid.New()
+	user := &models.User{
+		ID:       id,
+		Name:     "Stack " + role,
+		Email:    role + "-" + id.String()[:8] + "@example.com",
+		Password: hashed,
+		Role:     role,
+	}
+	require.NoError(t, s.users.Create(t.Context(), user))
+	return user
+}
+
+// token signs the user in with a new session, as a login would
+func (s *handlerStack) token(t *testing.T, user *models.User) string {
+	t.Helper()
+	now := time.Now()
+	session := &models.Session{
+		ID:             uuid.New(),
+		UserID:         user.ID,
+		Role:           user.Role,
+		CreatedAt:      now,
+		LastActivityAt: now,
+		ExpiresAt:      now.Add(time.Hour),
+	}
+	require.NoError(t, s.sessions.Create(t.Context(), session))
+
+	tokens, err := s.jwt.GenerateBoundTokenPair(user, user.Role, session.ID.String(), "")
+	require.NoError(t, err)
+	return tokens.AccessToken
+}
+
+// do sends a request with an optional bearer token and JSON body. Each
+// request comes from its own client IP, so IP rate limits do not interfere.
+func (s *handlerStack) do(t *testing.T, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&payload).Encode(body))
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	n := s.requests.Add(1)
+	req.RemoteAddr = fmt.Sprintf("10.%d.%d.%d:40000", n>>16&0xff, n>>8&0xff, n&0xff)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// routeParam matches the path parameters of a route pattern
+var routeParam = regexp.MustCompile(`[:*][A-Za-z_]+`)
+
+// TestHandlerStackAuthRules tests every route's auth rule: routes needing
+// authentication reject anonymous requests, admin routes reject users and
+// self-or-admin routes reject users acting on someone else
+func TestHandlerStackAuthRules(t *testing.T) {
+	stack := newHandlerStack(t, &config.Config{Directory: config.DirectoryConfig{Enabled: true}})
+	userToken := stack.token(t, stack.addUser(t, models.RoleUser))
+	otherUser := stack.addUser(t, models.RoleUser)
+
+	for _, route := range stack.manifest {
+		if route.Auth == middleware.AuthPublic || route.Auth == middleware.AuthOptional {
+			continue
+		}
+		path := routeParam.ReplaceAllString(route.Path, otherUser.ID.String())
+
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			w := stack.do(t, route.Method, path, "", nil)
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "anonymous request")
+
+			w = stack.do(t, route.Method, path, "not-a-token", nil)
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "invalid token")
+
+			if route.Auth == middleware.AuthAdmin || route.Auth == middleware.AuthSelfOrAdmin {
+				w = stack.do(t, route.Method, path, userToken, nil)
+				assert.Equal(t, http.StatusForbidden, w.Code, "user acting on another user")
+			}
+		})
+	}
+}
+
+// TestHandlerStackLogin tests signing in and using the issued token
+func TestHandlerStackLogin(t *testing.T) {
+	stack := newHandlerStack(t, &config.Config{})
+	user := stack.addUser(t, models.RoleUser)
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		status int
+		event  models.LogEventType
+	}{
+		{"valid credentials", models.LoginRequest{Email: user.Email, Password: stackPassword}, http.StatusOK, models.LoginSuccess},
+		{"wrong password", models.LoginRequest{Email: user.Email, Password: "wrong-password"}, http.StatusUnauthorized, models.LoginFailed},
+		{"unknown email", models.LoginRequest{Email: "nobody@example.com", Password: stackPassword}, http.StatusUnauthorized, models.LoginFailed},
+		{"missing password", map[string]string{"email": user.Email}, http.StatusBadRequest, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logged := len(stack.logs.events())
+			w := stack.do(t, http.MethodPost, "/api/auth/login", "", tc.body)
+			require.Equal(t, tc.status, w.Code, w.Body.String())
+
+			events := stack.logs.events()[logged:]
+			if tc.event == "" {
+				assert.Empty(t, events)
+				return
+			}
+			assert.Equal(t, []models.LogEventType{tc.event}, events)
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var login models.LoginResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+			assert.Equal(t, "Bearer", login.TokenType)
+			assert.Equal(t, user.ID, login.User.ID)
+
+			w = stack.do(t, http.MethodGet, "/api/auth/profile", login.Token, nil)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), user.Email)
+		})
+	}
+}
+
+// TestHandlerStackRefresh tests that refresh tokens stop working once their
+// session is revoked
+func TestHandlerStackRefresh(t *testing.T) {
+	stack := newHandlerStack(t, &config.Config{})
+	user := stack.addUser(t, models.RoleUser)
+
+	w := stack.do(t, http.MethodPost, "/api/auth/login", "", models.LoginRequest{Email: user.Email, Password: stackPassword})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var login models.LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+
+	refresh := models.RefreshTokenRequest{RefreshToken: login.RefreshToken}
+	w = stack.do(t, http.MethodPost, "/api/auth/refresh", "", refresh)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	claims, err := stack.jwt.ValidateToken(login.RefreshToken)
+	require.NoError(t, err)
+	require.NoError(t, stack.sessions.Revoke(t.Context(), uuid.MustParse(claims.SessionID), user.ID))
+
+	w = stack.do(t, http.MethodPost, "/api/auth/refresh", "", refresh)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+}
+
+// TestHandlerStackUsers tests creating, reading, updating and deleting users
+// through the API, with the response shapes clients rely on
+func TestHandlerStackUsers(t *testing.T) {
+	stack := newHandlerStack(t, &config.Config{})
+	adminToken := stack.token(t, stack.addUser(t, models.RoleAdmin))
+
+	w := stack.do(t, http.MethodPost, "/api/users", adminToken, models.UserCreateRequest{
+		Name:     "Jane Doe",
+		Email:    "jane@example.com",
+		Password: "jane-password-123",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created models.UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "jane@example.com", created.Email)
+	assert.Equal(t, models.RoleUser, created.Role)
+	assert.NotContains(t, w.Body.String(), "jane-password-123")
+	userPath := "/api/users/" + created.ID.String()
+
+	for _, tc := range []struct {
+		name   string
+		body   interface{}
+		status int
+	}{
+		{"duplicate email", models.UserCreateRequest{Name: "Jane Again", Email: "jane@example.com", Password: "jane-password-123"}, http.StatusConflict},
+		{"invalid email", models.UserCreateRequest{Name: "Bad", Email: "not-an-email", Password: "bad-password-123"}, http.StatusBadRequest},
+		{"missing name", map[string]string{"email": "anon@example.com", "password": "anon-password-123"}, http.StatusBadRequest},
+	} {
+		w := stack.do(t, http.MethodPost, "/api/users", adminToken, tc.body)
+		assert.Equal(t, tc.status, w.Code, tc.name)
+	}
+
+	w = stack.do(t, http.MethodGet, "/api/users?search=jane", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list models.UsersListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Users, 1)
+	assert.Equal(t, created.ID, list.Users[0].ID)
+	assert.EqualValues(t, 1, list.Total)
+
+	// Users may update themselves but not other users
+	jane, err := stack.users.GetByID(t.Context(), created.ID)
+	require.NoError(t, err)
+	janeToken := stack.token(t, jane)
+	name := "Jane Smith"
+	w = stack.do(t, http.MethodPut, userPath, janeToken, models.UserUpdateRequest{Name: &name})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), name)
+
+	w = stack.do(t, http.MethodDelete, userPath, janeToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = stack.do(t, http.MethodDelete, userPath, adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = stack.do(t, http.MethodGet, userPath, adminToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	assert.Equal(t, []models.LogEventType{models.UserCreated, models.UserUpdated, models.UserDeleted}, stack.logs.events())
+}
+
+// TestHandlerStackEmailDomainOnUpdate tests that users cannot move their
+// account to an email domain the registration rules deny
+func TestHandlerStackEmailDomainOnUpdate(t *testing.T) {
+	stack := newHandlerStack(t, &config.Config{Registration: config.RegistrationConfig{
+		AllowedEmailDomains: []string{"example.com"},
+		DeniedEmailDomains:  []string{"contractors.example.com"},
+	}})
+	user := stack.addUser(t, models.RoleUser)
+	userToken := stack.token(t, user)
+	userPath := "/api/users/" + user.ID.String()
+
+	for _, email := range []string{"someone@mailinator.com", "someone@contractors.example.com"} {
+		w := stack.do(t, http.MethodPut, userPath, userToken, models.UserUpdateRequest{Email: &email})
+		assert.Equal(t, http.StatusBadRequest, w.Code, email)
+		assert.Contains(t, w.Body.String(), "Email Domain Not Allowed", email)
+	}
+	stored, err := stack.users.GetByID(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, stored.Email)
+
+	allowed := "renamed-" + user.ID.String()[:8] + "@example.com"
+	w := stack.do(t, http.MethodPut, userPath, userToken, models.UserUpdateRequest{Email: &allowed})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), allowed)
+}
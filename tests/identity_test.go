@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/utils"
+)
+
+// TestIDTokenVerifier tests verification of external provider ID tokens
+// against a provider discovered from its issuer
+func TestIDTokenVerifier(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var issuer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{"jwks_uri": issuer + "/keys"})
+		case "/keys":
+			jwk := ecJWK(key)
+			jwk["kid"] = "key-1"
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": []interface{}{jwk}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	issuer = server.URL
+
+	sign := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		token.Header["kid"] = "key-1"
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   issuer,
+			"aud":   "my-client",
+			"sub":   "external-123",
+			"email": "john.doe@gmail.com",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+		}
+	}
+
+	verifier := utils.NewIDTokenVerifier(issuer, "my-client", "")
+
+	identity, err := verifier.Verify(context.Background(), sign(claims()))
+	require.NoError(t, err)
+	assert.Equal(t, "external-123", identity.Subject)
+	assert.Equal(t, "john.doe@gmail.com", identity.Email)
+
+	// Tokens for another client are rejected
+	wrongAudience := claims()
+	wrongAudience["aud"] = "other-client"
+	_, err = verifier.Verify(context.Background(), sign(wrongAudience))
+	assert.Error(t, err)
+
+	// Expired tokens are rejected
+	expired := claims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	_, err = verifier.Verify(context.Background(), sign(expired))
+	assert.Error(t, err)
+
+	// Tokens signed by another key are rejected
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims())
+	token.Header["kid"] = "key-1"
+	forged, err := token.SignedString(otherKey)
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), forged)
+	assert.Error(t, err)
+
+	// An issuer ending in a slash, as Auth0's does, is matched as configured
+	// and still discovered without a double slash
+	slashed := utils.NewIDTokenVerifier(issuer+"/", "my-client", "")
+	withSlash := claims()
+	withSlash["iss"] = issuer + "/"
+	identity, err = slashed.Verify(context.Background(), sign(withSlash))
+	require.NoError(t, err)
+	assert.Equal(t, "external-123", identity.Subject)
+	_, err = slashed.Verify(context.Background(), sign(claims()))
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/importer"
+)
+
+// TestImporterTransform tests IdP export transformation
+func TestImporterTransform(t *testing.T) {
+	mappings := importer.DefaultMappings()
+
+	t.Run("Azure AD CSV", func(t *testing.T) {
+		export := "version:v1.0\n" +
+			"userPrincipalName,displayName,mail\n" +
+			"jane@contoso.onmicrosoft.com,Jane Doe,Jane.Doe@contoso.com\n" +
+			"bob@contoso.onmicrosoft.com,Bob,\n"
+
+		records, rowErrors, err := importer.Transform(importer.FormatAzureADCSV, strings.NewReader(export), mappings[importer.FormatAzureADCSV])
+		require.NoError(t, err)
+		assert.Empty(t, rowErrors)
+		require.Len(t, records, 2)
+		assert.Equal(t, "Jane Doe", records[0].Name)
+		assert.Equal(t, "jane.doe@contoso.com", records[0].Email)
+		assert.Equal(t, "bob@contoso.onmicrosoft.com", records[1].Email) // Falls back to UPN
+	})
+
+	t.Run("Google Workspace CSV", func(t *testing.T) {
+		export := "First Name [Required],Last Name [Required],Email Address [Required]\n" +
+			"John,Smith,john@example.com\n" +
+			"Dup,User,JOHN@example.com\n" +
+			"No,Email,\n"
+
+		records, rowErrors, err := importer.Transform(importer.FormatGoogleWorkspaceCSV, strings.NewReader(export), mappings[importer.FormatGoogleWorkspaceCSV])
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "John Smith", records[0].Name)
+		require.Len(t, rowErrors, 2)
+		assert.Equal(t, 2, rowErrors[0].Row)
+		assert.Contains(t, rowErrors[0].Error, "duplicate")
+		assert.Equal(t, 3, rowErrors[1].Row)
+	})
+
+	t.Run("Generic JSON with mapping override", func(t *testing.T) {
+		custom, err := importer.NewMappings(map[string]config.ImportFieldMapping{
+			"generic_json": {Name: []string{"profile.fullName"}, Email: []string{"primaryEmail"}},
+		})
+		require.NoError(t, err)
+
+		export := `{"users": [{"primaryEmail": "ann@example.com", "profile": {"fullName": "Ann Lee"}}]}`
+		records, rowErrors, err := importer.Transform(importer.FormatGenericJSON, strings.NewReader(export), custom[importer.FormatGenericJSON])
+		require.NoError(t, err)
+		assert.Empty(t, rowErrors)
+		require.Len(t, records, 1)
+		assert.Equal(t, "Ann Lee", records[0].Name)
+		assert.Equal(t, "ann@example.com", records[0].Email)
+	})
+
+	t.Run("Unknown format override", func(t *testing.T) {
+		_, err := importer.NewMappings(map[string]config.ImportFieldMapping{"okta_csv": {}})
+		assert.Error(t, err)
+	})
+}
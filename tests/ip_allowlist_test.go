@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"testing"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIPAllowlist(t *testing.T) {
+	allowlist, err := models.ParseIPAllowlist([]string{
+		"10.1.2.3/8",
+		" 203.0.113.7 ",
+		"2001:db8::1",
+		"10.0.0.0/8",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.IPAllowlist{"10.0.0.0/8", "203.0.113.7/32", "2001:db8::1/128"}, allowlist)
+
+	_, err = models.ParseIPAllowlist([]string{"not-an-ip"})
+	assert.Error(t, err)
+	_, err = models.ParseIPAllowlist([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+
+	tooMany := make([]string, models.MaxIPAllowlistEntries+1)
+	for i := range tooMany {
+		tooMany[i] = "192.0.2.1"
+	}
+	_, err = models.ParseIPAllowlist(tooMany)
+	assert.Error(t, err)
+
+	empty, err := models.ParseIPAllowlist(nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestIPAllowlistAllows(t *testing.T) {
+	assert.True(t, models.IPAllowlist{}.Allows("198.51.100.1"), "empty allowlist allows any address")
+
+	allowlist := models.IPAllowlist{"10.0.0.0/8", "203.0.113.7/32", "2001:db8::/32"}
+	assert.True(t, allowlist.Allows("10.20.30.40"))
+	assert.True(t, allowlist.Allows("203.0.113.7"))
+	assert.True(t, allowlist.Allows("2001:db8::42"))
+	assert.False(t, allowlist.Allows("203.0.113.8"))
+	assert.False(t, allowlist.Allows("192.168.1.1"))
+	assert.False(t, allowlist.Allows("garbage"))
+}
+
+func TestIPAllowlistScanValue(t *testing.T) {
+	value, err := models.IPAllowlist{"10.0.0.0/8"}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `["10.0.0.0/8"]`, value)
+
+	empty, err := models.IPAllowlist(nil).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "[]", empty)
+
+	var scanned models.IPAllowlist
+	require.NoError(t, scanned.Scan([]byte(`["10.0.0.0/8","2001:db8::/32"]`)))
+	assert.Equal(t, models.IPAllowlist{"10.0.0.0/8", "2001:db8::/32"}, scanned)
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/jobs"
+)
+
+// TestJobTracker tests progress reporting of bulk operations by job ID
+func TestJobTracker(t *testing.T) {
+	t.Run("reports progress while running", func(t *testing.T) {
+		tracker := jobs.NewTracker(time.Hour)
+		job, err := tracker.Start("job-1", "bulk_create", "admin-1", 4)
+		require.NoError(t, err)
+
+		job.Advance(1, 0, 1)
+		job.Advance(1, 0, 0)
+
+		progress, ok := tracker.Get("job-1")
+		require.True(t, ok)
+		assert.Equal(t, jobs.StatusRunning, progress.Status)
+		assert.Equal(t, "bulk_create", progress.Operation)
+		assert.Equal(t, "admin-1", progress.StartedBy)
+		assert.Equal(t, 4, progress.Total)
+		assert.Equal(t, 2, progress.Processed)
+		assert.Equal(t, 0, progress.Succeeded)
+		assert.Equal(t, 1, progress.Failed)
+		assert.Equal(t, 50, progress.Percent)
+		assert.Nil(t, progress.FinishedAt)
+
+		job.Advance(2, 0, 0)
+		job.Advance(0, 3, 0)
+		job.Finish(nil)
+
+		progress, ok = tracker.Get("job-1")
+		require.True(t, ok)
+		assert.Equal(t, jobs.StatusCompleted, progress.Status)
+		assert.Equal(t, 3, progress.Succeeded)
+		assert.Equal(t, 100, progress.Percent)
+		assert.NotNil(t, progress.FinishedAt)
+	})
+
+	t.Run("records failure", func(t *testing.T) {
+		tracker := jobs.NewTracker(time.Hour)
+		job, err := tracker.Start("job-1", "bulk_delete", "admin-1", 2)
+		require.NoError(t, err)
+
+		job.Finish(errors.New("database unavailable"))
+
+		progress, ok := tracker.Get("job-1")
+		require.True(t, ok)
+		assert.Equal(t, jobs.StatusFailed, progress.Status)
+		assert.Equal(t, "database unavailable", progress.Error)
+	})
+
+	t.Run("rejects IDs in use", func(t *testing.T) {
+		tracker := jobs.NewTracker(time.Hour)
+		job, err := tracker.Start("job-1", "import", "admin-1", 1)
+		require.NoError(t, err)
+		assert.Equal(t, "job-1", job.ID())
+
+		_, err = tracker.Start("job-1", "import", "admin-2", 1)
+		assert.ErrorIs(t, err, jobs.ErrJobExists)
+	})
+
+	t.Run("drops finished jobs after retention", func(t *testing.T) {
+		tracker := jobs.NewTracker(-time.Second)
+		job, err := tracker.Start("job-1", "import", "admin-1", 1)
+		require.NoError(t, err)
+
+		_, ok := tracker.Get("job-1")
+		assert.True(t, ok, "running jobs are kept")
+
+		job.Finish(nil)
+		_, ok = tracker.Get("job-1")
+		assert.False(t, ok)
+
+		_, err = tracker.Start("job-1", "import", "admin-1", 1)
+		assert.NoError(t, err, "ID of a dropped job can be reused")
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		tracker := jobs.NewTracker(time.Hour)
+		_, ok := tracker.Get("missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("nil job ignores progress", func(t *testing.T) {
+		var job *jobs.Job
+		job.Advance(1, 1, 0)
+		job.Finish(nil)
+		assert.Equal(t, "", job.ID())
+	})
+}
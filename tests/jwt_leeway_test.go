@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/utils"
+)
+
+// signTestToken signs an access token for secret valid from notBefore to expiresAt
+func signTestToken(t *testing.T, secret string, notBefore, expiresAt time.Time) string {
+	claims := &models.JWTClaims{
+		UserID: uuid.New(),
+		Email:  "user@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{string(models.AccessToken)},
+			NotBefore: jwt.NewNumericDate(notBefore),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(notBefore),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+// TestJWTLeeway tests that token validation tolerates the configured clock skew
+func TestJWTLeeway(t *testing.T) {
+	const secret = "test-secret-key"
+	now := time.Now()
+	strict := utils.NewJWTManager(secret, time.Hour, 0)
+	tolerant := utils.NewJWTManager(secret, time.Hour, 30*time.Second)
+
+	t.Run("recently expired token", func(t *testing.T) {
+		token := signTestToken(t, secret, now.Add(-time.Hour), now.Add(-10*time.Second))
+
+		_, err := strict.ValidateToken(token)
+		assert.Error(t, err)
+
+		claims, err := tolerant.ValidateToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, "user@example.com", claims.Email)
+	})
+
+	t.Run("token from a clock running ahead", func(t *testing.T) {
+		token := signTestToken(t, secret, now.Add(10*time.Second), now.Add(time.Hour))
+
+		_, err := strict.ValidateToken(token)
+		assert.Error(t, err)
+
+		_, err = tolerant.ValidateToken(token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired past the leeway", func(t *testing.T) {
+		token := signTestToken(t, secret, now.Add(-time.Hour), now.Add(-time.Minute))
+
+		_, err := tolerant.ValidateToken(token)
+		assert.Error(t, err)
+	})
+
+	t.Run("claims validity", func(t *testing.T) {
+		claims := &models.JWTClaims{
+			UserID: uuid.New(),
+			Email:  "user@example.com",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(now.Add(-10 * time.Second)),
+			},
+		}
+		assert.False(t, claims.IsValid(0))
+		assert.True(t, claims.IsValid(30*time.Second))
+
+		claims.ExpiresAt = nil
+		assert.False(t, claims.IsValid(30*time.Second), "tokens without expiry are rejected")
+	})
+}
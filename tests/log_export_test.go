@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/internal/warehouse"
+)
+
+// exportLogs is a UserLogRepository serving a fixed set of logs for export
+type exportLogs struct {
+	repository.UserLogRepository
+	logs     []models.UserLog // In (timestamp, ID) order
+	pipeline repository.LogPipelineStatus
+}
+
+func (r *exportLogs) PipelineStatus() repository.LogPipelineStatus {
+	return r.pipeline
+}
+
+func (r *exportLogs) ListForExport(ctx context.Context, afterTimestamp time.Time, afterID string, before time.Time, limit int) ([]models.UserLog, error) {
+	var logs []models.UserLog
+	for _, entry := range r.logs {
+		after := entry.Timestamp.After(afterTimestamp) ||
+			(entry.Timestamp.Equal(afterTimestamp) && entry.ID.Hex() > afterID)
+		if after && entry.Timestamp.Before(before) && len(logs) < limit {
+			logs = append(logs, entry)
+		}
+	}
+	return logs, nil
+}
+
+// memoryCheckpoints is an in-memory LogExportRepository
+type memoryCheckpoints struct {
+	checkpoints map[string]models.LogExportCheckpoint
+}
+
+func (r *memoryCheckpoints) GetCheckpoint(ctx context.Context, target string) (*models.LogExportCheckpoint, error) {
+	checkpoint, ok := r.checkpoints[target]
+	if !ok {
+		return nil, nil
+	}
+	return &checkpoint, nil
+}
+
+func (r *memoryCheckpoints) SaveCheckpoint(ctx context.Context, checkpoint *models.LogExportCheckpoint) error {
+	r.checkpoints[checkpoint.Target] = *checkpoint
+	return nil
+}
+
+// fakeClickHouse records the rows inserted through the HTTP interface
+type fakeClickHouse struct {
+	mutex   sync.Mutex
+	queries []string
+	tokens  []string
+	ids     []string
+	fail    bool
+}
+
+func (f *fakeClickHouse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query().Get("query")
+	if query == "" {
+		query = string(body)
+	}
+	f.queries = append(f.queries, query)
+	if !strings.HasPrefix(query, "INSERT") {
+		return
+	}
+	if f.fail {
+		http.Error(w, "Code: 241. DB::Exception: Memory limit exceeded", http.StatusInternalServerError)
+		return
+	}
+	f.tokens = append(f.tokens, r.URL.Query().Get("insert_deduplication_token"))
+	for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+		var row warehouse.Row
+		if json.Unmarshal([]byte(line), &row) == nil {
+			f.ids = append(f.ids, row.ID)
+		}
+	}
+}
+
+// TestExportLogsResumesFromCheckpoint tests that runs send each log once,
+// stop at max_batches and resume where the previous run stopped
+func TestExportLogsResumesFromCheckpoint(t *testing.T) {
+	clickhouse := &fakeClickHouse{}
+	server := httptest.NewServer(clickhouse)
+	defer server.Close()
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	logs := &exportLogs{}
+	for i := 0; i < 5; i++ {
+		logs.logs = append(logs.logs, models.UserLog{
+			ID:        primitive.NewObjectID(),
+			Event:     models.UserLogin,
+			Timestamp: start.Add(time.Duration(i/2) * time.Second), // Pairs share a timestamp
+		})
+	}
+	logs.logs = append(logs.logs, models.UserLog{ID: primitive.NewObjectID(), Timestamp: time.Now()}) // Not settled
+
+	checkpoints := &memoryCheckpoints{checkpoints: map[string]models.LogExportCheckpoint{}}
+	cfg := &config.Config{LogExport: config.LogExportConfig{
+		Enabled:            true,
+		Provider:           config.WarehouseClickHouse,
+		Table:              "audit_logs",
+		BatchSize:          2,
+		MaxBatches:         2,
+		SettleDelay:        time.Minute,
+		ClickHouseURL:      server.URL,
+		ClickHouseDatabase: "analytics",
+	}}
+	rm, err := repository.NewRepositoryManagerWithRepos(cfg, &repository.Repository{Log: logs, LogExport: checkpoints})
+	require.NoError(t, err)
+
+	// Nothing is sent while the pipeline has entries spooled
+	logs.pipeline.SpooledBytes = 512
+	require.NoError(t, rm.ExportLogs(t.Context()))
+	assert.Empty(t, clickhouse.ids)
+	logs.pipeline.SpooledBytes = 0
+
+	require.NoError(t, rm.ExportLogs(t.Context()))
+	assert.Len(t, clickhouse.ids, 4)
+	assert.Contains(t, clickhouse.queries[0], "CREATE TABLE IF NOT EXISTS `analytics`.`audit_logs`")
+	assert.Contains(t, clickhouse.queries[0], "PARTITION BY toYYYYMMDD(timestamp)")
+
+	// A failed insert leaves the checkpoint where it was
+	clickhouse.fail = true
+	assert.Error(t, rm.ExportLogs(t.Context()))
+	assert.EqualValues(t, 4, checkpoints.checkpoints["clickhouse:analytics.audit_logs"].ExportedRows)
+
+	clickhouse.fail = false
+	require.NoError(t, rm.ExportLogs(t.Context()))
+	require.NoError(t, rm.ExportLogs(t.Context()))
+
+	var want []string
+	for _, entry := range logs.logs[:5] {
+		want = append(want, entry.ID.Hex())
+	}
+	assert.Equal(t, want, clickhouse.ids)
+	assert.Len(t, clickhouse.tokens, 3)
+	assert.NotEqual(t, clickhouse.tokens[0], clickhouse.tokens[1])
+}
+
+// TestBigQueryWarehouse tests table creation and merged inserts
+func TestBigQueryWarehouse(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("access-token\n"), 0o600))
+
+	type queryRequest struct {
+		Query           string `json:"query"`
+		QueryParameters []struct {
+			Name           string `json:"name"`
+			ParameterValue struct {
+				Value       string `json:"value"`
+				ArrayValues []struct {
+					StructValues map[string]map[string]string `json:"structValues"`
+				} `json:"arrayValues"`
+			} `json:"parameterValue"`
+		} `json:"queryParameters"`
+	}
+	var queries []queryRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/bigquery/v2/projects/acme/datasets/analytics/tables":
+			var table map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&table))
+			assert.Equal(t, map[string]interface{}{"type": "DAY", "field": "timestamp"}, table["timePartitioning"])
+			http.Error(w, `{"error": {"code": 409, "message": "Already Exists"}}`, http.StatusConflict)
+		case "/bigquery/v2/projects/acme/queries":
+			var request queryRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			queries = append(queries, request)
+			if len(queries) > 1 {
+				w.Write([]byte(`{"jobComplete": false}`))
+				return
+			}
+			w.Write([]byte(`{"jobComplete": true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	bq := warehouse.NewBigQuery(server.URL, "acme", "analytics", tokenFile, 5*time.Second)
+	assert.Equal(t, "bigquery:acme.analytics.audit_logs", bq.Target("audit_logs"))
+	require.NoError(t, bq.EnsureTable(t.Context(), "audit_logs"))
+
+	userID := "0b6a2f4e-8f57-4c52-9d36-6a1e5bb41d52"
+	row := warehouse.NewRow(models.UserLog{
+		ID:        primitive.NewObjectID(),
+		UserID:    &userID,
+		Event:     models.UserUpdated,
+		Data:      models.LogData{Action: "UPDATE_USER", NewValues: map[string]interface{}{"name": "Jane"}},
+		Timestamp: time.Now(),
+	})
+	system := warehouse.NewRow(models.UserLog{ID: primitive.NewObjectID(), Event: models.SystemStartup, Timestamp: time.Now()})
+	require.NoError(t, bq.Insert(t.Context(), "audit_logs", "token", []warehouse.Row{row, system}))
+	require.Len(t, queries, 1)
+
+	// Rows are merged on their ID, so a batch sent again adds nothing
+	assert.Contains(t, queries[0].Query, "MERGE `acme.analytics.audit_logs` AS target")
+	assert.Contains(t, queries[0].Query, "ON target.id = source.id")
+	assert.Contains(t, queries[0].Query, "WHEN NOT MATCHED THEN INSERT ROW")
+	require.Len(t, queries[0].QueryParameters, 3)
+	rows := queries[0].QueryParameters[0].ParameterValue.ArrayValues
+	require.Len(t, rows, 2)
+	assert.Equal(t, row.ID, rows[0].StructValues["id"]["value"])
+	assert.Equal(t, `{"name":"Jane"}`, rows[0].StructValues["new_values"]["value"])
+	assert.Equal(t, "false", rows[0].StructValues["batch_summary"]["value"])
+	assert.NotContains(t, rows[1].StructValues["user_id"], "value") // NULL
+
+	assert.ErrorContains(t, bq.Insert(t.Context(), "audit_logs", "token", []warehouse.Row{row}), "did not merge")
+}
+
+// TestLogExportConfigValidate tests log export configuration checks
+func TestLogExportConfigValidate(t *testing.T) {
+	valid := config.LogExportConfig{
+		Enabled:            true,
+		Provider:           config.WarehouseClickHouse,
+		Table:              "audit_logs",
+		Interval:           time.Minute,
+		BatchSize:          100,
+		MaxBatches:         10,
+		ClickHouseURL:      "http://localhost:8123",
+		ClickHouseDatabase: "default",
+	}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, config.LogExportConfig{}.Validate())
+
+	injected := valid
+	injected.Table = "logs; DROP TABLE users"
+	assert.Error(t, injected.Validate())
+
+	bigquery := valid
+	bigquery.Provider = config.WarehouseBigQuery
+	assert.Error(t, bigquery.Validate())
+	bigquery.BigQueryProject, bigquery.BigQueryDataset = "acme", "analytics"
+	assert.NoError(t, bigquery.Validate())
+}
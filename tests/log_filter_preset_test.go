@@ -0,0 +1,26 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"user_mgmt_go/internal/models"
+)
+
+// TestLogFilterPresetURL tests that a preset links to the filtered Logs page
+func TestLogFilterPresetURL(t *testing.T) {
+	assert.Equal(t, "/admin/logs?", models.LogFilterPreset{Name: "All"}.LogsPageURL())
+
+	event := models.UserDeleted
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	preset := models.LogFilterPreset{Name: "Deletions", Event: &event, UserID: &userID, StartDate: &start}
+
+	assert.Equal(t,
+		"/admin/logs?event=USER_DELETED&start_date=2024-03-01T00%3A00%3A00Z&user_id=550e8400-e29b-41d4-a716-446655440000",
+		preset.LogsPageURL(),
+	)
+}
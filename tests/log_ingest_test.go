@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/models"
+)
+
+// TestLogIngestion tests that batches from trusted services are validated
+// as a whole, land in the log pipeline and are refused while it is degraded
+func TestLogIngestion(t *testing.T) {
+	stack := newHandlerStack(t, &config.Config{LogIngest: config.LogIngestConfig{
+		Enabled:           true,
+		MaxEvents:         3,
+		MaxBodyBytes:      4096,
+		MaxEventAge:       time.Hour,
+		RequestsPerMinute: 600,
+		Burst:             100,
+	}})
+	admin := stack.addUser(t, models.RoleAdmin)
+	adminToken := stack.token(t, admin)
+
+	happened := time.Now().Add(-time.Minute).UTC()
+	batch := map[string]interface{}{
+		"source": "billing-service",
+		"events": []map[string]interface{}{
+			{"event": "USER_UPDATED", "action": "UPDATE_BILLING_ADDRESS", "user_id": admin.ID, "timestamp": happened},
+			{"event": "LOGIN_FAILED", "action": "INVOICE_PORTAL_LOGIN", "ip_address": "203.0.113.7"},
+		},
+	}
+
+	// Only admins may submit
+	w := stack.do(t, http.MethodPost, "/api/admin/logs/bulk", stack.token(t, stack.addUser(t, models.RoleUser)), batch)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = stack.do(t, http.MethodPost, "/api/admin/logs/bulk", adminToken, batch)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var accepted models.LogIngestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &accepted))
+	assert.Equal(t, 2, accepted.Accepted)
+
+	var ingested []*models.UserLog
+	stack.logs.mutex.Lock()
+	for _, entry := range stack.logs.entries {
+		if entry.BatchID == accepted.BatchID {
+			ingested = append(ingested, entry)
+		}
+	}
+	stack.logs.mutex.Unlock()
+	require.Len(t, ingested, 2)
+	assert.Equal(t, "UPDATE_BILLING_ADDRESS", ingested[0].Data.Action)
+	// Entries are stored at receipt so checkpointed readers still see them;
+	// the source's time is kept alongside
+	require.NotNil(t, ingested[0].OccurredAt)
+	assert.True(t, happened.Equal(*ingested[0].OccurredAt))
+	assert.True(t, ingested[0].Timestamp.After(happened))
+	assert.Nil(t, ingested[1].OccurredAt)
+	assert.Equal(t, "billing-service", ingested[0].Data.Details["ingest_source"])
+	assert.Equal(t, admin.ID.String(), ingested[1].Data.Details["ingested_by"])
+
+	// One invalid event rejects the whole batch, naming the event
+	invalid := map[string]interface{}{
+		"source": "billing-service",
+		"events": []map[string]interface{}{
+			{"event": "USER_UPDATED", "action": "UPDATE_BILLING_ADDRESS"},
+			{"event": "SYSTEM_STARTUP", "action": "FORGED_STARTUP"},
+			{"event": "USER_UPDATED", "action": "OLD_EVENT", "timestamp": time.Now().Add(-2 * time.Hour)},
+		},
+	}
+	w = stack.do(t, http.MethodPost, "/api/admin/logs/bulk", adminToken, invalid)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var rejected struct {
+		Details struct {
+			Events []models.LogIngestEventError `json:"events"`
+		} `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rejected))
+	require.Len(t, rejected.Details.Events, 2)
+	assert.Equal(t, 1, rejected.Details.Events[0].Index)
+	assert.Equal(t, 2, rejected.Details.Events[1].Index)
+
+	// Size limits
+	tooMany := map[string]interface{}{"source": "billing-service", "events": make([]map[string]interface{}, 4)}
+	w = stack.do(t, http.MethodPost, "/api/admin/logs/bulk", adminToken, tooMany)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	tooLarge := map[string]interface{}{
+		"source": "billing-service",
+		"events": []map[string]interface{}{
+			{"event": "USER_UPDATED", "action": "UPDATE_NOTES", "details": map[string]string{"notes": strings.Repeat("x", 5000)}},
+		},
+	}
+	w = stack.do(t, http.MethodPost, "/api/admin/logs/bulk", adminToken, tooLarge)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Back pressure while the pipeline is degraded
+	stack.logs.mutex.Lock()
+	stack.logs.pipeline.Degraded = true
+	stack.logs.mutex.Unlock()
+	w = stack.do(t, http.MethodPost, "/api/admin/logs/bulk", adminToken, batch)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+}
+
+func TestLogIngestConfigValidate(t *testing.T) {
+	cfg := config.LogIngestConfig{
+		Enabled:           true,
+		MaxEvents:         750,
+		MaxBodyBytes:      1024 * 1024,
+		MaxEventAge:       time.Hour,
+		RequestsPerMinute: 60,
+		Burst:             10,
+	}
+	assert.NoError(t, cfg.Validate())
+
+	// A batch must fit in the share of the log queue open to submitters
+	cfg.MaxEvents = 751
+	assert.Error(t, cfg.Validate())
+
+	assert.NoError(t, config.LogIngestConfig{}.Validate())
+}
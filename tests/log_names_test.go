@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"testing"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestSetUserLogNames tests naming log actors and targets from one user lookup
+func TestSetUserLogNames(t *testing.T) {
+	admin := &models.User{ID: uuid.New(), Name: "Admin", Email: "admin@example.com"}
+	target := &models.User{ID: uuid.New(), Name: "Target", Email: "target@example.com"}
+	deleted := uuid.New()
+
+	logs := []models.UserLogResponse{
+		{
+			UserID: &admin.ID,
+			Data: models.LogData{Details: map[string]interface{}{
+				"deleted_user_id": target.ID.String(),
+			}},
+		},
+		{
+			UserID: &admin.ID,
+			Data: models.LogData{Details: map[string]interface{}{
+				"target_user_id": primitive.Binary{Data: target.ID[:]},
+			}},
+		},
+		{
+			UserID: &deleted,
+			Data:   models.LogData{Details: map[string]interface{}{"ip_address": "10.0.0.1"}},
+		},
+		{},
+	}
+
+	ids := models.UserLogUserIDs(logs)
+	assert.ElementsMatch(t, []uuid.UUID{admin.ID, target.ID, deleted}, ids)
+
+	models.SetUserLogNames(logs, map[uuid.UUID]*models.User{
+		admin.ID:  admin,
+		target.ID: target,
+	})
+
+	assert.Equal(t, "Admin", logs[0].ActorName)
+	assert.Equal(t, "admin@example.com", logs[0].ActorEmail)
+	assert.Equal(t, "Target", logs[0].TargetName)
+	assert.Equal(t, "target@example.com", logs[0].TargetEmail)
+	assert.Equal(t, &target.ID, logs[0].TargetUserID)
+	assert.Equal(t, "Target", logs[1].TargetName)
+	assert.Empty(t, logs[2].ActorName, "users missing from the lookup stay unnamed")
+	assert.Nil(t, logs[2].TargetUserID)
+	assert.Empty(t, logs[3].ActorName)
+}
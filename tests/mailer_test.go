@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"user_mgmt_go/internal/mailer"
+	"user_mgmt_go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMailer records sent messages
+type fakeMailer struct {
+	sent chan mailer.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.sent <- msg
+	return nil
+}
+
+// TestAccountChangeMessage tests that account notices list changed fields
+// with values redacted as for non-admin log viewers
+func TestAccountChangeMessage(t *testing.T) {
+	policy := models.LogRedactionPolicy{"email": models.RedactionMaskEmail}
+	notifier := mailer.NewAccountNotifier(&fakeMailer{}, "support@example.com", policy)
+
+	user := &models.User{ID: uuid.New(), Name: "Jane", Email: "jane@example.com"}
+	entry := models.NewUserLog(models.UserLogCreateRequest{
+		Event:     models.UserUpdated,
+		Action:    "UPDATE_USER",
+		OldValues: map[string]interface{}{"email": "jane@example.com", "password": "[REDACTED]"},
+		NewValues: map[string]interface{}{"email": "jane@example.org", "password": "[REDACTED]"},
+	})
+
+	msg := notifier.Message(user, mailer.AccountUpdated, entry)
+	assert.Equal(t, "jane@example.com", msg.To)
+	assert.Equal(t, "Your account was updated", msg.Subject)
+	assert.Contains(t, msg.Body, "Hello Jane")
+	assert.Contains(t, msg.Body, "  - email: j***@example.com -> j***@example.org\n")
+	assert.Contains(t, msg.Body, "  - password: [REDACTED] -> [REDACTED]\n")
+	assert.Contains(t, msg.Body, "please contact support@example.com.")
+	assert.NotContains(t, msg.Body, "jane@example.org")
+}
+
+// TestAccountChangedSendsNotice tests that notices are sent in the
+// background and skipped for users without an email address
+func TestAccountChangedSendsNotice(t *testing.T) {
+	fake := &fakeMailer{sent: make(chan mailer.Message, 1)}
+	notifier := mailer.NewAccountNotifier(fake, "", nil)
+
+	notifier.AccountChanged(&models.User{ID: uuid.New()}, mailer.AccountDeactivated, nil)
+	notifier.AccountChanged(&models.User{ID: uuid.New(), Name: "Jane", Email: "jane@example.com"}, mailer.AccountRestored, nil)
+
+	select {
+	case msg := <-fake.sent:
+		assert.Equal(t, "jane@example.com", msg.To)
+		assert.Equal(t, "Your account was restored", msg.Subject)
+		assert.Contains(t, msg.Body, "please contact your administrator.")
+	case <-time.After(time.Second):
+		require.Fail(t, "notice was not sent")
+	}
+}
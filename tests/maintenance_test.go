@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/maintenance"
+	"user_mgmt_go/internal/notifier"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMaintenanceConfig() config.MaintenanceConfig {
+	return config.MaintenanceConfig{
+		Windows: []config.MaintenanceWindowConfig{
+			{Start: "2024-06-10T22:00:00Z", End: "2024-06-11T02:00:00Z", Message: "Second"},
+			{Start: "2024-06-01T22:00:00Z", End: "2024-06-02T02:00:00Z", Message: "First"},
+		},
+		InternalNetworks: []string{"10.0.0.0/8"},
+		BannerLeadTime:   24 * time.Hour,
+	}
+}
+
+// TestMaintenanceSchedule tests active and upcoming window lookups
+func TestMaintenanceSchedule(t *testing.T) {
+	schedule, err := maintenance.NewSchedule(testMaintenanceConfig())
+	require.NoError(t, err)
+
+	during := time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC)
+	require.NotNil(t, schedule.Active(during))
+	assert.Equal(t, "First", schedule.Active(during).Message)
+	assert.True(t, schedule.RelaxRateLimits("10.1.2.3", during))
+	assert.False(t, schedule.RelaxRateLimits("203.0.113.7", during))
+
+	banner := schedule.Banner(during)
+	assert.True(t, banner.Active)
+	assert.False(t, banner.Upcoming)
+
+	before := time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC)
+	assert.False(t, schedule.InMaintenance(before))
+	assert.False(t, schedule.RelaxRateLimits("10.1.2.3", before))
+	banner = schedule.Banner(before)
+	assert.True(t, banner.Upcoming)
+	require.NotNil(t, banner.Window)
+	assert.Equal(t, "Second", banner.Window.Message)
+
+	// Windows further ahead than the lead time are not announced
+	assert.Equal(t, maintenance.Banner{}, schedule.Banner(time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)))
+
+	// The end of a window is exclusive
+	assert.False(t, schedule.InMaintenance(time.Date(2024, 6, 2, 2, 0, 0, 0, time.UTC)))
+
+	var none *maintenance.Schedule
+	assert.False(t, none.InMaintenance(during))
+	assert.Equal(t, maintenance.Banner{}, none.Banner(during))
+}
+
+// TestMaintenanceConfigValidate tests rejecting malformed windows and networks
+func TestMaintenanceConfigValidate(t *testing.T) {
+	assert.NoError(t, testMaintenanceConfig().Validate())
+	assert.NoError(t, config.MaintenanceConfig{}.Validate())
+
+	badTime := testMaintenanceConfig()
+	badTime.Windows[0].Start = "tomorrow"
+	assert.Error(t, badTime.Validate())
+
+	reversed := testMaintenanceConfig()
+	reversed.Windows[0].Start, reversed.Windows[0].End = reversed.Windows[0].End, reversed.Windows[0].Start
+	assert.Error(t, reversed.Validate())
+
+	badNetwork := testMaintenanceConfig()
+	badNetwork.InternalNetworks = []string{"10.0.0.1"}
+	assert.Error(t, badNetwork.Validate())
+}
+
+// recordingNotifier records delivered alerts
+type recordingNotifier struct {
+	alerts []notifier.Alert
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, alert notifier.Alert) error {
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+// TestMutedNotifier tests that alerts are dropped during maintenance and
+// reported as muted, so callers do not count them as delivered
+func TestMutedNotifier(t *testing.T) {
+	schedule, err := maintenance.NewSchedule(testMaintenanceConfig())
+	require.NoError(t, err)
+
+	next := &recordingNotifier{}
+	muted := notifier.Muted(next, schedule.InMaintenance)
+
+	assert.ErrorIs(t, muted.Notify(context.Background(), notifier.Alert{
+		Title: "During",
+		Time:  time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC),
+	}), notifier.ErrMuted)
+	require.NoError(t, muted.Notify(context.Background(), notifier.Alert{
+		Title: "After",
+		Time:  time.Date(2024, 6, 2, 3, 0, 0, 0, time.UTC),
+	}))
+
+	require.Len(t, next.alerts, 1)
+	assert.Equal(t, "After", next.alerts[0].Title)
+}
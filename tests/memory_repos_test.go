@@ -0,0 +1,436 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+)
+
+// In-memory repositories for handler tests. Each embeds its repository
+// interface, left nil, so a method a test reaches without an in-memory
+// implementation panics and fails the test instead of silently passing.
+
+// memoryUsers is an in-memory UserRepository with soft deletion
+type memoryUsers struct {
+	repository.UserRepository
+
+	mutex sync.Mutex
+	users map[uuid.UUID]*models.User
+}
+
+func newMemoryUsers() *memoryUsers {
+	return &memoryUsers{users: make(map[uuid.UUID]*models.User)}
+}
+
+func (r *memoryUsers) Create(ctx context.Context, user *models.User) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Email == user.Email {
+			return fmt.Errorf("user with email %s: %w", user.Email, repository.ErrEmailTaken)
+		}
+	}
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+func (r *memoryUsers) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, fmt.Errorf("user with ID %s: %w", id, repository.ErrUserNotFound)
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *memoryUsers) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.User, error) {
+	users := make(map[uuid.UUID]*models.User, len(ids))
+	for _, id := range ids {
+		if user, err := r.GetByID(ctx, id); err == nil {
+			users[id] = user
+		}
+	}
+	return users, nil
+}
+
+func (r *memoryUsers) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, user := range r.users {
+		if user.Email == email && !user.DeletedAt.Valid {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, fmt.Errorf("user with email %s: %w", email, repository.ErrUserNotFound)
+}
+
+// Update applies the columns the handlers update
+func (r *memoryUsers) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return fmt.Errorf("user with ID %s: %w", id, repository.ErrUserNotFound)
+	}
+	for column, value := range updates {
+		switch column {
+		case "name":
+			user.Name = value.(string)
+		case "email":
+			user.Email = value.(string)
+		case "password":
+			user.Password = value.(string)
+		case "role":
+			user.Role = value.(string)
+		case "must_change_password":
+			user.MustChangePassword = value.(bool)
+		case "profile":
+			user.Profile = value.(models.Profile)
+		default:
+			return fmt.Errorf("memoryUsers: updating %s is not supported", column)
+		}
+	}
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *memoryUsers) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return fmt.Errorf("user with ID %s: %w", id, repository.ErrUserNotFound)
+	}
+	if user.DeletedAt.Valid {
+		return fmt.Errorf("user with ID %s: %w", id, repository.ErrUserAlreadyDeleted)
+	}
+	user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (r *memoryUsers) PermanentDelete(ctx context.Context, id uuid.UUID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return fmt.Errorf("user with ID %s: %w", id, repository.ErrUserNotFound)
+	}
+	delete(r.users, id)
+	return nil
+}
+
+// ListIDsByEmailDomain lists users, soft-deleted ones included, oldest first
+func (r *memoryUsers) ListIDsByEmailDomain(ctx context.Context, domain string, createdBefore time.Time, limit int) ([]uuid.UUID, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var users []*models.User
+	for _, user := range r.users {
+		email := strings.ToLower(user.Email)
+		if (strings.HasSuffix(email, "@"+domain) || strings.HasSuffix(email, "."+domain)) && user.CreatedAt.Before(createdBefore) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.Before(users[j].CreatedAt) })
+
+	ids := make([]uuid.UUID, 0, min(limit, len(users)))
+	for _, user := range users[:min(limit, len(users))] {
+		ids = append(ids, user.ID)
+	}
+	return ids, nil
+}
+
+func (r *memoryUsers) Exists(ctx context.Context, email string) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryUsers) Count(ctx context.Context, filter repository.UserFilter) (int64, error) {
+	return int64(len(r.matching("", filter))), nil
+}
+
+// ListFiltered matches the query against names and emails and sorts by
+// creation time, newest first
+func (r *memoryUsers) ListFiltered(ctx context.Context, query string, filter repository.UserFilter, params repository.ListParams) (*models.UsersListResponse, error) {
+	params.SetDefaults()
+	users := r.matching(query, filter)
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.After(users[j].CreatedAt) })
+
+	total := int64(len(users))
+	start := min(params.GetOffset(), len(users))
+	end := min(start+params.GetLimit(), len(users))
+
+	responses := make([]models.UserResponse, 0, end-start)
+	for _, user := range users[start:end] {
+		responses = append(responses, user.ToResponse())
+	}
+	return &models.UsersListResponse{
+		Users:      responses,
+		Total:      total,
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		TotalPages: repository.CalculateTotalPages(total, params.PageSize),
+	}, nil
+}
+
+// matching returns copies of the active users matching the query and filter
+func (r *memoryUsers) matching(query string, filter repository.UserFilter) []models.User {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	query = strings.ToLower(query)
+	var users []models.User
+	for _, user := range r.users {
+		if user.DeletedAt.Valid ||
+			(filter.Role != "" && user.Role != filter.Role) ||
+			(filter.Region != "" && user.Region != filter.Region) ||
+			(query != "" && !strings.Contains(strings.ToLower(user.Name), query) && !strings.Contains(strings.ToLower(user.Email), query)) {
+			continue
+		}
+		users = append(users, *user)
+	}
+	return users
+}
+
+// memoryLogs is an in-memory UserLogRepository recording the entries written
+type memoryLogs struct {
+	repository.UserLogRepository
+
+	mutex    sync.Mutex
+	entries  []*models.UserLog
+	pipeline repository.LogPipelineStatus
+}
+
+func (r *memoryLogs) Create(ctx context.Context, logEntry *models.UserLog) error {
+	return r.CreateAsync(logEntry)
+}
+
+func (r *memoryLogs) CreateAsync(logEntry *models.UserLog) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entries = append(r.entries, logEntry)
+	return nil
+}
+
+// CreateAsyncBatch refuses batches while the pipeline is marked degraded
+func (r *memoryLogs) CreateAsyncBatch(logs []*models.UserLog) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.pipeline.Degraded {
+		return repository.ErrLogPipelineBusy
+	}
+	r.entries = append(r.entries, logs...)
+	return nil
+}
+
+func (r *memoryLogs) BulkCreate(ctx context.Context, logs []*models.UserLog) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entries = append(r.entries, logs...)
+	return nil
+}
+
+// DeleteByUserIDs deletes the entries the users acted in or were the target
+// of, with target IDs logged as strings or UUID values
+func (r *memoryLogs) DeleteByUserIDs(ctx context.Context, userIDs []uuid.UUID) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	deleted := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		deleted[id.String()] = true
+	}
+	involves := func(entry *models.UserLog) bool {
+		if entry.UserID != nil && deleted[*entry.UserID] {
+			return true
+		}
+		for _, key := range models.TargetUserDetailKeys {
+			if deleted[fmt.Sprint(entry.Data.Details[key])] {
+				return true
+			}
+		}
+		return false
+	}
+	kept := r.entries[:0]
+	for _, entry := range r.entries {
+		if !involves(entry) {
+			kept = append(kept, entry)
+		}
+	}
+	count := int64(len(r.entries) - len(kept))
+	r.entries = kept
+	return count, nil
+}
+
+func (r *memoryLogs) PipelineStatus() repository.LogPipelineStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.pipeline
+}
+
+// events returns the event types logged so far, oldest first, leaving out
+// the per-request entries of the request logging middleware
+func (r *memoryLogs) events() []models.LogEventType {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	events := []models.LogEventType{}
+	for _, entry := range r.entries {
+		if entry.Data.Action != "HTTP_REQUEST" {
+			events = append(events, entry.Event)
+		}
+	}
+	return events
+}
+
+// memorySessions is an in-memory SessionRepository
+type memorySessions struct {
+	mutex    sync.Mutex
+	sessions map[uuid.UUID]*models.Session
+}
+
+func newMemorySessions() *memorySessions {
+	return &memorySessions{sessions: make(map[uuid.UUID]*models.Session)}
+}
+
+func (r *memorySessions) Create(ctx context.Context, session *models.Session) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *session
+	r.sessions[session.ID] = &stored
+	return nil
+}
+
+func (r *memorySessions) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("session %s: %w", id, repository.ErrSessionNotFound)
+	}
+	found := *session
+	return &found, nil
+}
+
+func (r *memorySessions) Touch(ctx context.Context, id uuid.UUID, ipAddress string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if session, ok := r.sessions[id]; ok {
+		session.LastActivityAt = time.Now()
+		session.IPAddress = ipAddress
+	}
+	return nil
+}
+
+func (r *memorySessions) ListActive(ctx context.Context, role string) ([]models.Session, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var sessions []models.Session
+	for _, session := range r.sessions {
+		if session.IsActive() && (role == "" || session.Role == role) {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *memorySessions) Revoke(ctx context.Context, id uuid.UUID, revokedBy uuid.UUID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return fmt.Errorf("session %s: %w", id, repository.ErrSessionNotFound)
+	}
+	now := time.Now()
+	session.RevokedAt, session.RevokedBy = &now, &revokedBy
+	return nil
+}
+
+func (r *memorySessions) RevokeAllForUser(ctx context.Context, userID uuid.UUID, revokedBy uuid.UUID) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var revoked int64
+	now := time.Now()
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt, session.RevokedBy = &now, &revokedBy
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (r *memorySessions) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var deleted int64
+	for id, session := range r.sessions {
+		if session.ExpiresAt.Before(before) {
+			delete(r.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// memoryIdentities is an IdentityRepository with no linked identities
+type memoryIdentities struct {
+	repository.IdentityRepository
+}
+
+func (memoryIdentities) ListForUser(ctx context.Context, userID uuid.UUID) ([]models.UserIdentity, error) {
+	return nil, nil
+}
+
+// memoryProfileFields is a ProfileFieldRepository with no required fields
+type memoryProfileFields struct {
+	repository.ProfileFieldRepository
+}
+
+func (memoryProfileFields) ListRequired(ctx context.Context) ([]models.RequiredProfileField, error) {
+	return nil, nil
+}
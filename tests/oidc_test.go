@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/oidc"
+)
+
+// TestOIDCProvider tests token issuance and verification with the published JWKS
+func TestOIDCProvider(t *testing.T) {
+	provider, err := oidc.NewProvider(config.OIDCConfig{
+		Issuer:      "https://users.example.com/",
+		CodeExpiry:  time.Minute,
+		TokenExpiry: time.Hour,
+		Clients: []config.OIDCClientConfig{
+			{ID: "app", Secret: "secret", RedirectURIs: []string{"https://app.example.com/callback"}},
+		},
+	})
+	require.NoError(t, err)
+
+	discovery := provider.Discovery()
+	assert.Equal(t, "https://users.example.com", discovery.Issuer)
+	assert.Equal(t, "https://users.example.com/oauth2/token", discovery.TokenEndpoint)
+
+	client, ok := provider.Client("app")
+	require.True(t, ok)
+	assert.True(t, oidc.ValidRedirectURI(client, "https://app.example.com/callback"))
+	assert.False(t, oidc.ValidRedirectURI(client, "https://evil.example.com/callback"))
+	assert.True(t, oidc.AuthenticateClient(client, "secret"))
+	assert.False(t, oidc.AuthenticateClient(client, "wrong"))
+
+	user := &models.User{ID: uuid.New(), Name: "Jane Doe", Email: "jane@example.com"}
+	tokens, err := provider.IssueTokens(user, &models.OIDCAuthorizationCode{
+		ClientID: "app",
+		Scope:    "openid email",
+		Nonce:    "n-0S6",
+		AuthTime: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", tokens.TokenType)
+	assert.Equal(t, 3600, tokens.ExpiresIn)
+
+	// Verify the ID token with the key from the JWKS, as a relying party would
+	jwks := provider.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	modulus, err := base64.RawURLEncoding.DecodeString(jwks.Keys[0].Modulus)
+	require.NoError(t, err)
+	exponent, err := base64.RawURLEncoding.DecodeString(jwks.Keys[0].Exponent)
+	require.NoError(t, err)
+	publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(new(big.Int).SetBytes(exponent).Int64())}
+
+	claims := &oidc.IDTokenClaims{}
+	token, err := jwt.ParseWithClaims(tokens.IDToken, claims, func(*jwt.Token) (interface{}, error) {
+		return publicKey, nil
+	}, jwt.WithAudience("app"), jwt.WithIssuer("https://users.example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, jwks.Keys[0].KeyID, token.Header["kid"])
+	assert.Equal(t, user.ID.String(), claims.Subject)
+	assert.Equal(t, "n-0S6", claims.Nonce)
+	assert.Equal(t, "jane@example.com", claims.Email)
+	assert.Empty(t, claims.Name, "name requires the profile scope")
+
+	// Access tokens are accepted by the userinfo endpoint, ID tokens are not
+	accessClaims, err := provider.ValidateAccessToken(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "app", accessClaims.ClientID)
+	_, err = provider.ValidateAccessToken(tokens.IDToken)
+	assert.Error(t, err)
+}
+
+// TestOIDCCodeChallenge tests PKCE verification and code hashing
+func TestOIDCCodeChallenge(t *testing.T) {
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	assert.True(t, oidc.VerifyCodeChallenge(challenge, oidc.ChallengeMethodS256, verifier))
+	assert.False(t, oidc.VerifyCodeChallenge(challenge, oidc.ChallengeMethodS256, "wrong"))
+	assert.True(t, oidc.VerifyCodeChallenge("plain-value", oidc.ChallengeMethodPlain, "plain-value"))
+	assert.True(t, oidc.VerifyCodeChallenge("", "", ""))
+	assert.False(t, oidc.VerifyCodeChallenge("", "", "unexpected"))
+
+	code, hash, err := oidc.NewAuthorizationCode()
+	require.NoError(t, err)
+	assert.NotEqual(t, code, hash)
+	assert.Equal(t, hash, oidc.HashAuthorizationCode(code))
+}
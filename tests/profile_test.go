@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"testing"
+
+	"user_mgmt_go/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidProfileField(t *testing.T) {
+	assert.True(t, models.IsValidProfileField("department"))
+	assert.True(t, models.IsValidProfileField("phone_2"))
+	assert.False(t, models.IsValidProfileField(""))
+	assert.False(t, models.IsValidProfileField("Department"))
+	assert.False(t, models.IsValidProfileField("2fa"))
+	assert.False(t, models.IsValidProfileField("job-title"))
+}
+
+func TestProfileMissing(t *testing.T) {
+	profile := models.Profile{"department": "Sales", "phone": ""}
+
+	assert.Equal(t, []string{"phone", "title"}, profile.Missing([]string{"department", "phone", "title"}))
+	assert.Empty(t, profile.Missing([]string{"department"}))
+	assert.Empty(t, profile.Missing(nil))
+}
+
+func TestProfileValueScan(t *testing.T) {
+	value, err := models.Profile{"department": "Sales"}.Value()
+	require.NoError(t, err)
+
+	var scanned models.Profile
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, "Sales", scanned["department"])
+
+	var empty models.Profile
+	require.NoError(t, empty.Scan(nil))
+	assert.NotNil(t, empty)
+}
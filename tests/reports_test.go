@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/notifier"
+	"user_mgmt_go/internal/reports"
+	"user_mgmt_go/internal/repository"
+)
+
+// growthUsers is a UserRepository reporting fixed daily creations and
+// deletions
+type growthUsers struct {
+	repository.UserRepository
+	calls int
+}
+
+func (r *growthUsers) CountCreatedAndDeletedByDay(ctx context.Context, from time.Time, days int) ([]int64, []int64, error) {
+	r.calls++
+	created, deleted := make([]int64, days), make([]int64, days)
+	for day := range created {
+		created[day] = int64(day + 1)
+		deleted[day] = 1
+	}
+	return created, deleted, nil
+}
+
+// TestRenderReport tests rendering a widget to CSV and PDF
+func TestRenderReport(t *testing.T) {
+	widget := &models.StatsWidget{
+		StatsWidgetInfo: models.StatsWidgetInfo{Title: "User Growth (Weekly)", Type: models.StatsWidgetLine},
+		Days:            2,
+		Labels:          []string{"2024-02-12", "2024-02-13"},
+		Datasets: []models.StatsWidgetDataset{
+			{Label: "Created", Data: []float64{3, 5}},
+			{Label: "Deleted", Data: []float64{1, 0}},
+		},
+		GeneratedAt: time.Date(2024, 2, 14, 0, 0, 0, 0, time.UTC),
+	}
+
+	csv, err := reports.Render(widget, reports.FormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, "Date,Created,Deleted\n2024-02-12,3,1\n2024-02-13,5,0\n", string(csv))
+
+	pdf, err := reports.Render(widget, reports.FormatPDF)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(pdf), "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(string(pdf), "%%EOF\n"))
+	assert.Contains(t, string(pdf), "(User Growth \\(Weekly\\)) '")
+
+	_, err = reports.Render(widget, "xlsx")
+	assert.Error(t, err)
+
+	now := time.Date(2024, 2, 14, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2024-W07", reports.PeriodKey(reports.Weekly, now))
+	assert.Equal(t, "2024-02", reports.PeriodKey(reports.Monthly, now))
+	assert.True(t, reports.IsValidFileName("2024-W07.pdf"))
+	assert.False(t, reports.IsValidFileName("../2024-02.csv"))
+}
+
+// TestReportsConfigValidate tests validation of the report schedules
+func TestReportsConfigValidate(t *testing.T) {
+	valid := config.ReportSchedule{Name: "weekly_growth", Widget: "user_growth", Every: "weekly", Days: 7, Formats: []string{"csv"}}
+	cfg := config.ReportsConfig{Enabled: true, CheckInterval: time.Hour, Schedules: []config.ReportSchedule{valid}}
+	assert.NoError(t, cfg.Validate())
+
+	invalid := map[string]func(s *config.ReportSchedule){
+		"name":    func(s *config.ReportSchedule) { s.Name = "Weekly Growth" },
+		"every":   func(s *config.ReportSchedule) { s.Every = "daily" },
+		"days":    func(s *config.ReportSchedule) { s.Days = 365 },
+		"formats": func(s *config.ReportSchedule) { s.Formats = []string{"xlsx"} },
+	}
+	for field, modify := range invalid {
+		schedule := valid
+		modify(&schedule)
+		cfg.Schedules = []config.ReportSchedule{schedule}
+		assert.Error(t, cfg.Validate(), field)
+	}
+
+	cfg.Schedules = []config.ReportSchedule{valid, valid}
+	assert.Error(t, cfg.Validate(), "duplicate name")
+}
+
+// TestGenerateDueReports tests that due reports are stored, delivered
+// through the notifier and generated once per period
+func TestGenerateDueReports(t *testing.T) {
+	var alerts []notifier.Alert
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert notifier.Alert
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		alerts = append(alerts, alert)
+	}))
+	defer webhook.Close()
+
+	dir := t.TempDir()
+	users := &growthUsers{}
+	cfg := &config.Config{
+		Storage:  config.StorageConfig{Path: dir},
+		Notifier: config.NotifierConfig{WebhookURL: webhook.URL, Timeout: time.Second},
+		Reports: config.ReportsConfig{Enabled: true, CheckInterval: time.Hour, Schedules: []config.ReportSchedule{
+			{Name: "weekly_growth", Widget: "user_growth", Every: "weekly", Days: 7, Formats: []string{"csv", "pdf"}},
+		}},
+	}
+	repoManager, err := repository.NewRepositoryManagerWithRepos(cfg, &repository.Repository{User: users})
+	require.NoError(t, err)
+
+	require.NoError(t, repoManager.GenerateDueReports(context.Background()))
+	period := reports.PeriodKey(reports.Weekly, time.Now())
+	csv, err := os.ReadFile(filepath.Join(dir, "reports", "weekly_growth", period+".csv"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(csv), "Date,Created,Deleted,Net change\n"))
+	assert.FileExists(t, filepath.Join(dir, "reports", "weekly_growth", period+".pdf"))
+
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "scheduled_report", alerts[0].Kind)
+	assert.Equal(t, period, alerts[0].Details["period"])
+	assert.Contains(t, alerts[0].Details["downloads"], "/api/admin/reports/weekly_growth/"+period+".pdf")
+
+	// Generated once per period
+	require.NoError(t, repoManager.GenerateDueReports(context.Background()))
+	assert.Equal(t, 1, users.calls)
+	assert.Len(t, alerts, 1)
+
+	list, err := repoManager.Reports(context.Background())
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, period, list[0].Period)
+	assert.Len(t, list[0].Files, 2)
+
+	_, _, err = repoManager.OpenReport(context.Background(), "other", period+".csv")
+	assert.ErrorIs(t, err, repository.ErrReportNotFound)
+	_, _, err = repoManager.OpenReport(context.Background(), "weekly_growth", "2000-01.csv")
+	assert.ErrorIs(t, err, repository.ErrReportNotFound)
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"user_mgmt_go/internal/repository"
+)
+
+// TestRepositoryErrorKinds tests that typed repository errors match their
+// generic kind, also when wrapped, while staying distinct from each other
+func TestRepositoryErrorKinds(t *testing.T) {
+	kinds := map[error][]error{
+		repository.ErrNotFound: {
+			repository.ErrUserNotFound,
+			repository.ErrSessionNotFound,
+			repository.ErrRoleChangeNotFound,
+			repository.ErrLogFilterPresetNotFound,
+			repository.ErrSavedUserSearchNotFound,
+			repository.ErrIdentityNotFound,
+			repository.ErrAPIKeyNotFound,
+			repository.ErrLogNotFound,
+		},
+		repository.ErrDuplicate: {
+			repository.ErrIdentityAlreadyLinked,
+			repository.ErrEmailTaken,
+		},
+		repository.ErrConflict: {
+			repository.ErrUserAlreadyDeleted,
+			repository.ErrRoleChangeConflict,
+			repository.ErrRoleChangeNotPending,
+			repository.ErrRoleUnchanged,
+			repository.ErrAPIKeyInactive,
+		},
+	}
+
+	for kind, errs := range kinds {
+		for _, err := range errs {
+			wrapped := fmt.Errorf("record 42: %w", err)
+			assert.ErrorIs(t, wrapped, kind, err.Error())
+			assert.ErrorIs(t, wrapped, err)
+			for other := range kinds {
+				if other != kind {
+					assert.NotErrorIs(t, wrapped, other, err.Error())
+				}
+			}
+		}
+	}
+
+	t.Run("messages are unchanged", func(t *testing.T) {
+		assert.Equal(t, "user not found", repository.ErrUserNotFound.Error())
+		assert.Equal(t, "user with ID 42: user not found", fmt.Errorf("user with ID 42: %w", repository.ErrUserNotFound).Error())
+	})
+
+	t.Run("typed errors are distinct", func(t *testing.T) {
+		assert.False(t, errors.Is(repository.ErrUserNotFound, repository.ErrSessionNotFound))
+		assert.False(t, errors.Is(repository.ErrEmailTaken, repository.ErrIdentityAlreadyLinked))
+	})
+
+	t.Run("errors without a kind match none", func(t *testing.T) {
+		for kind := range kinds {
+			assert.NotErrorIs(t, repository.ErrQuotaExceeded, kind)
+			assert.NotErrorIs(t, repository.ErrRegionNotAllowed, kind)
+			assert.NotErrorIs(t, repository.ErrEmailDomainNotAllowed, kind)
+		}
+		assert.NotErrorIs(t, repository.ErrQuotaExceeded, repository.ErrForeignKey)
+	})
+}
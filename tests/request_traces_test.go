@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+)
+
+// TestTraceIDMiddleware tests that requests get the caller's trace ID or a
+// new one, returned in the response and recorded in the request log
+func TestTraceIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logs := &memoryLogs{}
+	userID := uuid.New()
+
+	router := gin.New()
+	router.Use(middleware.TraceIDMiddleware())
+	router.Use(middleware.RequestLoggingMiddleware(logs))
+	router.GET("/fail", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Status(http.StatusBadGateway)
+	})
+
+	send := func(header, value string) string {
+		req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Header().Get(middleware.TraceIDHeader)
+	}
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736",
+		send("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	assert.Equal(t, "support-ticket-1234", send(middleware.TraceIDHeader, "support-ticket-1234"))
+
+	// Invalid IDs are replaced with a new one
+	assert.Regexp(t, `^[0-9a-f]{32}$`, send("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"))
+	assert.Regexp(t, `^[0-9a-f]{32}$`, send(middleware.TraceIDHeader, "<script>"))
+	assert.NotEqual(t, send("", ""), send("", ""))
+
+	require.Len(t, logs.entries, 6)
+	sample := models.NewRequestSample(*logs.entries[0])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sample.TraceID)
+	assert.Equal(t, userID.String(), sample.UserID)
+	assert.Equal(t, "GET", sample.Method)
+	assert.Equal(t, "/fail", sample.Path)
+	assert.True(t, sample.Failed)
+	assert.Empty(t, logs.entries[0].UserID) // Kept out of the user's own activity
+}
+
+// TestTracingConfig tests trace link templates and their checks
+func TestTracingConfig(t *testing.T) {
+	tracing := config.TracingConfig{
+		URLTemplate:   "https://jaeger.example.com/trace/{trace_id}?uiFind={trace_id}",
+		SlowThreshold: time.Second,
+		SampleLimit:   100,
+	}
+	require.NoError(t, tracing.Validate())
+	assert.Equal(t, "https://jaeger.example.com/trace/abc?uiFind=abc", tracing.TraceURL("abc"))
+	assert.Empty(t, tracing.TraceURL(""))
+	assert.Empty(t, config.TracingConfig{}.TraceURL("abc"))
+
+	noPlaceholder := tracing
+	noPlaceholder.URLTemplate = "https://jaeger.example.com/search"
+	assert.Error(t, noPlaceholder.Validate())
+
+	script := tracing
+	script.URLTemplate = "javascript:alert('{trace_id}')"
+	assert.Error(t, script.Validate())
+
+	unlimited := tracing
+	unlimited.SampleLimit = 0
+	assert.Error(t, unlimited.Validate())
+}
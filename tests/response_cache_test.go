@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"user_mgmt_go/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResponseCache tests that metadata responses are served from memory
+// until invalidated
+func TestResponseCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cache := middleware.NewResponseCache(5 * time.Minute)
+	calls := 0
+	status := http.StatusOK
+
+	router := gin.New()
+	router.GET("/meta", cache.Public(), func(c *gin.Context) {
+		calls++
+		c.JSON(status, gin.H{"calls": calls})
+	})
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/meta", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("")
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.JSONEq(t, `{"calls":1}`, first.Body.String())
+	assert.Equal(t, "public, max-age=300", first.Header().Get("Cache-Control"))
+	assert.Contains(t, first.Header().Get("Content-Type"), "application/json")
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	second := get("")
+	assert.JSONEq(t, `{"calls":1}`, second.Body.String())
+	assert.Equal(t, 1, calls)
+
+	// Query strings share the route's entry
+	req := httptest.NewRequest(http.MethodGet, "/meta?nocache=123", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.JSONEq(t, `{"calls":1}`, w.Body.String())
+
+	notModified := get(etag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+
+	// Failures are passed through and never cached
+	cache.Invalidate()
+	status = http.StatusInternalServerError
+	failed := get("")
+	assert.Equal(t, http.StatusInternalServerError, failed.Code)
+	assert.JSONEq(t, `{"calls":2}`, failed.Body.String())
+	assert.Empty(t, failed.Header().Get("ETag"))
+
+	status = http.StatusOK
+	fresh := get("")
+	assert.JSONEq(t, `{"calls":3}`, fresh.Body.String())
+	assert.NotEqual(t, etag, fresh.Header().Get("ETag"))
+}
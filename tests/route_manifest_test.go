@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/handlers"
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/oidc"
+	"user_mgmt_go/internal/repository"
+	"user_mgmt_go/internal/utils"
+)
+
+// middlewareServedRoutes are documented routes answered by global
+// middleware rather than registered with gin
+var middlewareServedRoutes = map[string]bool{
+	"GET /health":       true,
+	"GET /health/ready": true,
+}
+
+// TestRouteManifest tests that the documented routes match the registered
+// routes and the auth middleware in front of them
+func TestRouteManifest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Directory: config.DirectoryConfig{Enabled: true},
+		LogIngest: config.LogIngestConfig{Enabled: true, RequestsPerMinute: 60, Burst: 10},
+		Reports:   config.ReportsConfig{Enabled: true},
+	}
+	jwtManager := utils.NewJWTManager("route-manifest-test-secret-0123456789", time.Hour, 0)
+	repoManager := &repository.RepositoryManager{Repos: &repository.Repository{}}
+	provider, err := oidc.NewProvider(config.OIDCConfig{
+		Issuer:      "https://users.example.com/",
+		CodeExpiry:  time.Minute,
+		TokenExpiry: time.Hour,
+	})
+	require.NoError(t, err)
+
+	middlewareManager := middleware.NewMiddlewareManager(cfg, jwtManager, repoManager)
+	handlerManager := handlers.NewHandlerManager(
+		jwtManager, repoManager, middlewareManager, models.LogRedactionPolicy{}, nil, nil, nil,
+		0, 0, provider, "", cfg.Directory, nil, nil, cfg.APIKeys, cfg,
+	)
+
+	router := gin.New()
+	handlerManager.SetupRoutes(router)
+	handlerManager.SetupDocumentationRoute(router)
+	require.NoError(t, handlerManager.BuildRouteManifest(router))
+
+	registered := make(map[string]handlers.RouteManifestEntry)
+	for _, entry := range handlerManager.RouteManifest() {
+		registered[entry.Method+" "+entry.Path] = entry
+	}
+	assert.Equal(t, middleware.AuthAdmin, registered["GET /api/users"].Auth)
+	assert.Equal(t, middleware.AuthSelfOrAdmin, registered["PUT /api/users/:id"].Auth)
+	assert.Equal(t, middleware.AuthPublic, registered["POST /api/auth/login"].Auth)
+	assert.Contains(t, registered["GET /api/users"].Handler, "ListUsers")
+
+	documented := make(map[string]bool)
+	for category, routes := range handlerManager.GetRouteSummary() {
+		for _, route := range routes {
+			key := route.Method + " " + route.Path
+			documented[key] = true
+			if middlewareServedRoutes[key] {
+				continue
+			}
+			entry, ok := registered[key]
+			if !assert.True(t, ok, "%s: documented route %s is not registered", category, key) {
+				continue
+			}
+			assert.Equal(t, route.Auth, entry.Auth, "%s: documented auth of %s does not match its middleware", category, key)
+		}
+	}
+
+	// Every API route is documented
+	for key, entry := range registered {
+		if len(entry.Path) >= 5 && entry.Path[:5] == "/api/" {
+			assert.True(t, documented[key], "API route %s is not documented", key)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/middleware"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+)
+
+// TestSandboxConfig tests sandbox membership, validation and purge times
+func TestSandboxConfig(t *testing.T) {
+	sandbox := config.SandboxConfig{Enabled: true, EmailDomain: "sandbox.example.com", PurgeHour: 3}
+	assert.NoError(t, sandbox.Validate())
+	assert.True(t, sandbox.Contains("test@sandbox.example.com"))
+	assert.True(t, sandbox.Contains("Test@EU.Sandbox.Example.com"))
+	assert.False(t, sandbox.Contains("test@example.com"))
+	assert.False(t, sandbox.Contains("test@notsandbox.example.com"))
+
+	assert.Equal(t, time.Date(2024, 2, 14, 3, 0, 0, 0, time.UTC), sandbox.LastPurge(time.Date(2024, 2, 14, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2024, 2, 13, 3, 0, 0, 0, time.UTC), sandbox.LastPurge(time.Date(2024, 2, 14, 2, 59, 0, 0, time.UTC)))
+
+	for _, invalid := range []config.SandboxConfig{
+		{Enabled: true},
+		{Enabled: true, EmailDomain: "@sandbox.example.com"},
+		{Enabled: true, EmailDomain: "sandbox_%.com"},
+		{Enabled: true, EmailDomain: "sandbox.example.com", PurgeHour: 24},
+	} {
+		assert.Error(t, invalid.Validate(), invalid.EmailDomain)
+	}
+
+	sandbox.Enabled = false
+	assert.False(t, sandbox.Contains("test@sandbox.example.com"))
+}
+
+// TestPurgeSandbox tests that only sandbox users created before the last
+// purge time are deleted, together with their logs
+func TestPurgeSandbox(t *testing.T) {
+	users := newMemoryUsers()
+	logs := &memoryLogs{}
+	cfg := &config.Config{Sandbox: config.SandboxConfig{Enabled: true, EmailDomain: "sandbox.example.com", PurgeHour: 3}}
+	repoManager, err := repository.NewRepositoryManagerWithRepos(cfg, &repository.Repository{User: users, Log: logs})
+	require.NoError(t, err)
+
+	create := func(email string, age time.Duration) *models.User {
+		user := &models.User{Name: "Test", Email: email}
+		require.NoError(t, users.Create(context.Background(), user))
+		users.users[user.ID].CreatedAt = time.Now().Add(-age)
+		userID := user.ID.String()
+		require.NoError(t, logs.CreateAsync(&models.UserLog{UserID: &userID, Event: models.LoginSuccess}))
+		return user
+	}
+	expired := create("old@sandbox.example.com", 48*time.Hour)
+	deleted := create("deleted@eu.sandbox.example.com", 48*time.Hour)
+	require.NoError(t, users.Delete(context.Background(), deleted.ID))
+	fresh := create("new@sandbox.example.com", 0)
+	regular := create("old@example.com", 48*time.Hour)
+	// Entries about a sandbox user written by someone else go too
+	adminID := uuid.NewString()
+	require.NoError(t, logs.CreateAsync(&models.UserLog{UserID: &adminID, Event: models.UserUpdated,
+		Data: models.LogData{Details: map[string]interface{}{"target_user_id": expired.ID}}}))
+	require.NoError(t, logs.CreateAsync(&models.UserLog{UserID: &adminID, Event: models.UserUpdated,
+		Data: models.LogData{Details: map[string]interface{}{"target_user_id": regular.ID.String()}}}))
+
+	require.NoError(t, repoManager.PurgeSandbox(context.Background()))
+
+	_, err = users.GetByID(context.Background(), expired.ID)
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+	assert.NotContains(t, users.users, deleted.ID)
+	assert.Contains(t, users.users, fresh.ID)
+	assert.Contains(t, users.users, regular.ID)
+
+	var remaining []string
+	var purged []interface{}
+	var summary *models.UserLog
+	for _, entry := range logs.entries {
+		switch {
+		case entry.Data.Action == "PERMANENT_DELETE_USER":
+			purged = append(purged, entry.Data.Details["permanently_deleted_user_id"])
+		case entry.Data.Action == "PURGE_SANDBOX_USERS":
+			summary = entry
+		case entry.UserID != nil && *entry.UserID != adminID:
+			remaining = append(remaining, *entry.UserID)
+		}
+	}
+	assert.ElementsMatch(t, []string{fresh.ID.String(), regular.ID.String()}, remaining)
+	assert.Len(t, logs.entries, 2+1+2+1)
+
+	// Each purged user is recorded like a permanent deletion, so the user
+	// changes feed reports it, linked to the summary
+	assert.ElementsMatch(t, []interface{}{expired.ID, deleted.ID}, purged)
+	require.NotNil(t, summary)
+	assert.True(t, summary.BatchSummary)
+	assert.EqualValues(t, 2, summary.Data.Details["users"])
+	for _, entry := range logs.entries {
+		if entry.Data.Action == "PERMANENT_DELETE_USER" {
+			assert.Equal(t, summary.BatchID, entry.BatchID)
+		}
+	}
+}
+
+// TestSandboxHeaders tests that responses to sandbox users are flagged
+func TestSandboxHeaders(t *testing.T) {
+	stack := newHandlerStack(t, &config.Config{Sandbox: config.SandboxConfig{Enabled: true, EmailDomain: "example.com", PurgeHour: 3}})
+	sandboxUser := stack.addUser(t, models.RoleUser)
+	regular := stack.addUser(t, models.RoleUser)
+	regular.Email = "regular-" + uuid.NewString()[:8] + "@example.org"
+	stack.users.users[regular.ID].Email = regular.Email
+
+	w := stack.do(t, http.MethodGet, "/api/auth/profile", stack.token(t, sandboxUser), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(middleware.SandboxHeader))
+	purgeAt, err := time.Parse(time.RFC3339, w.Header().Get(middleware.SandboxPurgeAtHeader))
+	require.NoError(t, err)
+	assert.True(t, purgeAt.After(time.Now()))
+	assert.Equal(t, 3, purgeAt.Hour())
+
+	w = stack.do(t, http.MethodGet, "/api/auth/profile", stack.token(t, regular), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Empty(t, w.Header().Get(middleware.SandboxHeader))
+
+	w = stack.do(t, http.MethodPost, "/api/auth/login", "", models.LoginRequest{Email: sandboxUser.Email, Password: stackPassword})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(middleware.SandboxHeader))
+	var login models.LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+	assert.True(t, login.Sandbox)
+}
@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"user_mgmt_go/internal/models"
+)
+
+// TestSavedUserSearchURL tests that a saved search links to the filtered Users page
+func TestSavedUserSearchURL(t *testing.T) {
+	assert.Equal(t, "/admin/users?", models.SavedUserSearch{Name: "All"}.UsersPageURL())
+
+	days := 7
+	search := models.SavedUserSearch{
+		Name:              "EU signups this week",
+		Search:            "example.com",
+		Region:            "eu",
+		CreatedWithinDays: &days,
+		SortBy:            "created_at",
+		SortDir:           "desc",
+	}
+
+	assert.Equal(t,
+		"/admin/users?created_within_days=7&region=eu&search=example.com&sort_by=created_at&sort_dir=desc",
+		search.UsersPageURL(),
+	)
+	assert.Equal(t, "eu", search.QueryValues().Get("region"))
+	assert.Empty(t, search.QueryValues().Get("role"))
+}
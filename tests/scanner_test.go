@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/config"
+	"user_mgmt_go/internal/scanner"
+)
+
+// fakeClamd serves one INSTREAM scan per connection, flagging uploads that
+// contain "EICAR"
+func fakeClamd(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				command := make([]byte, len("zINSTREAM\x00"))
+				if _, err := io.ReadFull(conn, command); err != nil {
+					return
+				}
+				var data []byte
+				size := make([]byte, 4)
+				for {
+					if _, err := io.ReadFull(conn, size); err != nil {
+						return
+					}
+					n := binary.BigEndian.Uint32(size)
+					if n == 0 {
+						break
+					}
+					chunk := make([]byte, n)
+					if _, err := io.ReadFull(conn, chunk); err != nil {
+						return
+					}
+					data = append(data, chunk...)
+				}
+				if strings.Contains(string(data), "EICAR") {
+					conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+				} else {
+					conn.Write([]byte("stream: OK\x00"))
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestClamAVScanner(t *testing.T) {
+	s := scanner.NewClamAV(fakeClamd(t), 5*time.Second)
+
+	verdict, err := s.Scan(context.Background(), "avatar.png", strings.NewReader("png-bytes"))
+	require.NoError(t, err)
+	assert.False(t, verdict.Infected)
+
+	verdict, err = s.Scan(context.Background(), "users.csv", strings.NewReader("name,email\nEICAR,x@example.com"))
+	require.NoError(t, err)
+	assert.True(t, verdict.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", verdict.Threat)
+	assert.Equal(t, config.ScannerClamAV, verdict.Scanner)
+}
+
+func TestHTTPScanner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer scan-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "users.csv", r.Header.Get("X-File-Name"))
+		body, _ := io.ReadAll(r.Body)
+		infected := strings.Contains(string(body), "EICAR")
+		json.NewEncoder(w).Encode(map[string]interface{}{"infected": infected, "threat": "test-threat"})
+	}))
+	defer server.Close()
+
+	s := scanner.NewHTTP(server.URL, "scan-token", 5*time.Second)
+	verdict, err := s.Scan(context.Background(), "users.csv", strings.NewReader("EICAR"))
+	require.NoError(t, err)
+	assert.True(t, verdict.Infected)
+	assert.Equal(t, "test-threat", verdict.Threat)
+
+	verdict, err = s.Scan(context.Background(), "users.csv", strings.NewReader("clean"))
+	require.NoError(t, err)
+	assert.False(t, verdict.Infected)
+}
+
+func TestScannerFailures(t *testing.T) {
+	// Nothing listens on a closed listener's address
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	_, err = scanner.New(config.ScannerConfig{Provider: config.ScannerClamAV, ClamAVAddress: address, Timeout: time.Second}).
+		Scan(context.Background(), "avatar.png", strings.NewReader("png-bytes"))
+	assert.Error(t, err, "fails closed by default")
+
+	verdict, err := scanner.New(config.ScannerConfig{Provider: config.ScannerClamAV, ClamAVAddress: address, Timeout: time.Second, FailOpen: true}).
+		Scan(context.Background(), "avatar.png", strings.NewReader("png-bytes"))
+	require.NoError(t, err)
+	assert.True(t, verdict.Unscanned)
+	assert.False(t, verdict.Infected)
+
+	assert.NoError(t, config.ScannerConfig{}.Validate())
+	assert.Error(t, config.ScannerConfig{Provider: config.ScannerClamAV}.Validate())
+	assert.Error(t, config.ScannerConfig{Provider: config.ScannerHTTP}.Validate())
+	assert.Error(t, config.ScannerConfig{Provider: "antivirus"}.Validate())
+}
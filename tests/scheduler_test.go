@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"user_mgmt_go/internal/scheduler"
+)
+
+// localLocks grants every lock, as for a single instance
+type localLocks struct{}
+
+func (localLocks) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	return func() {}, true, nil
+}
+
+// neverRun reports every job as never run
+type neverRun struct{}
+
+func (neverRun) LastRun(ctx context.Context, name string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (neverRun) RecordRun(ctx context.Context, name string, startedAt time.Time, duration time.Duration, runErr error) error {
+	return nil
+}
+
+// TestSchedulerStopCancelsRunningJobs tests that Stop does not wait for a
+// running job's timeout
+func TestSchedulerStopCancelsRunningJobs(t *testing.T) {
+	s := scheduler.New(localLocks{}, neverRun{})
+	started := make(chan struct{})
+	s.Register(scheduler.Job{
+		Name:     "slow",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	s.Start()
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Stop waited for the running job")
+	}
+}
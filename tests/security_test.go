@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"user_mgmt_go/internal/middleware"
+)
+
+// TestLoginLimiter tests account lockout after failed logins
+func TestLoginLimiter(t *testing.T) {
+	limiter := middleware.NewLoginLimiter(3, time.Minute, time.Minute)
+
+	status := limiter.Status("user@example.com")
+	assert.False(t, status.Locked)
+	assert.Equal(t, 3, status.AttemptsRemaining)
+
+	// Accounts are matched case-insensitively
+	status = limiter.RecordFailure("User@Example.com")
+	assert.Equal(t, 2, status.AttemptsRemaining)
+	assert.Equal(t, 2, status.Details()["attempts_remaining"])
+	assert.NotContains(t, status.Details(), "lock_expires_at")
+
+	limiter.RecordFailure("user@example.com")
+	status = limiter.RecordFailure("user@example.com")
+	assert.True(t, status.Locked)
+	assert.Equal(t, 0, status.AttemptsRemaining)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), status.LockExpiresAt, time.Second)
+
+	details := status.Details()
+	assert.Equal(t, 60, details["retry_after_seconds"])
+	assert.Equal(t, status.LockExpiresAt, details["lock_expires_at"])
+
+	// Other accounts are unaffected
+	assert.False(t, limiter.Status("other@example.com").Locked)
+
+	// A successful login clears the failures
+	limiter.Reset("user@example.com")
+	assert.Equal(t, 3, limiter.Status("user@example.com").AttemptsRemaining)
+
+	// Lockout can be disabled
+	disabled := middleware.NewLoginLimiter(0, time.Minute, time.Minute)
+	assert.False(t, disabled.Enabled())
+	assert.False(t, disabled.RecordFailure("user@example.com").Locked)
+}
+
+// TestRateLimiterReserve tests the retry delay reported by the rate limiter
+func TestRateLimiterReserve(t *testing.T) {
+	limiter := middleware.NewRateLimiter(time.Minute, 2)
+
+	allowed, _ := limiter.Reserve("10.0.0.1")
+	assert.True(t, allowed)
+	assert.True(t, limiter.Allow("10.0.0.1"))
+
+	allowed, retryAfter := limiter.Reserve("10.0.0.1")
+	assert.False(t, allowed)
+	assert.InDelta(t, time.Minute.Seconds(), retryAfter.Seconds(), 1)
+
+	assert.True(t, limiter.Allow("10.0.0.2"))
+}
+
+// TestRateLimiterStats tests the visitor and rejection counts reported by the rate limiter
+func TestRateLimiterStats(t *testing.T) {
+	limiter := middleware.NewRateLimiter(time.Minute, 2)
+
+	for i := 0; i < 5; i++ {
+		limiter.Allow("10.0.0.1")
+	}
+	for i := 0; i < 3; i++ {
+		limiter.Allow("10.0.0.2")
+	}
+	limiter.Allow("10.0.0.3")
+
+	stats := limiter.Stats(10)
+	assert.Equal(t, 3, stats.Visitors)
+	assert.Equal(t, 2, stats.ThrottledVisitors)
+	assert.Equal(t, int64(5), stats.Allowed)
+	assert.Equal(t, int64(4), stats.Rejected)
+	assert.InDelta(t, 4.0/9.0, stats.RejectionRate, 0.001)
+	assert.InDelta(t, 1.0, stats.RequestsPerMinute, 0.001)
+
+	if assert.Len(t, stats.TopThrottled, 2) {
+		assert.Equal(t, "10.0.0.1", stats.TopThrottled[0].Key)
+		assert.Equal(t, int64(3), stats.TopThrottled[0].Rejected)
+		assert.True(t, stats.TopThrottled[0].Throttled)
+		assert.Equal(t, "10.0.0.2", stats.TopThrottled[1].Key)
+	}
+
+	assert.Len(t, limiter.Stats(1).TopThrottled, 1)
+}
@@ -207,7 +207,7 @@ func TestHTTPRequest(t *testing.T) {
 
 // TestJWTManager tests JWT functionality with actual available methods
 func TestJWTManager(t *testing.T) {
-	jwtManager := utils.NewJWTManager("test-secret-key", time.Hour)
+	jwtManager := utils.NewJWTManager("test-secret-key", time.Hour, 0)
 
 	t.Run("JWT Manager Creation", func(t *testing.T) {
 		assert.NotNil(t, jwtManager)
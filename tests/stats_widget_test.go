@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/handlers"
+	"user_mgmt_go/internal/models"
+	"user_mgmt_go/internal/repository"
+)
+
+// TestStatsWidgetsAPI tests listing widgets and rejecting bad widget requests
+func TestStatsWidgetsAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repoManager := &repository.RepositoryManager{Repos: &repository.Repository{}}
+	handler := handlers.NewAdminHandler(nil, nil, repoManager, nil, nil, nil, nil)
+	router := gin.New()
+	router.GET("/api/admin/stats/widgets", handler.ListStatsWidgets)
+	router.GET("/api/admin/stats/widgets/:name", handler.GetStatsWidget)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/stats/widgets", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var widgets []models.StatsWidgetInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &widgets))
+
+	names := make([]string, len(widgets))
+	for i, widget := range widgets {
+		names[i] = widget.Name
+		assert.Positive(t, widget.RefreshSeconds, widget.Name)
+	}
+	assert.Equal(t, []string{"summary", "health", "log_pipeline", "event_distribution", "activity_timeline", "user_growth", "admin_activity"}, names)
+
+	for _, tc := range []struct {
+		path   string
+		status int
+	}{
+		{"/api/admin/stats/widgets/unknown", http.StatusNotFound},
+		{"/api/admin/stats/widgets/event_distribution?days=91", http.StatusBadRequest},
+		{"/api/admin/stats/widgets/activity_timeline?days=-1", http.StatusBadRequest},
+		{"/api/admin/stats/widgets/activity_timeline?days=week", http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		assert.Equal(t, tc.status, w.Code, tc.path)
+	}
+}
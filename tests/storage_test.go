@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/handlers"
+	"user_mgmt_go/internal/scanner"
+	"user_mgmt_go/internal/storage"
+)
+
+// TestLocalStorage tests the filesystem asset storage
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	object, err := store.Put(ctx, "avatars/a.png", strings.NewReader("png-bytes"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), object.Size)
+	assert.Equal(t, "image/png", object.ContentType)
+	assert.NotEmpty(t, object.ETag)
+
+	file, got, err := store.Get(ctx, "avatars/a.png")
+	require.NoError(t, err)
+	file.Close()
+	assert.Equal(t, object.ETag, got.ETag)
+
+	require.NoError(t, store.Delete(ctx, "avatars/a.png"))
+	_, _, err = store.Get(ctx, "avatars/a.png")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	_, err = store.Put(ctx, "../escape.png", strings.NewReader("x"))
+	assert.Error(t, err)
+}
+
+// TestServeAssetCaching tests cache headers and conditional requests on served assets
+func TestServeAssetCaching(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	_, err = store.Put(context.Background(), "avatars/a.png", strings.NewReader("png-bytes"))
+	require.NoError(t, err)
+
+	handler := handlers.NewAssetHandler(store, scanner.Nop{}, nil, nil, 1024, time.Hour)
+	router := gin.New()
+	router.GET("/assets/*key", handler.ServeAsset)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/assets/avatars/a.png", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "png-bytes", w.Body.String())
+	assert.Equal(t, "public, max-age=3600, immutable", w.Header().Get("Cache-Control"))
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/avatars/a.png", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/assets/other/a.png", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
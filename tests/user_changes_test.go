@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"user_mgmt_go/internal/handlers"
+	"user_mgmt_go/internal/repository"
+)
+
+// TestUserChangesCursor tests that cursors round-trip and malformed ones are rejected
+func TestUserChangesCursor(t *testing.T) {
+	changedAt := time.Date(2024, 3, 1, 12, 30, 15, 123456000, time.FixedZone("CET", 3600))
+	userID := uuid.New()
+
+	since, afterID, err := repository.DecodeUserChangesCursor(repository.EncodeUserChangesCursor(changedAt, userID))
+	require.NoError(t, err)
+	assert.True(t, since.Equal(changedAt))
+	assert.Equal(t, userID, afterID)
+
+	since, afterID, err = repository.DecodeUserChangesCursor("")
+	require.NoError(t, err)
+	assert.True(t, since.IsZero())
+	assert.Equal(t, uuid.Nil, afterID)
+
+	for _, cursor := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("2024-03-01T12:00:00Z")),
+		base64.RawURLEncoding.EncodeToString([]byte("yesterday|" + userID.String())),
+		base64.RawURLEncoding.EncodeToString([]byte("2024-03-01T12:00:00Z|not-a-uuid")),
+	} {
+		_, _, err := repository.DecodeUserChangesCursor(cursor)
+		assert.ErrorIs(t, err, repository.ErrInvalidChangesCursor, cursor)
+	}
+}
+
+// TestUserChangesAPIRejectsBadRequests tests that bad cursors and limits are client errors
+func TestUserChangesAPIRejectsBadRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repoManager := &repository.RepositoryManager{Repos: &repository.Repository{}}
+	handler := handlers.NewUserHandler(nil, nil, repoManager, nil)
+	router := gin.New()
+	router.GET("/api/users/changes", handler.GetUserChanges)
+
+	for _, query := range []string{"since=garbage", "limit=0", "limit=ten"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/changes?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}